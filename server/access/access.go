@@ -0,0 +1,125 @@
+// Package access implements server.Allower types that control which players
+// may join a server: BanList bans players by name or XUID, IPBanList bans IP
+// addresses and ranges, Whitelist only admits listed players and Maintenance
+// only admits players that may bypass it while enabled. They are combined into
+// a single server.Allower using Chain.
+//
+// Every list is backed by a JSON file, which is saved whenever the list is
+// changed and reloaded automatically when changed on disk, so that it may be
+// edited by hand or by other processes while the server is running. Lists may
+// be managed through their methods or using the optional commands registered
+// by Commands.Register.
+//
+//	bans, err := access.NewBanList("bans.json")
+//	...
+//	whitelist, err := access.NewWhitelist("whitelist.json")
+//	...
+//	conf.Allower = access.Chain{bans, whitelist}
+package access
+
+import (
+	"net"
+	"strings"
+
+	"github.com/df-mc/dragonfly/server"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/login"
+)
+
+// Chain is a server.Allower that combines multiple server.Allowers. A
+// connection is allowed if it is allowed by all of them. If one of them
+// disallows it, the message of the first to do so is returned.
+type Chain []server.Allower
+
+// Allow ...
+func (c Chain) Allow(addr net.Addr, d login.IdentityData, cd login.ClientData) (string, bool) {
+	for _, a := range c {
+		if msg, ok := a.Allow(addr, d, cd); !ok {
+			return msg, false
+		}
+	}
+	return "", true
+}
+
+// Enforce disconnects all players online on the server passed that are not
+// allowed by the server.Allower passed, as if they had just tried to join.
+// Enforce may be called after changing a list, for example after banning a
+// player, to apply the change to players already online. Players are
+// disconnected asynchronously, so Enforce may be called from within a
+// transaction.
+func Enforce(srv *server.Server, a server.Allower) {
+	go func() {
+		for p := range srv.Players(nil) {
+			d := login.IdentityData{DisplayName: p.Name(), XUID: p.XUID(), Identity: p.UUID().String()}
+			if msg, ok := a.Allow(p.Addr(), d, login.ClientData{}); !ok {
+				p.Disconnect(msg)
+			}
+		}
+	}()
+}
+
+// Entry identifies a player by name, XUID or both. Entries are used by
+// Whitelist and Maintenance.
+type Entry struct {
+	// Name is the name of the player. Names are compared case-insensitively.
+	Name string `json:"name,omitempty"`
+	// XUID is the XUID of the player. If not empty, the player is matched by
+	// XUID, so that the Entry is kept when the player changes its name.
+	XUID string `json:"xuid,omitempty"`
+}
+
+// matches checks if the Entry matches a player with the name and XUID passed.
+func (e Entry) matches(name, xuid string) bool {
+	if e.XUID != "" && xuid != "" {
+		return e.XUID == xuid
+	}
+	return e.Name != "" && strings.EqualFold(e.Name, name)
+}
+
+// matchesKey checks if the Entry has the name or XUID passed.
+func (e Entry) matchesKey(nameOrXUID string) bool {
+	return (e.XUID != "" && e.XUID == nameOrXUID) || (e.Name != "" && strings.EqualFold(e.Name, nameOrXUID))
+}
+
+// entryList is a list of Entries shared by Whitelist and Maintenance.
+type entryList []Entry
+
+// find returns the index of the Entry matching a player with the name and
+// XUID passed, or -1 if none matches.
+func (l entryList) find(name, xuid string) int {
+	for i, e := range l {
+		if e.matches(name, xuid) {
+			return i
+		}
+	}
+	return -1
+}
+
+// add adds the Entry passed, replacing any Entry with the same name or XUID.
+func (l entryList) add(e Entry) entryList {
+	return append(l.remove(e.Name).remove(e.XUID), e)
+}
+
+// remove removes all Entries with the name or XUID passed.
+func (l entryList) remove(nameOrXUID string) entryList {
+	if nameOrXUID == "" {
+		return l
+	}
+	n := l[:0]
+	for _, e := range l {
+		if !e.matchesKey(nameOrXUID) {
+			n = append(n, e)
+		}
+	}
+	return n
+}
+
+// learn sets the XUID of the Entry at index i to the XUID passed if it only
+// had a name, so that the Entry is kept when the player changes its name.
+// True is returned if the Entry was changed.
+func (l entryList) learn(i int, xuid string) bool {
+	if i < 0 || l[i].XUID != "" || xuid == "" {
+		return false
+	}
+	l[i].XUID = xuid
+	return true
+}
@@ -0,0 +1,339 @@
+package access
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sandertv/gophertunnel/minecraft/protocol/login"
+)
+
+// Ban is a ban of a player by name, XUID or both.
+type Ban struct {
+	// Name is the name of the banned player. Names are compared
+	// case-insensitively.
+	Name string `json:"name,omitempty"`
+	// XUID is the XUID of the banned player. If empty, it is set when the
+	// player first tries to join after being banned, so that the ban is kept
+	// when the player changes its name.
+	XUID string `json:"xuid,omitempty"`
+	// Reason is the reason for the ban, which is shown to the player.
+	Reason string `json:"reason,omitempty"`
+	// Source is the name of who issued the ban, such as the name of an
+	// operator.
+	Source string `json:"source,omitempty"`
+	// Created is the time at which the ban was issued.
+	Created time.Time `json:"created"`
+	// Expires is the time at which the ban expires. If zero, the ban never
+	// expires.
+	Expires time.Time `json:"expires,omitzero"`
+}
+
+// Expired checks if the Ban has expired at the time passed.
+func (b Ban) Expired(now time.Time) bool {
+	return !b.Expires.IsZero() && !now.Before(b.Expires)
+}
+
+// entry returns the Entry of the player banned.
+func (b Ban) entry() Entry {
+	return Entry{Name: b.Name, XUID: b.XUID}
+}
+
+// BanList is a server.Allower that disallows players banned by name or XUID.
+// A BanList is backed by a JSON file. It is safe for concurrent use.
+type BanList struct {
+	s *store[[]Ban]
+}
+
+// NewBanList opens the BanList backed by the JSON file at the path passed.
+// The file is created when the first ban is added. If path is empty, the
+// BanList is only held in memory.
+func NewBanList(path string) (*BanList, error) {
+	s, err := openStore[[]Ban](path)
+	if err != nil {
+		return nil, fmt.Errorf("open ban list: %w", err)
+	}
+	return &BanList{s: s}, nil
+}
+
+// Ban bans a player. Any existing ban of a player with the same name or XUID
+// is replaced. If b.Created is zero, it is set to the current time.
+func (l *BanList) Ban(b Ban) error {
+	if b.Name == "" && b.XUID == "" {
+		return fmt.Errorf("ban: name or xuid must be set")
+	}
+	if b.Created.IsZero() {
+		b.Created = time.Now()
+	}
+	return l.s.update(func(bans *[]Ban) bool {
+		*bans = append(pruneBans(*bans, b.Name, b.XUID), b)
+		return true
+	})
+}
+
+// Unban removes the ban of the player with the name or XUID passed. False is
+// returned if the player was not banned.
+func (l *BanList) Unban(nameOrXUID string) (bool, error) {
+	var removed bool
+	err := l.s.update(func(bans *[]Ban) bool {
+		n := len(*bans)
+		*bans = pruneBans(*bans, nameOrXUID)
+		removed = len(*bans) != n
+		return removed
+	})
+	return removed, err
+}
+
+// Banned returns the ban of a player with the name and XUID passed. False is
+// returned if the player is not banned or if its ban has expired.
+func (l *BanList) Banned(name, xuid string) (Ban, bool) {
+	var (
+		b  Ban
+		ok bool
+	)
+	l.s.view(func(bans *[]Ban) {
+		now := time.Now()
+		for _, ban := range *bans {
+			if !ban.Expired(now) && ban.entry().matches(name, xuid) {
+				b, ok = ban, true
+				return
+			}
+		}
+	})
+	return b, ok
+}
+
+// Bans returns all bans in the BanList that have not expired.
+func (l *BanList) Bans() []Ban {
+	var bans []Ban
+	l.s.view(func(v *[]Ban) {
+		now := time.Now()
+		for _, b := range *v {
+			if !b.Expired(now) {
+				bans = append(bans, b)
+			}
+		}
+	})
+	return bans
+}
+
+// Reload reloads the BanList from its file. Changes to the file are reloaded
+// automatically, so Reload only needs to be called to apply them immediately.
+func (l *BanList) Reload() error {
+	return l.s.Reload()
+}
+
+// Allow disallows players that are banned, with a message holding the reason
+// and expiry of the ban. If a player banned only by name joins, its XUID is
+// added to the ban. Expired bans are removed. Changes are saved without
+// waiting for the file to be written.
+func (l *BanList) Allow(_ net.Addr, d login.IdentityData, _ login.ClientData) (string, bool) {
+	var (
+		b  Ban
+		ok bool
+	)
+	l.s.updateAsync(func(bans *[]Ban) bool {
+		n := len(*bans)
+		*bans = pruneBans(*bans)
+		changed := len(*bans) != n
+		for i, ban := range *bans {
+			if ban.entry().matches(d.DisplayName, d.XUID) {
+				b, ok = ban, true
+				if ban.XUID == "" && d.XUID != "" {
+					(*bans)[i].XUID, changed = d.XUID, true
+				}
+				break
+			}
+		}
+		return changed
+	})
+	if ok {
+		return banMessage("You are banned from this server.", b.Reason, b.Expires), false
+	}
+	return "", true
+}
+
+// pruneBans removes expired bans and bans of players with any of the names or
+// XUIDs passed from the bans passed.
+func pruneBans(bans []Ban, keys ...string) []Ban {
+	now := time.Now()
+	return slices.DeleteFunc(bans, func(b Ban) bool {
+		return b.Expired(now) || slices.ContainsFunc(keys, func(k string) bool {
+			return k != "" && b.entry().matchesKey(k)
+		})
+	})
+}
+
+// IPBan is a ban of an IP address or a range of IP addresses.
+type IPBan struct {
+	// Prefix is the IP address or range of IP addresses banned. A single IP
+	// address is banned using a prefix holding all its bits, such as
+	// 203.0.113.7/32.
+	Prefix netip.Prefix `json:"prefix"`
+	// Reason is the reason for the ban, which is shown to the player.
+	Reason string `json:"reason,omitempty"`
+	// Source is the name of who issued the ban, such as the name of an
+	// operator.
+	Source string `json:"source,omitempty"`
+	// Created is the time at which the ban was issued.
+	Created time.Time `json:"created"`
+	// Expires is the time at which the ban expires. If zero, the ban never
+	// expires.
+	Expires time.Time `json:"expires,omitzero"`
+}
+
+// Expired checks if the IPBan has expired at the time passed.
+func (b IPBan) Expired(now time.Time) bool {
+	return !b.Expires.IsZero() && !now.Before(b.Expires)
+}
+
+// IPBanList is a server.Allower that disallows connections from banned IP
+// addresses and ranges. An IPBanList is backed by a JSON file. It is safe for
+// concurrent use.
+type IPBanList struct {
+	s *store[[]IPBan]
+}
+
+// NewIPBanList opens the IPBanList backed by the JSON file at the path
+// passed. The file is created when the first ban is added. If path is empty,
+// the IPBanList is only held in memory.
+func NewIPBanList(path string) (*IPBanList, error) {
+	s, err := openStore[[]IPBan](path)
+	if err != nil {
+		return nil, fmt.Errorf("open ip ban list: %w", err)
+	}
+	return &IPBanList{s: s}, nil
+}
+
+// Ban bans an IP address or range. Any existing ban of the same prefix is
+// replaced. If b.Created is zero, it is set to the current time.
+func (l *IPBanList) Ban(b IPBan) error {
+	if !b.Prefix.IsValid() {
+		return fmt.Errorf("ban ip: invalid prefix")
+	}
+	b.Prefix = unmapPrefix(b.Prefix)
+	if b.Created.IsZero() {
+		b.Created = time.Now()
+	}
+	return l.s.update(func(bans *[]IPBan) bool {
+		*bans = append(pruneIPBans(*bans, b.Prefix), b)
+		return true
+	})
+}
+
+// Unban removes the ban of the prefix passed. Bans of other prefixes holding
+// the prefix are kept. False is returned if the prefix was not banned.
+func (l *IPBanList) Unban(prefix netip.Prefix) (bool, error) {
+	prefix = unmapPrefix(prefix)
+	var removed bool
+	err := l.s.update(func(bans *[]IPBan) bool {
+		n := len(*bans)
+		*bans = pruneIPBans(*bans, prefix)
+		removed = len(*bans) != n
+		return removed
+	})
+	return removed, err
+}
+
+// Banned returns the ban holding the IP address passed. False is returned if
+// the address is not banned or if its ban has expired.
+func (l *IPBanList) Banned(addr netip.Addr) (IPBan, bool) {
+	addr = addr.Unmap()
+	var (
+		b  IPBan
+		ok bool
+	)
+	l.s.view(func(bans *[]IPBan) {
+		now := time.Now()
+		for _, ban := range *bans {
+			if !ban.Expired(now) && ban.Prefix.Contains(addr) {
+				b, ok = ban, true
+				return
+			}
+		}
+	})
+	return b, ok
+}
+
+// Bans returns all bans in the IPBanList that have not expired.
+func (l *IPBanList) Bans() []IPBan {
+	var bans []IPBan
+	l.s.view(func(v *[]IPBan) {
+		now := time.Now()
+		for _, b := range *v {
+			if !b.Expired(now) {
+				bans = append(bans, b)
+			}
+		}
+	})
+	return bans
+}
+
+// Reload reloads the IPBanList from its file. Changes to the file are
+// reloaded automatically, so Reload only needs to be called to apply them
+// immediately.
+func (l *IPBanList) Reload() error {
+	return l.s.Reload()
+}
+
+// Allow disallows connections from banned IP addresses, with a message
+// holding the reason and expiry of the ban. Connections of which the address
+// is not an IP address are always allowed.
+func (l *IPBanList) Allow(addr net.Addr, _ login.IdentityData, _ login.ClientData) (string, bool) {
+	ip, ok := addrIP(addr)
+	if !ok {
+		return "", true
+	}
+	if b, ok := l.Banned(ip); ok {
+		return banMessage("Your IP address is banned from this server.", b.Reason, b.Expires), false
+	}
+	return "", true
+}
+
+// pruneIPBans removes expired bans and bans of the prefix passed from the
+// bans passed.
+func pruneIPBans(bans []IPBan, prefix netip.Prefix) []IPBan {
+	now := time.Now()
+	return slices.DeleteFunc(bans, func(b IPBan) bool {
+		return b.Expired(now) || b.Prefix == prefix
+	})
+}
+
+// unmapPrefix returns the prefix passed with IPv4-mapped IPv6 addresses
+// converted to IPv4, so that they are matched against IPv4 addresses.
+func unmapPrefix(prefix netip.Prefix) netip.Prefix {
+	if !prefix.Addr().Is4In6() || prefix.Bits() < 96 {
+		return prefix.Masked()
+	}
+	return netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96).Masked()
+}
+
+// addrIP returns the IP address of the net.Addr passed. False is returned if
+// the address does not hold an IP address.
+func addrIP(addr net.Addr) (netip.Addr, bool) {
+	if addr == nil {
+		return netip.Addr{}, false
+	}
+	if ap, err := netip.ParseAddrPort(addr.String()); err == nil {
+		return ap.Addr().Unmap(), true
+	}
+	ip, err := netip.ParseAddr(addr.String())
+	return ip.Unmap(), err == nil
+}
+
+// banMessage returns the disconnect message of a ban with the reason and
+// expiry time passed.
+func banMessage(msg, reason string, expires time.Time) string {
+	var sb strings.Builder
+	sb.WriteString(msg)
+	if reason != "" {
+		sb.WriteString("\nReason: " + reason)
+	}
+	if !expires.IsZero() {
+		sb.WriteString("\nExpires: " + expires.Format(time.DateTime))
+	}
+	return sb.String()
+}
@@ -0,0 +1,144 @@
+package access
+
+import (
+	"net"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sandertv/gophertunnel/minecraft/protocol/login"
+)
+
+func TestBanList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bans.json")
+	l, err := NewBanList(path)
+	if err != nil {
+		t.Fatalf("NewBanList() = %v, want nil", err)
+	}
+	addr := &net.UDPAddr{IP: net.IPv4(203, 0, 113, 7), Port: 19132}
+
+	if err := l.Ban(Ban{}); err == nil {
+		t.Fatalf("Ban() without name or xuid = nil, want error")
+	}
+	if err := l.Ban(Ban{Name: "Steve", Reason: "Griefing"}); err != nil {
+		t.Fatalf("Ban() = %v, want nil", err)
+	}
+	msg, ok := l.Allow(addr, login.IdentityData{DisplayName: "steve", XUID: "123"}, login.ClientData{})
+	if ok || !strings.Contains(msg, "Griefing") {
+		t.Fatalf("Allow() of banned player = %q, %v, want message with reason, false", msg, ok)
+	}
+	// The XUID of the player was learned, so the ban holds after a name
+	// change.
+	if _, ok := l.Banned("Alex", "123"); !ok {
+		t.Fatalf("Banned() by learned xuid = false, want true")
+	}
+	if _, ok := l.Allow(addr, login.IdentityData{DisplayName: "Alex", XUID: "456"}, login.ClientData{}); !ok {
+		t.Fatalf("Allow() of player not banned = false, want true")
+	}
+
+	if err := l.Ban(Ban{Name: "Alex", Expires: time.Now().Add(-time.Minute)}); err != nil {
+		t.Fatalf("Ban() = %v, want nil", err)
+	}
+	if _, ok := l.Banned("Alex", ""); ok {
+		t.Fatalf("Banned() with expired ban = true, want false")
+	}
+	if bans := l.Bans(); len(bans) != 1 || bans[0].XUID != "123" {
+		t.Fatalf("Bans() = %v, want single ban with learned xuid", bans)
+	}
+
+	if removed, err := l.Unban("123"); err != nil || !removed {
+		t.Fatalf("Unban() = %v, %v, want true, nil", removed, err)
+	}
+	if removed, _ := l.Unban("123"); removed {
+		t.Fatalf("Unban() of player not banned = true, want false")
+	}
+	if _, ok := l.Banned("Steve", "123"); ok {
+		t.Fatalf("Banned() after Unban() = true, want false")
+	}
+}
+
+func TestBanListAllowSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bans.json")
+	l, err := NewBanList(path)
+	if err != nil {
+		t.Fatalf("NewBanList() = %v, want nil", err)
+	}
+	if err := l.Ban(Ban{Name: "Steve"}); err != nil {
+		t.Fatalf("Ban() = %v, want nil", err)
+	}
+	if err := l.Ban(Ban{Name: "Alex", Expires: time.Now().Add(-time.Minute)}); err != nil {
+		t.Fatalf("Ban() = %v, want nil", err)
+	}
+
+	// Expired bans are removed and the XUID learned right away, while the
+	// file is written in the background.
+	if _, ok := l.Allow(nil, login.IdentityData{DisplayName: "Steve", XUID: "123"}, login.ClientData{}); ok {
+		t.Fatalf("Allow() of banned player = true, want false")
+	}
+	l.s.view(func(bans *[]Ban) {
+		if len(*bans) != 1 || (*bans)[0].XUID != "123" {
+			t.Fatalf("bans after Allow() = %v, want single ban with learned xuid", *bans)
+		}
+	})
+	deadline := time.Now().Add(time.Second * 5)
+	for {
+		b, _ := os.ReadFile(path)
+		if strings.Contains(string(b), "123") && !strings.Contains(string(b), "Alex") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("file after Allow() = %s, want expired ban removed and xuid learned", b)
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	reopened, err := NewBanList(path)
+	if err != nil {
+		t.Fatalf("NewBanList() = %v, want nil", err)
+	}
+	if bans := reopened.Bans(); len(bans) != 1 {
+		t.Fatalf("Bans() after reopening = %v, want single ban", bans)
+	}
+}
+
+func TestIPBanList(t *testing.T) {
+	l, err := NewIPBanList("")
+	if err != nil {
+		t.Fatalf("NewIPBanList() = %v, want nil", err)
+	}
+	if err := l.Ban(IPBan{}); err == nil {
+		t.Fatalf("Ban() of invalid prefix = nil, want error")
+	}
+	if err := l.Ban(IPBan{Prefix: netip.MustParsePrefix("203.0.113.9/24")}); err != nil {
+		t.Fatalf("Ban() = %v, want nil", err)
+	}
+
+	tests := map[string]struct {
+		addr net.Addr
+		want bool
+	}{
+		"in range":       {addr: &net.UDPAddr{IP: net.IPv4(203, 0, 113, 7), Port: 19132}, want: false},
+		"ipv4 in ipv6":   {addr: &net.UDPAddr{IP: net.ParseIP("::ffff:203.0.113.200"), Port: 19132}, want: false},
+		"out of range":   {addr: &net.UDPAddr{IP: net.IPv4(203, 0, 114, 7), Port: 19132}, want: true},
+		"ipv6":           {addr: &net.UDPAddr{IP: net.ParseIP("2001:db8::1"), Port: 19132}, want: true},
+		"not ip address": {addr: &net.UnixAddr{Name: "socket", Net: "unix"}, want: true},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, ok := l.Allow(tc.addr, login.IdentityData{}, login.ClientData{}); ok != tc.want {
+				t.Fatalf("Allow(%v) = %v, want %v", tc.addr, ok, tc.want)
+			}
+		})
+	}
+
+	// Prefixes are masked when banned, so the ban is removed by the masked
+	// prefix.
+	if removed, err := l.Unban(netip.MustParsePrefix("203.0.113.0/24")); err != nil || !removed {
+		t.Fatalf("Unban() = %v, %v, want true, nil", removed, err)
+	}
+	if _, ok := l.Banned(netip.MustParseAddr("203.0.113.7")); ok {
+		t.Fatalf("Banned() after Unban() = true, want false")
+	}
+}
@@ -0,0 +1,487 @@
+package access
+
+import (
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/df-mc/dragonfly/server"
+	"github.com/df-mc/dragonfly/server/cmd"
+	"github.com/df-mc/dragonfly/server/player"
+	"github.com/df-mc/dragonfly/server/world"
+)
+
+// Commands registers commands to manage the lists of the access package.
+// Commands are only registered for lists that are not nil.
+type Commands struct {
+	// Bans is managed using /ban, /tempban, /unban and /banlist.
+	Bans *BanList
+	// IPBans is managed using /ban-ip, /tempban-ip, /unban-ip and
+	// /banlist.
+	IPBans *IPBanList
+	// Whitelist is managed using /whitelist.
+	Whitelist *Whitelist
+	// Maintenance is managed using /maintenance.
+	Maintenance *Maintenance
+	// Server is the server that the commands are registered on. If not nil,
+	// players online are disconnected when they are banned or when the
+	// Whitelist or Maintenance is enabled.
+	Server *server.Server
+	// Allow is called to check if a source may run the commands. If nil,
+	// players may not run them and only other sources, such as the console,
+	// may.
+	Allow func(src cmd.Source) bool
+}
+
+// Register registers the commands using cmd.Register.
+func (c Commands) Register() {
+	if c.Allow == nil {
+		c.Allow = func(src cmd.Source) bool {
+			_, ok := src.(*player.Player)
+			return !ok
+		}
+	}
+	base := command{c: c}
+	if c.Bans != nil {
+		cmd.Register(cmd.New("ban", "Bans a player from the server.", nil, ban{command: base}))
+		cmd.Register(cmd.New("tempban", "Bans a player from the server for a duration.", nil, tempBan{command: base}))
+		cmd.Register(cmd.New("unban", "Unbans a player.", []string{"pardon"}, unban{command: base}))
+	}
+	if c.IPBans != nil {
+		cmd.Register(cmd.New("ban-ip", "Bans an IP address or range from the server.", nil, banIP{command: base}))
+		cmd.Register(cmd.New("tempban-ip", "Bans an IP address or range from the server for a duration.", nil, tempBanIP{command: base}))
+		cmd.Register(cmd.New("unban-ip", "Unbans an IP address or range.", []string{"pardon-ip"}, unbanIP{command: base}))
+	}
+	if c.Bans != nil || c.IPBans != nil {
+		cmd.Register(cmd.New("banlist", "Lists all bans.", nil, banList{command: base}))
+	}
+	if c.Whitelist != nil {
+		cmd.Register(cmd.New("whitelist", "Manages the whitelist.", nil,
+			whitelistAdd{command: base}, whitelistRemove{command: base}, whitelistOn{command: base},
+			whitelistOff{command: base}, whitelistList{command: base}))
+	}
+	if c.Maintenance != nil {
+		cmd.Register(cmd.New("maintenance", "Manages the maintenance mode.", nil,
+			maintenanceOn{command: base}, maintenanceOff{command: base}, maintenanceBypass{command: base},
+			maintenanceRemove{command: base}))
+	}
+}
+
+// enforce disconnects players online that are not allowed by the
+// server.Allower passed if c.Server is not nil.
+func (c Commands) enforce(a server.Allower) {
+	if c.Server != nil {
+		Enforce(c.Server, a)
+	}
+}
+
+// command is embedded by all access commands. It limits the commands to the
+// sources allowed by Commands.Allow.
+type command struct {
+	c Commands
+}
+
+// Allow ...
+func (c command) Allow(src cmd.Source) bool {
+	return c.c.Allow(src)
+}
+
+// ban implements the /ban command.
+type ban struct {
+	command
+	Player string                    `cmd:"player"`
+	Reason cmd.Optional[cmd.Varargs] `cmd:"reason"`
+}
+
+// Run ...
+func (c ban) Run(src cmd.Source, o *cmd.Output, _ *world.Tx) {
+	c.ban(src, o, c.Player, c.Reason, 0)
+}
+
+// tempBan implements the /tempban command.
+type tempBan struct {
+	command
+	Player   string                    `cmd:"player"`
+	Duration string                    `cmd:"duration"`
+	Reason   cmd.Optional[cmd.Varargs] `cmd:"reason"`
+}
+
+// Run ...
+func (c tempBan) Run(src cmd.Source, o *cmd.Output, _ *world.Tx) {
+	d, err := parseDuration(c.Duration)
+	if err != nil {
+		o.Errorf("Invalid duration %v: %v", c.Duration, err)
+		return
+	}
+	c.ban(src, o, c.Player, c.Reason, d)
+}
+
+// ban bans the player passed for the duration d, or permanently if d is 0.
+func (c command) ban(src cmd.Source, o *cmd.Output, name string, reason cmd.Optional[cmd.Varargs], d time.Duration) {
+	b := Ban{Name: name, Reason: string(reason.LoadOr("")), Source: sourceName(src), Created: time.Now()}
+	if d > 0 {
+		b.Expires = b.Created.Add(d)
+	}
+	if err := c.c.Bans.Ban(b); err != nil {
+		o.Errorf("Could not ban %v: %v", name, err)
+		return
+	}
+	c.c.enforce(c.c.Bans)
+	o.Printf("Banned %v%v.", name, expiry(b.Expires))
+}
+
+// unban implements the /unban command.
+type unban struct {
+	command
+	Player string `cmd:"player"`
+}
+
+// Run ...
+func (c unban) Run(_ cmd.Source, o *cmd.Output, _ *world.Tx) {
+	removed, err := c.c.Bans.Unban(c.Player)
+	switch {
+	case err != nil:
+		o.Errorf("Could not unban %v: %v", c.Player, err)
+	case !removed:
+		o.Errorf("%v is not banned.", c.Player)
+	default:
+		o.Printf("Unbanned %v.", c.Player)
+	}
+}
+
+// banIP implements the /ban-ip command.
+type banIP struct {
+	command
+	Address string                    `cmd:"address"`
+	Reason  cmd.Optional[cmd.Varargs] `cmd:"reason"`
+}
+
+// Run ...
+func (c banIP) Run(src cmd.Source, o *cmd.Output, _ *world.Tx) {
+	c.banIP(src, o, c.Address, c.Reason, 0)
+}
+
+// tempBanIP implements the /tempban-ip command.
+type tempBanIP struct {
+	command
+	Address  string                    `cmd:"address"`
+	Duration string                    `cmd:"duration"`
+	Reason   cmd.Optional[cmd.Varargs] `cmd:"reason"`
+}
+
+// Run ...
+func (c tempBanIP) Run(src cmd.Source, o *cmd.Output, _ *world.Tx) {
+	d, err := parseDuration(c.Duration)
+	if err != nil {
+		o.Errorf("Invalid duration %v: %v", c.Duration, err)
+		return
+	}
+	c.banIP(src, o, c.Address, c.Reason, d)
+}
+
+// banIP bans the IP address or range passed for the duration d, or
+// permanently if d is 0.
+func (c command) banIP(src cmd.Source, o *cmd.Output, address string, reason cmd.Optional[cmd.Varargs], d time.Duration) {
+	prefix, err := parsePrefix(address)
+	if err != nil {
+		o.Errorf("Invalid IP address or range %v.", address)
+		return
+	}
+	b := IPBan{Prefix: prefix, Reason: string(reason.LoadOr("")), Source: sourceName(src), Created: time.Now()}
+	if d > 0 {
+		b.Expires = b.Created.Add(d)
+	}
+	if err := c.c.IPBans.Ban(b); err != nil {
+		o.Errorf("Could not ban %v: %v", address, err)
+		return
+	}
+	c.c.enforce(c.c.IPBans)
+	o.Printf("Banned %v%v.", prefix, expiry(b.Expires))
+}
+
+// unbanIP implements the /unban-ip command.
+type unbanIP struct {
+	command
+	Address string `cmd:"address"`
+}
+
+// Run ...
+func (c unbanIP) Run(_ cmd.Source, o *cmd.Output, _ *world.Tx) {
+	prefix, err := parsePrefix(c.Address)
+	if err != nil {
+		o.Errorf("Invalid IP address or range %v.", c.Address)
+		return
+	}
+	removed, err := c.c.IPBans.Unban(prefix)
+	switch {
+	case err != nil:
+		o.Errorf("Could not unban %v: %v", prefix, err)
+	case !removed:
+		o.Errorf("%v is not banned.", prefix)
+	default:
+		o.Printf("Unbanned %v.", prefix)
+	}
+}
+
+// banList implements the /banlist command.
+type banList struct {
+	command
+}
+
+// Run ...
+func (c banList) Run(_ cmd.Source, o *cmd.Output, _ *world.Tx) {
+	var bans, ipBans []string
+	if c.c.Bans != nil {
+		for _, b := range c.c.Bans.Bans() {
+			name := b.Name
+			if name == "" {
+				name = b.XUID
+			}
+			bans = append(bans, name+expiry(b.Expires))
+		}
+	}
+	if c.c.IPBans != nil {
+		for _, b := range c.c.IPBans.Bans() {
+			ipBans = append(ipBans, b.Prefix.String()+expiry(b.Expires))
+		}
+	}
+	if len(bans) == 0 && len(ipBans) == 0 {
+		o.Print("There are no bans.")
+		return
+	}
+	if len(bans) != 0 {
+		o.Printf("Banned players (%v): %v", len(bans), strings.Join(bans, ", "))
+	}
+	if len(ipBans) != 0 {
+		o.Printf("Banned IP addresses (%v): %v", len(ipBans), strings.Join(ipBans, ", "))
+	}
+}
+
+// whitelistAdd implements the /whitelist add command.
+type whitelistAdd struct {
+	command
+	Add    cmd.SubCommand `cmd:"add"`
+	Player string         `cmd:"player"`
+}
+
+// Run ...
+func (c whitelistAdd) Run(_ cmd.Source, o *cmd.Output, _ *world.Tx) {
+	if err := c.c.Whitelist.Add(Entry{Name: c.Player}); err != nil {
+		o.Errorf("Could not add %v to the whitelist: %v", c.Player, err)
+		return
+	}
+	o.Printf("Added %v to the whitelist.", c.Player)
+}
+
+// whitelistRemove implements the /whitelist remove command.
+type whitelistRemove struct {
+	command
+	Remove cmd.SubCommand `cmd:"remove"`
+	Player string         `cmd:"player"`
+}
+
+// Run ...
+func (c whitelistRemove) Run(_ cmd.Source, o *cmd.Output, _ *world.Tx) {
+	removed, err := c.c.Whitelist.Remove(c.Player)
+	switch {
+	case err != nil:
+		o.Errorf("Could not remove %v from the whitelist: %v", c.Player, err)
+	case !removed:
+		o.Errorf("%v is not whitelisted.", c.Player)
+	default:
+		c.c.enforce(c.c.Whitelist)
+		o.Printf("Removed %v from the whitelist.", c.Player)
+	}
+}
+
+// whitelistOn implements the /whitelist on command.
+type whitelistOn struct {
+	command
+	On cmd.SubCommand `cmd:"on"`
+}
+
+// Run ...
+func (c whitelistOn) Run(_ cmd.Source, o *cmd.Output, _ *world.Tx) {
+	if err := c.c.Whitelist.SetEnabled(true); err != nil {
+		o.Errorf("Could not turn the whitelist on: %v", err)
+		return
+	}
+	c.c.enforce(c.c.Whitelist)
+	o.Print("Turned the whitelist on.")
+}
+
+// whitelistOff implements the /whitelist off command.
+type whitelistOff struct {
+	command
+	Off cmd.SubCommand `cmd:"off"`
+}
+
+// Run ...
+func (c whitelistOff) Run(_ cmd.Source, o *cmd.Output, _ *world.Tx) {
+	if err := c.c.Whitelist.SetEnabled(false); err != nil {
+		o.Errorf("Could not turn the whitelist off: %v", err)
+		return
+	}
+	o.Print("Turned the whitelist off.")
+}
+
+// whitelistList implements the /whitelist list command.
+type whitelistList struct {
+	command
+	List cmd.SubCommand `cmd:"list"`
+}
+
+// Run ...
+func (c whitelistList) Run(_ cmd.Source, o *cmd.Output, _ *world.Tx) {
+	entries := c.c.Whitelist.Entries()
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.Name != "" {
+			names = append(names, e.Name)
+		} else {
+			names = append(names, e.XUID)
+		}
+	}
+	state := "off"
+	if c.c.Whitelist.Enabled() {
+		state = "on"
+	}
+	o.Printf("The whitelist is %v. Whitelisted players (%v): %v", state, len(names), strings.Join(names, ", "))
+}
+
+// maintenanceOn implements the /maintenance on command.
+type maintenanceOn struct {
+	command
+	On      cmd.SubCommand            `cmd:"on"`
+	Message cmd.Optional[cmd.Varargs] `cmd:"message"`
+}
+
+// Run ...
+func (c maintenanceOn) Run(_ cmd.Source, o *cmd.Output, _ *world.Tx) {
+	if msg, ok := c.Message.Load(); ok {
+		if err := c.c.Maintenance.SetMessage(string(msg)); err != nil {
+			o.Errorf("Could not set the maintenance message: %v", err)
+			return
+		}
+	}
+	if err := c.c.Maintenance.SetEnabled(true); err != nil {
+		o.Errorf("Could not turn maintenance on: %v", err)
+		return
+	}
+	c.c.enforce(c.c.Maintenance)
+	o.Print("Turned maintenance on.")
+}
+
+// maintenanceOff implements the /maintenance off command.
+type maintenanceOff struct {
+	command
+	Off cmd.SubCommand `cmd:"off"`
+}
+
+// Run ...
+func (c maintenanceOff) Run(_ cmd.Source, o *cmd.Output, _ *world.Tx) {
+	if err := c.c.Maintenance.SetEnabled(false); err != nil {
+		o.Errorf("Could not turn maintenance off: %v", err)
+		return
+	}
+	o.Print("Turned maintenance off.")
+}
+
+// maintenanceBypass implements the /maintenance bypass command.
+type maintenanceBypass struct {
+	command
+	Bypass cmd.SubCommand `cmd:"bypass"`
+	Player string         `cmd:"player"`
+}
+
+// Run ...
+func (c maintenanceBypass) Run(_ cmd.Source, o *cmd.Output, _ *world.Tx) {
+	if err := c.c.Maintenance.AddBypass(Entry{Name: c.Player}); err != nil {
+		o.Errorf("Could not allow %v to bypass maintenance: %v", c.Player, err)
+		return
+	}
+	o.Printf("%v may now join during maintenance.", c.Player)
+}
+
+// maintenanceRemove implements the /maintenance remove command.
+type maintenanceRemove struct {
+	command
+	Remove cmd.SubCommand `cmd:"remove"`
+	Player string         `cmd:"player"`
+}
+
+// Run ...
+func (c maintenanceRemove) Run(_ cmd.Source, o *cmd.Output, _ *world.Tx) {
+	removed, err := c.c.Maintenance.RemoveBypass(c.Player)
+	switch {
+	case err != nil:
+		o.Errorf("Could not remove %v from the maintenance bypass list: %v", c.Player, err)
+	case !removed:
+		o.Errorf("%v may not bypass maintenance.", c.Player)
+	default:
+		c.c.enforce(c.c.Maintenance)
+		o.Printf("%v may no longer join during maintenance.", c.Player)
+	}
+}
+
+// parseDuration parses a duration such as 30m, 12h, 7d or 2w. Durations
+// without a d or w suffix are parsed using time.ParseDuration.
+func parseDuration(s string) (time.Duration, error) {
+	unit := time.Duration(0)
+	switch {
+	case strings.HasSuffix(s, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(s, "w"):
+		unit = 7 * 24 * time.Hour
+	}
+	var d time.Duration
+	if unit != 0 {
+		n, err := strconv.ParseFloat(s[:len(s)-1], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number %v", s[:len(s)-1])
+		}
+		d = time.Duration(n * float64(unit))
+	} else {
+		var err error
+		if d, err = time.ParseDuration(s); err != nil {
+			return 0, err
+		}
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("duration must be positive")
+	}
+	return d, nil
+}
+
+// parsePrefix parses an IP address or a range of IP addresses in CIDR
+// notation.
+func parsePrefix(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		return netip.ParsePrefix(s)
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// sourceName returns the name of the cmd.Source passed, or "Server" if the
+// source has no name.
+func sourceName(src cmd.Source) string {
+	if n, ok := src.(cmd.NamedTarget); ok {
+		return n.Name()
+	}
+	return "Server"
+}
+
+// expiry returns a suffix describing when a ban that expires at the time
+// passed expires, or an empty string if it never expires.
+func expiry(expires time.Time) string {
+	if expires.IsZero() {
+		return ""
+	}
+	return " until " + expires.Format(time.DateTime)
+}
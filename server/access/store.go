@@ -0,0 +1,164 @@
+package access
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// reloadInterval is the minimum time between two checks for changes of the
+// file of a store.
+const reloadInterval = time.Second
+
+// store holds a value of type T that is backed by a JSON file. The value is
+// reloaded when the file is changed on disk and written to the file when
+// saved. A store with an empty path is only held in memory.
+type store[T any] struct {
+	path string
+
+	mu      sync.Mutex
+	v       T
+	mod     time.Time
+	checked time.Time
+	// dirty specifies if the value was changed by updateAsync without being
+	// saved yet. The file is not reloaded while the value is dirty, so that
+	// the change is not lost.
+	dirty bool
+}
+
+// openStore opens a store for the file at the path passed. If the file does
+// not exist, the store holds the zero value of T until saved.
+func openStore[T any](path string) (*store[T], error) {
+	s := &store[T]{path: path}
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Reload reloads the value of the store from its file, regardless of whether
+// it was changed.
+func (s *store[T]) Reload() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.reload()
+}
+
+// view calls f with the value of the store, after reloading it if its file
+// was changed.
+func (s *store[T]) view(f func(v *T)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refresh()
+	f(&s.v)
+}
+
+// update calls f with the value of the store, after reloading it if its file
+// was changed, and saves the value if f returns true.
+func (s *store[T]) update(f func(v *T) bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refresh()
+	if !f(&s.v) {
+		return nil
+	}
+	return s.save()
+}
+
+// updateAsync calls f with the value of the store like update, but saves the
+// value on a separate goroutine if f returns true, so that the caller does not
+// wait for the file to be written, such as when a player joins. If saving
+// fails, the value is saved with the next call to update.
+func (s *store[T]) updateAsync(f func(v *T) bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refresh()
+	if !f(&s.v) || s.path == "" || s.dirty {
+		return
+	}
+	s.dirty = true
+	go func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.dirty {
+			_ = s.save()
+		}
+	}()
+}
+
+// refresh reloads the value of the store if its file was changed since it
+// was last loaded or saved. The file is checked at most once per
+// reloadInterval. If the file could not be loaded, the current value is kept.
+// s.mu must be held.
+func (s *store[T]) refresh() {
+	now := time.Now()
+	if s.path == "" || s.dirty || now.Sub(s.checked) < reloadInterval {
+		return
+	}
+	s.checked = now
+	if info, err := os.Stat(s.path); err == nil && !info.ModTime().Equal(s.mod) {
+		_ = s.reload()
+	}
+}
+
+// reload reads the value of the store from its file. s.mu must be held.
+func (s *store[T]) reload() error {
+	if s.path == "" {
+		return nil
+	}
+	s.checked = time.Now()
+	b, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("read %v: %w", s.path, err)
+	}
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return fmt.Errorf("read %v: %w", s.path, err)
+	}
+	var v T
+	if len(b) != 0 {
+		if err := json.Unmarshal(b, &v); err != nil {
+			return fmt.Errorf("decode %v: %w", s.path, err)
+		}
+	}
+	s.v, s.mod = v, info.ModTime()
+	return nil
+}
+
+// save writes the value of the store to its file. The value is written to a
+// temporary file first, which then replaces the file, so that the file is
+// never left partially written. s.mu must be held.
+func (s *store[T]) save() error {
+	if s.path == "" {
+		return nil
+	}
+	b, err := json.MarshalIndent(s.v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode %v: %w", s.path, err)
+	}
+	if dir := filepath.Dir(s.path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("save %v: %w", s.path, err)
+		}
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0644); err != nil {
+		return fmt.Errorf("save %v: %w", s.path, err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("save %v: %w", s.path, err)
+	}
+	if info, err := os.Stat(s.path); err == nil {
+		s.mod = info.ModTime()
+	}
+	s.checked, s.dirty = time.Now(), false
+	return nil
+}
@@ -0,0 +1,97 @@
+package access
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStoreReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "whitelist.json")
+	w, err := NewWhitelist(path)
+	if err != nil {
+		t.Fatalf("NewWhitelist() of missing file = %v, want nil", err)
+	}
+	if w.Enabled() || len(w.Entries()) != 0 {
+		t.Fatalf("NewWhitelist() of missing file is not empty")
+	}
+	if err := w.Add(Entry{Name: "Steve"}); err != nil {
+		t.Fatalf("Add() = %v, want nil", err)
+	}
+	if _, err := os.Stat(path + ".tmp"); err == nil {
+		t.Fatalf("temporary file was left after saving")
+	}
+
+	reopened, err := NewWhitelist(path)
+	if err != nil {
+		t.Fatalf("NewWhitelist() = %v, want nil", err)
+	}
+	if !reopened.Listed("Steve", "") {
+		t.Fatalf("Listed() after reopening = false, want true")
+	}
+
+	// writeFile changes the file of the Whitelist like an operator editing
+	// it would, with a modification time that differs from the last save.
+	writeFile := func(data string, mod time.Time) {
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("WriteFile() = %v, want nil", err)
+		}
+		if err := os.Chtimes(path, mod, mod); err != nil {
+			t.Fatalf("Chtimes() = %v, want nil", err)
+		}
+	}
+
+	writeFile(`{"enabled":true,"players":[{"name":"Alex"}]}`, time.Now().Add(time.Minute))
+	if w.Enabled() {
+		t.Fatalf("Enabled() within reload interval = true, want false")
+	}
+	if err := w.Reload(); err != nil {
+		t.Fatalf("Reload() = %v, want nil", err)
+	}
+	if !w.Enabled() || !w.Listed("Alex", "") || w.Listed("Steve", "") {
+		t.Fatalf("Whitelist after Reload() = %v, want enabled with Alex listed", w.Entries())
+	}
+
+	writeFile(`{"enabled":false,"players":[]}`, time.Now().Add(time.Hour))
+	w.s.checked = time.Now().Add(-reloadInterval)
+	if w.Enabled() {
+		t.Fatalf("Enabled() after file changed = true, want false")
+	}
+
+	writeFile(`{"enabled":true`, time.Now().Add(time.Hour*2))
+	w.s.checked = time.Now().Add(-reloadInterval)
+	if w.Enabled() {
+		t.Fatalf("Enabled() after invalid file = true, want value before change")
+	}
+	if err := w.Reload(); err == nil {
+		t.Fatalf("Reload() of invalid file = nil, want error")
+	}
+	if _, err := NewWhitelist(path); err == nil {
+		t.Fatalf("NewWhitelist() of invalid file = nil, want error")
+	}
+}
+
+func TestStoreMemory(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	l, err := NewBanList("")
+	if err != nil {
+		t.Fatalf("NewBanList() = %v, want nil", err)
+	}
+	if err := l.Ban(Ban{Name: "Steve"}); err != nil {
+		t.Fatalf("Ban() = %v, want nil", err)
+	}
+	if _, ok := l.Banned("Steve", ""); !ok {
+		t.Fatalf("Banned() = false, want true")
+	}
+	if err := l.Reload(); err != nil {
+		t.Fatalf("Reload() = %v, want nil", err)
+	}
+	if _, ok := l.Banned("Steve", ""); !ok {
+		t.Fatalf("Banned() after Reload() of memory store = false, want true")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Fatalf("ban list without path wrote %v files, want 0", len(entries))
+	}
+}
@@ -0,0 +1,244 @@
+package access
+
+import (
+	"fmt"
+	"net"
+	"slices"
+
+	"github.com/sandertv/gophertunnel/minecraft/protocol/login"
+)
+
+// whitelistData is the data of a Whitelist stored in its file.
+type whitelistData struct {
+	Enabled bool      `json:"enabled"`
+	Players entryList `json:"players"`
+}
+
+// Whitelist is a server.Allower that, while enabled, only allows players on
+// the whitelist. A Whitelist is backed by a JSON file. It is safe for
+// concurrent use.
+type Whitelist struct {
+	s *store[whitelistData]
+}
+
+// NewWhitelist opens the Whitelist backed by the JSON file at the path
+// passed. The file is created when the Whitelist is first changed. If path is
+// empty, the Whitelist is only held in memory. A new Whitelist is disabled.
+func NewWhitelist(path string) (*Whitelist, error) {
+	s, err := openStore[whitelistData](path)
+	if err != nil {
+		return nil, fmt.Errorf("open whitelist: %w", err)
+	}
+	return &Whitelist{s: s}, nil
+}
+
+// Enabled checks if the Whitelist is enabled.
+func (w *Whitelist) Enabled() bool {
+	var enabled bool
+	w.s.view(func(v *whitelistData) {
+		enabled = v.Enabled
+	})
+	return enabled
+}
+
+// SetEnabled enables or disables the Whitelist.
+func (w *Whitelist) SetEnabled(enabled bool) error {
+	return w.s.update(func(v *whitelistData) bool {
+		changed := v.Enabled != enabled
+		v.Enabled = enabled
+		return changed
+	})
+}
+
+// Add adds a player to the Whitelist, replacing any Entry with the same name
+// or XUID. If e.XUID is empty, it is set when the player first joins.
+func (w *Whitelist) Add(e Entry) error {
+	if e.Name == "" && e.XUID == "" {
+		return fmt.Errorf("whitelist: name or xuid must be set")
+	}
+	return w.s.update(func(v *whitelistData) bool {
+		v.Players = v.Players.add(e)
+		return true
+	})
+}
+
+// Remove removes the player with the name or XUID passed from the Whitelist.
+// False is returned if the player was not on the Whitelist.
+func (w *Whitelist) Remove(nameOrXUID string) (bool, error) {
+	var removed bool
+	err := w.s.update(func(v *whitelistData) bool {
+		n := len(v.Players)
+		v.Players = v.Players.remove(nameOrXUID)
+		removed = len(v.Players) != n
+		return removed
+	})
+	return removed, err
+}
+
+// Listed checks if a player with the name and XUID passed is on the
+// Whitelist, regardless of whether it is enabled.
+func (w *Whitelist) Listed(name, xuid string) bool {
+	var listed bool
+	w.s.view(func(v *whitelistData) {
+		listed = v.Players.find(name, xuid) != -1
+	})
+	return listed
+}
+
+// Entries returns all players on the Whitelist.
+func (w *Whitelist) Entries() []Entry {
+	var entries []Entry
+	w.s.view(func(v *whitelistData) {
+		entries = slices.Clone(v.Players)
+	})
+	return entries
+}
+
+// Reload reloads the Whitelist from its file. Changes to the file are
+// reloaded automatically, so Reload only needs to be called to apply them
+// immediately.
+func (w *Whitelist) Reload() error {
+	return w.s.Reload()
+}
+
+// Allow disallows players not on the Whitelist while it is enabled. If a
+// player listed only by name joins, its XUID is added to its Entry without
+// waiting for the file to be written.
+func (w *Whitelist) Allow(_ net.Addr, d login.IdentityData, _ login.ClientData) (string, bool) {
+	allowed := true
+	w.s.updateAsync(func(v *whitelistData) bool {
+		i := v.Players.find(d.DisplayName, d.XUID)
+		allowed = !v.Enabled || i != -1
+		return v.Players.learn(i, d.XUID)
+	})
+	if !allowed {
+		return "You are not whitelisted on this server.", false
+	}
+	return "", true
+}
+
+// maintenanceData is the data of Maintenance stored in its file.
+type maintenanceData struct {
+	Enabled bool      `json:"enabled"`
+	Message string    `json:"message,omitempty"`
+	Bypass  entryList `json:"bypass"`
+}
+
+// Maintenance is a server.Allower that, while enabled, only allows players
+// that may bypass it, such as staff members. Maintenance is backed by a JSON
+// file. It is safe for concurrent use.
+type Maintenance struct {
+	s *store[maintenanceData]
+}
+
+// NewMaintenance opens the Maintenance backed by the JSON file at the path
+// passed. The file is created when the Maintenance is first changed. If path
+// is empty, the Maintenance is only held in memory. A new Maintenance is
+// disabled.
+func NewMaintenance(path string) (*Maintenance, error) {
+	s, err := openStore[maintenanceData](path)
+	if err != nil {
+		return nil, fmt.Errorf("open maintenance: %w", err)
+	}
+	return &Maintenance{s: s}, nil
+}
+
+// Enabled checks if the Maintenance is enabled.
+func (m *Maintenance) Enabled() bool {
+	var enabled bool
+	m.s.view(func(v *maintenanceData) {
+		enabled = v.Enabled
+	})
+	return enabled
+}
+
+// SetEnabled enables or disables the Maintenance.
+func (m *Maintenance) SetEnabled(enabled bool) error {
+	return m.s.update(func(v *maintenanceData) bool {
+		changed := v.Enabled != enabled
+		v.Enabled = enabled
+		return changed
+	})
+}
+
+// Message returns the message shown to players disallowed while the
+// Maintenance is enabled.
+func (m *Maintenance) Message() string {
+	var msg string
+	m.s.view(func(v *maintenanceData) {
+		msg = v.Message
+	})
+	if msg == "" {
+		return "The server is under maintenance. Please try again later."
+	}
+	return msg
+}
+
+// SetMessage sets the message shown to players disallowed while the
+// Maintenance is enabled. If empty, a default message is shown.
+func (m *Maintenance) SetMessage(msg string) error {
+	return m.s.update(func(v *maintenanceData) bool {
+		changed := v.Message != msg
+		v.Message = msg
+		return changed
+	})
+}
+
+// AddBypass allows a player to join while the Maintenance is enabled,
+// replacing any Entry with the same name or XUID.
+func (m *Maintenance) AddBypass(e Entry) error {
+	if e.Name == "" && e.XUID == "" {
+		return fmt.Errorf("maintenance: name or xuid must be set")
+	}
+	return m.s.update(func(v *maintenanceData) bool {
+		v.Bypass = v.Bypass.add(e)
+		return true
+	})
+}
+
+// RemoveBypass disallows the player with the name or XUID passed to join
+// while the Maintenance is enabled. False is returned if the player could
+// not bypass the Maintenance.
+func (m *Maintenance) RemoveBypass(nameOrXUID string) (bool, error) {
+	var removed bool
+	err := m.s.update(func(v *maintenanceData) bool {
+		n := len(v.Bypass)
+		v.Bypass = v.Bypass.remove(nameOrXUID)
+		removed = len(v.Bypass) != n
+		return removed
+	})
+	return removed, err
+}
+
+// Bypass returns all players that may join while the Maintenance is enabled.
+func (m *Maintenance) Bypass() []Entry {
+	var entries []Entry
+	m.s.view(func(v *maintenanceData) {
+		entries = slices.Clone(v.Bypass)
+	})
+	return entries
+}
+
+// Reload reloads the Maintenance from its file. Changes to the file are
+// reloaded automatically, so Reload only needs to be called to apply them
+// immediately.
+func (m *Maintenance) Reload() error {
+	return m.s.Reload()
+}
+
+// Allow disallows players that may not bypass the Maintenance while it is
+// enabled, with the message of the Maintenance. If a player listed only by
+// name joins, its XUID is added to its Entry without waiting for the file to
+// be written.
+func (m *Maintenance) Allow(_ net.Addr, d login.IdentityData, _ login.ClientData) (string, bool) {
+	var enabled, bypass bool
+	m.s.updateAsync(func(v *maintenanceData) bool {
+		i := v.Bypass.find(d.DisplayName, d.XUID)
+		enabled, bypass = v.Enabled, i != -1
+		return v.Bypass.learn(i, d.XUID)
+	})
+	if enabled && !bypass {
+		return m.Message(), false
+	}
+	return "", true
+}
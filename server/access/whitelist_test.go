@@ -0,0 +1,78 @@
+package access
+
+import (
+	"net"
+	"testing"
+
+	"github.com/sandertv/gophertunnel/minecraft/protocol/login"
+)
+
+func TestWhitelist(t *testing.T) {
+	w, err := NewWhitelist("")
+	if err != nil {
+		t.Fatalf("NewWhitelist() = %v, want nil", err)
+	}
+	addr := &net.UDPAddr{IP: net.IPv4(203, 0, 113, 7), Port: 19132}
+	steve := login.IdentityData{DisplayName: "Steve", XUID: "123"}
+
+	if _, ok := w.Allow(addr, steve, login.ClientData{}); !ok {
+		t.Fatalf("Allow() with disabled whitelist = false, want true")
+	}
+	if err := w.SetEnabled(true); err != nil {
+		t.Fatalf("SetEnabled() = %v, want nil", err)
+	}
+	if msg, ok := w.Allow(addr, steve, login.ClientData{}); ok || msg == "" {
+		t.Fatalf("Allow() of player not listed = %q, %v, want message, false", msg, ok)
+	}
+
+	if err := w.Add(Entry{}); err == nil {
+		t.Fatalf("Add() without name or xuid = nil, want error")
+	}
+	if err := w.Add(Entry{Name: "steve"}); err != nil {
+		t.Fatalf("Add() = %v, want nil", err)
+	}
+	if _, ok := w.Allow(addr, steve, login.ClientData{}); !ok {
+		t.Fatalf("Allow() of listed player = false, want true")
+	}
+	// The XUID of the player was learned, so the player stays listed after a
+	// name change.
+	if !w.Listed("Alex", "123") {
+		t.Fatalf("Listed() by learned xuid = false, want true")
+	}
+
+	if removed, err := w.Remove("Steve"); err != nil || !removed {
+		t.Fatalf("Remove() = %v, %v, want true, nil", removed, err)
+	}
+	if w.Listed("Steve", "123") || len(w.Entries()) != 0 {
+		t.Fatalf("Listed() after Remove() = true, want false")
+	}
+}
+
+func TestMaintenance(t *testing.T) {
+	m, err := NewMaintenance("")
+	if err != nil {
+		t.Fatalf("NewMaintenance() = %v, want nil", err)
+	}
+	addr := &net.UDPAddr{IP: net.IPv4(203, 0, 113, 7), Port: 19132}
+	steve := login.IdentityData{DisplayName: "Steve", XUID: "123"}
+
+	if _, ok := m.Allow(addr, steve, login.ClientData{}); !ok {
+		t.Fatalf("Allow() with disabled maintenance = false, want true")
+	}
+	_ = m.SetEnabled(true)
+	_ = m.SetMessage("Back soon.")
+	if msg, ok := m.Allow(addr, steve, login.ClientData{}); ok || msg != "Back soon." {
+		t.Fatalf("Allow() during maintenance = %q, %v, want \"Back soon.\", false", msg, ok)
+	}
+	_ = m.AddBypass(Entry{XUID: "123"})
+	if _, ok := m.Allow(addr, steve, login.ClientData{}); !ok {
+		t.Fatalf("Allow() of player bypassing maintenance = false, want true")
+	}
+
+	w, _ := NewWhitelist("")
+	_ = w.SetEnabled(true)
+	c := Chain{m, w}
+	if _, ok := c.Allow(addr, steve, login.ClientData{}); ok {
+		t.Fatalf("Chain.Allow() with player not whitelisted = true, want false")
+	}
+}
@@ -405,7 +405,7 @@ func cropSeedDrops(seed, crop world.Item, growth int) func(item.Tool, []item.Enc
 // the block as items.
 func breakBlock(b world.Block, pos cube.Pos, tx *world.Tx) {
 	breakBlockNoDrops(b, pos, tx)
-	if breakable, ok := b.(Breakable); ok {
+	if breakable, ok := b.(Breakable); ok && world.GameRuleDoTileDrops.Value(tx) {
 		for _, drop := range breakable.BreakInfo().Drops(item.ToolNone{}, nil) {
 			dropItem(tx, drop, pos.Vec3Centre())
 		}
@@ -58,7 +58,7 @@ func (b *brewer) InsertItem(h Hopper, pos cube.Pos, tx *world.Tx) bool {
 			_, okSplash := sourceStack.Item().(item.SplashPotion)
 			_, okLingering := sourceStack.Item().(item.LingeringPotion)
 			_, okBottle := sourceStack.Item().(item.GlassBottle)
-			if !okPotion && !okSplash && !okLingering && !okBottle {
+			if !okPotion && !okSplash && !okLingering && !okBottle && !recipe.ValidBrewingInput(sourceStack.Item()) {
 				continue
 			}
 			for brewingSlot, brewingStack := range b.inventory.Slots() {
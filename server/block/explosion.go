@@ -46,6 +46,15 @@ type ExplodableEntity interface {
 	Explode(src world.ExplosionSource, impact float64)
 }
 
+// PrimedTNTType is implemented by the world.EntityType of primed TNT.
+// Explosions caused by entities of this type destroy blocks even if
+// world.GameRuleMobGriefing is disabled.
+type PrimedTNTType interface {
+	world.EntityType
+	// PrimedTNT marks the world.EntityType as that of primed TNT.
+	PrimedTNT()
+}
+
 // Explodable represents a block that can be exploded.
 type Explodable interface {
 	// Explode is called when an explosion occurs. The block can react using the source passed.
@@ -161,6 +170,13 @@ func (c ExplosionConfig) Explode(tx *world.Tx, src world.ExplosionSource) {
 		}
 	}
 
+	if e, ok := src.(world.EntityExplosionSource); ok && !world.GameRuleMobGriefing.Value(tx) {
+		if _, tnt := e.Entity.H().Type().(PrimedTNTType); !tnt {
+			// Explosions caused by entities other than TNT do not destroy blocks if mob griefing is disabled.
+			affectedBlocks = affectedBlocks[:0]
+		}
+	}
+
 	ctx := tx.Event()
 	spawnFire := c.SpawnFire
 	itemDropChance := c.ItemDropChance
@@ -197,7 +213,7 @@ func (c ExplosionConfig) Explode(tx *world.Tx, src world.ExplosionSource) {
 			if breakHandler != nil {
 				breakHandler(pos, tx, nil)
 			}
-			if itemDropChance > r.Float64() {
+			if itemDropChance > r.Float64() && world.GameRuleDoTileDrops.Value(tx) {
 				for _, drop := range breakable.BreakInfo().Drops(item.ToolNone{}, nil) {
 					dropItem(tx, drop, pos.Vec3Centre())
 				}
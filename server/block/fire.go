@@ -83,7 +83,7 @@ func rainingAround(pos cube.Pos, tx *world.Tx) bool {
 
 // tick ...
 func (f Fire) tick(pos cube.Pos, tx *world.Tx, r *rand.Rand) {
-	if f.Type == SoulFire() {
+	if f.Type == SoulFire() || !world.GameRuleDoFireTick.Value(tx) {
 		return
 	}
 	infinitelyBurns := infinitelyBurning(pos, tx)
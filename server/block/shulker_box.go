@@ -130,7 +130,9 @@ func (s ShulkerBox) UseOnBlock(pos cube.Pos, face cube.Face, _ mgl64.Vec3, tx *w
 	if !used {
 		return
 	}
-	s = s.initialised()
+	// The contents are copied so that the placed shulker box does not share its inventory with the item it was
+	// placed from.
+	s = s.withContents(s.Contents()...)
 	s.Facing = face
 	place(tx, pos, s, user, ctx)
 	return placed(ctx)
@@ -147,6 +149,42 @@ func (s ShulkerBox) initialised() ShulkerBox {
 	return n
 }
 
+// Contents returns the items currently stored in the shulker box, including empty stacks for empty slots.
+func (s ShulkerBox) Contents() []item.Stack {
+	if s.inventory == nil {
+		return make([]item.Stack, 27)
+	}
+	return s.inventory.Slots()
+}
+
+// WithContents returns a copy of the shulker box with the items passed as its contents. Items are placed in the slot
+// matching their index. Nested shulker boxes and items beyond the 27th slot are dropped, and stacks holding more
+// items than their maximum count are reduced to it. WithContents may be used to create shulker box items holding
+// items.
+func (s ShulkerBox) WithContents(items ...item.Stack) ShulkerBox {
+	return s.withContents(items...)
+}
+
+// withContents returns a shulker box with a new inventory holding the items passed, so that the result does not
+// share its inventory with s.
+func (s ShulkerBox) withContents(items ...item.Stack) ShulkerBox {
+	n := NewShulkerBox()
+	n.Colour, n.Facing, n.CustomName = s.Colour, s.Facing, s.CustomName
+	for slot, it := range items {
+		if slot >= n.inventory.Size() {
+			break
+		}
+		if _, nested := it.Item().(ShulkerBox); nested || it.Empty() {
+			continue
+		}
+		if it.Count() > it.MaxCount() {
+			it = it.Grow(it.MaxCount() - it.Count())
+		}
+		_ = n.inventory.SetItem(slot, it)
+	}
+	return n
+}
+
 // open opens the shulker box, displaying the animation and playing a sound.
 func (s ShulkerBox) open(tx *world.Tx, pos cube.Pos) {
 	s.animationStatus.Store(shulkerStateOpening)
@@ -251,7 +289,13 @@ func shulkerPushDelta(facing cube.Face, shulkerBBox, entityBBox cube.BBox) (delt
 }
 
 func (s ShulkerBox) BreakInfo() BreakInfo {
-	return newBreakInfo(2, alwaysHarvestable, pickaxeEffective, oneOf(s))
+	return newBreakInfo(2, alwaysHarvestable, pickaxeEffective, func(item.Tool, []item.Enchantment) []item.Stack {
+		// The shulker box keeps its contents when broken. A copy is dropped so that the item does not share its
+		// inventory with the block that was broken.
+		it := s.withContents(s.Contents()...)
+		it.Facing = 0
+		return []item.Stack{item.NewStack(it, 1)}
+	})
 }
 
 func (s ShulkerBox) MaxCount() int {
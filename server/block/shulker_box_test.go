@@ -0,0 +1,35 @@
+package block_test
+
+import (
+	"testing"
+
+	"github.com/df-mc/dragonfly/server/block"
+	"github.com/df-mc/dragonfly/server/item"
+)
+
+func TestShulkerBoxWithContents(t *testing.T) {
+	nested := block.NewShulkerBox().WithContents(item.NewStack(item.Apple{}, 1))
+	box := block.NewShulkerBox().WithContents(
+		item.NewStack(item.Apple{}, 10),
+		item.NewStack(nested, 1),
+		item.NewStack(item.EnderPearl{}, 40),
+	)
+	contents := box.Contents()
+	if len(contents) != 27 {
+		t.Fatalf("len(Contents()) = %v, want 27", len(contents))
+	}
+	if contents[0].Count() != 10 {
+		t.Fatalf("Contents()[0] = %v, want 10 apples", contents[0])
+	}
+	if !contents[1].Empty() {
+		t.Fatalf("Contents()[1] = %v, want nested shulker box dropped", contents[1])
+	}
+	if contents[2].Count() != 16 {
+		t.Fatalf("Contents()[2] = %v, want ender pearls reduced to 16", contents[2])
+	}
+
+	decoded := block.ShulkerBox{}.DecodeNBT(box.EncodeNBT()).(block.ShulkerBox)
+	if got := decoded.Contents()[0]; got.Count() != 10 {
+		t.Fatalf("Contents()[0] after NBT round trip = %v, want 10 apples", got)
+	}
+}
@@ -0,0 +1,452 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/go-gl/mathgl/mgl64"
+	"github.com/google/uuid"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// eyeHeight is the height of the eyes of a player above its feet, which is
+// the offset of positions sent over the network for players.
+const eyeHeight = 1.62
+
+// selfEntityRuntimeID is the entity runtime ID of the player of a Client.
+const selfEntityRuntimeID = 1
+
+// MaxReceived is the maximum number of packets that a Client holds that were
+// not yet read. Once reached, the oldest packet is discarded for every new
+// packet received.
+const MaxReceived = 4096
+
+// Client is a headless client connected to a server through a Listener. A
+// Client records the packets it receives, which may be read using
+// ReadPacket, Expect and Await, and keeps track of its position, rotation,
+// inventory and the last form it was sent, so that it may be scripted to act
+// like a player. At most MaxReceived packets that were not yet read are held,
+// and Filter may be used to only record the packets of interest. The methods
+// of a Client are safe for concurrent use.
+type Client struct {
+	conn *minecraft.Conn
+
+	mu       sync.Mutex
+	received []packet.Packet
+	filter   func(pk packet.Packet) bool
+	notify   chan struct{}
+	closed   chan struct{}
+	err      error
+
+	tick       uint64
+	pos        mgl64.Vec3
+	yaw, pitch float64
+	heldSlot   int
+	inventory  map[int]protocol.ItemInstance
+	form       *packet.ModalFormRequest
+}
+
+// newClient returns a Client for the spawned minecraft.Conn passed and starts
+// reading packets from it.
+func newClient(conn *minecraft.Conn) *Client {
+	data := conn.GameData()
+	c := &Client{
+		conn:      conn,
+		notify:    make(chan struct{}, 1),
+		closed:    make(chan struct{}),
+		pos:       vec32To64(data.PlayerPosition).Sub(mgl64.Vec3{0, eyeHeight}),
+		yaw:       float64(data.Yaw),
+		pitch:     float64(data.Pitch),
+		inventory: make(map[int]protocol.ItemInstance),
+	}
+	go c.read()
+	return c
+}
+
+// Conn returns the minecraft.Conn of the Client, which may be used to write
+// packets that the Client has no method for.
+func (c *Client) Conn() *minecraft.Conn {
+	return c.conn
+}
+
+// Close disconnects the Client from the server.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Closed returns a channel that is closed once the Client is disconnected.
+func (c *Client) Closed() <-chan struct{} {
+	return c.closed
+}
+
+// Position returns the position of the feet of the player of the Client, as
+// last moved by the Client or teleported by the server.
+func (c *Client) Position() mgl64.Vec3 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.pos
+}
+
+// Rotation returns the rotation of the player of the Client.
+func (c *Client) Rotation() cube.Rotation {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return cube.Rotation{c.yaw, c.pitch}
+}
+
+// Move moves the player of the Client to the position of its feet passed.
+func (c *Client) Move(pos mgl64.Vec3) error {
+	return c.input(func(pk *packet.PlayerAuthInput) {
+		pk.Delta = vec64To32(pos.Sub(c.pos))
+		c.pos = pos
+	})
+}
+
+// Look rotates the player of the Client to the yaw and pitch passed.
+func (c *Client) Look(yaw, pitch float64) error {
+	return c.input(func(*packet.PlayerAuthInput) {
+		c.yaw, c.pitch = yaw, pitch
+	})
+}
+
+// StartBreaking starts breaking the block at the position passed, punching
+// it on the face passed.
+func (c *Client) StartBreaking(pos cube.Pos, face cube.Face) error {
+	return c.blockAction(protocol.PlayerActionStartBreak, pos, face)
+}
+
+// FinishBreaking finishes breaking the block that the Client started
+// breaking using StartBreaking. The server only breaks the block if it has
+// been broken for long enough, if configured to validate block breaking.
+func (c *Client) FinishBreaking(pos cube.Pos, face cube.Face) error {
+	return c.blockAction(protocol.PlayerActionPredictDestroyBlock, pos, face)
+}
+
+// BreakBlock starts and immediately finishes breaking the block at the
+// position passed.
+func (c *Client) BreakBlock(pos cube.Pos, face cube.Face) error {
+	if err := c.StartBreaking(pos, face); err != nil {
+		return err
+	}
+	return c.FinishBreaking(pos, face)
+}
+
+// UseItemOnBlock uses the held item on the face passed of the block at the
+// position passed, which places the block held or activates the block
+// clicked.
+func (c *Client) UseItemOnBlock(pos cube.Pos, face cube.Face) error {
+	c.mu.Lock()
+	data := &protocol.UseItemTransactionData{
+		ActionType:       protocol.UseItemActionClickBlock,
+		TriggerType:      protocol.TriggerTypePlayerInput,
+		BlockPosition:    protocol.BlockPos{int32(pos[0]), int32(pos[1]), int32(pos[2])},
+		BlockFace:        int32(face),
+		HotBarSlot:       int32(c.heldSlot),
+		HeldItem:         c.inventory[c.heldSlot],
+		Position:         vec64To32(c.pos),
+		ClickedPosition:  vec64To32(mgl64.Vec3{0.5, 0.5, 0.5}.Add(pos.Side(face).Sub(pos).Vec3().Mul(0.5))),
+		ClientPrediction: protocol.ClientPredictionSuccess,
+	}
+	c.mu.Unlock()
+	return c.conn.WritePacket(&packet.InventoryTransaction{TransactionData: data})
+}
+
+// SelectSlot selects the hotbar slot passed, from 0 to 8, as the held slot.
+func (c *Client) SelectSlot(slot int) error {
+	if slot < 0 || slot > 8 {
+		return fmt.Errorf("select slot: slot %v out of range", slot)
+	}
+	c.mu.Lock()
+	c.heldSlot = slot
+	it := c.inventory[slot]
+	c.mu.Unlock()
+	return c.conn.WritePacket(&packet.MobEquipment{
+		EntityRuntimeID: selfEntityRuntimeID,
+		NewItem:         it,
+		InventorySlot:   byte(slot),
+		HotBarSlot:      byte(slot),
+		WindowID:        protocol.WindowIDInventory,
+	})
+}
+
+// Chat sends a chat message.
+func (c *Client) Chat(message string) error {
+	return c.conn.WritePacket(&packet.Text{
+		TextType:   packet.TextTypeChat,
+		SourceName: c.conn.IdentityData().DisplayName,
+		Message:    message,
+		XUID:       c.conn.IdentityData().XUID,
+	})
+}
+
+// ExecuteCommand executes the command line passed, such as "/gamemode 1".
+func (c *Client) ExecuteCommand(commandLine string) error {
+	return c.conn.WritePacket(&packet.CommandRequest{
+		CommandLine: commandLine,
+		CommandOrigin: protocol.CommandOrigin{
+			Origin:    protocol.CommandOriginPlayer,
+			UUID:      uuid.New(),
+			RequestID: "bot",
+		},
+	})
+}
+
+// Form returns the JSON data of the last form sent to the Client that was not
+// yet responded to. Form returns false if no form is open.
+func (c *Client) Form() (json.RawMessage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.form == nil {
+		return nil, false
+	}
+	return c.form.FormData, true
+}
+
+// ClickButton clicks the button with the index passed in the last form sent
+// to the Client. For menu forms, index is the index of the button. For modal
+// forms, index 0 clicks the first button and any other index the second.
+func (c *Client) ClickButton(index int) error {
+	data, ok := c.Form()
+	if !ok {
+		return errors.New("click button: no form open")
+	}
+	var f struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("click button: decode form: %w", err)
+	}
+	if f.Type == "modal" {
+		return c.SubmitForm(index == 0)
+	}
+	return c.SubmitForm(index)
+}
+
+// SubmitForm submits the response passed, encoded as JSON, to the last form
+// sent to the Client. For custom forms, the response is a slice holding the
+// value of every element.
+func (c *Client) SubmitForm(response any) error {
+	b, err := json.Marshal(response)
+	if err != nil {
+		return fmt.Errorf("submit form: encode response: %w", err)
+	}
+	return c.respond(protocol.Option(b))
+}
+
+// CloseForm closes the last form sent to the Client without responding to
+// it.
+func (c *Client) CloseForm() error {
+	return c.respond(protocol.Optional[[]byte]{})
+}
+
+// respond responds to the last form sent to the Client with the response
+// data passed.
+func (c *Client) respond(data protocol.Optional[[]byte]) error {
+	c.mu.Lock()
+	f := c.form
+	c.form = nil
+	c.mu.Unlock()
+	if f == nil {
+		return errors.New("respond to form: no form open")
+	}
+	pk := &packet.ModalFormResponse{FormID: f.FormID, ResponseData: data}
+	if _, ok := data.Value(); !ok {
+		pk.CancelReason = protocol.Option[uint8](packet.ModalFormCancelReasonUserClosed)
+	}
+	return c.conn.WritePacket(pk)
+}
+
+// Filter sets a function that decides which packets received by the Client
+// are recorded to be read using ReadPacket, Expect and Await. Only packets for
+// which f returns true are recorded from then on. The state of the Client,
+// such as its position and the last form it was sent, is updated using all
+// packets regardless. Passing nil records all packets again.
+func (c *Client) Filter(f func(pk packet.Packet) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.filter = f
+}
+
+// ReadPacket returns the next packet received by the Client that was not
+// yet read. ReadPacket blocks until a packet is received, the Client is
+// disconnected or the context.Context passed is done.
+func (c *Client) ReadPacket(ctx context.Context) (packet.Packet, error) {
+	for {
+		c.mu.Lock()
+		if len(c.received) > 0 {
+			pk := c.received[0]
+			c.received = c.received[1:]
+			c.mu.Unlock()
+			return pk, nil
+		}
+		err := c.err
+		c.mu.Unlock()
+		if err != nil {
+			return nil, err
+		}
+
+		select {
+		case <-c.notify:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Packets returns all packets received by the Client that were not yet read
+// and marks them as read.
+func (c *Client) Packets() []packet.Packet {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	received := c.received
+	c.received = nil
+	return received
+}
+
+// Expect reads packets received by the Client until f returns true for one
+// of them, which is then returned. Packets read before are discarded.
+func (c *Client) Expect(ctx context.Context, f func(pk packet.Packet) bool) (packet.Packet, error) {
+	for {
+		pk, err := c.ReadPacket(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if f(pk) {
+			return pk, nil
+		}
+	}
+}
+
+// Await reads packets received by the Client passed until one of type T is
+// read, such as *packet.Text, which is then returned. Packets read before are
+// discarded.
+func Await[T packet.Packet](ctx context.Context, c *Client) (T, error) {
+	pk, err := c.Expect(ctx, func(pk packet.Packet) bool {
+		_, ok := pk.(T)
+		return ok
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return pk.(T), nil
+}
+
+// input writes a PlayerAuthInput packet with the position and rotation of the
+// Client after calling f, which may modify the packet and the Client.
+func (c *Client) input(f func(pk *packet.PlayerAuthInput)) error {
+	c.mu.Lock()
+	c.tick++
+	pk := &packet.PlayerAuthInput{
+		InputData:        protocol.NewInputFlags(packet.InputFlagCount),
+		InputMode:        packet.InputModeMouse,
+		PlayMode:         packet.PlayModeNormal,
+		InteractionModel: packet.InteractionModelCrosshair,
+		Tick:             c.tick,
+	}
+	f(pk)
+	pk.Position = vec64To32(c.pos.Add(mgl64.Vec3{0, eyeHeight}))
+	pk.Pitch, pk.Yaw, pk.HeadYaw = float32(c.pitch), float32(c.yaw), float32(c.yaw)
+	pk.InteractPitch, pk.InteractYaw = pk.Pitch, pk.Yaw
+	c.mu.Unlock()
+	return c.conn.WritePacket(pk)
+}
+
+// blockAction writes a PlayerAuthInput packet with the block action passed.
+func (c *Client) blockAction(action int32, pos cube.Pos, face cube.Face) error {
+	return c.input(func(pk *packet.PlayerAuthInput) {
+		pk.InputData.Set(packet.InputFlagPerformBlockActions)
+		pk.BlockActions = protocol.Option([]protocol.PlayerBlockAction{{
+			Action:   action,
+			BlockPos: protocol.BlockPos{int32(pos[0]), int32(pos[1]), int32(pos[2])},
+			Face:     int32(face),
+		}})
+	})
+}
+
+// read reads packets from the connection of the Client until it is closed.
+func (c *Client) read() {
+	defer close(c.closed)
+	for {
+		pk, err := c.conn.ReadPacket()
+		c.mu.Lock()
+		if err != nil {
+			c.err = err
+			c.mu.Unlock()
+			c.signal()
+			return
+		}
+		c.handlePacket(pk)
+		c.record(pk)
+		c.mu.Unlock()
+		c.signal()
+	}
+}
+
+// record records a packet received so that it may be read, if it passes the
+// filter of the Client. The oldest packet that was not yet read is discarded
+// if MaxReceived packets are already held.
+func (c *Client) record(pk packet.Packet) {
+	if c.filter != nil && !c.filter(pk) {
+		return
+	}
+	if len(c.received) >= MaxReceived {
+		c.received = c.received[len(c.received)-MaxReceived+1:]
+	}
+	c.received = append(c.received, pk)
+}
+
+// signal notifies a call to ReadPacket waiting for a packet.
+func (c *Client) signal() {
+	select {
+	case c.notify <- struct{}{}:
+	default:
+	}
+}
+
+// handlePacket updates the state of the Client using a packet received.
+func (c *Client) handlePacket(pk packet.Packet) {
+	switch pk := pk.(type) {
+	case *packet.MovePlayer:
+		if pk.EntityRuntimeID == selfEntityRuntimeID {
+			c.pos = vec32To64(pk.Position).Sub(mgl64.Vec3{0, eyeHeight})
+			c.yaw, c.pitch = float64(pk.Yaw), float64(pk.Pitch)
+		}
+	case *packet.CorrectPlayerMovePrediction:
+		c.pos = vec32To64(pk.Position).Sub(mgl64.Vec3{0, eyeHeight})
+	case *packet.InventoryContent:
+		if pk.WindowID == protocol.WindowIDInventory {
+			clear(c.inventory)
+			for slot, it := range pk.Content {
+				c.inventory[slot] = it
+			}
+		}
+	case *packet.InventorySlot:
+		if pk.WindowID == protocol.WindowIDInventory {
+			c.inventory[int(pk.Slot)] = pk.NewItem
+		}
+	case *packet.MobEquipment:
+		if pk.EntityRuntimeID == selfEntityRuntimeID && pk.WindowID == protocol.WindowIDInventory {
+			c.heldSlot = int(pk.HotBarSlot)
+		}
+	case *packet.ModalFormRequest:
+		c.form = pk
+	}
+}
+
+// vec32To64 converts a mgl32.Vec3 to a mgl64.Vec3.
+func vec32To64(vec3 mgl32.Vec3) mgl64.Vec3 {
+	return mgl64.Vec3{float64(vec3[0]), float64(vec3[1]), float64(vec3[2])}
+}
+
+// vec64To32 converts a mgl64.Vec3 to a mgl32.Vec3.
+func vec64To32(vec3 mgl64.Vec3) mgl32.Vec3 {
+	return mgl32.Vec3{float32(vec3[0]), float32(vec3[1]), float32(vec3[2])}
+}
@@ -0,0 +1,224 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/df-mc/dragonfly/server"
+	"github.com/df-mc/dragonfly/server/block"
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/player"
+	"github.com/df-mc/dragonfly/server/player/form"
+	"github.com/df-mc/dragonfly/server/world"
+	"github.com/google/uuid"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+func TestClient(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+
+	l := NewListener()
+	srv := server.Config{
+		Log:       slog.New(slog.DiscardHandler),
+		Listeners: []func(server.Config) (server.Listener, error){l.Listen},
+	}.New()
+	srv.Listen()
+	t.Cleanup(func() { _ = srv.Close() })
+
+	players := make(chan *player.Player, 1)
+	go func() {
+		for p := range srv.Accept() {
+			players <- p
+		}
+	}()
+
+	c, err := l.Connect(ctx, "Steve")
+	if err != nil {
+		t.Fatalf("Connect() = %v, want nil", err)
+	}
+	defer c.Close()
+	var h *world.EntityHandle
+	select {
+	case p := <-players:
+		h = p.H()
+	case <-ctx.Done():
+		t.Fatalf("player of client was never accepted")
+	}
+	exec := func(f func(tx *world.Tx, p *player.Player)) {
+		err := srv.World().Do(func(tx *world.Tx) {
+			e, _ := h.Entity(tx)
+			f(tx, e.(*player.Player))
+		}).Wait(ctx)
+		if err != nil {
+			t.Fatalf("Do() = %v, want nil", err)
+		}
+	}
+
+	t.Run("chat", func(t *testing.T) {
+		if err := c.Chat("Hello world!"); err != nil {
+			t.Fatalf("Chat() = %v, want nil", err)
+		}
+		if _, err := c.Expect(ctx, func(pk packet.Packet) bool {
+			text, ok := pk.(*packet.Text)
+			return ok && strings.Contains(text.Message, "Hello world!")
+		}); err != nil {
+			t.Fatalf("Expect() of chat message = %v, want nil", err)
+		}
+	})
+	t.Run("break", func(t *testing.T) {
+		var pos cube.Pos
+		exec(func(tx *world.Tx, p *player.Player) {
+			p.SetGameMode(world.GameModeCreative)
+			pos = cube.PosFromVec3(p.Position()).Side(cube.FaceDown)
+			tx.SetBlock(pos, block.Stone{}, nil)
+		})
+		want := protocol.BlockPos{int32(pos[0]), int32(pos[1]), int32(pos[2])}
+		update := func(pk packet.Packet) bool {
+			update, ok := pk.(*packet.UpdateBlock)
+			return ok && update.Position == want
+		}
+		if _, err := c.Expect(ctx, update); err != nil {
+			t.Fatalf("Expect() of stone placed = %v, want nil", err)
+		}
+		if err := c.BreakBlock(pos, cube.FaceUp); err != nil {
+			t.Fatalf("BreakBlock() = %v, want nil", err)
+		}
+		if _, err := c.Expect(ctx, update); err != nil {
+			t.Fatalf("Expect() of block update = %v, want nil", err)
+		}
+		exec(func(tx *world.Tx, p *player.Player) {
+			if b := tx.Block(pos); b != (block.Air{}) {
+				t.Errorf("block after BreakBlock() = %v, want air", b)
+			}
+		})
+	})
+	t.Run("form", func(t *testing.T) {
+		pressed := make(chan form.Button, 1)
+		exec(func(tx *world.Tx, p *player.Player) {
+			p.SendForm(form.NewMenu(testMenu{pressed: pressed}, "Menu").WithButtons(form.NewButton("A", ""), form.NewButton("B", "")))
+		})
+		if _, err := Await[*packet.ModalFormRequest](ctx, c); err != nil {
+			t.Fatalf("Await() of form = %v, want nil", err)
+		}
+		if err := c.ClickButton(1); err != nil {
+			t.Fatalf("ClickButton() = %v, want nil", err)
+		}
+		select {
+		case b := <-pressed:
+			if b.Text != "B" {
+				t.Fatalf("pressed button = %v, want B", b.Text)
+			}
+		case <-ctx.Done():
+			t.Fatalf("form was never submitted")
+		}
+		if _, ok := c.Form(); ok {
+			t.Fatalf("Form() after ClickButton() = true, want false")
+		}
+	})
+}
+
+func TestClientReplay(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+
+	buf := bytes.NewBuffer(nil)
+	rec := world.NewRecorder(buf)
+	l := NewListener()
+	srv := server.Config{
+		Log:       slog.New(slog.DiscardHandler),
+		Listeners: []func(server.Config) (server.Listener, error){l.Listen},
+		Recorder: func(dim world.Dimension) *world.Recorder {
+			if dim == world.Overworld {
+				return rec
+			}
+			return nil
+		},
+	}.New()
+	srv.Listen()
+	go func() {
+		for range srv.Accept() {
+		}
+	}()
+
+	c, err := l.Connect(ctx, "Steve")
+	if err != nil {
+		t.Fatalf("Connect() = %v, want nil", err)
+	}
+	if err := c.Chat("Hello world!"); err != nil {
+		t.Fatalf("Chat() = %v, want nil", err)
+	}
+	if _, err := c.Expect(ctx, func(pk packet.Packet) bool {
+		_, ok := pk.(*packet.Text)
+		return ok
+	}); err != nil {
+		t.Fatalf("Expect() of chat message = %v, want nil", err)
+	}
+	// Let the Session send chunks for a while before closing the server.
+	time.Sleep(time.Second / 4)
+	_ = srv.Close()
+	_ = c.Close()
+	if err := rec.Err(); err != nil {
+		t.Fatalf("Recorder.Err() = %v, want nil", err)
+	}
+
+	r, err := world.NewReplay(buf)
+	if err != nil {
+		t.Fatalf("NewReplay() = %v, want nil", err)
+	}
+	src, _ := r.RandSource()
+	w := world.Config{Synchronous: true, RandSource: src}.New()
+	defer w.Close()
+
+	var inputs int
+	stats, err := r.Run(w, func(tx *world.Tx, id uuid.UUID, data []byte) error {
+		inputs++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Run() of recording with a connected session = %v, want nil", err)
+	}
+	if stats.Ticks == 0 || inputs == 0 {
+		t.Fatalf("Run() stats = %+v, want ticks and inputs", stats)
+	}
+}
+
+func TestClientRecord(t *testing.T) {
+	c := &Client{}
+	for range MaxReceived + 10 {
+		c.record(&packet.Text{})
+	}
+	c.record(&packet.SetTime{})
+	received := c.Packets()
+	if len(received) != MaxReceived {
+		t.Fatalf("len(Packets()) = %v, want %v", len(received), MaxReceived)
+	}
+	if _, ok := received[len(received)-1].(*packet.SetTime); !ok {
+		t.Fatalf("last packet = %T, want newest packet *packet.SetTime", received[len(received)-1])
+	}
+
+	c.Filter(func(pk packet.Packet) bool {
+		_, ok := pk.(*packet.Text)
+		return ok
+	})
+	c.record(&packet.SetTime{})
+	c.record(&packet.Text{})
+	if received = c.Packets(); len(received) != 1 {
+		t.Fatalf("len(Packets()) with filter = %v, want 1", len(received))
+	}
+}
+
+// testMenu is a form.MenuSubmittable that passes the button pressed to a
+// channel.
+type testMenu struct {
+	pressed chan form.Button
+}
+
+func (m testMenu) Submit(_ form.Submitter, pressed form.Button, _ *world.Tx) {
+	m.pressed <- pressed
+}
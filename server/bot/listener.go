@@ -0,0 +1,108 @@
+// Package bot implements an in-process server.Listener and a headless client
+// that connects to it, so that gameplay may be tested end-to-end without a
+// Minecraft client or any networking. Clients log in without authentication,
+// spawn as a player and may then be scripted to move, break and place blocks,
+// chat and respond to forms, while the packets they receive are recorded.
+//
+//	l := bot.NewListener()
+//	conf.Listeners = []func(server.Config) (server.Listener, error){l.Listen}
+//	srv := conf.New()
+//	srv.Listen()
+//	go func() {
+//	  for p := range srv.Accept() {
+//	    // Use p
+//	  }
+//	}()
+//
+//	c, err := l.Connect(ctx, "Steve")
+//	...
+//	_ = c.Chat("Hello world!")
+//	text, err := bot.Await[*packet.Text](ctx, c)
+package bot
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/df-mc/dragonfly/server"
+	"github.com/df-mc/dragonfly/server/session"
+	"github.com/google/uuid"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/login"
+)
+
+// Listener is a server.Listener that accepts Clients connected using Connect
+// from within the same process. A Listener must be added to the
+// server.Config.Listeners of a server using Listen before Clients can connect.
+type Listener struct {
+	n *network
+}
+
+// NewListener returns a new Listener. Listener.Listen must be added to the
+// server.Config.Listeners of a server to start listening.
+func NewListener() *Listener {
+	return &Listener{n: &network{}}
+}
+
+// Listen starts listening for Clients using the server.Config passed. Listen
+// has the signature of the functions in server.Config.Listeners, so that it
+// may be added to them directly. Authentication is always disabled for
+// Clients connecting to the Listener.
+func (l *Listener) Listen(conf server.Config) (server.Listener, error) {
+	cfg := minecraft.ListenConfig{
+		MaximumPlayers:         conf.MaxPlayers,
+		StatusProvider:         conf.StatusProvider,
+		AuthenticationDisabled: true,
+		ResourcePacks:          conf.Resources,
+		TexturePacksRequired:   conf.ResourcesRequired,
+		Compression:            conf.Compression,
+		Allow:                  conf.Allower.Allow,
+		PacketFunc:             conf.PacketFunc,
+	}
+	ml, err := cfg.ListenNetwork(l.n, "bot")
+	if err != nil {
+		return nil, fmt.Errorf("create bot listener: %w", err)
+	}
+	return listener{ml}, nil
+}
+
+// Connect connects a new Client with the name passed to the server that the
+// Listener was added to. Connect blocks until the Client logged in and
+// spawned, or until the context.Context passed is done. The UUID of the
+// Client is derived from its name, so that its player data is kept when
+// reconnecting with the same name.
+func (l *Listener) Connect(ctx context.Context, name string) (*Client, error) {
+	conn, err := minecraft.Dialer{
+		IdentityData: login.IdentityData{
+			DisplayName: name,
+			Identity:    uuid.NewSHA1(uuid.NameSpaceOID, []byte("bot:"+name)).String(),
+		},
+	}.DialContextNetwork(ctx, l.n, "bot")
+	if err != nil {
+		return nil, fmt.Errorf("connect bot: %w", err)
+	}
+	if err := conn.DoSpawnContext(ctx); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("connect bot: spawn: %w", err)
+	}
+	return newClient(conn), nil
+}
+
+// listener is the server.Listener returned by Listener.Listen.
+type listener struct {
+	*minecraft.Listener
+}
+
+// Accept blocks until the next Client is connected and returns it.
+func (l listener) Accept() (session.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return conn.(session.Conn), nil
+}
+
+// Disconnect disconnects a Client from the Listener with a reason.
+func (l listener) Disconnect(conn session.Conn, reason string) error {
+	return l.Listener.Disconnect(conn.(*minecraft.Conn), reason)
+}
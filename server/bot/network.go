@@ -0,0 +1,202 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sandertv/gophertunnel/minecraft"
+)
+
+// network is a minecraft.Network that connects clients to a listener within
+// the same process. Batches of packets are passed between the two ends of a
+// connection over channels, without any networking.
+type network struct {
+	mu sync.Mutex
+	l  *pipeListener
+	n  atomic.Int64
+}
+
+// DialContext connects to the pipeListener of the network.
+func (n *network) DialContext(ctx context.Context, _ string) (net.Conn, error) {
+	n.mu.Lock()
+	l := n.l
+	n.mu.Unlock()
+	if l == nil {
+		return nil, errors.New("dial bot: listener not running")
+	}
+	server, client := newPipe(l.addr, pipeAddr("bot-"+strconv.FormatInt(n.n.Add(1), 10)))
+	select {
+	case l.incoming <- server:
+		return client, nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// PingContext returns the pong data of the pipeListener of the network.
+func (n *network) PingContext(context.Context, string) ([]byte, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.l == nil {
+		return nil, errors.New("ping bot: listener not running")
+	}
+	return *n.l.pong.Load(), nil
+}
+
+// Listen creates the pipeListener of the network. Only one pipeListener may
+// be running per network at a time.
+func (n *network) Listen(address string) (minecraft.NetworkListener, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.l != nil {
+		return nil, errors.New("listen bot: listener already running")
+	}
+	n.l = &pipeListener{n: n, addr: pipeAddr(address), id: rand.Int64(), incoming: make(chan net.Conn), closed: make(chan struct{})}
+	n.l.pong.Store(new([]byte))
+	return n.l, nil
+}
+
+// pipeListener is the minecraft.NetworkListener of a network.
+type pipeListener struct {
+	n    *network
+	addr pipeAddr
+	id   int64
+	pong atomic.Pointer[[]byte]
+
+	incoming chan net.Conn
+	closed   chan struct{}
+	once     sync.Once
+}
+
+// Accept blocks until a client dials the network of the pipeListener.
+func (l *pipeListener) Accept() (net.Conn, error) {
+	select {
+	case c := <-l.incoming:
+		return c, nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close closes the pipeListener, so that a new one may be created for its
+// network.
+func (l *pipeListener) Close() error {
+	l.once.Do(func() {
+		close(l.closed)
+		l.n.mu.Lock()
+		l.n.l = nil
+		l.n.mu.Unlock()
+	})
+	return nil
+}
+
+// Addr ...
+func (l *pipeListener) Addr() net.Addr {
+	return l.addr
+}
+
+// ID ...
+func (l *pipeListener) ID() int64 {
+	return l.id
+}
+
+// PongData ...
+func (l *pipeListener) PongData(data []byte) {
+	l.pong.Store(&data)
+}
+
+// pipeAddr is the net.Addr of one end of a pipe.
+type pipeAddr string
+
+// Network ...
+func (pipeAddr) Network() string { return "bot" }
+
+// String ...
+func (a pipeAddr) String() string { return string(a) }
+
+// pipe holds the state shared by both ends of an in-process connection.
+type pipe struct {
+	closed chan struct{}
+	once   sync.Once
+}
+
+// pipeConn is one end of an in-process connection. Every Write to a pipeConn
+// is read as a single batch by ReadPacket or Read on the other end.
+type pipeConn struct {
+	p             *pipe
+	in, out       chan []byte
+	local, remote net.Addr
+}
+
+// newPipe returns both ends of a new in-process connection.
+func newPipe(serverAddr, clientAddr net.Addr) (server, client *pipeConn) {
+	p := &pipe{closed: make(chan struct{})}
+	a, b := make(chan []byte, 256), make(chan []byte, 256)
+	return &pipeConn{p: p, in: a, out: b, local: serverAddr, remote: clientAddr},
+		&pipeConn{p: p, in: b, out: a, local: clientAddr, remote: serverAddr}
+}
+
+// ReadPacket reads the next batch written to the other end of the pipeConn.
+func (c *pipeConn) ReadPacket() ([]byte, error) {
+	select {
+	case b := <-c.in:
+		return b, nil
+	case <-c.p.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+// Read reads the next batch written to the other end of the pipeConn into b.
+func (c *pipeConn) Read(b []byte) (int, error) {
+	batch, err := c.ReadPacket()
+	if err != nil {
+		return 0, err
+	}
+	return copy(b, batch), nil
+}
+
+// Write writes b as a single batch to the other end of the pipeConn.
+func (c *pipeConn) Write(b []byte) (int, error) {
+	select {
+	case c.out <- append([]byte(nil), b...):
+		return len(b), nil
+	case <-c.p.closed:
+		return 0, net.ErrClosed
+	}
+}
+
+// Close closes both ends of the pipeConn.
+func (c *pipeConn) Close() error {
+	c.p.once.Do(func() {
+		close(c.p.closed)
+	})
+	return nil
+}
+
+// LocalAddr ...
+func (c *pipeConn) LocalAddr() net.Addr {
+	return c.local
+}
+
+// RemoteAddr ...
+func (c *pipeConn) RemoteAddr() net.Addr {
+	return c.remote
+}
+
+// SetDeadline is a no-op, as reads and writes are stopped by closing the
+// pipeConn.
+func (c *pipeConn) SetDeadline(time.Time) error { return nil }
+
+// SetReadDeadline is a no-op.
+func (c *pipeConn) SetReadDeadline(time.Time) error { return nil }
+
+// SetWriteDeadline is a no-op.
+func (c *pipeConn) SetWriteDeadline(time.Time) error { return nil }
@@ -0,0 +1,52 @@
+package builder
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/df-mc/dragonfly/server/cmd"
+	"github.com/df-mc/dragonfly/server/world"
+)
+
+// blockArg is a command parameter that parses a block by its name, such as 'stone' or 'minecraft:oak_planks'. The
+// block parsed is the first registered state of the block with that name.
+type blockArg struct {
+	b world.Block
+}
+
+// Type ...
+func (blockArg) Type() string {
+	return "block"
+}
+
+// Parse ...
+func (blockArg) Parse(line *cmd.Line, v reflect.Value) error {
+	arg, ok := line.Next()
+	if !ok {
+		return line.UsageError()
+	}
+	name := strings.ToLower(arg)
+	if !strings.Contains(name, ":") {
+		name = "minecraft:" + name
+	}
+	b, ok := blocksByName()[name]
+	if !ok {
+		return cmd.MessageParameterInvalid.F(arg)
+	}
+	v.Set(reflect.ValueOf(blockArg{b: b}))
+	return nil
+}
+
+// blocksByName returns a map of all blocks in the world.DefaultBlockRegistry indexed by their name. The map is
+// created the first time blocksByName is called.
+var blocksByName = sync.OnceValue(func() map[string]world.Block {
+	m := make(map[string]world.Block)
+	for _, b := range world.Blocks() {
+		name, _ := b.EncodeBlock()
+		if _, ok := m[name]; !ok {
+			m[name] = b
+		}
+	}
+	return m
+})
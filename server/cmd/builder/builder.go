@@ -0,0 +1,218 @@
+// Package builder implements an optional set of in-game building commands similar to WorldEdit. Players select a
+// region using a wand item or the //pos1 and //pos2 commands and may then change the blocks within it using
+// commands such as //set, //replace, //copy and //paste.
+//
+// The commands are registered using Tools.Register. For the selection wand to work, players must have a
+// player.Handler returned by Tools.Handler set.
+package builder
+
+import (
+	"image/color"
+	"sync"
+	"time"
+
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/cmd"
+	"github.com/df-mc/dragonfly/server/item"
+	"github.com/df-mc/dragonfly/server/player"
+	"github.com/df-mc/dragonfly/server/player/debug"
+	"github.com/df-mc/dragonfly/server/world"
+	"github.com/go-gl/mathgl/mgl64"
+	"github.com/google/uuid"
+)
+
+// Config holds the configuration of Tools. Fields left empty are set to their default value.
+type Config struct {
+	// Wand is the item used to select the corners of a region. Breaking a block with the wand selects the first
+	// corner and using it on a block selects the second. Wand defaults to a wooden axe.
+	Wand world.Item
+	// MaxVolume is the maximum number of blocks that a single command may change. The blocks changed by a command
+	// are all changed within a single transaction, during which the world cannot tick, so MaxVolume should be
+	// kept low enough not to stall the world. MaxVolume defaults to 32768.
+	MaxVolume int
+	// BlocksPerSecond limits the rate at which a player may change blocks. After every command, a player must
+	// wait for the number of blocks changed divided by BlocksPerSecond seconds before running another command
+	// that changes blocks. This prevents players from stalling the world with many edits in a row.
+	// BlocksPerSecond defaults to 65536.
+	BlocksPerSecond int
+	// HistorySize is the maximum number of edits of a player that may be undone. HistorySize defaults to 16.
+	HistorySize int
+	// SchematicDir is the directory that schematics are saved to and loaded from using //schem. SchematicDir
+	// defaults to 'schematics'.
+	SchematicDir string
+	// Allow is called to check if a player may use the commands and the wand. If nil, only players in creative
+	// mode may use them.
+	Allow func(p *player.Player) bool
+}
+
+// Tools holds the selections, clipboards and edit histories of all players using the building commands.
+type Tools struct {
+	conf Config
+
+	mu     sync.Mutex
+	states map[uuid.UUID]*state
+}
+
+// New returns new Tools using the Config passed.
+func New(conf Config) *Tools {
+	if conf.Wand == nil {
+		conf.Wand = item.Axe{Tier: item.ToolTierWood}
+	}
+	if conf.MaxVolume <= 0 {
+		conf.MaxVolume = 1 << 15
+	}
+	if conf.BlocksPerSecond <= 0 {
+		conf.BlocksPerSecond = 1 << 16
+	}
+	if conf.HistorySize <= 0 {
+		conf.HistorySize = 16
+	}
+	if conf.SchematicDir == "" {
+		conf.SchematicDir = "schematics"
+	}
+	if conf.Allow == nil {
+		conf.Allow = func(p *player.Player) bool {
+			return p.GameMode() == world.GameModeCreative
+		}
+	}
+	return &Tools{conf: conf, states: make(map[uuid.UUID]*state)}
+}
+
+// Register registers all building commands using cmd.Register. The commands are named after their WorldEdit
+// counterparts and are run by players by typing two slashes, such as //set.
+func (t *Tools) Register() {
+	c := command{t: t}
+	cmd.Register(cmd.New("/pos1", "Sets the first corner of the selection.", nil, pos{command: c}))
+	cmd.Register(cmd.New("/pos2", "Sets the second corner of the selection.", nil, pos{command: c, second: true}))
+	cmd.Register(cmd.New("/set", "Sets all blocks in the selection.", nil, set{command: c}))
+	cmd.Register(cmd.New("/replace", "Replaces blocks in the selection.", nil, replace{command: c}))
+	cmd.Register(cmd.New("/copy", "Copies the selection to the clipboard.", nil, copyCmd{command: c}))
+	cmd.Register(cmd.New("/paste", "Pastes the clipboard at your position.", nil, paste{command: c}))
+	cmd.Register(cmd.New("/rotate", "Rotates the clipboard.", nil, rotate{command: c}))
+	cmd.Register(cmd.New("/undo", "Undoes your last edit.", nil, undo{command: c}))
+	cmd.Register(cmd.New("/redo", "Redoes your last undone edit.", nil, undo{command: c, redo: true}))
+	cmd.Register(cmd.New("/schem", "Saves or loads the clipboard as a schematic.", nil, schemSave{command: c}, schemLoad{command: c}))
+}
+
+// state holds the selection, clipboard and edit history of a single player. The fields of a state may only be
+// accessed while holding its mutex.
+type state struct {
+	mu sync.Mutex
+
+	// w is the world that the selection was made in. The selection is discarded when used in a different world.
+	w      *world.World
+	corner [2]cube.Pos
+	set    [2]bool
+	box    *debug.Box
+
+	clipboard *world.Clipboard
+	history   *world.History
+	// next is the time at which the player may make its next edit.
+	next time.Time
+}
+
+// lock returns the state of the player passed with its mutex locked, creating the state if it does not yet exist.
+// The caller must unlock the mutex of the state when done with it.
+func (t *Tools) lock(p *player.Player) *state {
+	t.mu.Lock()
+	s, ok := t.states[p.UUID()]
+	if !ok {
+		s = &state{history: world.NewHistory(t.conf.HistorySize), box: &debug.Box{Colour: color.RGBA{R: 0xff, G: 0xaa, A: 0xff}}}
+		t.states[p.UUID()] = s
+	}
+	t.mu.Unlock()
+
+	s.mu.Lock()
+	return s
+}
+
+// selectCorner sets one of the corners of the selection of the player passed to pos and shows the selection to the
+// player.
+func (t *Tools) selectCorner(p *player.Player, tx *world.Tx, pos cube.Pos, second bool) {
+	s, i := t.lock(p), 0
+	defer s.mu.Unlock()
+	if second {
+		i = 1
+	}
+	if s.w != tx.World() {
+		s.w, s.set = tx.World(), [2]bool{}
+	}
+	s.corner[i], s.set[i] = pos, true
+
+	lo, hi := pos, pos
+	if s.set[0] && s.set[1] {
+		lo, hi = cube.Min(s.corner[0], s.corner[1]), cube.Max(s.corner[0], s.corner[1])
+		size := hi.Sub(lo).Add(cube.Pos{1, 1, 1})
+		p.Messagef("Corner %v set to %v (%v blocks).", i+1, pos, size[0]*size[1]*size[2])
+	} else {
+		p.Messagef("Corner %v set to %v.", i+1, pos)
+	}
+	s.box.Position = lo.Vec3()
+	s.box.Bounds = hi.Sub(lo).Add(cube.Pos{1, 1, 1}).Vec3()
+	p.AddDebugShape(s.box)
+}
+
+// selection returns the selection of the state passed as a cube.BBox. An error is added to the output if no region
+// was selected in the world of the transaction.
+func (s *state) selection(tx *world.Tx, o *cmd.Output) (cube.BBox, bool) {
+	if s.w != tx.World() || !s.set[0] || !s.set[1] {
+		o.Error("You must first select a region using //pos1 and //pos2 or the wand.")
+		return cube.BBox{}, false
+	}
+	lo, hi := cube.Min(s.corner[0], s.corner[1]), cube.Max(s.corner[0], s.corner[1])
+	return cube.Box(float64(lo[0]), float64(lo[1]), float64(lo[2]), float64(hi[0]+1), float64(hi[1]+1), float64(hi[2]+1)), true
+}
+
+// allowEdit checks if the player with the state passed may change the number of blocks passed, taking the maximum
+// volume and the rate limit into account. If not, an error is added to the output and false is returned. If true is
+// returned, the blocks are charged against the rate limit of the player.
+func (t *Tools) allowEdit(s *state, volume int, o *cmd.Output) bool {
+	if !t.withinVolume(volume, o) {
+		return false
+	}
+	now := time.Now()
+	if now.Before(s.next) {
+		o.Errorf("You must wait %.1f seconds before making another edit.", s.next.Sub(now).Seconds())
+		return false
+	}
+	s.next = now.Add(time.Duration(float64(volume) / float64(t.conf.BlocksPerSecond) * float64(time.Second)))
+	return true
+}
+
+// withinVolume checks if the number of blocks passed does not exceed the maximum volume. Unlike allowEdit, it does
+// not take the rate limit into account, so that commands that do not change blocks, such as //copy, are not
+// limited by it. If the volume is exceeded, an error is added to the output and false is returned.
+func (t *Tools) withinVolume(volume int, o *cmd.Output) bool {
+	if volume > t.conf.MaxVolume {
+		o.Errorf("This would change %v blocks, but at most %v blocks may be changed at once.", volume, t.conf.MaxVolume)
+		return false
+	}
+	return true
+}
+
+// remove removes the state of the player passed.
+func (t *Tools) remove(p *player.Player) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.states, p.UUID())
+}
+
+// isWand checks if the item.Stack passed holds the wand item.
+func (t *Tools) isWand(s item.Stack) bool {
+	if s.Empty() {
+		return false
+	}
+	name, meta := s.Item().EncodeItem()
+	wandName, wandMeta := t.conf.Wand.EncodeItem()
+	return name == wandName && meta == wandMeta
+}
+
+// volume returns the number of blocks within the cube.BBox passed.
+func volume(box cube.BBox) int {
+	return int(box.Width()) * int(box.Height()) * int(box.Length())
+}
+
+// blockPos returns the position of the block that the player passed is standing in.
+func blockPos(p *player.Player) cube.Pos {
+	return cube.PosFromVec3(p.Position().Add(mgl64.Vec3{0, 1e-3}))
+}
@@ -0,0 +1,159 @@
+package builder
+
+import (
+	"testing"
+
+	"github.com/df-mc/dragonfly/server/block"
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/cmd"
+	"github.com/df-mc/dragonfly/server/player"
+	"github.com/df-mc/dragonfly/server/world"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// builderTest runs f with a creative player added to a new world, along with
+// the Tools and the commands using them.
+func builderTest(t *testing.T, conf Config, f func(tx *world.Tx, p *player.Player, t *Tools, c command)) {
+	w := world.Config{Synchronous: true}.New()
+	defer w.Close()
+
+	tools := New(conf)
+	h := world.EntitySpawnOpts{Position: mgl64.Vec3{0.5, 0, 0.5}}.New(player.Type, player.Config{Name: "Builder", GameMode: world.GameModeCreative})
+	w.Do(func(tx *world.Tx) {
+		p := tx.AddEntity(h).(*player.Player)
+		defer tx.RemoveEntity(p)
+		f(tx, p, tools, command{t: tools})
+	})
+}
+
+// selectRegion selects the region between the corners passed for the player.
+func selectRegion(tx *world.Tx, p *player.Player, t *Tools, a, b cube.Pos) {
+	t.selectCorner(p, tx, a, false)
+	t.selectCorner(p, tx, b, true)
+}
+
+// errorCount returns the number of errors in the cmd.Output passed after resetting it.
+func errorCount(o *cmd.Output) int {
+	n := o.ErrorCount()
+	*o = cmd.Output{}
+	return n
+}
+
+func TestSelection(t *testing.T) {
+	builderTest(t, Config{}, func(tx *world.Tx, p *player.Player, tools *Tools, _ command) {
+		o := &cmd.Output{}
+		s := tools.lock(p)
+		if _, ok := s.selection(tx, o); ok || errorCount(o) != 1 {
+			t.Fatalf("selection() without corners = true, want false")
+		}
+		s.mu.Unlock()
+
+		tools.selectCorner(p, tx, cube.Pos{4, 1, -2}, false)
+		s = tools.lock(p)
+		if _, ok := s.selection(tx, o); ok || errorCount(o) != 1 {
+			t.Fatalf("selection() with one corner = true, want false")
+		}
+		s.mu.Unlock()
+
+		tools.selectCorner(p, tx, cube.Pos{0, 3, 1}, true)
+		s = tools.lock(p)
+		box, ok := s.selection(tx, o)
+		s.mu.Unlock()
+		if want := cube.Box(0, 1, -2, 5, 4, 2); !ok || box != want {
+			t.Fatalf("selection() = %v, %v, want %v, true", box, ok, want)
+		}
+		if v := volume(box); v != 5*3*4 {
+			t.Fatalf("volume() = %v, want %v", v, 5*3*4)
+		}
+	})
+}
+
+func TestCopyPaste(t *testing.T) {
+	builderTest(t, Config{}, func(tx *world.Tx, p *player.Player, tools *Tools, c command) {
+		o := &cmd.Output{}
+		selectRegion(tx, p, tools, cube.Pos{0, 0, 0}, cube.Pos{1, 1, 0})
+		tx.SetBlock(cube.Pos{0, 0, 0}, block.Stone{}, nil)
+		tx.SetBlock(cube.Pos{1, 1, 0}, block.Dirt{}, nil)
+
+		copyCmd{command: c}.Run(p, o, tx)
+		if errorCount(o) != 0 {
+			t.Fatalf("//copy failed")
+		}
+		p.Teleport(mgl64.Vec3{10.5, 5, 10.5})
+		paste{command: c}.Run(p, o, tx)
+		if errorCount(o) != 0 {
+			t.Fatalf("//paste failed")
+		}
+		// The clipboard is pasted relative to the position of the player
+		// when copying.
+		if _, ok := tx.Block(cube.Pos{10, 5, 10}).(block.Stone); !ok {
+			t.Fatalf("block at (10, 5, 10) after //paste = %v, want stone", tx.Block(cube.Pos{10, 5, 10}))
+		}
+		if _, ok := tx.Block(cube.Pos{11, 6, 10}).(block.Dirt); !ok {
+			t.Fatalf("block at (11, 6, 10) after //paste = %v, want dirt", tx.Block(cube.Pos{11, 6, 10}))
+		}
+	})
+}
+
+func TestRateLimit(t *testing.T) {
+	builderTest(t, Config{BlocksPerSecond: 1, MaxVolume: 8}, func(tx *world.Tx, p *player.Player, tools *Tools, c command) {
+		o := &cmd.Output{}
+		selectRegion(tx, p, tools, cube.Pos{0, 0, 0}, cube.Pos{1, 1, 1})
+
+		// Copying does not change blocks, so it is not limited by the rate
+		// limit.
+		copyCmd{command: c}.Run(p, o, tx)
+		copyCmd{command: c}.Run(p, o, tx)
+		if n := errorCount(o); n != 0 {
+			t.Fatalf("errors after //copy = %v, want 0", n)
+		}
+		set{command: c, Block: blockArg{b: block.Stone{}}}.Run(p, o, tx)
+		if n := errorCount(o); n != 0 {
+			t.Fatalf("errors after //set = %v, want 0", n)
+		}
+		set{command: c, Block: blockArg{b: block.Dirt{}}}.Run(p, o, tx)
+		if n := errorCount(o); n != 1 {
+			t.Fatalf("errors after rate limited //set = %v, want 1", n)
+		}
+
+		selectRegion(tx, p, tools, cube.Pos{0, 0, 0}, cube.Pos{2, 2, 2})
+		copyCmd{command: c}.Run(p, o, tx)
+		if n := errorCount(o); n != 1 {
+			t.Fatalf("errors after //copy exceeding MaxVolume = %v, want 1", n)
+		}
+	})
+}
+
+func TestUndo(t *testing.T) {
+	builderTest(t, Config{}, func(tx *world.Tx, p *player.Player, tools *Tools, c command) {
+		o := &cmd.Output{}
+		pos := cube.Pos{3, 0, 3}
+		selectRegion(tx, p, tools, pos, pos)
+
+		undo{command: c}.Run(p, o, tx)
+		if n := errorCount(o); n != 1 {
+			t.Fatalf("errors after //undo without history = %v, want 1", n)
+		}
+		set{command: c, Block: blockArg{b: block.Stone{}}}.Run(p, o, tx)
+		set{command: c, Block: blockArg{b: block.Dirt{}}}.Run(p, o, tx)
+		if n := errorCount(o); n != 0 {
+			t.Fatalf("errors after //set = %v, want 0", n)
+		}
+
+		undo{command: c}.Run(p, o, tx)
+		if _, ok := tx.Block(pos).(block.Stone); !ok {
+			t.Fatalf("block after //undo = %v, want stone", tx.Block(pos))
+		}
+		undo{command: c}.Run(p, o, tx)
+		if _, ok := tx.Block(pos).(block.Air); !ok {
+			t.Fatalf("block after second //undo = %v, want air", tx.Block(pos))
+		}
+		undo{command: c, redo: true}.Run(p, o, tx)
+		if _, ok := tx.Block(pos).(block.Stone); !ok {
+			t.Fatalf("block after //redo = %v, want stone", tx.Block(pos))
+		}
+		if n := errorCount(o); n != 0 {
+			t.Fatalf("errors after //undo and //redo = %v, want 0", n)
+		}
+	})
+}
@@ -0,0 +1,304 @@
+package builder
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/cmd"
+	"github.com/df-mc/dragonfly/server/player"
+	"github.com/df-mc/dragonfly/server/world"
+	"github.com/sandertv/gophertunnel/minecraft/text"
+)
+
+// command is embedded by all building commands. It limits the commands to players allowed by Config.Allow.
+type command struct {
+	t *Tools
+}
+
+// Allow ...
+func (c command) Allow(src cmd.Source) bool {
+	p, ok := src.(*player.Player)
+	return ok && c.t.conf.Allow(p)
+}
+
+// pos implements the //pos1 and //pos2 commands.
+type pos struct {
+	command
+	second bool
+}
+
+// Run ...
+func (c pos) Run(src cmd.Source, _ *cmd.Output, tx *world.Tx) {
+	p := src.(*player.Player)
+	c.t.selectCorner(p, tx, blockPos(p), c.second)
+}
+
+// set implements the //set command.
+type set struct {
+	command
+	Block blockArg `cmd:"block"`
+}
+
+// Run ...
+func (c set) Run(src cmd.Source, o *cmd.Output, tx *world.Tx) {
+	s := c.t.lock(src.(*player.Player))
+	defer s.mu.Unlock()
+	box, ok := s.selection(tx, o)
+	if !ok || !c.t.allowEdit(s, volume(box), o) {
+		return
+	}
+	s.record(tx.Fill(box, c.Block.b, nil), o)
+}
+
+// replace implements the //replace command.
+type replace struct {
+	command
+	From blockArg `cmd:"from"`
+	To   blockArg `cmd:"to"`
+}
+
+// Run ...
+func (c replace) Run(src cmd.Source, o *cmd.Output, tx *world.Tx) {
+	s := c.t.lock(src.(*player.Player))
+	defer s.mu.Unlock()
+	box, ok := s.selection(tx, o)
+	if !ok || !c.t.allowEdit(s, volume(box), o) {
+		return
+	}
+	s.record(tx.Fill(box, c.To.b, world.MatchBlocks(c.From.b)), o)
+}
+
+// copyCmd implements the //copy command.
+type copyCmd struct {
+	command
+}
+
+// Run ...
+func (c copyCmd) Run(src cmd.Source, o *cmd.Output, tx *world.Tx) {
+	p := src.(*player.Player)
+	s := c.t.lock(p)
+	defer s.mu.Unlock()
+	box, ok := s.selection(tx, o)
+	if !ok || !c.t.withinVolume(volume(box), o) {
+		return
+	}
+	clipboard := tx.Copy(box, blockPos(p))
+	if clipboard == nil {
+		o.Error("The selection holds no blocks within the world height.")
+		return
+	}
+	s.clipboard = clipboard
+	size := clipboard.Dimensions()
+	o.Printf("Copied %vx%vx%v blocks to the clipboard.", size[0], size[1], size[2])
+}
+
+// paste implements the //paste command.
+type paste struct {
+	command
+	IgnoreAir cmd.Optional[bool] `cmd:"ignoreAir"`
+}
+
+// Run ...
+func (c paste) Run(src cmd.Source, o *cmd.Output, tx *world.Tx) {
+	p := src.(*player.Player)
+	s := c.t.lock(p)
+	defer s.mu.Unlock()
+	clipboard, ok := s.loadClipboard(o)
+	if !ok {
+		return
+	}
+	size := clipboard.Dimensions()
+	if !c.t.allowEdit(s, size[0]*size[1]*size[2], o) {
+		return
+	}
+	ignoreAir, _ := c.IgnoreAir.Load()
+	s.record(tx.Paste(blockPos(p), clipboard, ignoreAir), o)
+}
+
+// rotate implements the //rotate command.
+type rotate struct {
+	command
+	Degrees int `cmd:"degrees"`
+}
+
+// Run ...
+func (c rotate) Run(src cmd.Source, o *cmd.Output, _ *world.Tx) {
+	s := c.t.lock(src.(*player.Player))
+	defer s.mu.Unlock()
+	clipboard, ok := s.loadClipboard(o)
+	if !ok {
+		return
+	}
+	if c.Degrees%90 != 0 {
+		o.Error("The clipboard can only be rotated by a multiple of 90 degrees.")
+		return
+	}
+	s.clipboard = clipboard.Rotate(cube.Rotation{float64(c.Degrees)})
+	o.Printf("Rotated the clipboard by %v degrees.", c.Degrees)
+}
+
+// undo implements the //undo and //redo commands.
+type undo struct {
+	command
+	redo bool
+}
+
+// Run ...
+func (c undo) Run(src cmd.Source, o *cmd.Output, tx *world.Tx) {
+	s := c.t.lock(src.(*player.Player))
+	defer s.mu.Unlock()
+	e := s.history.NextUndo()
+	if c.redo {
+		e = s.history.NextRedo()
+	}
+	if e != nil && !c.t.allowEdit(s, e.Volume(), o) {
+		return
+	}
+	switch {
+	case c.redo && s.history.Redo(tx):
+		o.Print("Redid your last undone edit.")
+	case c.redo:
+		o.Error("There is nothing to redo.")
+	case s.history.Undo(tx):
+		o.Print("Undid your last edit.")
+	default:
+		o.Error("There is nothing to undo.")
+	}
+}
+
+// schemSave implements the //schem save command.
+type schemSave struct {
+	command
+	Save cmd.SubCommand `cmd:"save"`
+	Name string         `cmd:"name"`
+}
+
+// Run ...
+func (c schemSave) Run(src cmd.Source, o *cmd.Output, _ *world.Tx) {
+	p := src.(*player.Player)
+	s := c.t.lock(p)
+	clipboard, ok := s.loadClipboard(o)
+	s.mu.Unlock()
+	if !ok {
+		return
+	}
+	path, err := c.t.schematicPath(c.Name)
+	if err != nil {
+		o.Errorf("Could not save schematic %v: %v", c.Name, err)
+		return
+	}
+	// The schematic is written outside the transaction, so that writing it does not stall the world.
+	h, name := p.H(), c.Name
+	go func() {
+		if err := writeSchematic(path, clipboard); err != nil {
+			notify(h, "<red>Could not save schematic %v: %v</red>", name, err)
+			return
+		}
+		notify(h, "Saved the clipboard as schematic %v.", name)
+	}()
+}
+
+// schemLoad implements the //schem load command.
+type schemLoad struct {
+	command
+	Load cmd.SubCommand `cmd:"load"`
+	Name string         `cmd:"name"`
+}
+
+// Run ...
+func (c schemLoad) Run(src cmd.Source, o *cmd.Output, _ *world.Tx) {
+	path, err := c.t.schematicPath(c.Name)
+	if err != nil {
+		o.Errorf("Could not load schematic %v: %v", c.Name, err)
+		return
+	}
+	// The schematic is read outside the transaction, so that reading it does not stall the world.
+	p := src.(*player.Player)
+	h, name := p.H(), c.Name
+	go func() {
+		clipboard, err := readSchematic(path)
+		if err != nil {
+			notify(h, "<red>Could not load schematic %v: %v</red>", name, err)
+			return
+		}
+		h.Do(func(_ *world.Tx, e world.Entity) {
+			p := e.(*player.Player)
+			s := c.t.lock(p)
+			s.clipboard = clipboard
+			s.mu.Unlock()
+			p.Messagef("Loaded schematic %v into the clipboard.", name)
+		})
+	}()
+}
+
+// record adds the world.Edit passed to the history of the state passed and reports the number of blocks changed.
+func (s *state) record(e *world.Edit, o *cmd.Output) {
+	if e == nil {
+		o.Error("The region holds no blocks within the world height.")
+		return
+	}
+	s.history.Add(e)
+	o.Printf("Changed %v blocks.", e.Volume())
+}
+
+// loadClipboard returns the clipboard of the state passed. An error is added to the output if the clipboard is
+// empty.
+func (s *state) loadClipboard(o *cmd.Output) (*world.Clipboard, bool) {
+	c := s.clipboard
+	if c == nil {
+		o.Error("Your clipboard is empty. Use //copy or //schem load first.")
+		return nil, false
+	}
+	return c, true
+}
+
+// schematicName matches valid schematic names. Names are limited so that they cannot refer to files outside of
+// Config.SchematicDir.
+var schematicName = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// schematicPath returns the path of the schematic file with the name passed.
+func (t *Tools) schematicPath(name string) (string, error) {
+	if !schematicName.MatchString(name) {
+		return "", errors.New("name may only contain letters, digits, '_' and '-'")
+	}
+	return filepath.Join(t.conf.SchematicDir, name+".mcstructure"), nil
+}
+
+// readSchematic reads a world.Clipboard from the .mcstructure file at the path passed.
+func readSchematic(path string) (*world.Clipboard, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return world.ReadStructure(f, nil)
+}
+
+// writeSchematic writes the world.Clipboard passed to a .mcstructure file at the path passed.
+func writeSchematic(path string, c *world.Clipboard) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		return fmt.Errorf("create directory: %w", err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := c.WriteStructure(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// notify sends a message, formatted using text.Colourf, to the player with the world.EntityHandle passed. It is used
+// to report the result of work done outside a transaction after the command has already returned.
+func notify(h *world.EntityHandle, format string, a ...any) {
+	msg := text.Colourf(format, a...)
+	h.Do(func(_ *world.Tx, e world.Entity) {
+		e.(*player.Player).Message(msg)
+	})
+}
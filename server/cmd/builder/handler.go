@@ -0,0 +1,56 @@
+package builder
+
+import (
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/item"
+	"github.com/df-mc/dragonfly/server/player"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// Handler returns a player.Handler that handles the use of the wand by players and removes the state of players
+// when they quit. All other events, as well as wand events of players not allowed to use the wand, are passed to h,
+// which may be nil.
+func (t *Tools) Handler(h player.Handler) player.Handler {
+	if h == nil {
+		h = player.NopHandler{}
+	}
+	return handler{Handler: h, t: t}
+}
+
+// handler implements player.Handler for Tools.
+type handler struct {
+	player.Handler
+	t *Tools
+}
+
+// HandleBlockBreak selects the first corner of the selection if the player breaks a block with the wand.
+func (h handler) HandleBlockBreak(ctx *player.Context, pos cube.Pos, drops *[]item.Stack, xp *int) {
+	if p := ctx.Player(); h.usesWand(p) {
+		ctx.Cancel()
+		h.t.selectCorner(p, p.Tx(), pos, false)
+		return
+	}
+	h.Handler.HandleBlockBreak(ctx, pos, drops, xp)
+}
+
+// HandleItemUseOnBlock selects the second corner of the selection if the player uses the wand on a block.
+func (h handler) HandleItemUseOnBlock(ctx *player.Context, pos cube.Pos, face cube.Face, clickPos mgl64.Vec3) {
+	if p := ctx.Player(); h.usesWand(p) {
+		ctx.Cancel()
+		h.t.selectCorner(p, p.Tx(), pos, true)
+		return
+	}
+	h.Handler.HandleItemUseOnBlock(ctx, pos, face, clickPos)
+}
+
+// HandleQuit removes the state of the player.
+func (h handler) HandleQuit(p *player.Player) {
+	h.t.remove(p)
+	h.Handler.HandleQuit(p)
+}
+
+// usesWand checks if the player passed holds the wand in its main hand and is allowed to use it.
+func (h handler) usesWand(p *player.Player) bool {
+	held, _ := p.HeldItems()
+	return h.t.isWand(held) && h.t.conf.Allow(p)
+}
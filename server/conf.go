@@ -3,6 +3,8 @@ package server
 import (
 	"fmt"
 	"log/slog"
+	"net"
+	"net/netip"
 	"os"
 	"path/filepath"
 	"slices"
@@ -15,6 +17,7 @@ import (
 	"github.com/df-mc/dragonfly/server/player"
 	"github.com/df-mc/dragonfly/server/player/chat"
 	"github.com/df-mc/dragonfly/server/player/playerdb"
+	"github.com/df-mc/dragonfly/server/session"
 	"github.com/df-mc/dragonfly/server/world"
 	"github.com/df-mc/dragonfly/server/world/biome"
 	"github.com/df-mc/dragonfly/server/world/generator"
@@ -65,6 +68,30 @@ type Config struct {
 	// MaxPlayers is the maximum amount of players allowed to join the server at
 	// once.
 	MaxPlayers int
+	// RecipeUnlocking specifies if players should only see and be able to
+	// craft recipes that they unlocked, for example by obtaining one of the
+	// ingredients of the recipe or through player.Player.UnlockRecipes. If
+	// false, all recipes are available to every player.
+	RecipeUnlocking bool
+	// ValidateMovement specifies if the movement of players should be
+	// simulated by the server and compared to the movement claimed by their
+	// clients. Players that move to a position other than the one predicted
+	// are corrected, unless the violation is cancelled in
+	// player.Handler.HandleViolation.
+	ValidateMovement bool
+	// ValidateCombat specifies if attacks of players should be checked for
+	// reach, line of sight and the number of clicks per second. Targets are
+	// rewound by the latency of the player for these checks. Attacks that fail
+	// these checks are cancelled, unless the violation is cancelled in
+	// player.Handler.HandleViolation.
+	ValidateCombat bool
+	// ValidateBlocks specifies if the time players take to break blocks should
+	// be checked against the break duration of the block, and if the blocks
+	// players break or interact with should be checked for reach, face
+	// visibility and, when placing blocks, collision with entities. Rejected
+	// actions are reverted by resending the blocks, unless the violation is
+	// cancelled in player.Handler.HandleViolation.
+	ValidateBlocks bool
 	// MaxChunkRadius is the maximum view distance that each player may have,
 	// measured in chunks. A chunk radius generally leads to more memory usage.
 	MaxChunkRadius int
@@ -75,13 +102,44 @@ type Config struct {
 	// joining or quitting.
 	// ShutdownMessage is set to chat.MessageServerDisconnect if empty.
 	JoinMessage, QuitMessage, ShutdownMessage chat.Translation
+	// DeathMessages specifies if messages describing the deaths of players
+	// are written to chat.Global. Death messages are only written in worlds
+	// with world.GameRuleShowDeathMessages enabled.
+	DeathMessages bool
 	// StatusProvider provides the server status shown to players in the server
 	// list. By default, StatusProvider will show the server name from the Name
-	// field and the current player count and maximum players.
+	// field and the current player count and maximum players. If
+	// StatusProvider implements PingStatusProvider, the default listener
+	// shows a status specific to every address pinging the server.
 	StatusProvider minecraft.ServerStatusProvider
 	// Compression is the packet compression used for connections accepted by
 	// the default listener. If nil, gophertunnel's default compression is used.
 	Compression packet.Compression
+	// PacketFunc is called for every packet read from or written to a
+	// connection accepted by the default listener, with the header and
+	// payload of the packet and the source and destination address. It may be
+	// used to monitor network traffic and must be safe for concurrent use.
+	PacketFunc func(header packet.Header, payload []byte, src, dst net.Addr)
+	// PacketMiddleware is the chain of session.Middleware that packets
+	// received from and sent to every player pass through, in order. It may be
+	// used to observe, modify or drop packets, for example to implement
+	// custom protocol extensions.
+	PacketMiddleware []session.Middleware
+	// PacketRateLimits holds the session.RateLimit of every category of
+	// packets that players may send. Packets exceeding a limit are dropped,
+	// and players that keep sending them are warned or disconnected, unless
+	// cancelled in player.Handler.HandleRateLimit. Players send a packet every
+	// tick, so the limit of session.PacketCategoryAll should allow at least 20
+	// packets per second.
+	PacketRateLimits map[session.PacketCategory]session.RateLimit
+	// ConnectionRate is the maximum average number of connections per second
+	// that the default listener accepts from a single IP address. Connections
+	// exceeding the rate are closed before logging in. ConnectionBurst is the
+	// maximum number of connections accepted at once from a single IP
+	// address, which defaults to ConnectionRate rounded up. If ConnectionRate
+	// is 0, connections are not limited.
+	ConnectionRate  float64
+	ConnectionBurst int
 	// PlayerProvider is the player.Provider used for storing and loading player
 	// data. If left as nil, player data will be newly created every time a
 	// player joins the server and no data will be stored.
@@ -125,10 +183,35 @@ type Config struct {
 	// may be added to the Server's worlds. If no entity types are registered,
 	// Entities will be set to entity.DefaultRegistry.
 	Entities world.EntityRegistry
+	// WorldsFile is the path of the file that the worlds added using
+	// Server.AddWorld are stored in, so that they are added again when the
+	// Server is restarted. If empty, worlds added are not stored.
+	WorldsFile string
+	// WorldLoader returns the world.Provider and world.Generator to use for a
+	// world added using Server.AddWorld. If nil, WorldLoader stores the world
+	// in WorldConfig.Folder using the LevelDB provider and supports the 'flat'
+	// and 'void' generators, using Generator if WorldConfig.Generator is
+	// empty.
+	WorldLoader func(conf WorldConfig) (world.Provider, world.Generator, error)
 	// Blocks is the BlockRegistry template used for newly created worlds. If nil, world.DefaultBlockRegistry is used.
 	// For a non-default registry, set this to world.NewBlockRegistry(), register blocks on that instance, and ensure
 	// it is finalized before use.
 	Blocks world.BlockRegistry
+	// WorldObserver is set as the world.Observer of all worlds of the Server,
+	// including worlds added using Server.AddWorld. It may be used to export
+	// metrics of the time spent ticking worlds and loading, generating and
+	// saving chunks.
+	WorldObserver world.Observer
+	// Recorder returns the world.Recorder that records the default world of
+	// the dimension passed, so that it may be replayed using a world.Replay.
+	// If nil or if nil is returned, the world is not recorded.
+	Recorder func(dim world.Dimension) *world.Recorder
+	// ActivationRanges is set as the world.Config.ActivationRanges of all
+	// worlds of the Server, including worlds added using Server.AddWorld.
+	// Entities of an ActivationCategory with an ActivationRange are ticked
+	// less frequently, or not at all, when far away from players. If nil, all
+	// entities are ticked every tick.
+	ActivationRanges map[world.ActivationCategory]world.ActivationRange
 }
 
 // New creates a Server using fields of conf. The Server's worlds are created
@@ -159,6 +242,9 @@ func (conf Config) New() *Server {
 	if conf.Generator == nil {
 		conf.Generator = loadGenerator
 	}
+	if conf.WorldLoader == nil {
+		conf.WorldLoader = defaultWorldLoader(conf)
+	}
 	if conf.MaxChunkRadius == 0 {
 		conf.MaxChunkRadius = 12
 	}
@@ -189,6 +275,8 @@ func (conf Config) New() *Server {
 		conf:     conf,
 		incoming: make(chan incoming),
 		p:        make(map[uuid.UUID]*onlinePlayer),
+		worlds:   make(map[string]*managedWorld),
+		closing:  make(chan struct{}),
 		world:    &world.World{}, nether: &world.World{}, end: &world.World{},
 	}
 	for _, lf := range conf.Listeners {
@@ -205,6 +293,7 @@ func (conf Config) New() *Server {
 	srv.world = srv.createWorld(world.Overworld, &srv.nether, &srv.end)
 	srv.nether = srv.createWorld(world.Nether, &srv.world, &srv.end)
 	srv.end = srv.createWorld(world.End, &srv.nether, &srv.world)
+	srv.loadWorlds()
 
 	return srv
 }
@@ -229,9 +318,34 @@ type UserConfig struct {
 		// DisableJoinQuitMessages specifies if default join and quit messages
 		// for players should be disabled.
 		DisableJoinQuitMessages bool
+		// DeathMessages specifies if messages describing the deaths of
+		// players should be broadcast to all players.
+		DeathMessages bool
 		// MuteEmoteChat specifies if the player emote chat should be muted or not.
 		MuteEmoteChat bool
 	}
+	Status struct {
+		// MOTD holds the lines shown as the name of the server in the server
+		// list. The lines are shown in turn, switching every Interval seconds.
+		// If empty, Server.Name is shown.
+		MOTD []string
+		// SubMOTD holds the lines shown as the sub-name of the server in the
+		// friend list, which are shown in turn like MOTD.
+		SubMOTD []string
+		// Interval is the number of seconds after which the next line of MOTD
+		// and SubMOTD is shown. If 0, the lines switch every 5 seconds.
+		Interval float64
+		// Overrides hold the MOTD and SubMOTD shown to specific addresses,
+		// such as those of a local network.
+		Overrides []struct {
+			// Addresses are the IP addresses and ranges in CIDR notation that
+			// the override applies to.
+			Addresses []string
+			// MOTD and SubMOTD replace the lines shown to the addresses. If
+			// empty, the lines of the Status are shown.
+			MOTD, SubMOTD []string
+		}
+	}
 	World struct {
 		// SaveData controls whether a world's data will be saved and loaded.
 		// If true, the server will use the default LevelDB data provider and if
@@ -241,6 +355,9 @@ type UserConfig struct {
 		SaveData bool
 		// Folder is the folder that the data of the world resides in.
 		Folder string
+		// WorldsFile is the file that worlds added to the server at runtime
+		// are stored in, so that they are added again after a restart.
+		WorldsFile string
 	}
 	Players struct {
 		// MaxCount is the maximum amount of players allowed to join the server
@@ -285,9 +402,18 @@ func (uc UserConfig) Config(log *slog.Logger) (Config, error) {
 		ResourcesRequired:       uc.Resources.Required,
 		AuthDisabled:            !uc.Server.AuthEnabled,
 		MuteEmoteChat:           uc.Server.MuteEmoteChat,
+		DeathMessages:           uc.Server.DeathMessages,
 		MaxPlayers:              uc.Players.MaxCount,
 		MaxChunkRadius:          uc.Players.MaximumChunkRadius,
 		DisableResourceBuilding: !uc.Resources.AutoBuildPack,
+		WorldsFile:              uc.World.WorldsFile,
+	}
+	status, err := uc.statusProvider()
+	if err != nil {
+		return conf, fmt.Errorf("create status provider: %w", err)
+	}
+	if status != nil {
+		conf.StatusProvider = status
 	}
 	if !uc.Server.DisableJoinQuitMessages {
 		conf.JoinMessage, conf.QuitMessage = chat.MessageJoin, chat.MessageQuit
@@ -312,6 +438,39 @@ func (uc UserConfig) Config(log *slog.Logger) (Config, error) {
 	return conf, nil
 }
 
+// statusProvider creates the StatusProvider configured in the Status section
+// of the UserConfig. Nil is returned if the Status section is empty, in which
+// case the default status provider is used.
+func (uc UserConfig) statusProvider() (*StatusProvider, error) {
+	if len(uc.Status.MOTD) == 0 && len(uc.Status.SubMOTD) == 0 && uc.Status.Interval == 0 && len(uc.Status.Overrides) == 0 {
+		return nil, nil
+	}
+	conf := StatusConfig{
+		MOTD:     uc.Status.MOTD,
+		SubMOTD:  uc.Status.SubMOTD,
+		Interval: time.Duration(uc.Status.Interval * float64(time.Second)),
+	}
+	if len(conf.MOTD) == 0 {
+		conf.MOTD = []string{uc.Server.Name}
+	}
+	for _, o := range uc.Status.Overrides {
+		override := StatusOverride{MOTD: o.MOTD, SubMOTD: o.SubMOTD}
+		for _, addr := range o.Addresses {
+			prefix, err := netip.ParsePrefix(addr)
+			if err != nil {
+				ip, ipErr := netip.ParseAddr(addr)
+				if ipErr != nil {
+					return nil, fmt.Errorf("parse override address %v: %w", addr, err)
+				}
+				prefix = netip.PrefixFrom(ip, ip.BitLen())
+			}
+			override.Prefixes = append(override.Prefixes, prefix)
+		}
+		conf.Overrides = append(conf.Overrides, override)
+	}
+	return NewStatusProvider(conf), nil
+}
+
 // loadResources loads all resource packs found in a directory passed.
 func loadResources(dir string) ([]*resource.Pack, error) {
 	_ = os.MkdirAll(dir, 0777)
@@ -353,6 +512,7 @@ func DefaultConfig() UserConfig {
 	c.Server.AuthEnabled = true
 	c.World.SaveData = true
 	c.World.Folder = "world"
+	c.World.WorldsFile = "worlds.json"
 	c.Players.MaximumChunkRadius = 32
 	c.Players.SaveData = true
 	c.Players.Folder = "players"
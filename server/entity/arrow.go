@@ -63,6 +63,9 @@ func (t arrowType) Open(tx *world.Tx, handle *world.EntityHandle, data *world.En
 }
 
 func (arrowType) EncodeEntity() string { return "minecraft:arrow" }
+func (arrowType) ActivationCategory() world.ActivationCategory {
+	return world.ActivationProjectiles
+}
 func (arrowType) BBox(world.Entity) cube.BBox {
 	return cube.Box(-0.125, 0, -0.125, 0.125, 0.25, 0.125)
 }
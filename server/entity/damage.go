@@ -49,6 +49,10 @@ type (
 		// Source is the source of the explosion that dealt the damage.
 		Source world.ExplosionSource
 	}
+
+	// WorldBorderDamageSource is used for damage caused by an entity being
+	// beyond the world border.
+	WorldBorderDamageSource struct{}
 )
 
 func (FallDamageSource) ReducedByArmour() bool     { return false }
@@ -95,4 +99,8 @@ func (ExplosionDamageSource) Fire() bool                { return false }
 func (ExplosionDamageSource) AffectedByEnchantment(e item.EnchantmentType) bool {
 	return e == enchantment.BlastProtection
 }
-func (ExplosionDamageSource) IgnoreTotem() bool { return false }
+func (ExplosionDamageSource) IgnoreTotem() bool           { return false }
+func (WorldBorderDamageSource) ReducedByArmour() bool     { return false }
+func (WorldBorderDamageSource) ReducedByResistance() bool { return true }
+func (WorldBorderDamageSource) Fire() bool                { return false }
+func (WorldBorderDamageSource) IgnoreTotem() bool         { return false }
@@ -170,6 +170,26 @@ func (e *Ent) Tick(tx *world.Tx, current int64) {
 	e.data.Age += time.Second / 20
 }
 
+// CatchUp advances the Ent by ticks that were skipped because it was outside
+// its activation range, as configured using world.Config.ActivationRanges.
+// Its fire duration progresses and, if its Behaviour supports it, it moves as
+// it would have over the ticks skipped, so that it still falls to the ground.
+// Its age progresses by at most maxCatchUpTicks, so that items dropped out of
+// range of players do not despawn as soon as a player comes close.
+func (e *Ent) CatchUp(tx *world.Tx, ticks int64) {
+	if c, ok := e.Behaviour().(catchUpBehaviour); ok {
+		c.catchUp(e, tx, ticks)
+	}
+	e.SetOnFire(e.OnFireDuration() - time.Duration(ticks)*(time.Second/20))
+	e.data.Age += time.Duration(min(ticks, maxCatchUpTicks)) * (time.Second / 20)
+}
+
+// catchUpBehaviour is a Behaviour that is able to catch up on ticks skipped
+// while the Ent was inactive.
+type catchUpBehaviour interface {
+	catchUp(e *Ent, tx *world.Tx, ticks int64)
+}
+
 // Close closes the Ent and removes the associated entity from the world.
 func (e *Ent) Close() error {
 	e.once.Do(func() {
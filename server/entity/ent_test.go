@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/df-mc/dragonfly/server/item"
+	"github.com/df-mc/dragonfly/server/world"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestEntCatchUp(t *testing.T) {
+	w := world.Config{Synchronous: true}.New()
+	defer w.Close()
+
+	h := NewItem(world.EntitySpawnOpts{Position: mgl64.Vec3{0.5, 64, 0.5}}, item.NewStack(item.Stick{}, 1))
+	w.Do(func(tx *world.Tx) {
+		e := tx.AddEntity(h).(*Ent)
+		e.SetOnFire(time.Minute)
+
+		// An item skipped for longer than it takes to despawn does not age
+		// beyond maxCatchUpTicks.
+		e.CatchUp(tx, 20*60*10)
+		if want := time.Duration(maxCatchUpTicks) * (time.Second / 20); e.Age() != want {
+			t.Fatalf("Age() after CatchUp() = %v, want %v", e.Age(), want)
+		}
+		if e.OnFireDuration() != 0 {
+			t.Fatalf("OnFireDuration() after CatchUp() = %v, want 0", e.OnFireDuration())
+		}
+		e.CatchUp(tx, 20)
+		if want := time.Duration(maxCatchUpTicks+20) * (time.Second / 20); e.Age() != want {
+			t.Fatalf("Age() after second CatchUp() = %v, want %v", e.Age(), want)
+		}
+	})
+}
@@ -51,6 +51,9 @@ func (t experienceOrbType) Open(tx *world.Tx, handle *world.EntityHandle, data *
 }
 
 func (experienceOrbType) EncodeEntity() string { return "minecraft:xp_orb" }
+func (experienceOrbType) ActivationCategory() world.ActivationCategory {
+	return world.ActivationExperienceOrbs
+}
 func (experienceOrbType) BBox(world.Entity) cube.BBox {
 	return cube.Box(-0.125, 0, -0.125, 0.125, 0.25, 0.125)
 }
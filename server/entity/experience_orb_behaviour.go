@@ -70,6 +70,12 @@ func (exp *ExperienceOrbBehaviour) Tick(e *Ent, tx *world.Tx) *Movement {
 	return exp.passive.Tick(e, tx)
 }
 
+// catchUp moves the experience orb as it would have moved over the ticks
+// passed.
+func (exp *ExperienceOrbBehaviour) catchUp(e *Ent, tx *world.Tx, ticks int64) {
+	exp.passive.catchUp(e, tx, ticks)
+}
+
 // followBox is the bounding box used to search for collectors to follow for experience orbs.
 var followBox = cube.Box(-8, -8, -8, 8, 8, 8)
 
@@ -82,6 +88,9 @@ func (exp *ExperienceOrbBehaviour) tick(e *Ent, tx *world.Tx) {
 	hasTarget := ok && !target.Dead() && pos.Sub(target.Position()).Len() <= 8
 	if !hasTarget && time.Since(exp.lastSearch) >= time.Second {
 		exp.findTarget(tx, pos)
+		if exp.target == nil {
+			exp.mergeNearby(e, tx)
+		}
 	} else if hasTarget {
 		exp.moveToTarget(e, target)
 	}
@@ -99,6 +108,44 @@ func (exp *ExperienceOrbBehaviour) findTarget(tx *world.Tx, pos mgl64.Vec3) {
 	exp.lastSearch = time.Now()
 }
 
+// orbMergeBox is the bounding box in which experience orbs without a target
+// merge with other experience orbs.
+var orbMergeBox = cube.Box(-1, -0.5, -1, 1, 0.5, 1)
+
+// maxMergedExperience is the maximum amount of experience that an experience
+// orb may hold after merging with other orbs.
+var maxMergedExperience = orbSplitSizes[0]
+
+// mergeNearby merges the experience orb with other experience orbs close to
+// it, so that dense piles of orbs reduce to a few orbs holding all of their
+// experience.
+func (exp *ExperienceOrbBehaviour) mergeNearby(e *Ent, tx *world.Tx) {
+	var merged []*Ent
+	for o := range tx.EntitiesWithin(orbMergeBox.Translate(e.Position())) {
+		if o.H() == e.H() || o.H().Type() != ExperienceOrbType {
+			continue
+		}
+		other := o.(*Ent)
+		xp := other.Behaviour().(*ExperienceOrbBehaviour).conf.Experience
+		if exp.conf.Experience+xp > maxMergedExperience {
+			continue
+		}
+		exp.conf.Experience += xp
+		merged = append(merged, other)
+	}
+	if len(merged) == 0 {
+		return
+	}
+	for _, other := range merged {
+		_ = other.Close()
+	}
+	// The size of an orb depends on its experience, so viewers must be
+	// updated after merging.
+	for _, v := range tx.Viewers(e.Position()) {
+		v.ViewEntityState(e)
+	}
+}
+
 // moveToTarget applies velocity to the experience orb so that it moves towards
 // its current target. If it intersects with the target, the orb is collected.
 func (exp *ExperienceOrbBehaviour) moveToTarget(e *Ent, target experienceCollector) {
@@ -58,6 +58,12 @@ func (f *FallingBlockBehaviour) Tick(e *Ent, tx *world.Tx) *Movement {
 	return f.passive.Tick(e, tx)
 }
 
+// catchUp moves the falling block as it would have moved over the ticks
+// passed. It solidifies on its next tick if it reached the ground.
+func (f *FallingBlockBehaviour) catchUp(e *Ent, tx *world.Tx, ticks int64) {
+	f.passive.catchUp(e, tx, ticks)
+}
+
 // tick checks if the falling block should solidify.
 func (f *FallingBlockBehaviour) tick(e *Ent, tx *world.Tx) {
 	pos := e.Position()
@@ -84,7 +90,7 @@ func (f *FallingBlockBehaviour) solidify(e *Ent, pos mgl64.Vec3, tx *world.Tx) {
 
 	if r, ok := tx.Block(bpos).(replaceable); ok && r.ReplaceableBy(f.block) {
 		tx.SetBlock(bpos, f.block, nil)
-	} else if i, ok := f.block.(world.Item); ok {
+	} else if i, ok := f.block.(world.Item); ok && world.GameRuleDoEntityDrops.Value(tx) {
 		opts := world.EntitySpawnOpts{Position: bpos.Vec3Middle()}
 		tx.AddEntity(NewItem(opts, item.NewStack(i, 1)))
 	}
@@ -44,6 +44,9 @@ func (t itemType) Open(tx *world.Tx, handle *world.EntityHandle, data *world.Ent
 
 func (itemType) EncodeEntity() string   { return "minecraft:item" }
 func (itemType) NetworkOffset() float64 { return 0.125 }
+func (itemType) ActivationCategory() world.ActivationCategory {
+	return world.ActivationItems
+}
 func (itemType) BBox(world.Entity) cube.BBox {
 	return cube.Box(-0.125, 0, -0.125, 0.125, 0.25, 0.125)
 }
@@ -102,6 +102,15 @@ func (i *ItemBehaviour) Tick(e *Ent, tx *world.Tx) *Movement {
 	return i.passive.Tick(e, tx)
 }
 
+// catchUp moves the item as it would have moved over the ticks passed and
+// progresses its pickup delay.
+func (i *ItemBehaviour) catchUp(e *Ent, tx *world.Tx, ticks int64) {
+	i.passive.catchUp(e, tx, ticks)
+	if i.pickupDelay < math.MaxInt16*(time.Second/20) {
+		i.pickupDelay = max(i.pickupDelay-time.Duration(ticks)*(time.Second/20), 0)
+	}
+}
+
 // Explode reacts to explosions. The item entity is destroyed, unless the item
 // type is blast proof.
 func (i *ItemBehaviour) Explode(e *Ent, _ world.ExplosionSource, impact float64) {
@@ -124,48 +133,88 @@ func (i *ItemBehaviour) tick(e *Ent, tx *world.Tx) {
 
 // checkNearby checks the nearby entities for item collectors and other item
 // stacks. If a collector is found in range, the item will be picked up. If
-// another item stack with the same item type is found in range, the item
+// other item stacks with the same item type are found in range, the item
 // stacks will merge.
 func (i *ItemBehaviour) checkNearby(e *Ent, tx *world.Tx) {
 	pos := e.Position()
 	bbox := e.H().Type().BBox(e)
 	grown := bbox.GrowVec3(mgl64.Vec3{1, 0.5, 1}).Translate(pos)
+	merge := i.mergeTick(e)
 
+	var pile []*Ent
 	for other := range tx.EntitiesWithin(bbox.Translate(pos).Grow(2)) {
-		if e.H() == other.H() || !other.H().Type().BBox(other).Translate(other.Position()).IntersectsWith(grown) {
+		collector, isCollector := other.(Collector)
+		if e.H() == other.H() || (!isCollector && (!merge || other.H().Type() != ItemType)) {
+			continue
+		}
+		if !other.H().Type().BBox(other).Translate(other.Position()).IntersectsWith(grown) {
 			continue
 		}
-		if collector, ok := other.(Collector); ok {
+		if isCollector {
 			// A collector was within range to pick up the entity.
 			i.collect(e, collector, tx)
 			return
-		} else if other.H().Type() == ItemType {
-			// Another item entity was in range to merge with.
-			if i.merge(e, other.(*Ent), tx) {
-				return
-			}
 		}
+		pile = append(pile, other.(*Ent))
+	}
+	if len(pile) > 0 {
+		i.merge(e, pile, tx)
 	}
 }
 
-// merge merges the item entity with another item entity.
-func (i *ItemBehaviour) merge(e *Ent, other *Ent, tx *world.Tx) bool {
-	pos := e.Position()
-	otherBehaviour := other.Behaviour().(*ItemBehaviour)
-	if otherBehaviour.i.Count() == otherBehaviour.i.MaxCount() || i.i.Count() == i.i.MaxCount() || !i.i.Comparable(otherBehaviour.i) {
-		// Either stack is already filled up to the maximum, meaning we can't
-		// change anything any way, other the stack types weren't comparable.
-		return false
+// mergeTick checks if the item should search for nearby item stacks to merge
+// with during the current tick. Moving items search every other tick, while
+// items at rest only search every 2 seconds, so that large piles of items do
+// not compare every pair of items every tick.
+func (i *ItemBehaviour) mergeTick(e *Ent) bool {
+	ticks := int64(e.Age() / (time.Second / 20))
+	if e.Velocity().ApproxEqualThreshold(zeroVec3, epsilon) {
+		return ticks%40 == 0
 	}
-	a, b := otherBehaviour.i.AddStack(i.i)
+	return ticks%2 == 0
+}
 
-	tx.AddEntity(NewItem(world.EntitySpawnOpts{Position: other.Position(), Velocity: other.Velocity()}, a))
-	if !b.Empty() {
-		tx.AddEntity(NewItem(world.EntitySpawnOpts{Position: pos, Velocity: e.Velocity()}, b))
+// merge merges the item entity with the item entities passed. As many stacks
+// as fit are combined into a single item entity at once, so that dense piles
+// of items merge in as few ticks as possible.
+func (i *ItemBehaviour) merge(e *Ent, others []*Ent, tx *world.Tx) {
+	var (
+		stack    = i.i
+		merged   []*Ent
+		leftover item.Stack
+		leftPos  mgl64.Vec3
+		leftVel  mgl64.Vec3
+	)
+	for _, other := range others {
+		if stack.Count() >= stack.MaxCount() {
+			break
+		}
+		o := other.Behaviour().(*ItemBehaviour).i
+		if o.Count() >= o.MaxCount() || !stack.Comparable(o) {
+			// The other stack is already full or the stack types weren't
+			// comparable.
+			continue
+		}
+		stack, leftover = stack.AddStack(o)
+		merged = append(merged, other)
+		if !leftover.Empty() {
+			// The other stack only partially fit: Spawn the rest of it where
+			// it was and stop merging.
+			leftPos, leftVel = other.Position(), other.Velocity()
+			break
+		}
+	}
+	if len(merged) == 0 {
+		return
+	}
+	tx.AddEntity(NewItem(world.EntitySpawnOpts{Position: e.Position(), Velocity: e.Velocity()}, stack))
+	if !leftover.Empty() {
+		tx.AddEntity(NewItem(world.EntitySpawnOpts{Position: leftPos, Velocity: leftVel}, leftover))
 	}
 	_ = e.Close()
-	_ = other.Close()
-	return true
+	for _, other := range merged {
+		_ = other.Close()
+	}
 }
 
 // collect makes a collector collect the item (or at least part of it).
@@ -68,6 +68,18 @@ func (c *MovementComputer) TickMovement(e world.Entity, pos, vel mgl64.Vec3, rot
 	velBefore := vel
 	vel = c.applyHorizontalForces(tx, pos, c.applyVerticalForces(vel))
 	dPos, vel := c.CheckCollision(tx, e, pos, vel)
+	if b := tx.Border(); b.Within(pos) {
+		// Entities within the world border are unable to move beyond it.
+		if res := b.Clamp(pos.Add(dPos)); res != pos.Add(dPos) {
+			if res[0] != pos[0]+dPos[0] {
+				vel[0] = 0
+			}
+			if res[2] != pos[2]+dPos[2] {
+				vel[2] = 0
+			}
+			dPos = res.Sub(pos)
+		}
+	}
 
 	return &Movement{v: viewers, e: e,
 		pos: pos.Add(dPos), vel: vel, dpos: dPos, dvel: vel.Sub(velBefore),
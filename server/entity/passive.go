@@ -76,6 +76,30 @@ func (p *PassiveBehaviour) Fuse() time.Duration {
 	return -1
 }
 
+// maxCatchUpTicks is the maximum number of ticks an entity catches up on when
+// it becomes active again: Movement is simulated and its age progresses for at
+// most this many ticks.
+const maxCatchUpTicks = 200
+
+// catchUp moves the entity as it would have moved over the ticks passed, until
+// it comes to rest.
+func (p *PassiveBehaviour) catchUp(e *Ent, tx *world.Tx, ticks int64) {
+	start := e.data.Pos
+	for range min(ticks, maxCatchUpTicks) {
+		m := p.mc.TickMovement(e, e.data.Pos, e.data.Vel, e.data.Rot, tx)
+		e.data.Pos, e.data.Vel = m.pos, m.vel
+		p.fallDistance = math.Max(p.fallDistance-m.dpos[1], 0)
+		if p.mc.OnGround() && m.vel.ApproxEqualThreshold(zeroVec3, epsilon) {
+			break
+		}
+	}
+	if !e.data.Pos.ApproxEqualThreshold(start, epsilon) {
+		for _, v := range tx.Viewers(e.data.Pos) {
+			v.ViewEntityMovement(e, e.data.Pos, e.data.Rot, p.mc.OnGround())
+		}
+	}
+}
+
 // Tick implements the behaviour for a passive entity. It performs movement and
 // updates its state.
 func (p *PassiveBehaviour) Tick(e *Ent, tx *world.Tx) *Movement {
@@ -47,6 +47,7 @@ func (t tntType) Open(tx *world.Tx, handle *world.EntityHandle, data *world.Enti
 }
 
 func (tntType) EncodeEntity() string   { return "minecraft:tnt" }
+func (tntType) PrimedTNT()             {}
 func (tntType) NetworkOffset() float64 { return 0.49 }
 func (tntType) BBox(world.Entity) cube.BBox {
 	return cube.Box(-0.49, 0, -0.49, 0.49, 0.98, 0.49)
@@ -0,0 +1,187 @@
+// Package proxyproto implements reading and writing headers of version 2 of
+// the PROXY protocol, which proxies use to pass the address of the client
+// that a connection was forwarded for to the server.
+package proxyproto
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+)
+
+// signature is the signature that every version 2 PROXY protocol header
+// starts with.
+var signature = []byte{0x0d, 0x0a, 0x0d, 0x0a, 0x00, 0x0d, 0x0a, 0x51, 0x55, 0x49, 0x54, 0x0a}
+
+const (
+	// headerLen is the length of the fixed part of a header: The signature,
+	// the version and command, the family and protocol and the length of the
+	// remainder of the header.
+	headerLen = 16
+	// maxLen is the maximum length of the remainder of a header.
+	maxLen = 4096
+
+	commandLocal = 0x20
+	commandProxy = 0x21
+
+	familyInet  = 0x10
+	familyInet6 = 0x20
+
+	protocolStream = 0x01
+	protocolDgram  = 0x02
+)
+
+// Header is a version 2 PROXY protocol header.
+type Header struct {
+	// Source and Destination are the addresses of the client and the proxy
+	// respectively. They are invalid for headers of connections that the
+	// proxy made itself, such as health checks.
+	Source, Destination netip.AddrPort
+	// Datagram specifies if the forwarded connection was made over a datagram
+	// transport, such as UDP.
+	Datagram bool
+	// TLVs holds the type-length-value fields of the header by their type.
+	TLVs map[byte][]byte
+}
+
+// Local checks if the Header was sent for a connection made by the proxy
+// itself rather than forwarded for a client.
+func (h Header) Local() bool {
+	return !h.Source.IsValid()
+}
+
+// SourceAddr returns the source of the Header as a net.Addr of the type
+// matching the transport of the forwarded connection.
+func (h Header) SourceAddr() net.Addr {
+	if h.Datagram {
+		return net.UDPAddrFromAddrPort(h.Source)
+	}
+	return net.TCPAddrFromAddrPort(h.Source)
+}
+
+// Is checks if b starts with the signature of a version 2 PROXY protocol
+// header.
+func Is(b []byte) bool {
+	return bytes.HasPrefix(b, signature)
+}
+
+// Read reads a Header from r. Read reads exactly the bytes of the header, so
+// that the data that follows it may be read from r afterwards.
+func Read(r io.Reader) (Header, error) {
+	fixed := make([]byte, headerLen)
+	if _, err := io.ReadFull(r, fixed); err != nil {
+		return Header{}, fmt.Errorf("read proxy header: %w", err)
+	}
+	n := int(binary.BigEndian.Uint16(fixed[14:]))
+	if n > maxLen {
+		return Header{}, fmt.Errorf("read proxy header: length %v exceeds maximum of %v", n, maxLen)
+	}
+	b := make([]byte, headerLen+n)
+	copy(b, fixed)
+	if _, err := io.ReadFull(r, b[headerLen:]); err != nil {
+		return Header{}, fmt.Errorf("read proxy header: %w", err)
+	}
+	h, _, err := Parse(b)
+	return h, err
+}
+
+// Parse parses a Header from the start of b. The number of bytes that the
+// Header spans is returned, so that the data following it may be read from
+// b[n:].
+func Parse(b []byte) (h Header, n int, err error) {
+	if len(b) < headerLen || !Is(b) {
+		return h, 0, errors.New("parse proxy header: missing signature")
+	}
+	n = headerLen + int(binary.BigEndian.Uint16(b[14:]))
+	if len(b) < n {
+		return h, 0, fmt.Errorf("parse proxy header: need %v bytes, got %v", n, len(b))
+	}
+	body := b[headerLen:n]
+	switch b[12] {
+	case commandLocal:
+		// The addresses of local connections are ignored, but TLVs may still
+		// be present.
+		if b[13]&0xf0 == familyInet {
+			body = body[min(12, len(body)):]
+		} else if b[13]&0xf0 == familyInet6 {
+			body = body[min(36, len(body)):]
+		}
+	case commandProxy:
+		h.Datagram = b[13]&0x0f == protocolDgram
+		switch b[13] & 0xf0 {
+		case familyInet:
+			if len(body) < 12 {
+				return h, 0, errors.New("parse proxy header: short IPv4 addresses")
+			}
+			h.Source = netip.AddrPortFrom(netip.AddrFrom4([4]byte(body[0:4])), binary.BigEndian.Uint16(body[8:]))
+			h.Destination = netip.AddrPortFrom(netip.AddrFrom4([4]byte(body[4:8])), binary.BigEndian.Uint16(body[10:]))
+			body = body[12:]
+		case familyInet6:
+			if len(body) < 36 {
+				return h, 0, errors.New("parse proxy header: short IPv6 addresses")
+			}
+			h.Source = netip.AddrPortFrom(netip.AddrFrom16([16]byte(body[0:16])), binary.BigEndian.Uint16(body[32:]))
+			h.Destination = netip.AddrPortFrom(netip.AddrFrom16([16]byte(body[16:32])), binary.BigEndian.Uint16(body[34:]))
+			body = body[36:]
+		default:
+			return h, 0, fmt.Errorf("parse proxy header: unsupported address family %#x", b[13]&0xf0)
+		}
+	default:
+		return h, 0, fmt.Errorf("parse proxy header: unsupported version or command %#x", b[12])
+	}
+	h.TLVs = make(map[byte][]byte)
+	for len(body) > 0 {
+		if len(body) < 3 {
+			return h, 0, errors.New("parse proxy header: short TLV")
+		}
+		l := int(binary.BigEndian.Uint16(body[1:]))
+		if len(body) < 3+l {
+			return h, 0, fmt.Errorf("parse proxy header: TLV of type %#x exceeds header", body[0])
+		}
+		h.TLVs[body[0]] = body[3 : 3+l]
+		body = body[3+l:]
+	}
+	return h, n, nil
+}
+
+// Append appends the encoded Header to b and returns the resulting slice.
+// TLVs are appended in ascending order of their type.
+func (h Header) Append(b []byte) []byte {
+	b = append(b, signature...)
+	proto := byte(protocolStream)
+	if h.Datagram {
+		proto = protocolDgram
+	}
+
+	var body []byte
+	switch {
+	case h.Local():
+		b = append(b, commandLocal, 0)
+	case h.Source.Addr().Is4() && h.Destination.Addr().Is4():
+		b = append(b, commandProxy, familyInet|proto)
+		body = append(body, h.Source.Addr().AsSlice()...)
+		body = append(body, h.Destination.Addr().AsSlice()...)
+		body = binary.BigEndian.AppendUint16(body, h.Source.Port())
+		body = binary.BigEndian.AppendUint16(body, h.Destination.Port())
+	default:
+		src, dst := h.Source.Addr().As16(), h.Destination.Addr().As16()
+		b = append(b, commandProxy, familyInet6|proto)
+		body = append(body, src[:]...)
+		body = append(body, dst[:]...)
+		body = binary.BigEndian.AppendUint16(body, h.Source.Port())
+		body = binary.BigEndian.AppendUint16(body, h.Destination.Port())
+	}
+	for t := range 256 {
+		if v, ok := h.TLVs[byte(t)]; ok {
+			body = append(body, byte(t))
+			body = binary.BigEndian.AppendUint16(body, uint16(len(v)))
+			body = append(body, v...)
+		}
+	}
+	b = binary.BigEndian.AppendUint16(b, uint16(len(body)))
+	return append(b, body...)
+}
@@ -0,0 +1,121 @@
+package proxyproto
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net/netip"
+	"reflect"
+	"testing"
+)
+
+func TestHeaderRoundTrip(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		h    Header
+	}{
+		{"ipv4", Header{
+			Source:      netip.MustParseAddrPort("192.0.2.1:50000"),
+			Destination: netip.MustParseAddrPort("192.0.2.2:19132"),
+			TLVs:        map[byte][]byte{},
+		}},
+		{"ipv6 datagram", Header{
+			Source:      netip.MustParseAddrPort("[2001:db8::1]:50000"),
+			Destination: netip.MustParseAddrPort("[2001:db8::2]:19132"),
+			Datagram:    true,
+			TLVs:        map[byte][]byte{},
+		}},
+		{"tlvs", Header{
+			Source:      netip.MustParseAddrPort("192.0.2.1:50000"),
+			Destination: netip.MustParseAddrPort("192.0.2.2:19132"),
+			TLVs:        map[byte][]byte{0x01: []byte("h2"), 0xe0: []byte("identity"), 0x05: {}},
+		}},
+		{"local", Header{TLVs: map[byte][]byte{0x04: {}}}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			b := tt.h.Append([]byte("prefix"))[len("prefix"):]
+			if !Is(b) {
+				t.Fatalf("Is() of appended header = false, want true")
+			}
+			h, n, err := Parse(append(b, "data"...))
+			if err != nil {
+				t.Fatalf("Parse() = %v, want nil", err)
+			}
+			if n != len(b) {
+				t.Fatalf("Parse() length = %v, want %v", n, len(b))
+			}
+			if !reflect.DeepEqual(h, tt.h) {
+				t.Fatalf("Parse() = %+v, want %+v", h, tt.h)
+			}
+			if h.Local() != !tt.h.Source.IsValid() {
+				t.Fatalf("Local() = %v, want %v", h.Local(), !tt.h.Source.IsValid())
+			}
+
+			r := bytes.NewReader(append(b, "data"...))
+			if h, err = Read(r); err != nil || !reflect.DeepEqual(h, tt.h) {
+				t.Fatalf("Read() = %+v, %v, want %+v, nil", h, err, tt.h)
+			}
+			if r.Len() != len("data") {
+				t.Fatalf("Read() left %v bytes, want %v", r.Len(), len("data"))
+			}
+		})
+	}
+}
+
+func TestHeaderMixedFamilies(t *testing.T) {
+	h := Header{Source: netip.MustParseAddrPort("192.0.2.1:50000"), Destination: netip.MustParseAddrPort("[2001:db8::2]:19132")}
+	parsed, _, err := Parse(h.Append(nil))
+	if err != nil {
+		t.Fatalf("Parse() = %v, want nil", err)
+	}
+	if parsed.Source.Addr().Unmap() != h.Source.Addr() || parsed.Source.Port() != h.Source.Port() {
+		t.Fatalf("Parse() source = %v, want %v mapped to IPv6", parsed.Source, h.Source)
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	valid := Header{
+		Source:      netip.MustParseAddrPort("192.0.2.1:50000"),
+		Destination: netip.MustParseAddrPort("192.0.2.2:19132"),
+		TLVs:        map[byte][]byte{0x01: []byte("h2")},
+	}.Append(nil)
+	// withBody returns the fixed part of a header with the command and family
+	// passed, followed by the body passed.
+	withBody := func(command, family byte, body []byte) []byte {
+		b := append(append([]byte{}, signature...), command, family)
+		b = binary.BigEndian.AppendUint16(b, uint16(len(body)))
+		return append(b, body...)
+	}
+
+	for _, tt := range []struct {
+		name string
+		b    []byte
+	}{
+		{"empty", nil},
+		{"no signature", append([]byte("GET / HTTP/1.1\r\n"), make([]byte, 16)...)},
+		{"truncated", valid[:len(valid)-1]},
+		{"version 1", withBody(0x11, familyInet|protocolStream, make([]byte, 12))},
+		{"unknown command", withBody(0x2f, familyInet|protocolStream, make([]byte, 12))},
+		{"unix family", withBody(commandProxy, 0x30|protocolStream, make([]byte, 216))},
+		{"short ipv4", withBody(commandProxy, familyInet|protocolStream, make([]byte, 8))},
+		{"short ipv6", withBody(commandProxy, familyInet6|protocolStream, make([]byte, 20))},
+		{"short tlv", withBody(commandProxy, familyInet|protocolStream, make([]byte, 14))},
+		{"tlv exceeds header", withBody(commandProxy, familyInet|protocolStream, append(make([]byte, 12), 0x01, 0x00, 0x05, 'a'))},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := Parse(tt.b); err == nil {
+				t.Fatalf("Parse() = nil, want error")
+			}
+			if _, err := Read(bytes.NewReader(tt.b)); err == nil {
+				t.Fatalf("Read() = nil, want error")
+			}
+		})
+	}
+
+	t.Run("length exceeds maximum", func(t *testing.T) {
+		b := withBody(commandProxy, familyInet|protocolStream, nil)
+		binary.BigEndian.PutUint16(b[14:], maxLen+1)
+		if _, err := Read(bytes.NewReader(b)); err == nil {
+			t.Fatalf("Read() = nil, want error")
+		}
+	})
+}
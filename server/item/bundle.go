@@ -0,0 +1,180 @@
+package item
+
+import (
+	"slices"
+	"sync/atomic"
+)
+
+// BundleCapacity is the total weight of items that a Bundle can hold. A full stack of items that stack up to 64
+// weighs exactly BundleCapacity.
+const BundleCapacity = 64
+
+// Bundle is an item that can hold a stack's worth of items of different types. Every item added to the bundle takes
+// up a weight depending on its max count: Items that stack up to 64 weigh 1 each, while unstackable items fill the
+// whole bundle.
+type Bundle struct {
+	// Colour is the colour of the bundle. A zero OptionalColour represents the undyed variant.
+	Colour OptionalColour
+
+	// id is a unique ID of the bundle, used to identify its contents while it is being viewed.
+	id uint32
+	// contents holds the stacks stored in the bundle, with the most recently added stack first. It is a pointer so
+	// that Bundle remains comparable. The slice pointed to is never modified: Changing the contents of a Bundle
+	// always creates a new slice.
+	contents *[]Stack
+}
+
+// bundleID is a counter used to assign a unique ID to every Bundle created.
+var bundleID atomic.Uint32
+
+// NewBundle returns a new, empty Bundle with the OptionalColour passed. Bundles created using NewBundle have a unique
+// ID, which allows players to view and modify their contents from the inventory.
+func NewBundle(colour OptionalColour) Bundle {
+	return Bundle{Colour: colour, id: bundleID.Add(1)}
+}
+
+// ID returns the unique ID of the Bundle. ID returns 0 if the Bundle was not created using NewBundle or decoded from
+// NBT. IDs are assigned when a Bundle is created or decoded and are not stored in its NBT.
+func (b Bundle) ID() uint32 {
+	return b.id
+}
+
+// Contents returns the stacks currently stored in the Bundle, with the most recently added stack first.
+func (b Bundle) Contents() []Stack {
+	return slices.Clone(b.stacks())
+}
+
+// stacks returns the stacks stored in the Bundle. The slice returned must not be modified.
+func (b Bundle) stacks() []Stack {
+	if b.contents == nil {
+		return nil
+	}
+	return *b.contents
+}
+
+// withStacks returns the Bundle with its contents set to the stacks passed, which must not be modified afterwards.
+func (b Bundle) withStacks(stacks []Stack) Bundle {
+	if len(stacks) == 0 {
+		b.contents = nil
+		return b
+	}
+	b.contents = &stacks
+	return b
+}
+
+// WithContents returns the Bundle with its contents replaced by the stacks passed. Empty stacks are ignored. Note that
+// WithContents does not check if the stacks exceed the BundleCapacity.
+func (b Bundle) WithContents(contents ...Stack) Bundle {
+	stacks := make([]Stack, 0, len(contents))
+	for _, s := range contents {
+		if !s.Empty() {
+			stacks = append(stacks, s)
+		}
+	}
+	return b.withStacks(stacks)
+}
+
+// Empty checks if the Bundle has no contents.
+func (b Bundle) Empty() bool {
+	return len(b.stacks()) == 0
+}
+
+// Weight returns the total weight of the contents of the Bundle. This value is never higher than BundleCapacity for
+// bundles filled using Add.
+func (b Bundle) Weight() int {
+	var w int
+	for _, s := range b.stacks() {
+		w += BundleWeight(s)
+	}
+	return w
+}
+
+// Add adds as much of the stack passed to the Bundle as fits. It returns the new Bundle and the number of items that
+// were added. Items are merged into an existing stack in the Bundle if possible, after which that stack is moved to
+// the front of the Bundle.
+func (b Bundle) Add(s Stack) (Bundle, int) {
+	if s.Empty() {
+		return b, 0
+	}
+	n := min(s.Count(), (BundleCapacity-b.Weight())/bundleItemWeight(s))
+	if n <= 0 {
+		return b, 0
+	}
+	contents := make([]Stack, 1, len(b.stacks())+1)
+	added := s.Grow(n - s.Count())
+	for _, existing := range b.stacks() {
+		if existing.Comparable(added) && existing.Count()+added.Count() <= existing.MaxCount() {
+			added = existing.Grow(added.Count())
+			continue
+		}
+		contents = append(contents, existing)
+	}
+	contents[0] = added
+	return b.withStacks(contents), n
+}
+
+// Remove removes the stack added to the Bundle most recently. It returns the new Bundle and the stack removed, or an
+// empty stack if the Bundle was empty.
+func (b Bundle) Remove() (Bundle, Stack) {
+	stacks := b.stacks()
+	if len(stacks) == 0 {
+		return b, Stack{}
+	}
+	return b.withStacks(slices.Clone(stacks[1:])), stacks[0]
+}
+
+// BundleWeight returns the weight that the stack passed takes up when stored in a Bundle. Items that stack up to 64
+// weigh 1 per item, items that stack up to 16 weigh 4 per item and unstackable items weigh BundleCapacity. A Bundle
+// weighs 4 plus the weight of its contents.
+func BundleWeight(s Stack) int {
+	return bundleItemWeight(s) * s.Count()
+}
+
+// bundleItemWeight returns the weight of a single item of the stack passed.
+func bundleItemWeight(s Stack) int {
+	if b, ok := s.Item().(Bundle); ok {
+		return 4 + b.Weight()
+	}
+	return BundleCapacity / max(s.MaxCount(), 1)
+}
+
+// MaxCount always returns 1.
+func (Bundle) MaxCount() int {
+	return 1
+}
+
+// DecodeNBT ...
+func (b Bundle) DecodeNBT(data map[string]any) any {
+	// IDs are only assigned by the server and never decoded, so that clients
+	// cannot claim the ID of another bundle.
+	b.id = bundleID.Add(1)
+	var stacks []Stack
+	for _, v := range nbtSlice(data, "Items") {
+		if m, ok := v.(map[string]any); ok {
+			if s := ReadNBT(m, nil); !s.Empty() {
+				stacks = append(stacks, s)
+			}
+		}
+	}
+	return b.withStacks(stacks)
+}
+
+// EncodeNBT ...
+func (b Bundle) EncodeNBT() map[string]any {
+	stacks := b.stacks()
+	if len(stacks) == 0 {
+		return nil
+	}
+	items := make([]any, 0, len(stacks))
+	for i, s := range stacks {
+		data := WriteNBT(s, true)
+		data["Slot"] = byte(i)
+		items = append(items, data)
+	}
+	return map[string]any{"Items": items}
+}
+
+// EncodeItem ...
+func (b Bundle) EncodeItem() (name string, meta int16) {
+	return "minecraft:" + b.Colour.Prepend("bundle"), 0
+}
@@ -0,0 +1,95 @@
+package item
+
+import (
+	"testing"
+)
+
+func TestBundleWeight(t *testing.T) {
+	tests := []struct {
+		name string
+		s    Stack
+		want int
+	}{
+		{name: "stackable by 64", s: NewStack(Apple{}, 10), want: 10},
+		{name: "stackable by 16", s: NewStack(EnderPearl{}, 3), want: 12},
+		{name: "unstackable", s: NewStack(Sword{Tier: ToolTierIron}, 1), want: BundleCapacity},
+		{name: "empty bundle", s: NewStack(NewBundle(OptionalColour(0)), 1), want: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BundleWeight(tt.s); got != tt.want {
+				t.Fatalf("BundleWeight() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	b, _ := NewBundle(OptionalColour(0)).Add(NewStack(Apple{}, 6))
+	if got := BundleWeight(NewStack(b, 1)); got != 10 {
+		t.Fatalf("BundleWeight() of bundle holding 6 apples = %v, want 10", got)
+	}
+}
+
+func TestBundleAdd(t *testing.T) {
+	t.Run("partial", func(t *testing.T) {
+		b, n := NewBundle(OptionalColour(0)).Add(NewStack(EnderPearl{}, 16))
+		if n != 16 {
+			t.Fatalf("Add() added %v, want 16", n)
+		}
+		b, n = b.Add(NewStack(Apple{}, 64))
+		if n != 0 {
+			t.Fatalf("Add() to full bundle added %v, want 0", n)
+		}
+		if b.Weight() != BundleCapacity {
+			t.Fatalf("Weight() = %v, want %v", b.Weight(), BundleCapacity)
+		}
+	})
+	t.Run("merge", func(t *testing.T) {
+		b, _ := NewBundle(OptionalColour(0)).Add(NewStack(Apple{}, 10))
+		b, _ = b.Add(NewStack(Snowball{}, 2))
+		b, n := b.Add(NewStack(Apple{}, 5))
+		if n != 5 {
+			t.Fatalf("Add() added %v, want 5", n)
+		}
+		contents := b.Contents()
+		if len(contents) != 2 {
+			t.Fatalf("len(Contents()) = %v, want 2", len(contents))
+		}
+		if _, ok := contents[0].Item().(Apple); !ok || contents[0].Count() != 15 {
+			t.Fatalf("Contents()[0] = %v, want 15 apples moved to the front", contents[0])
+		}
+	})
+	t.Run("unchanged original", func(t *testing.T) {
+		b, _ := NewBundle(OptionalColour(0)).Add(NewStack(Apple{}, 10))
+		added, _ := b.Add(NewStack(Apple{}, 10))
+		removed, _ := b.Remove()
+		if b.Weight() != 10 || added.Weight() != 20 || !removed.Empty() {
+			t.Fatalf("Weight() = %v, %v, %v after Add and Remove, want 10, 20, 0", b.Weight(), added.Weight(), removed.Weight())
+		}
+		if b == added {
+			t.Fatalf("bundles with different contents compare equal")
+		}
+	})
+	t.Run("nested", func(t *testing.T) {
+		inner := NewBundle(OptionalColour(0))
+		b, n := NewBundle(OptionalColour(0)).Add(NewStack(inner, 1))
+		if n != 1 || b.Weight() != 4 {
+			t.Fatalf("Add() of empty bundle added %v with weight %v, want 1 with weight 4", n, b.Weight())
+		}
+	})
+}
+
+func TestBundleNBT(t *testing.T) {
+	b, _ := NewBundle(OptionalColour(0)).Add(NewStack(Apple{}, 10))
+	data := b.EncodeNBT()
+	data["bundle_id"] = int32(b.ID())
+	decoded := Bundle{}.DecodeNBT(data).(Bundle)
+	if decoded.ID() == 0 || decoded.ID() == b.ID() {
+		t.Fatalf("DecodeNBT() ID = %v, want new ID other than %v", decoded.ID(), b.ID())
+	}
+	if decoded.Weight() != 10 {
+		t.Fatalf("DecodeNBT() Weight() = %v, want 10", decoded.Weight())
+	}
+	if !NewStack(decoded, 1).Comparable(NewStack(b, 1)) {
+		t.Fatalf("decoded bundle is not comparable to the bundle encoded")
+	}
+}
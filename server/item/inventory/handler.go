@@ -19,6 +19,10 @@ type Handler interface {
 	HandlePlace(ctx *Context, slot int, it item.Stack)
 	// HandleDrop handles the dropping of an item.Stack in a slot out of the inventory.
 	HandleDrop(ctx *Context, slot int, it item.Stack)
+	// HandleTransaction handles a Transaction performed by a player that involves the inventory. It is called once
+	// for the whole Transaction, before any of its Actions are performed and before HandleTake, HandlePlace and
+	// HandleDrop are called for the individual slots. ctx.Cancel() may be called to cancel the whole Transaction.
+	HandleTransaction(ctx *Context, t Transaction)
 }
 
 // Check to make sure NopHandler implements Handler.
@@ -28,6 +32,7 @@ var _ Handler = NopHandler{}
 // Handler of an Inventory.
 type NopHandler struct{}
 
-func (NopHandler) HandleTake(*Context, int, item.Stack)  {}
-func (NopHandler) HandlePlace(*Context, int, item.Stack) {}
-func (NopHandler) HandleDrop(*Context, int, item.Stack)  {}
+func (NopHandler) HandleTake(*Context, int, item.Stack)    {}
+func (NopHandler) HandlePlace(*Context, int, item.Stack)   {}
+func (NopHandler) HandleDrop(*Context, int, item.Stack)    {}
+func (NopHandler) HandleTransaction(*Context, Transaction) {}
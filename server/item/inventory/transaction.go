@@ -0,0 +1,94 @@
+package inventory
+
+import (
+	"slices"
+
+	"github.com/df-mc/dragonfly/server/item"
+)
+
+// Transaction is a set of Actions performed at once by a player, such as moving an item from its hotbar into a
+// chest or a shift-click spreading an item stack over multiple slots. The Actions are performed in order.
+type Transaction struct {
+	// Actions holds all Actions of the Transaction in the order they are performed.
+	Actions []Action
+}
+
+// Inventories returns all distinct inventories that are the source or destination of one of the Actions of the
+// Transaction.
+func (t Transaction) Inventories() []*Inventory {
+	var invs []*Inventory
+	for _, a := range t.Actions {
+		for _, inv := range [2]*Inventory{a.Source.Inventory, a.Destination.Inventory} {
+			if inv != nil && !slices.Contains(invs, inv) {
+				invs = append(invs, inv)
+			}
+		}
+	}
+	return invs
+}
+
+// Action is a single action in a Transaction, such as an item being moved from one slot to another.
+type Action struct {
+	// Type is the type of the Action.
+	Type ActionType
+	// Source is the slot that items are taken from. Its Inventory is nil for Actions that have no source slot, such as
+	// crafting or taking items from the creative inventory.
+	Source Location
+	// Destination is the slot that items are moved to. Its Inventory is nil for Actions that have no destination slot,
+	// such as dropping or destroying items.
+	Destination Location
+	// Count is the amount of items affected by the Action.
+	Count int
+	// Item is the item.Stack affected by the Action, with the count set to Count. For Actions with a Source, it is
+	// the item in that slot after all Actions before it in the Transaction were performed. It is empty if the item
+	// is not known before the Action is performed, such as for crafting or for taking the result of crafting.
+	Item item.Stack
+}
+
+// Location is a slot in an Inventory.
+type Location struct {
+	// Inventory is the Inventory that the slot is in. It is nil if the Location does not point to any slot.
+	Inventory *Inventory
+	// Slot is the index of the slot in the Inventory.
+	Slot int
+}
+
+// ActionType is the type of Action. It is one of the constants below.
+type ActionType uint8
+
+const (
+	// ActionTransfer moves Count items from the Source to the Destination slot. It is used for both picking up items
+	// with the cursor and putting them down again.
+	ActionTransfer ActionType = iota
+	// ActionSwap swaps the item stacks in the Source and Destination slots.
+	ActionSwap
+	// ActionDrop drops Count items from the Source slot on the ground.
+	ActionDrop
+	// ActionDestroy destroys Count items in the Source slot. It is only used in creative mode.
+	ActionDestroy
+	// ActionCraft crafts the output of a recipe, for example in a crafting table, stonecutter, loom or anvil. The
+	// output is placed in the created output slot, which is the Destination of the Action.
+	ActionCraft
+	// ActionCreative takes an item from the creative inventory and places it in the created output slot, which is the
+	// Destination of the Action.
+	ActionCreative
+)
+
+// String returns the ActionType as a string.
+func (t ActionType) String() string {
+	switch t {
+	case ActionTransfer:
+		return "transfer"
+	case ActionSwap:
+		return "swap"
+	case ActionDrop:
+		return "drop"
+	case ActionDestroy:
+		return "destroy"
+	case ActionCraft:
+		return "craft"
+	case ActionCreative:
+		return "creative"
+	}
+	panic("should never happen")
+}
@@ -0,0 +1,41 @@
+package inventory
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTransactionInventories(t *testing.T) {
+	a, b, c := New(9, nil), New(9, nil), New(9, nil)
+	tx := Transaction{Actions: []Action{
+		{Type: ActionTransfer, Source: Location{Inventory: a}, Destination: Location{Inventory: b, Slot: 1}},
+		{Type: ActionTransfer, Source: Location{Inventory: b, Slot: 1}, Destination: Location{Inventory: a, Slot: 2}},
+		{Type: ActionDrop, Source: Location{Inventory: c, Slot: 3}},
+		{Type: ActionCreative, Destination: Location{Inventory: b}},
+	}}
+	if invs, want := tx.Inventories(), []*Inventory{a, b, c}; !slices.Equal(invs, want) {
+		t.Fatalf("Inventories() = %v, want %v", invs, want)
+	}
+	if invs := (Transaction{}).Inventories(); len(invs) != 0 {
+		t.Fatalf("Inventories() of empty Transaction = %v, want none", invs)
+	}
+}
+
+func TestActionTypeString(t *testing.T) {
+	tests := []struct {
+		typ  ActionType
+		want string
+	}{
+		{typ: ActionTransfer, want: "transfer"},
+		{typ: ActionSwap, want: "swap"},
+		{typ: ActionDrop, want: "drop"},
+		{typ: ActionDestroy, want: "destroy"},
+		{typ: ActionCraft, want: "craft"},
+		{typ: ActionCreative, want: "creative"},
+	}
+	for _, test := range tests {
+		if got := test.typ.String(); got != test.want {
+			t.Fatalf("String() = %v, want %v", got, test.want)
+		}
+	}
+}
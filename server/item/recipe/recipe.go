@@ -140,6 +140,46 @@ func NewPotion(input, reagent Item, output item.Stack) Potion {
 	}}
 }
 
+// Stonecutter is a recipe that may be crafted in a stonecutter. It converts a single input item into an output,
+// consuming one input item for every craft.
+type Stonecutter struct {
+	recipe
+}
+
+// NewStonecutter creates a new stonecutter recipe and returns it. The recipe is shown in the stonecutter UI of the
+// client when the input item is placed in the stonecutter.
+func NewStonecutter(input Item, output item.Stack) Stonecutter {
+	return Stonecutter{recipe: recipe{
+		input:  []Item{input},
+		output: []item.Stack{output},
+		block:  "stonecutter",
+	}}
+}
+
+// Loom is a recipe that makes a banner pattern craftable in a loom using a specific pattern item. Vanilla banner
+// patterns that have a pattern item (such as the creeper or flower patterns) may additionally be crafted with any of
+// the items of Loom recipes registered for the same pattern. Patterns that have no pattern item in vanilla require
+// one of the registered items once a Loom recipe is registered for them.
+type Loom struct {
+	recipe
+	pattern string
+}
+
+// NewLoom creates a new loom recipe for the banner pattern with the ID passed, such as "cre" or "flo", which may be
+// applied to a banner if the pattern item passed is present in the pattern slot of the loom. The pattern item is not
+// consumed when crafting.
+func NewLoom(pattern string, patternItem Item) Loom {
+	return Loom{pattern: pattern, recipe: recipe{
+		input: []Item{patternItem},
+		block: "loom",
+	}}
+}
+
+// Pattern returns the ID of the banner pattern that the Loom recipe makes craftable.
+func (l Loom) Pattern() string {
+	return l.pattern
+}
+
 // Shaped is a recipe that has a specific shape that must be used to craft the output of the recipe.
 type Shaped struct {
 	recipe
@@ -179,6 +219,8 @@ type recipe struct {
 	block string
 	// priority is the priority of the recipe versus others.
 	priority uint32
+	// id is the ID of the recipe, as returned by ID. It is set when the recipe is registered.
+	id string
 }
 
 // Input ...
@@ -200,3 +242,9 @@ func (r recipe) Block() string {
 func (r recipe) Priority() uint32 {
 	return r.priority
 }
+
+// registeredID returns the ID of the recipe set when it was registered, or an empty string if the recipe was not
+// registered.
+func (r recipe) registeredID() string {
+	return r.id
+}
@@ -0,0 +1,73 @@
+package recipe
+
+import (
+	"testing"
+
+	"github.com/df-mc/dragonfly/server/item"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		r     Recipe
+		valid bool
+	}{
+		{name: "stonecutter", r: NewStonecutter(item.NewStack(item.Brick{}, 1), item.NewStack(item.Stick{}, 2)), valid: true},
+		{name: "stonecutter without input", r: NewStonecutter(item.Stack{}, item.NewStack(item.Stick{}, 2))},
+		{name: "stonecutter without output", r: NewStonecutter(item.NewStack(item.Brick{}, 1), item.Stack{})},
+		{name: "loom", r: NewLoom("cre", item.NewStack(item.Brick{}, 1)), valid: true},
+		{name: "loom without pattern", r: NewLoom("", item.NewStack(item.Brick{}, 1))},
+		{name: "loom without pattern item", r: NewLoom("cre", item.Stack{})},
+		{name: "potion", r: NewPotion(item.NewStack(item.Brick{}, 1), item.NewStack(item.Stick{}, 1), item.NewStack(item.Stick{}, 1)), valid: true},
+		{name: "potion with tag", r: NewPotion(NewItemTag("minecraft:planks", 1), item.NewStack(item.Stick{}, 1), item.NewStack(item.Stick{}, 1))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validate(tt.r); (err == nil) != tt.valid {
+				t.Fatalf("validate() = %v, want valid %v", err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestID(t *testing.T) {
+	shapeless := func(priority uint32, count int) Shapeless {
+		return Shapeless{recipe{
+			input:    []Item{item.NewStack(item.Brick{}, 1), item.NewStack(item.Stick{}, 1)},
+			output:   []item.Stack{item.NewStack(item.Arrow{}, count)},
+			block:    "crafting_table",
+			priority: priority,
+		}}
+	}
+	if ID(shapeless(0, 1)) != ID(shapeless(0, 1)) {
+		t.Fatalf("ID() of identical recipes differs")
+	}
+	if ID(shapeless(0, 1)) == ID(shapeless(1, 1)) {
+		t.Fatalf("ID() of recipes with different priorities is equal")
+	}
+	if ID(shapeless(0, 1)) == ID(shapeless(0, 2)) {
+		t.Fatalf("ID() of recipes with different outputs is equal")
+	}
+	if ID(shapeless(0, 1)) == ID(Stonecutter{shapeless(0, 1).recipe}) {
+		t.Fatalf("ID() of recipes of different types is equal")
+	}
+}
+
+func TestRegister(t *testing.T) {
+	r := NewShapeless([]Item{item.NewStack(item.ClayBall{}, 1), item.NewStack(item.Feather{}, 1)}, item.NewStack(item.Paper{}, 3), "crafting_table")
+	Register(r)
+
+	registered, ok := ByID(ID(r))
+	if !ok {
+		t.Fatalf("ByID() of registered recipe = false, want true")
+	}
+	if id := registered.(Shapeless).id; id != ID(r) {
+		t.Fatalf("ID stored at Register = %q, want %q", id, ID(r))
+	}
+
+	n := len(Recipes())
+	Register(NewShapeless([]Item{item.NewStack(item.ClayBall{}, 1), item.NewStack(item.Feather{}, 1)}, item.NewStack(item.Paper{}, 3), "crafting_table"))
+	if l := len(Recipes()); l != n {
+		t.Fatalf("len(Recipes()) after registering identical recipe = %v, want %v", l, n)
+	}
+}
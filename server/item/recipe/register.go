@@ -1,11 +1,14 @@
 package recipe
 
 import (
+	"fmt"
+	"github.com/cespare/xxhash/v2"
 	"github.com/df-mc/dragonfly/server/internal/sliceutil"
 	"github.com/df-mc/dragonfly/server/item"
 	"github.com/df-mc/dragonfly/server/world"
 	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"unsafe"
 )
@@ -19,6 +22,14 @@ var (
 	index = make(map[string]map[string]Recipe)
 	// reagent maps the item name and an item.Stack.
 	reagent = make(map[string]item.Stack)
+	// brewingInput holds the names of all items that may be brewed into another item in a brewing stand.
+	brewingInput = make(map[string]struct{})
+	// loomRecipes maps a banner pattern ID to all Loom recipes registered for it.
+	loomRecipes = make(map[string][]Loom)
+	// byID maps the ID of a recipe, as returned by ID, to the recipe.
+	byID = make(map[string]Recipe)
+	// ingredients maps the name of an item to all recipes that have the item as an input.
+	ingredients = make(map[string][]Recipe)
 )
 
 // Recipes returns each recipe in a slice.
@@ -31,13 +42,36 @@ func DynamicRecipes() []DynamicRecipe {
 	return slices.Clone(dynamicRecipes)
 }
 
-// Register registers a new recipe.
+// Register registers a new recipe. Register panics if the recipe passed is not valid, for example if a Potion
+// recipe is registered with an ItemTag as input or a Stonecutter recipe is registered without output. Registering a
+// recipe identical to one already registered, with the same ID, has no effect: The vanilla data, for example, holds
+// some recipes multiple times under different names.
 func Register(recipe Recipe) {
+	if err := validate(recipe); err != nil {
+		panic(fmt.Sprintf("register recipe %T: %v", recipe, err))
+	}
+	id := computeID(recipe)
+	if _, ok := byID[id]; !ok {
+		register(recipe, id)
+	}
+}
+
+// register registers a valid recipe with the ID passed, which must not have been registered yet.
+func register(recipe Recipe, id string) {
+	recipe = withID(recipe, id)
 	recipes = append(recipes, recipe)
 
+	byID[id] = recipe
+	for _, name := range inputNames(recipe) {
+		ingredients[name] = append(ingredients[name], recipe)
+	}
+
 	_, ok := recipe.(PotionContainerChange)
 	p, okTwo := recipe.(Potion)
 
+	if l, ok := recipe.(Loom); ok {
+		loomRecipes[l.Pattern()] = append(loomRecipes[l.Pattern()], l)
+	}
 	if okTwo {
 		stack := p.Input()[1].(item.Stack)
 		name, _ := stack.Item().EncodeItem()
@@ -45,6 +79,9 @@ func Register(recipe Recipe) {
 	}
 
 	if ok || okTwo {
+		name, _ := recipe.Input()[0].(item.Stack).Item().EncodeItem()
+		brewingInput[name] = struct{}{}
+
 		input := make([]world.Item, len(recipe.Input()))
 		for i, stack := range recipe.Input() {
 			if s, ok := stack.(item.Stack); ok {
@@ -61,6 +98,37 @@ func Register(recipe Recipe) {
 	}
 }
 
+// validate checks if a recipe has the inputs and outputs required by its type. An error is returned if this is not
+// the case.
+func validate(r Recipe) error {
+	switch r := r.(type) {
+	case Potion, PotionContainerChange:
+		for _, in := range r.Input() {
+			if s, ok := in.(item.Stack); !ok || s.Empty() {
+				return fmt.Errorf("brewing input and reagent must be non-empty item stacks, got %v", in)
+			}
+		}
+		if len(r.Output()) != 1 || r.Output()[0].Empty() {
+			return fmt.Errorf("brewing recipe must have exactly one non-empty output")
+		}
+	case Stonecutter:
+		if len(r.Input()) != 1 || r.Input()[0] == nil || r.Input()[0].Empty() {
+			return fmt.Errorf("stonecutter recipe must have exactly one non-empty input")
+		}
+		if len(r.Output()) != 1 || r.Output()[0].Empty() {
+			return fmt.Errorf("stonecutter recipe must have exactly one non-empty output")
+		}
+	case Loom:
+		if r.Pattern() == "" {
+			return fmt.Errorf("loom recipe must have a banner pattern")
+		}
+		if r.Input()[0] == nil || r.Input()[0].Empty() {
+			return fmt.Errorf("loom recipe must have a non-empty pattern item")
+		}
+	}
+	return nil
+}
+
 // Perform performs the recipe with the given block and inputs and returns the outputs. If the inputs do not map to
 // any outputs, false is returned for the second return value.
 func Perform(block string, input ...world.Item) (output []item.Stack, ok bool) {
@@ -123,6 +191,138 @@ func ValidBrewingReagent(i world.Item) bool {
 	return exists
 }
 
+// ValidBrewingInput checks if the world.Item may be brewed into another item in a brewing stand, either through a
+// Potion or a PotionContainerChange recipe.
+func ValidBrewingInput(i world.Item) bool {
+	name, _ := i.EncodeItem()
+	_, exists := brewingInput[name]
+	return exists
+}
+
+// LoomRecipes returns all Loom recipes registered for the banner pattern with the ID passed.
+func LoomRecipes(pattern string) []Loom {
+	return slices.Clone(loomRecipes[pattern])
+}
+
+// ID returns an ID that identifies the recipe passed. The ID is computed from the type, block, priority, inputs and
+// outputs of the recipe, so that it remains the same across restarts as long as the recipe itself does not change. It
+// is used as the name of the recipe in the recipe book of the client. The ID of a registered recipe is computed once
+// when it is registered.
+func ID(r Recipe) string {
+	if r, ok := r.(interface{ registeredID() string }); ok {
+		if id := r.registeredID(); id != "" {
+			return id
+		}
+	}
+	return computeID(r)
+}
+
+// withID returns the recipe passed with its ID set to the ID passed, so that ID does not need to compute it again.
+// Recipes of types not defined in this package are returned unchanged.
+func withID(r Recipe, id string) Recipe {
+	switch r := r.(type) {
+	case Shapeless:
+		r.id = id
+		return r
+	case UserDataShapeless:
+		r.id = id
+		return r
+	case Multi:
+		r.id = id
+		return r
+	case SmithingTransform:
+		r.id = id
+		return r
+	case SmithingTrim:
+		r.id = id
+		return r
+	case PotionContainerChange:
+		r.id = id
+		return r
+	case Potion:
+		r.id = id
+		return r
+	case Stonecutter:
+		r.id = id
+		return r
+	case Loom:
+		r.id = id
+		return r
+	case Shaped:
+		r.id = id
+		return r
+	}
+	return r
+}
+
+// computeID computes the ID of the recipe passed, as returned by ID.
+func computeID(r Recipe) string {
+	h := xxhash.New()
+	_, _ = fmt.Fprintf(h, "%T;%v;%v;", r, r.Block(), r.Priority())
+	switch r := r.(type) {
+	case Multi:
+		_, _ = h.WriteString(r.UUID().String())
+	case Shaped:
+		_, _ = fmt.Fprintf(h, "%vx%v;", r.Shape().Width(), r.Shape().Height())
+	case Loom:
+		_, _ = h.WriteString(r.Pattern())
+	}
+	for _, in := range r.Input() {
+		switch in := in.(type) {
+		case item.Stack:
+			if in.Empty() {
+				_, _ = h.WriteString("air;")
+				continue
+			}
+			name, meta := in.Item().EncodeItem()
+			_, variants := in.Value("variants")
+			_, _ = fmt.Fprintf(h, "%v:%v:%v:%v;", name, meta, in.Count(), variants)
+		case ItemTag:
+			_, _ = fmt.Fprintf(h, "#%v:%v;", in.Tag(), in.Count())
+		}
+	}
+	_, _ = h.WriteString("=>")
+	for _, out := range r.Output() {
+		if out.Empty() {
+			continue
+		}
+		name, meta := out.Item().EncodeItem()
+		_, _ = fmt.Fprintf(h, "%v:%v:%v;", name, meta, out.Count())
+	}
+	return "dragonfly:" + strconv.FormatUint(h.Sum64(), 16)
+}
+
+// ByID looks up a recipe by the ID returned by ID. If no recipe with the ID was registered, false is returned.
+func ByID(id string) (Recipe, bool) {
+	r, ok := byID[id]
+	return r, ok
+}
+
+// ByIngredient returns all recipes that have the world.Item passed as one of their inputs, either directly or
+// through an item tag.
+func ByIngredient(i world.Item) []Recipe {
+	name, _ := i.EncodeItem()
+	return slices.Clone(ingredients[name])
+}
+
+// inputNames returns the names of all items that may be used as input of the recipe passed, without duplicates.
+func inputNames(r Recipe) []string {
+	var names []string
+	for _, in := range r.Input() {
+		switch in := in.(type) {
+		case item.Stack:
+			if !in.Empty() {
+				name, _ := in.Item().EncodeItem()
+				names = append(names, name)
+			}
+		case ItemTag:
+			names = append(names, in.items...)
+		}
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
 // RegisterDynamic registers a new dynamic recipe. Dynamic recipes are not sent to the client
 // and are validated server-side.
 func RegisterDynamic(recipe DynamicRecipe) {
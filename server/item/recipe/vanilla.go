@@ -97,6 +97,15 @@ func registerVanilla() {
 			// This can be expected to happen, as some recipes contain blocks or items that aren't currently implemented.
 			continue
 		}
+		if s.Block == "stonecutter" && len(input) == 1 && len(output) == 1 {
+			Register(Stonecutter{recipe{
+				input:    input,
+				output:   output,
+				block:    s.Block,
+				priority: uint32(s.Priority),
+			}})
+			continue
+		}
 		Register(Shapeless{recipe{
 			input:    input,
 			output:   output,
@@ -144,6 +144,9 @@ func init() {
 		world.RegisterItem(Dye{Colour: c})
 		world.RegisterItem(FireworkStar{FireworkExplosion: FireworkExplosion{Colour: c}})
 	}
+	for _, c := range OptionalColours() {
+		world.RegisterItem(Bundle{Colour: c})
+	}
 	for _, horn := range sound.GoatHorns() {
 		world.RegisterItem(GoatHorn{Type: horn})
 	}
@@ -24,6 +24,29 @@ type Listener interface {
 // listenerFunc may be used to return a *minecraft.Listener using a Config. It
 // is the standard listener used when UserConfig.Config() is called.
 func (uc UserConfig) listenerFunc(conf Config) (Listener, error) {
+	cfg := listenConfig(conf)
+	log := cfg.ErrorLog
+	if log == nil {
+		log = slog.New(slog.DiscardHandler)
+	}
+	var n minecraft.Network = rakNet{log: log}
+	if p, ok := conf.StatusProvider.(PingStatusProvider); ok {
+		n = statusNetwork{Network: n, provider: p}
+	}
+	if conf.ConnectionRate > 0 {
+		n = limitedNetwork{Network: n, limiter: newConnectionLimiter(conf.ConnectionRate, conf.ConnectionBurst), log: conf.Log}
+	}
+	l, err := cfg.ListenNetwork(n, uc.Network.Address)
+	if err != nil {
+		return nil, fmt.Errorf("create minecraft listener: %w", err)
+	}
+	conf.Log.Info("Listener running.", "addr", l.Addr())
+	return listener{l}, nil
+}
+
+// listenConfig returns the minecraft.ListenConfig used to create a
+// minecraft.Listener from a Config.
+func listenConfig(conf Config) minecraft.ListenConfig {
 	cfg := minecraft.ListenConfig{
 		MaximumPlayers:         conf.MaxPlayers,
 		StatusProvider:         conf.StatusProvider,
@@ -32,16 +55,12 @@ func (uc UserConfig) listenerFunc(conf Config) (Listener, error) {
 		TexturePacksRequired:   conf.ResourcesRequired,
 		Compression:            conf.Compression,
 		Allow:                  conf.Allower.Allow,
+		PacketFunc:             conf.PacketFunc,
 	}
 	if conf.Log.Enabled(context.Background(), slog.LevelDebug) {
 		cfg.ErrorLog = conf.Log.With("net origin", "gophertunnel")
 	}
-	l, err := cfg.Listen("raknet", uc.Network.Address)
-	if err != nil {
-		return nil, fmt.Errorf("create minecraft listener: %w", err)
-	}
-	conf.Log.Info("Listener running.", "addr", l.Addr())
-	return listener{l}, nil
+	return cfg
 }
 
 // listener is a Listener implementation that wraps around a minecraft.Listener so that it can be listened on by
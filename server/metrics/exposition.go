@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"bufio"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// label is a name-value pair identifying a single series of a metric.
+type label struct {
+	name, value string
+}
+
+// exposition writes metrics in the Prometheus text exposition format. The
+// first error that occurs while writing is kept and returned by flush.
+type exposition struct {
+	w *bufio.Writer
+}
+
+// family writes the HELP and TYPE lines of a metric family. It must be
+// called once before writing the samples of the family.
+func (e exposition) family(name, typ, help string) {
+	e.w.WriteString("# HELP " + name + " " + escapeHelp(help) + "\n")
+	e.w.WriteString("# TYPE " + name + " " + typ + "\n")
+}
+
+// sample writes a single sample of a metric with the labels passed.
+func (e exposition) sample(name string, v float64, labels ...label) {
+	e.w.WriteString(name)
+	if len(labels) > 0 {
+		e.w.WriteByte('{')
+		for i, l := range labels {
+			if i > 0 {
+				e.w.WriteByte(',')
+			}
+			e.w.WriteString(l.name + `="` + escapeLabel(l.value) + `"`)
+		}
+		e.w.WriteByte('}')
+	}
+	e.w.WriteByte(' ')
+	e.w.WriteString(formatFloat(v))
+	e.w.WriteByte('\n')
+}
+
+// histogram writes the buckets, sum and count of a histogram with the labels
+// passed.
+func (e exposition) histogram(name string, h *histogram, labels ...label) {
+	cumulative, count, sum := h.snapshot()
+	for i, bound := range h.bounds {
+		e.sample(name+"_bucket", float64(cumulative[i]), append(labels, label{"le", formatFloat(bound)})...)
+	}
+	e.sample(name+"_bucket", float64(count), append(labels, label{"le", "+Inf"})...)
+	e.sample(name+"_sum", sum, labels...)
+	e.sample(name+"_count", float64(count), labels...)
+}
+
+// flush writes all buffered metrics to the underlying writer.
+func (e exposition) flush() error {
+	return e.w.Flush()
+}
+
+// formatFloat formats a float64 as a sample value.
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpReplacer  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+// escapeHelp escapes the text of a HELP line.
+func escapeHelp(s string) string {
+	return helpReplacer.Replace(s)
+}
+
+// escapeLabel escapes the value of a label.
+func escapeLabel(s string) string {
+	return labelReplacer.Replace(s)
+}
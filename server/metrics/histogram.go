@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+)
+
+var (
+	// tickBuckets are the upper bounds of the buckets of world tick duration
+	// histograms in seconds. A tick should take no longer than 50ms.
+	tickBuckets = []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25}
+	// chunkBuckets are the upper bounds of the buckets of chunk load and save
+	// duration histograms in seconds.
+	chunkBuckets = []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1}
+)
+
+// histogram counts observed durations in buckets with fixed upper bounds. The
+// methods of a histogram are safe for concurrent use.
+type histogram struct {
+	bounds []float64
+
+	mu     sync.Mutex
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// newHistogram returns a histogram with buckets using the upper bounds in
+// seconds passed.
+func newHistogram(bounds []float64) *histogram {
+	return &histogram{bounds: bounds, counts: make([]uint64, len(bounds))}
+}
+
+// observe adds a duration to the histogram.
+func (h *histogram) observe(d time.Duration) {
+	v := d.Seconds()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, bound := range h.bounds {
+		if v <= bound {
+			h.counts[i]++
+			break
+		}
+	}
+	h.count++
+	h.sum += v
+}
+
+// snapshot returns the cumulative count of every bucket, the total count and
+// the sum of all durations observed.
+func (h *histogram) snapshot() (cumulative []uint64, count uint64, sum float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	cumulative = make([]uint64, len(h.counts))
+	var total uint64
+	for i, c := range h.counts {
+		total += c
+		cumulative[i] = total
+	}
+	return cumulative, h.count, h.sum
+}
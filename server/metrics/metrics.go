@@ -0,0 +1,492 @@
+// Package metrics implements a Collector that exports metrics of a Server and
+// its worlds in the Prometheus text exposition format, such as the duration of
+// world ticks, the number of loaded chunks and entities, the time taken to
+// load, generate and save chunks and the network traffic and client
+// diagnostics of players.
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/df-mc/dragonfly/server"
+	"github.com/df-mc/dragonfly/server/player"
+	"github.com/df-mc/dragonfly/server/session"
+	"github.com/df-mc/dragonfly/server/world"
+	"github.com/google/uuid"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// scrapeTimeout is the maximum time spent collecting metrics from a single
+// world when metrics are written.
+const scrapeTimeout = time.Second * 5
+
+// trafficExpiry is the time after which the traffic of an address that no
+// longer sends or receives packets is discarded if no player with that
+// address is online.
+const trafficExpiry = time.Minute
+
+// Collector collects metrics of a Server and its worlds. A Collector must be
+// set up using Configure before the Server is created, after which the metrics
+// may be served using ListenAndServe or Handler:
+//
+//	c := metrics.New()
+//	c.Configure(&conf)
+//	srv := conf.New()
+//	go func() {
+//		_ = c.ListenAndServe(srv, "127.0.0.1:9100")
+//	}()
+//
+// Client diagnostics are only collected for players that have the
+// player.Handler returned by PlayerHandler.
+//
+// Per-player metrics, such as latency, network traffic and client
+// diagnostics, are labelled by the name of the player. Every player that joins
+// therefore creates new series in the monitoring system scraping them, whose
+// number is unbounded on servers with many unique players. Per-player metrics
+// may be disabled using Config.DisablePlayerMetrics.
+type Collector struct {
+	conf Config
+
+	wmu    sync.Mutex
+	worlds map[*world.World]*worldMetrics
+
+	// traffic maps the key of an address, as returned by addrKey, to its
+	// *traffic.
+	traffic sync.Map
+
+	dmu         sync.Mutex
+	diagnostics map[uuid.UUID]session.Diagnostics
+}
+
+// Config holds options for a Collector.
+type Config struct {
+	// DisablePlayerMetrics disables the metrics of individual players, which
+	// are labelled by player name, so that the number of series exported does
+	// not grow with the number of unique players. The number of players online
+	// is still exported.
+	DisablePlayerMetrics bool
+}
+
+// worldMetrics holds the histograms of a single world.
+type worldMetrics struct {
+	tick, load, generate, save *histogram
+}
+
+// traffic holds the network traffic of a single remote address. Its fields are
+// updated atomically, so that packets of different connections are counted
+// without contention.
+type traffic struct {
+	packetsIn, packetsOut atomic.Uint64
+	bytesIn, bytesOut     atomic.Uint64
+	// last is the time of the last packet in Unix nanoseconds.
+	last atomic.Int64
+}
+
+// New returns a new Collector with the default Config.
+func New() *Collector {
+	return Config{}.New()
+}
+
+// New returns a new Collector using the Config.
+func (conf Config) New() *Collector {
+	return &Collector{
+		conf:        conf,
+		worlds:      make(map[*world.World]*worldMetrics),
+		diagnostics: make(map[uuid.UUID]session.Diagnostics),
+	}
+}
+
+// Configure sets the Collector as the server.Config.WorldObserver of the
+// server.Config passed and sets its PacketFunc to collect the network traffic
+// of players, unless per-player metrics are disabled. A WorldObserver or
+// PacketFunc already set is still called.
+func (c *Collector) Configure(conf *server.Config) {
+	if conf.WorldObserver != nil {
+		conf.WorldObserver = observers{conf.WorldObserver, c}
+	} else {
+		conf.WorldObserver = c
+	}
+	if c.conf.DisablePlayerMetrics {
+		return
+	}
+	if f := conf.PacketFunc; f != nil {
+		conf.PacketFunc = func(header packet.Header, payload []byte, src, dst net.Addr) {
+			f(header, payload, src, dst)
+			c.HandlePacket(header, payload, src, dst)
+		}
+	} else {
+		conf.PacketFunc = c.HandlePacket
+	}
+}
+
+// ObserveTick adds the duration of a tick of the world passed to its tick
+// duration histogram.
+func (c *Collector) ObserveTick(w *world.World, d time.Duration) {
+	c.world(w).tick.observe(d)
+}
+
+// ObserveChunkLoad adds the duration of loading or generating a chunk of the
+// world passed to the respective histogram.
+func (c *Collector) ObserveChunkLoad(w *world.World, d time.Duration, generated bool) {
+	if generated {
+		c.world(w).generate.observe(d)
+		return
+	}
+	c.world(w).load.observe(d)
+}
+
+// ObserveChunkSave adds the duration of saving a chunk of the world passed to
+// its save duration histogram.
+func (c *Collector) ObserveChunkSave(w *world.World, d time.Duration) {
+	c.world(w).save.observe(d)
+}
+
+// world returns the worldMetrics of the world passed, creating them if they
+// do not yet exist.
+func (c *Collector) world(w *world.World) *worldMetrics {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	m, ok := c.worlds[w]
+	if !ok {
+		m = &worldMetrics{
+			tick:     newHistogram(tickBuckets),
+			load:     newHistogram(chunkBuckets),
+			generate: newHistogram(chunkBuckets),
+			save:     newHistogram(chunkBuckets),
+		}
+		c.worlds[w] = m
+	}
+	return m
+}
+
+// HandlePacket counts a packet read from or written to a connection. It is
+// set as the server.Config.PacketFunc by Configure. The address of the player
+// is always the source of packets read and the destination of packets written,
+// so both addresses are counted and only those of online players are exported.
+func (c *Collector) HandlePacket(_ packet.Header, payload []byte, src, dst net.Addr) {
+	now, n := time.Now().UnixNano(), uint64(len(payload))
+
+	in, out := c.trafficOf(src, now), c.trafficOf(dst, now)
+	in.packetsIn.Add(1)
+	in.bytesIn.Add(n)
+	out.packetsOut.Add(1)
+	out.bytesOut.Add(n)
+}
+
+// trafficOf returns the traffic of the address passed, creating it if it does
+// not yet exist, and sets the time of its last packet to now.
+func (c *Collector) trafficOf(addr net.Addr, now int64) *traffic {
+	key := addrKey(addr)
+	v, ok := c.traffic.Load(key)
+	if !ok {
+		v, _ = c.traffic.LoadOrStore(key, &traffic{})
+	}
+	t := v.(*traffic)
+	t.last.Store(now)
+	return t
+}
+
+// addrKey returns the key of the traffic of a net.Addr. Connections pass the
+// same net.Addr for every packet, so the address itself is used as key if it
+// is comparable, which avoids formatting it for every packet.
+func addrKey(addr net.Addr) any {
+	if addr == nil {
+		return nil
+	}
+	if reflect.TypeOf(addr).Comparable() {
+		return addr
+	}
+	return addr.String()
+}
+
+// PlayerHandler returns a player.Handler that collects the client diagnostics
+// of the player it is set to and discards its metrics when it quits. Events
+// are passed to h, which may be nil.
+func (c *Collector) PlayerHandler(h player.Handler) player.Handler {
+	if h == nil {
+		h = player.NopHandler{}
+	}
+	return playerHandler{Handler: h, c: c}
+}
+
+// playerHandler implements player.Handler for a Collector.
+type playerHandler struct {
+	player.Handler
+	c *Collector
+}
+
+// HandleDiagnostics stores the latest diagnostics of the player.
+func (h playerHandler) HandleDiagnostics(p *player.Player, d session.Diagnostics) {
+	h.c.dmu.Lock()
+	h.c.diagnostics[p.UUID()] = d
+	h.c.dmu.Unlock()
+	h.Handler.HandleDiagnostics(p, d)
+}
+
+// HandleQuit discards the diagnostics and network traffic of the player.
+func (h playerHandler) HandleQuit(p *player.Player) {
+	h.c.dmu.Lock()
+	delete(h.c.diagnostics, p.UUID())
+	h.c.dmu.Unlock()
+
+	if addr := p.Addr(); addr != nil {
+		h.c.traffic.Delete(addrKey(addr))
+	}
+	h.Handler.HandleQuit(p)
+}
+
+// Handler returns an http.Handler that serves the metrics of the Server
+// passed in the Prometheus text exposition format.
+func (c *Collector) Handler(srv *server.Server) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if err := c.Write(r.Context(), srv, w); err != nil && !errors.Is(err, context.Canceled) {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// ListenAndServe serves the metrics of the Server passed on the path /metrics
+// of an HTTP server listening on the address passed, such as
+// "127.0.0.1:9100". It blocks until the HTTP server fails.
+func (c *Collector) ListenAndServe(srv *server.Server, addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", c.Handler(srv))
+	hs := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: time.Second * 10}
+	return hs.ListenAndServe()
+}
+
+// Write writes the metrics of the Server passed to w in the Prometheus text
+// exposition format. The context passed is used to cancel collecting metrics
+// from the worlds of the Server.
+func (c *Collector) Write(ctx context.Context, srv *server.Server, w io.Writer) error {
+	e := exposition{w: bufio.NewWriter(w)}
+	c.writeWorlds(ctx, srv, e)
+	c.writePlayers(srv, e)
+	return e.flush()
+}
+
+// worldSnapshot holds the metrics of a world collected within a transaction.
+type worldSnapshot struct {
+	name     string
+	m        *worldMetrics
+	chunks   int
+	entities map[string]int
+}
+
+// writeWorlds writes the metrics of all loaded worlds of the Server.
+func (c *Collector) writeWorlds(ctx context.Context, srv *server.Server, e exposition) {
+	var (
+		snapshots []worldSnapshot
+		loaded    = make(map[*world.World]struct{})
+	)
+	for name, w := range srv.LoadedWorlds() {
+		loaded[w] = struct{}{}
+		s := worldSnapshot{name: name, m: c.world(w)}
+
+		wctx, cancel := context.WithTimeout(ctx, scrapeTimeout)
+		_, err := world.Call(wctx, w, func(tx *world.Tx) (struct{}, error) {
+			s.chunks, s.entities = tx.LoadedChunks(), make(map[string]int)
+			for ent := range tx.Entities() {
+				s.entities[ent.H().Type().EncodeEntity()]++
+			}
+			return struct{}{}, nil
+		})
+		cancel()
+		if err != nil {
+			// The world was closed or did not respond in time: Only export
+			// its histograms.
+			s.chunks, s.entities = -1, nil
+		}
+		snapshots = append(snapshots, s)
+	}
+
+	// Discard the metrics of worlds that are no longer loaded.
+	c.wmu.Lock()
+	for w := range c.worlds {
+		if _, ok := loaded[w]; !ok {
+			delete(c.worlds, w)
+		}
+	}
+	c.wmu.Unlock()
+
+	e.family("dragonfly_world_tick_duration_seconds", "histogram", "Time taken by world ticks.")
+	for _, s := range snapshots {
+		e.histogram("dragonfly_world_tick_duration_seconds", s.m.tick, label{"world", s.name})
+	}
+	e.family("dragonfly_world_chunk_load_duration_seconds", "histogram", "Time taken to load chunks from the world provider or to generate them.")
+	for _, s := range snapshots {
+		e.histogram("dragonfly_world_chunk_load_duration_seconds", s.m.load, label{"world", s.name}, label{"source", "provider"})
+		e.histogram("dragonfly_world_chunk_load_duration_seconds", s.m.generate, label{"world", s.name}, label{"source", "generator"})
+	}
+	e.family("dragonfly_world_chunk_save_duration_seconds", "histogram", "Time taken to store chunks in the world provider.")
+	for _, s := range snapshots {
+		e.histogram("dragonfly_world_chunk_save_duration_seconds", s.m.save, label{"world", s.name})
+	}
+	e.family("dragonfly_world_loaded_chunks", "gauge", "Number of chunks loaded in worlds.")
+	for _, s := range snapshots {
+		if s.chunks >= 0 {
+			e.sample("dragonfly_world_loaded_chunks", float64(s.chunks), label{"world", s.name})
+		}
+	}
+	e.family("dragonfly_world_entities", "gauge", "Number of entities in worlds by entity type.")
+	for _, s := range snapshots {
+		types := make([]string, 0, len(s.entities))
+		for t := range s.entities {
+			types = append(types, t)
+		}
+		slices.Sort(types)
+		for _, t := range types {
+			e.sample("dragonfly_world_entities", float64(s.entities[t]), label{"world", s.name}, label{"type", t})
+		}
+	}
+}
+
+// playerSnapshot holds the metrics of an online player.
+type playerSnapshot struct {
+	id      uuid.UUID
+	name    string
+	addr    net.Addr
+	latency time.Duration
+}
+
+// writePlayers writes the player count and the metrics of every online player
+// of the Server.
+func (c *Collector) writePlayers(srv *server.Server, e exposition) {
+	var players []playerSnapshot
+	for p := range srv.Players(nil) {
+		players = append(players, playerSnapshot{id: p.UUID(), name: p.Name(), addr: p.Addr(), latency: p.Latency()})
+	}
+	slices.SortFunc(players, func(a, b playerSnapshot) int {
+		return strings.Compare(a.name, b.name)
+	})
+
+	e.family("dragonfly_players", "gauge", "Number of players online.")
+	e.sample("dragonfly_players", float64(len(players)))
+	e.family("dragonfly_max_players", "gauge", "Maximum number of players that may be online.")
+	e.sample("dragonfly_max_players", float64(srv.MaxPlayerCount()))
+	if c.conf.DisablePlayerMetrics {
+		return
+	}
+	e.family("dragonfly_player_latency_seconds", "gauge", "Latency of the connections of players.")
+	for _, p := range players {
+		e.sample("dragonfly_player_latency_seconds", p.latency.Seconds(), label{"player", p.name})
+	}
+
+	c.writeTraffic(players, e)
+	c.writeDiagnostics(players, e)
+}
+
+// writeTraffic writes the network traffic of online players and discards the
+// traffic of addresses that expired.
+func (c *Collector) writeTraffic(players []playerSnapshot, e exposition) {
+	online := make(map[any]struct{}, len(players))
+	counts := make([]*traffic, len(players))
+	for i, p := range players {
+		if p.addr == nil {
+			counts[i] = &traffic{}
+			continue
+		}
+		key := addrKey(p.addr)
+		online[key] = struct{}{}
+		if v, ok := c.traffic.Load(key); ok {
+			counts[i] = v.(*traffic)
+		} else {
+			counts[i] = &traffic{}
+		}
+	}
+	expired := time.Now().Add(-trafficExpiry).UnixNano()
+	c.traffic.Range(func(key, v any) bool {
+		if _, ok := online[key]; !ok && v.(*traffic).last.Load() < expired {
+			c.traffic.Delete(key)
+		}
+		return true
+	})
+
+	counters := []struct {
+		name, help string
+		v          func(t *traffic) uint64
+	}{
+		{"dragonfly_player_packets_received_total", "Number of packets received from players.", func(t *traffic) uint64 { return t.packetsIn.Load() }},
+		{"dragonfly_player_packets_sent_total", "Number of packets sent to players.", func(t *traffic) uint64 { return t.packetsOut.Load() }},
+		{"dragonfly_player_received_bytes_total", "Size of the uncompressed payloads of packets received from players.", func(t *traffic) uint64 { return t.bytesIn.Load() }},
+		{"dragonfly_player_sent_bytes_total", "Size of the uncompressed payloads of packets sent to players.", func(t *traffic) uint64 { return t.bytesOut.Load() }},
+	}
+	for _, counter := range counters {
+		e.family(counter.name, "counter", counter.help)
+		for i, p := range players {
+			e.sample(counter.name, float64(counter.v(counts[i])), label{"player", p.name})
+		}
+	}
+}
+
+// writeDiagnostics writes the latest client diagnostics of online players.
+func (c *Collector) writeDiagnostics(players []playerSnapshot, e exposition) {
+	type entry struct {
+		name string
+		d    session.Diagnostics
+	}
+	var diagnostics []entry
+
+	c.dmu.Lock()
+	for _, p := range players {
+		if d, ok := c.diagnostics[p.id]; ok {
+			diagnostics = append(diagnostics, entry{name: p.name, d: d})
+		}
+	}
+	c.dmu.Unlock()
+
+	gauges := []struct {
+		name, help string
+		v          func(d session.Diagnostics) float64
+	}{
+		{"dragonfly_client_frames_per_second", "Average frames per second of clients.", func(d session.Diagnostics) float64 { return d.AverageFramesPerSecond }},
+		{"dragonfly_client_server_sim_tick_milliseconds", "Average time clients spend simulating a server tick.", func(d session.Diagnostics) float64 { return d.AverageServerSimTickTime }},
+		{"dragonfly_client_client_sim_tick_milliseconds", "Average time clients spend simulating a client tick.", func(d session.Diagnostics) float64 { return d.AverageClientSimTickTime }},
+		{"dragonfly_client_begin_frame_milliseconds", "Average time clients spend beginning a frame.", func(d session.Diagnostics) float64 { return d.AverageBeginFrameTime }},
+		{"dragonfly_client_input_milliseconds", "Average time clients spend processing input.", func(d session.Diagnostics) float64 { return d.AverageInputTime }},
+		{"dragonfly_client_render_milliseconds", "Average time clients spend rendering.", func(d session.Diagnostics) float64 { return d.AverageRenderTime }},
+		{"dragonfly_client_end_frame_milliseconds", "Average time clients spend ending a frame.", func(d session.Diagnostics) float64 { return d.AverageEndFrameTime }},
+		{"dragonfly_client_remainder_time_percent", "Average percentage of time clients spend on remaining tasks.", func(d session.Diagnostics) float64 { return d.AverageRemainderTimePercent }},
+		{"dragonfly_client_unaccounted_time_percent", "Average percentage of time clients spend on unaccounted tasks.", func(d session.Diagnostics) float64 { return d.AverageUnaccountedTimePercent }},
+	}
+	for _, g := range gauges {
+		e.family(g.name, "gauge", g.help)
+		for _, d := range diagnostics {
+			e.sample(g.name, g.v(d.d), label{"player", d.name})
+		}
+	}
+}
+
+// observers is a world.Observer that passes all observations to multiple
+// world.Observers.
+type observers []world.Observer
+
+func (o observers) ObserveTick(w *world.World, d time.Duration) {
+	for _, obs := range o {
+		obs.ObserveTick(w, d)
+	}
+}
+
+func (o observers) ObserveChunkLoad(w *world.World, d time.Duration, generated bool) {
+	for _, obs := range o {
+		obs.ObserveChunkLoad(w, d, generated)
+	}
+}
+
+func (o observers) ObserveChunkSave(w *world.World, d time.Duration) {
+	for _, obs := range o {
+		obs.ObserveChunkSave(w, d)
+	}
+}
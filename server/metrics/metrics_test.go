@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"net"
+	"testing"
+
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+func TestHandlePacket(t *testing.T) {
+	c := New()
+	server, client := &net.UDPAddr{Port: 19132}, &net.UDPAddr{Port: 50000}
+	c.HandlePacket(packet.Header{}, make([]byte, 10), client, server)
+	c.HandlePacket(packet.Header{}, make([]byte, 5), client, server)
+	c.HandlePacket(packet.Header{}, make([]byte, 3), server, client)
+
+	v, ok := c.traffic.Load(addrKey(client))
+	if !ok {
+		t.Fatalf("traffic of client address was not stored")
+	}
+	tr := v.(*traffic)
+	if in, out := tr.packetsIn.Load(), tr.packetsOut.Load(); in != 2 || out != 1 {
+		t.Fatalf("packets of client = %v in, %v out, want 2 in, 1 out", in, out)
+	}
+	if in, out := tr.bytesIn.Load(), tr.bytesOut.Load(); in != 15 || out != 3 {
+		t.Fatalf("bytes of client = %v in, %v out, want 15 in, 3 out", in, out)
+	}
+	if tr.last.Load() == 0 {
+		t.Fatalf("time of last packet of client was not set")
+	}
+}
+
+func TestAddrKey(t *testing.T) {
+	addr := &net.UDPAddr{Port: 19132}
+	if key := addrKey(addr); key != any(addr) {
+		t.Fatalf("addrKey() of comparable address = %v, want the address itself", key)
+	}
+	if key := addrKey(uncomparableAddr{"a"}); key != "a" {
+		t.Fatalf("addrKey() of uncomparable address = %v, want its string", key)
+	}
+	if key := addrKey(nil); key != nil {
+		t.Fatalf("addrKey() of nil address = %v, want nil", key)
+	}
+}
+
+// uncomparableAddr is a net.Addr that cannot be used as a map key.
+type uncomparableAddr []string
+
+func (uncomparableAddr) Network() string  { return "test" }
+func (a uncomparableAddr) String() string { return a[0] }
@@ -47,6 +47,13 @@ type Config struct {
 	FireTicks              int64
 	FallDistance           float64
 	Effects                []effect.Effect
+	// UnlockedRecipes holds the IDs, as returned by recipe.ID, of the recipes
+	// that the player has unlocked.
+	UnlockedRecipes []string
+	// DeathMessages specifies if a message describing the death of the player
+	// is written to chat.Global when it dies, in worlds with
+	// world.GameRuleShowDeathMessages enabled.
+	DeathMessages bool
 }
 
 // Apply applies fields from a Config to a world.EntityData, filling out empty
@@ -87,6 +94,11 @@ func (cfg Config) Apply(data *world.EntityData) {
 		alwaysShowNameTag:   true,
 		fireTicks:           conf.FireTicks,
 		fallDistance:        conf.FallDistance,
+		unlockedRecipes:     make(map[string]struct{}, len(conf.UnlockedRecipes)),
+		deathMessages:       conf.DeathMessages,
+	}
+	for _, id := range conf.UnlockedRecipes {
+		pdata.unlockedRecipes[id] = struct{}{}
 	}
 	playerUUID := conf.UUID
 	pdata.portalTravel = &entity.PortalTravelComputer{
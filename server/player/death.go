@@ -0,0 +1,121 @@
+package player
+
+import (
+	"strings"
+
+	"github.com/df-mc/dragonfly/server/block"
+	"github.com/df-mc/dragonfly/server/entity"
+	"github.com/df-mc/dragonfly/server/entity/effect"
+	"github.com/df-mc/dragonfly/server/player/chat"
+	"github.com/df-mc/dragonfly/server/world"
+	"golang.org/x/text/language"
+)
+
+// https://github.com/Mojang/bedrock-samples/blob/main/resource_pack/texts/en_GB.lang
+
+var (
+	messageDeathGeneric     = chat.Translate(str("%death.attack.generic"), 1, `%v died`)
+	messageDeathPlayer      = chat.Translate(str("%death.attack.player"), 2, `%v was slain by %v`)
+	messageDeathMob         = chat.Translate(str("%death.attack.mob"), 2, `%v was slain by %v`)
+	messageDeathArrow       = chat.Translate(str("%death.attack.arrow"), 2, `%v was shot by %v`)
+	messageDeathThrown      = chat.Translate(str("%death.attack.thrown"), 2, `%v was pelted by %v`)
+	messageDeathExplosion   = chat.Translate(str("%death.attack.explosion"), 1, `%v blew up`)
+	messageDeathFall        = chat.Translate(str("%death.attack.fall"), 1, `%v hit the ground too hard`)
+	messageDeathGlide       = chat.Translate(str("%death.attack.flyIntoWall"), 1, `%v experienced kinetic energy`)
+	messageDeathVoid        = chat.Translate(str("%death.attack.outOfWorld"), 1, `%v fell out of the world`)
+	messageDeathSuffocation = chat.Translate(str("%death.attack.inWall"), 1, `%v suffocated in a wall`)
+	messageDeathDrowning    = chat.Translate(str("%death.attack.drown"), 1, `%v drowned`)
+	messageDeathLightning   = chat.Translate(str("%death.attack.lightningBolt"), 1, `%v was struck by lightning`)
+	messageDeathFire        = chat.Translate(str("%death.attack.onFire"), 1, `%v burned to death`)
+	messageDeathLava        = chat.Translate(str("%death.attack.lava"), 1, `%v tried to swim in lava`)
+	messageDeathMagma       = chat.Translate(str("%death.attack.magma"), 1, `%v discovered the floor was lava`)
+	messageDeathCactus      = chat.Translate(str("%death.attack.cactus"), 1, `%v was pricked to death`)
+	messageDeathStarvation  = chat.Translate(str("%death.attack.starve"), 1, `%v starved to death`)
+	messageDeathMagic       = chat.Translate(str("%death.attack.magic"), 1, `%v was killed by magic`)
+	messageDeathWither      = chat.Translate(str("%death.attack.wither"), 1, `%v withered away`)
+)
+
+type str string
+
+// Resolve returns the translation identifier as a string.
+func (s str) Resolve(language.Tag) string { return string(s) }
+
+// broadcastDeathMessage writes the message describing the death of the player
+// to the damage source passed to chat.Global, if death messages were enabled
+// using Config.DeathMessages and world.GameRuleShowDeathMessages is enabled.
+func (p *Player) broadcastDeathMessage(src world.DamageSource) {
+	if !p.deathMessages || !world.GameRuleShowDeathMessages.Value(p.tx) {
+		return
+	}
+	msg, killer := deathMessage(src)
+	if killer == nil {
+		chat.Global.Writet(msg, p.Name())
+		return
+	}
+	chat.Global.Writet(msg, p.Name(), killer)
+}
+
+// deathMessage returns the message describing a death to the damage source
+// passed. If the message has a second parameter, the name of the entity
+// responsible for the death is returned too.
+func deathMessage(src world.DamageSource) (chat.Translation, any) {
+	switch src := src.(type) {
+	case entity.AttackDamageSource:
+		if _, ok := src.Attacker.(*Player); ok {
+			return messageDeathPlayer, entityName(src.Attacker)
+		}
+		return messageDeathMob, entityName(src.Attacker)
+	case entity.ProjectileDamageSource:
+		if src.Owner == nil {
+			return messageDeathGeneric, nil
+		}
+		if src.Projectile != nil && src.Projectile.H().Type() == entity.ArrowType {
+			return messageDeathArrow, entityName(src.Owner)
+		}
+		return messageDeathThrown, entityName(src.Owner)
+	case entity.ExplosionDamageSource:
+		return messageDeathExplosion, nil
+	case entity.FallDamageSource:
+		return messageDeathFall, nil
+	case entity.GlideDamageSource:
+		return messageDeathGlide, nil
+	case entity.VoidDamageSource:
+		return messageDeathVoid, nil
+	case entity.SuffocationDamageSource:
+		return messageDeathSuffocation, nil
+	case entity.DrowningDamageSource:
+		return messageDeathDrowning, nil
+	case entity.LightningDamageSource:
+		return messageDeathLightning, nil
+	case block.FireDamageSource:
+		return messageDeathFire, nil
+	case block.LavaDamageSource:
+		return messageDeathLava, nil
+	case block.MagmaDamageSource:
+		return messageDeathMagma, nil
+	case block.DamageSource:
+		if _, ok := src.Block.(block.Cactus); ok {
+			return messageDeathCactus, nil
+		}
+	case StarvationDamageSource:
+		return messageDeathStarvation, nil
+	case effect.InstantDamageSource, effect.PoisonDamageSource:
+		return messageDeathMagic, nil
+	case effect.WitherDamageSource:
+		return messageDeathWither, nil
+	}
+	return messageDeathGeneric, nil
+}
+
+// entityName returns the name shown for the entity passed in a death message.
+// Players are shown by their name, other entities by their name tag or by the
+// translated name of their type.
+func entityName(e world.Entity) any {
+	if p, ok := e.(*Player); ok {
+		return p.Name()
+	}
+	if n, ok := e.(interface{ NameTag() string }); ok && n.NameTag() != "" {
+		return n.NameTag()
+	}
+	return str("%entity." + strings.TrimPrefix(e.H().Type().EncodeEntity(), "minecraft:") + ".name")
+}
@@ -7,6 +7,8 @@ import (
 	"github.com/df-mc/dragonfly/server/block/cube"
 	"github.com/df-mc/dragonfly/server/cmd"
 	"github.com/df-mc/dragonfly/server/item"
+	"github.com/df-mc/dragonfly/server/item/inventory"
+	"github.com/df-mc/dragonfly/server/item/recipe"
 	"github.com/df-mc/dragonfly/server/player/skin"
 	"github.com/df-mc/dragonfly/server/session"
 	"github.com/df-mc/dragonfly/server/world"
@@ -136,6 +138,16 @@ type Handler interface {
 	// HandleItemPickup handles the player picking up an item from the ground. The item stack laying on the
 	// ground is passed. ctx.Cancel() may be called to prevent the player from picking up the item.
 	HandleItemPickup(ctx *Context, i *item.Stack)
+	// HandleRecipeUnlock handles recipes being unlocked for the player, for example because one of the
+	// ingredients of the recipes was added to the inventory of the player or because Player.UnlockRecipes was
+	// called. ctx.Cancel() may be called to prevent the recipes from being unlocked. The recipes unlocked may be
+	// changed by assigning to *recipes.
+	HandleRecipeUnlock(ctx *Context, recipes *[]recipe.Recipe)
+	// HandleInventoryTransaction handles the player performing an inventory.Transaction, such as moving items between
+	// its inventory and an opened container, shift-clicking or crafting. It is called once for the whole transaction
+	// before any of its actions are performed, and after the HandleTransaction method of the handlers of the
+	// inventories involved. ctx.Cancel() may be called to cancel the whole transaction.
+	HandleInventoryTransaction(ctx *Context, t inventory.Transaction)
 	// HandleHeldSlotChange handles the player changing the slot they are currently holding.
 	HandleHeldSlotChange(ctx *Context, from, to int)
 	// HandleItemDrop handles the player dropping an item on the ground.
@@ -154,6 +166,18 @@ type Handler interface {
 	// not sent by every client however, only those with the "Creator > Enable Client Diagnostics" setting
 	// enabled.
 	HandleDiagnostics(p *Player, d session.Diagnostics)
+	// HandleViolation handles a violation detected while validating the input
+	// of the player's client, such as movement that diverges from the movement
+	// predicted by the server. ctx.Cancel() may be called to ignore the
+	// violation and accept the input of the client as is.
+	HandleViolation(ctx *Context, v session.Violation)
+	// HandleRateLimit handles the player's client exceeding the rate limit of
+	// a category of packets. The action taken against the client may be
+	// changed, for example to disconnect the client immediately.
+	// ctx.Cancel() may be called to handle the packet as usual. HandleRateLimit
+	// is only called for the first packet dropped in a row and when the client
+	// is warned or kicked, not for every packet dropped.
+	HandleRateLimit(ctx *Context, category session.PacketCategory, action *session.RateLimitAction)
 }
 
 // NopHandler implements the Handler interface but does not execute any code when an event is called. The
@@ -164,41 +188,45 @@ type NopHandler struct{}
 // Compile time check to make sure NopHandler implements Handler.
 var _ Handler = NopHandler{}
 
-func (NopHandler) HandleItemDrop(*Context, item.Stack)                                     {}
-func (NopHandler) HandleHeldSlotChange(*Context, int, int)                                 {}
-func (NopHandler) HandleMove(*Context, mgl64.Vec3, cube.Rotation)                          {}
-func (NopHandler) HandleJump(*Player)                                                      {}
-func (NopHandler) HandleTeleport(*Context, mgl64.Vec3)                                     {}
-func (NopHandler) HandleChangeWorld(*Player, *world.World, *world.World)                   {}
-func (NopHandler) HandleToggleSprint(*Context, bool)                                       {}
-func (NopHandler) HandleToggleSneak(*Context, bool)                                        {}
-func (NopHandler) HandleCommandExecution(*Context, cmd.Command, []string)                  {}
-func (NopHandler) HandleTransfer(*Context, *net.UDPAddr)                                   {}
-func (NopHandler) HandleChat(*Context, *string)                                            {}
-func (NopHandler) HandleSkinChange(*Context, *skin.Skin)                                   {}
-func (NopHandler) HandleFireExtinguish(*Context, cube.Pos)                                 {}
-func (NopHandler) HandleStartBreak(*Context, cube.Pos)                                     {}
-func (NopHandler) HandleBlockBreak(*Context, cube.Pos, *[]item.Stack, *int)                {}
-func (NopHandler) HandleBlockPlace(*Context, cube.Pos, world.Block)                        {}
-func (NopHandler) HandleBlockPick(*Context, cube.Pos, world.Block)                         {}
-func (NopHandler) HandleSignEdit(*Context, cube.Pos, bool, string, string)                 {}
-func (NopHandler) HandleSleep(*Context, *bool)                                             {}
-func (NopHandler) HandleLecternPageTurn(*Context, cube.Pos, int, *int)                     {}
-func (NopHandler) HandleItemPickup(*Context, *item.Stack)                                  {}
-func (NopHandler) HandleItemUse(*Context)                                                  {}
-func (NopHandler) HandleItemUseOnBlock(*Context, cube.Pos, cube.Face, mgl64.Vec3)          {}
-func (NopHandler) HandleItemUseOnEntity(*Context, world.Entity)                            {}
-func (NopHandler) HandleItemRelease(ctx *Context, item item.Stack, dur time.Duration)      {}
-func (NopHandler) HandleItemConsume(*Context, item.Stack)                                  {}
-func (NopHandler) HandleItemDamage(*Context, item.Stack, *int)                             {}
-func (NopHandler) HandleAttackEntity(*Context, world.Entity, *float64, *float64, *bool)    {}
-func (NopHandler) HandleExperienceGain(*Context, *int)                                     {}
-func (NopHandler) HandlePunchAir(*Context)                                                 {}
-func (NopHandler) HandleHurt(*Context, *float64, bool, *time.Duration, world.DamageSource) {}
-func (NopHandler) HandleSetOnFire(*Context, *time.Duration)                                {}
-func (NopHandler) HandleHeal(*Context, *float64, world.HealingSource)                      {}
-func (NopHandler) HandleFoodLoss(*Context, int, *int)                                      {}
-func (NopHandler) HandleDeath(*Player, world.DamageSource, *bool)                          {}
-func (NopHandler) HandleRespawn(*Player, *mgl64.Vec3, **world.World)                       {}
-func (NopHandler) HandleQuit(*Player)                                                      {}
-func (NopHandler) HandleDiagnostics(*Player, session.Diagnostics)                          {}
+func (NopHandler) HandleItemDrop(*Context, item.Stack)                                        {}
+func (NopHandler) HandleHeldSlotChange(*Context, int, int)                                    {}
+func (NopHandler) HandleMove(*Context, mgl64.Vec3, cube.Rotation)                             {}
+func (NopHandler) HandleJump(*Player)                                                         {}
+func (NopHandler) HandleTeleport(*Context, mgl64.Vec3)                                        {}
+func (NopHandler) HandleChangeWorld(*Player, *world.World, *world.World)                      {}
+func (NopHandler) HandleToggleSprint(*Context, bool)                                          {}
+func (NopHandler) HandleToggleSneak(*Context, bool)                                           {}
+func (NopHandler) HandleCommandExecution(*Context, cmd.Command, []string)                     {}
+func (NopHandler) HandleTransfer(*Context, *net.UDPAddr)                                      {}
+func (NopHandler) HandleChat(*Context, *string)                                               {}
+func (NopHandler) HandleSkinChange(*Context, *skin.Skin)                                      {}
+func (NopHandler) HandleFireExtinguish(*Context, cube.Pos)                                    {}
+func (NopHandler) HandleStartBreak(*Context, cube.Pos)                                        {}
+func (NopHandler) HandleBlockBreak(*Context, cube.Pos, *[]item.Stack, *int)                   {}
+func (NopHandler) HandleBlockPlace(*Context, cube.Pos, world.Block)                           {}
+func (NopHandler) HandleBlockPick(*Context, cube.Pos, world.Block)                            {}
+func (NopHandler) HandleSignEdit(*Context, cube.Pos, bool, string, string)                    {}
+func (NopHandler) HandleSleep(*Context, *bool)                                                {}
+func (NopHandler) HandleLecternPageTurn(*Context, cube.Pos, int, *int)                        {}
+func (NopHandler) HandleItemPickup(*Context, *item.Stack)                                     {}
+func (NopHandler) HandleRecipeUnlock(*Context, *[]recipe.Recipe)                              {}
+func (NopHandler) HandleInventoryTransaction(*Context, inventory.Transaction)                 {}
+func (NopHandler) HandleItemUse(*Context)                                                     {}
+func (NopHandler) HandleItemUseOnBlock(*Context, cube.Pos, cube.Face, mgl64.Vec3)             {}
+func (NopHandler) HandleItemUseOnEntity(*Context, world.Entity)                               {}
+func (NopHandler) HandleItemRelease(ctx *Context, item item.Stack, dur time.Duration)         {}
+func (NopHandler) HandleItemConsume(*Context, item.Stack)                                     {}
+func (NopHandler) HandleItemDamage(*Context, item.Stack, *int)                                {}
+func (NopHandler) HandleAttackEntity(*Context, world.Entity, *float64, *float64, *bool)       {}
+func (NopHandler) HandleExperienceGain(*Context, *int)                                        {}
+func (NopHandler) HandlePunchAir(*Context)                                                    {}
+func (NopHandler) HandleHurt(*Context, *float64, bool, *time.Duration, world.DamageSource)    {}
+func (NopHandler) HandleSetOnFire(*Context, *time.Duration)                                   {}
+func (NopHandler) HandleHeal(*Context, *float64, world.HealingSource)                         {}
+func (NopHandler) HandleFoodLoss(*Context, int, *int)                                         {}
+func (NopHandler) HandleDeath(*Player, world.DamageSource, *bool)                             {}
+func (NopHandler) HandleRespawn(*Player, *mgl64.Vec3, **world.World)                          {}
+func (NopHandler) HandleQuit(*Player)                                                         {}
+func (NopHandler) HandleDiagnostics(*Player, session.Diagnostics)                             {}
+func (NopHandler) HandleViolation(*Context, session.Violation)                                {}
+func (NopHandler) HandleRateLimit(*Context, session.PacketCategory, *session.RateLimitAction) {}
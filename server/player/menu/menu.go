@@ -0,0 +1,221 @@
+package menu
+
+import (
+	"sync"
+
+	"github.com/df-mc/dragonfly/server/item"
+	"github.com/df-mc/dragonfly/server/item/inventory"
+)
+
+// Menu is a virtual container menu that may be opened by a player without a container block being present in the
+// world. A fake block is sent to the client to display the Menu, which is removed again when the Menu is closed.
+// A Menu may be opened by multiple players at the same time, in which case changes to its inventory are shown to all
+// of them.
+type Menu struct {
+	typ  Type
+	name string
+	inv  *inventory.Inventory
+
+	mu      sync.Mutex
+	viewers map[Viewer]struct{}
+	clicks  map[int]ClickFunc
+	locked  map[int]struct{}
+	lockAll bool
+	close   func(h inventory.Holder)
+
+	// lastCtx and lastSlot are the context and slot of the last click, so that a swap, which takes from and places
+	// in the same slot using the same context, is only handled as a single click.
+	lastCtx  *inventory.Context
+	lastSlot int
+}
+
+// ClickFunc is a function called when a slot of a Menu is clicked by a player, which is when an item is taken from,
+// placed in or dropped from the slot. it is the item taken, placed or dropped. Swapping the item in a slot with the
+// item held calls the ClickFunc only once, with the item taken. ctx.Val() returns the inventory.Holder, usually the
+// player, that clicked the slot.
+type ClickFunc func(ctx *inventory.Context, slot int, it item.Stack)
+
+// Viewer is a viewer of a Menu. Viewers are updated when the contents of the Menu change.
+type Viewer interface {
+	// ViewSlotChange views a change of a single slot in the inventory of a Menu.
+	ViewSlotChange(slot int, newItem item.Stack)
+}
+
+// New creates a new Menu of the Type passed. The name passed is shown as the title of the Menu. If empty, the default
+// name of the container is used.
+func New(typ Type, name string) *Menu {
+	m := &Menu{
+		typ:     typ,
+		name:    name,
+		viewers: make(map[Viewer]struct{}),
+		clicks:  make(map[int]ClickFunc),
+		locked:  make(map[int]struct{}),
+	}
+	m.inv = inventory.New(typ.Size(), func(slot int, _, after item.Stack) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		for v := range m.viewers {
+			v.ViewSlotChange(slot, after)
+		}
+	})
+	m.inv.Handle(handler{m: m})
+	return m
+}
+
+// Type returns the Type of the Menu.
+func (m *Menu) Type() Type {
+	return m.typ
+}
+
+// Name returns the name of the Menu that is shown as its title.
+func (m *Menu) Name() string {
+	return m.name
+}
+
+// Inventory returns the inventory of the Menu. Items may be added to it like any other inventory, but its Handler
+// must not be changed, as the Menu relies on it to call ClickFuncs and to lock slots.
+func (m *Menu) Inventory() *inventory.Inventory {
+	return m.inv
+}
+
+// SetButton sets the item.Stack passed in a slot of the Menu, locks the slot and calls the ClickFunc passed when a
+// player clicks it. If f is nil, the slot is only locked.
+func (m *Menu) SetButton(slot int, it item.Stack, f ClickFunc) {
+	m.mu.Lock()
+	m.locked[slot] = struct{}{}
+	if f != nil {
+		m.clicks[slot] = f
+	} else {
+		delete(m.clicks, slot)
+	}
+	m.mu.Unlock()
+	_ = m.inv.SetItem(slot, it)
+}
+
+// OnClick sets a ClickFunc that is called when a player clicks the slot passed. Unlike SetButton, OnClick does not
+// lock the slot, so players may still take or place items in it unless ctx.Cancel() is called.
+func (m *Menu) OnClick(slot int, f ClickFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if f == nil {
+		delete(m.clicks, slot)
+		return
+	}
+	m.clicks[slot] = f
+}
+
+// Lock locks the slots passed, so that players can neither take items from nor place items in them.
+func (m *Menu) Lock(slots ...int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, slot := range slots {
+		m.locked[slot] = struct{}{}
+	}
+}
+
+// Unlock unlocks the slots passed again after a call to Lock or SetButton.
+func (m *Menu) Unlock(slots ...int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, slot := range slots {
+		delete(m.locked, slot)
+	}
+}
+
+// LockAll locks or unlocks all slots of the Menu at once, regardless of the slots locked using Lock.
+func (m *Menu) LockAll(lock bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lockAll = lock
+}
+
+// Locked checks if the slot passed is locked.
+func (m *Menu) Locked(slot int) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.locked[slot]
+	return ok || m.lockAll
+}
+
+// OnClose sets a function that is called when a player closes the Menu.
+func (m *Menu) OnClose(f func(h inventory.Holder)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.close = f
+}
+
+// Clear clears all items, click functions and locked slots of the Menu.
+func (m *Menu) Clear() {
+	m.mu.Lock()
+	clear(m.clicks)
+	clear(m.locked)
+	m.mu.Unlock()
+	_ = m.inv.Clear()
+}
+
+// AddViewer adds a Viewer to the Menu, so that it is updated whenever the contents of the Menu change.
+func (m *Menu) AddViewer(v Viewer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.viewers[v] = struct{}{}
+}
+
+// RemoveViewer removes a Viewer from the Menu, so that it is no longer updated when the contents of the Menu change.
+func (m *Menu) RemoveViewer(v Viewer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.viewers, v)
+}
+
+// Close calls the function set using OnClose with the inventory.Holder passed. It is called by the session of a
+// player when the Menu is closed.
+func (m *Menu) Close(h inventory.Holder) {
+	m.mu.Lock()
+	f := m.close
+	m.mu.Unlock()
+	if f != nil {
+		f(h)
+	}
+}
+
+// click calls the ClickFunc of the slot passed, if any, and cancels the context if the slot is locked. The ClickFunc
+// is not called again for the same slot and context.
+func (m *Menu) click(ctx *inventory.Context, slot int, it item.Stack) {
+	m.mu.Lock()
+	f := m.clicks[slot]
+	if m.lastCtx == ctx && m.lastSlot == slot {
+		f = nil
+	}
+	m.lastCtx, m.lastSlot = ctx, slot
+	m.mu.Unlock()
+	if f != nil {
+		f(ctx, slot, it)
+	}
+	if m.Locked(slot) {
+		ctx.Cancel()
+	}
+}
+
+// handler is the inventory.Handler of the inventory of a Menu. It calls the ClickFuncs of the Menu and prevents
+// changes to locked slots.
+type handler struct {
+	m *Menu
+}
+
+// HandleTake ...
+func (h handler) HandleTake(ctx *inventory.Context, slot int, it item.Stack) {
+	h.m.click(ctx, slot, it)
+}
+
+// HandlePlace ...
+func (h handler) HandlePlace(ctx *inventory.Context, slot int, it item.Stack) {
+	h.m.click(ctx, slot, it)
+}
+
+// HandleDrop ...
+func (h handler) HandleDrop(ctx *inventory.Context, slot int, it item.Stack) {
+	h.m.click(ctx, slot, it)
+}
+
+// HandleTransaction ...
+func (h handler) HandleTransaction(*inventory.Context, inventory.Transaction) {}
@@ -0,0 +1,62 @@
+package menu
+
+import (
+	"testing"
+
+	"github.com/df-mc/dragonfly/server/event"
+	"github.com/df-mc/dragonfly/server/item"
+	"github.com/df-mc/dragonfly/server/item/inventory"
+)
+
+func TestMenuClick(t *testing.T) {
+	m := New(Chest(), "")
+	var clicks []item.Stack
+	m.OnClick(3, func(_ *inventory.Context, _ int, it item.Stack) {
+		clicks = append(clicks, it)
+	})
+	h := m.Inventory().Handler()
+	taken, placed := item.NewStack(item.Diamond{}, 1), item.NewStack(item.Emerald{}, 1)
+
+	t.Run("place", func(t *testing.T) {
+		clicks = nil
+		ctx := event.C[inventory.Holder](nil)
+		if h.HandlePlace(ctx, 3, placed); ctx.Cancelled() {
+			t.Fatalf("HandlePlace() in unlocked slot cancelled")
+		}
+		if len(clicks) != 1 || !clicks[0].Equal(placed) {
+			t.Fatalf("clicks after HandlePlace() = %v, want [%v]", clicks, placed)
+		}
+	})
+	t.Run("swap", func(t *testing.T) {
+		clicks = nil
+		ctx := event.C[inventory.Holder](nil)
+		h.HandleTake(ctx, 3, taken)
+		h.HandlePlace(ctx, 3, placed)
+		if len(clicks) != 1 || !clicks[0].Equal(taken) {
+			t.Fatalf("clicks after swap = %v, want [%v]", clicks, taken)
+		}
+	})
+	t.Run("locked", func(t *testing.T) {
+		clicks = nil
+		m.Lock(3)
+		defer m.Unlock(3)
+		ctx := event.C[inventory.Holder](nil)
+		if h.HandlePlace(ctx, 3, placed); !ctx.Cancelled() {
+			t.Fatalf("HandlePlace() in locked slot not cancelled")
+		}
+		if len(clicks) != 1 {
+			t.Fatalf("clicks after HandlePlace() in locked slot = %v, want 1 click", len(clicks))
+		}
+	})
+}
+
+func TestTypeSize(t *testing.T) {
+	for _, tt := range []struct {
+		typ  Type
+		size int
+	}{{Chest(), 27}, {DoubleChest(), 54}, {Hopper(), 5}, {Dispenser(), 9}, {Dropper(), 9}} {
+		if size := New(tt.typ, "").Inventory().Size(); size != tt.size {
+			t.Fatalf("size of %v menu = %v, want %v", tt.typ.BlockActorID(), size, tt.size)
+		}
+	}
+}
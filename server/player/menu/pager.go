@@ -0,0 +1,83 @@
+package menu
+
+import (
+	"github.com/df-mc/dragonfly/server/item"
+	"github.com/df-mc/dragonfly/server/item/inventory"
+)
+
+// Button is an item displayed in a Menu by a Pager. Its ClickFunc is called when a player clicks it.
+type Button struct {
+	// Item is the item.Stack displayed in the Menu.
+	Item item.Stack
+	// Click is called when a player clicks the Button. It may be nil.
+	Click ClickFunc
+}
+
+// Pager spreads a list of Buttons over multiple pages of a Menu. A fixed set of slots of the Menu is used for the
+// Buttons, and two navigation slots are used to move to the previous and next page.
+type Pager struct {
+	m       *Menu
+	buttons []Button
+	slots   []int
+
+	prevSlot, nextSlot int
+	prev, next         item.Stack
+
+	page int
+}
+
+// NewPager creates a Pager that displays the Buttons passed in the slots of the Menu passed. The item.Stacks prev and
+// next are displayed in the slots prevSlot and nextSlot if a previous or next page exists, respectively. The first
+// page is displayed immediately.
+func NewPager(m *Menu, buttons []Button, slots []int, prevSlot int, prev item.Stack, nextSlot int, next item.Stack) *Pager {
+	p := &Pager{m: m, buttons: buttons, slots: slots, prevSlot: prevSlot, nextSlot: nextSlot, prev: prev, next: next}
+	p.SetPage(0)
+	return p
+}
+
+// Page returns the index of the page currently displayed, starting at 0.
+func (p *Pager) Page() int {
+	return p.page
+}
+
+// Pages returns the total amount of pages of the Pager. It is always at least 1.
+func (p *Pager) Pages() int {
+	if len(p.slots) == 0 || len(p.buttons) == 0 {
+		return 1
+	}
+	return (len(p.buttons) + len(p.slots) - 1) / len(p.slots)
+}
+
+// Next displays the next page, if there is one.
+func (p *Pager) Next() {
+	p.SetPage(p.page + 1)
+}
+
+// Previous displays the previous page, if there is one.
+func (p *Pager) Previous() {
+	p.SetPage(p.page - 1)
+}
+
+// SetPage displays the page with the index passed. The index is clamped to the available pages.
+func (p *Pager) SetPage(page int) {
+	p.page = max(0, min(page, p.Pages()-1))
+
+	offset := p.page * len(p.slots)
+	for i, slot := range p.slots {
+		if offset+i >= len(p.buttons) {
+			p.m.SetButton(slot, item.Stack{}, nil)
+			continue
+		}
+		b := p.buttons[offset+i]
+		p.m.SetButton(slot, b.Item, b.Click)
+	}
+
+	p.m.SetButton(p.prevSlot, item.Stack{}, nil)
+	if p.page > 0 {
+		p.m.SetButton(p.prevSlot, p.prev, func(*inventory.Context, int, item.Stack) { p.Previous() })
+	}
+	p.m.SetButton(p.nextSlot, item.Stack{}, nil)
+	if p.page < p.Pages()-1 {
+		p.m.SetButton(p.nextSlot, p.next, func(*inventory.Context, int, item.Stack) { p.Next() })
+	}
+}
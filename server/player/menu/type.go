@@ -0,0 +1,101 @@
+package menu
+
+import "github.com/sandertv/gophertunnel/minecraft/protocol"
+
+// Type is the type of Menu. It determines the size of the Menu's inventory and the block that is sent to the client
+// to display the Menu.
+type Type struct {
+	t menuType
+}
+
+type menuType uint8
+
+const (
+	menuChest menuType = iota
+	menuDoubleChest
+	menuHopper
+	menuDispenser
+	menuDropper
+)
+
+// Chest returns a Type for a single chest menu with 27 slots.
+func Chest() Type {
+	return Type{menuChest}
+}
+
+// DoubleChest returns a Type for a double chest menu with 54 slots. Two fake chest blocks are sent to display it.
+func DoubleChest() Type {
+	return Type{menuDoubleChest}
+}
+
+// Hopper returns a Type for a hopper menu with 5 slots.
+func Hopper() Type {
+	return Type{menuHopper}
+}
+
+// Dispenser returns a Type for a dispenser menu with 9 slots.
+func Dispenser() Type {
+	return Type{menuDispenser}
+}
+
+// Dropper returns a Type for a dropper menu with 9 slots.
+func Dropper() Type {
+	return Type{menuDropper}
+}
+
+// Size returns the amount of slots that a Menu of the Type has.
+func (t Type) Size() int {
+	switch t.t {
+	case menuChest:
+		return 27
+	case menuDoubleChest:
+		return 54
+	case menuHopper:
+		return 5
+	}
+	return 9
+}
+
+// Double checks if the Type is displayed using two paired blocks, which is the case for DoubleChest.
+func (t Type) Double() bool {
+	return t.t == menuDoubleChest
+}
+
+// Block returns the name and properties of the block state that is sent to the client to display the Menu.
+func (t Type) Block() (name string, properties map[string]any) {
+	switch t.t {
+	case menuChest, menuDoubleChest:
+		return "minecraft:chest", map[string]any{"minecraft:cardinal_direction": "north"}
+	case menuHopper:
+		return "minecraft:hopper", map[string]any{"facing_direction": int32(0), "toggle_bit": false}
+	case menuDispenser:
+		return "minecraft:dispenser", map[string]any{"facing_direction": int32(0), "triggered_bit": false}
+	}
+	return "minecraft:dropper", map[string]any{"facing_direction": int32(0), "triggered_bit": false}
+}
+
+// BlockActorID returns the ID of the block actor of the block sent to display the Menu.
+func (t Type) BlockActorID() string {
+	switch t.t {
+	case menuChest, menuDoubleChest:
+		return "Chest"
+	case menuHopper:
+		return "Hopper"
+	case menuDispenser:
+		return "Dispenser"
+	}
+	return "Dropper"
+}
+
+// ContainerType returns the container type sent to the client when opening a Menu of the Type.
+func (t Type) ContainerType() byte {
+	switch t.t {
+	case menuHopper:
+		return protocol.ContainerTypeHopper
+	case menuDispenser:
+		return protocol.ContainerTypeDispenser
+	case menuDropper:
+		return protocol.ContainerTypeDropper
+	}
+	return protocol.ContainerTypeContainer
+}
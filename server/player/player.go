@@ -3,6 +3,8 @@ package player
 import (
 	"errors"
 	"fmt"
+	"image/color"
+	"maps"
 	"math"
 	"math/rand/v2"
 	"net"
@@ -21,6 +23,7 @@ import (
 	"github.com/df-mc/dragonfly/server/item"
 	"github.com/df-mc/dragonfly/server/item/enchantment"
 	"github.com/df-mc/dragonfly/server/item/inventory"
+	"github.com/df-mc/dragonfly/server/item/recipe"
 	"github.com/df-mc/dragonfly/server/player/bossbar"
 	"github.com/df-mc/dragonfly/server/player/chat"
 	"github.com/df-mc/dragonfly/server/player/debug"
@@ -28,6 +31,7 @@ import (
 	"github.com/df-mc/dragonfly/server/player/form"
 	"github.com/df-mc/dragonfly/server/player/hud"
 	"github.com/df-mc/dragonfly/server/player/input"
+	"github.com/df-mc/dragonfly/server/player/menu"
 	"github.com/df-mc/dragonfly/server/player/scoreboard"
 	"github.com/df-mc/dragonfly/server/player/skin"
 	"github.com/df-mc/dragonfly/server/player/title"
@@ -75,6 +79,9 @@ type playerData struct {
 
 	cooldowns map[string]time.Time
 
+	unlockedRecipes map[string]struct{}
+	deathMessages   bool
+
 	speed               float64
 	flightSpeed         float64
 	verticalFlightSpeed float64
@@ -626,6 +633,9 @@ func (p *Player) Hurt(dmg float64, src world.DamageSource) (float64, bool) {
 	if _, ok := p.Effect(effect.FireResistance); (ok && src.Fire()) || p.Dead() || !p.GameMode().AllowsTakingDamage() || dmg < 0 {
 		return 0, false
 	}
+	if p.immuneByGameRules(src) {
+		return 0, false
+	}
 	totalDamage := p.FinalDamageFrom(dmg, src)
 	damageLeft := totalDamage
 
@@ -702,6 +712,26 @@ func (p *Player) Hurt(dmg float64, src world.DamageSource) (float64, bool) {
 	return totalDamage, true
 }
 
+// immuneByGameRules checks if the player is immune to damage from the world.DamageSource passed because of the game
+// rules of the world it is in.
+func (p *Player) immuneByGameRules(src world.DamageSource) bool {
+	switch s := src.(type) {
+	case entity.FallDamageSource:
+		return !world.GameRuleFallDamage.Value(p.tx)
+	case entity.DrowningDamageSource:
+		return !world.GameRuleDrowningDamage.Value(p.tx)
+	case entity.AttackDamageSource:
+		if _, ok := s.Attacker.(*Player); ok {
+			return !world.GameRulePVP.Value(p.tx)
+		}
+	case entity.ProjectileDamageSource:
+		if _, ok := s.Owner.(*Player); ok {
+			return !world.GameRulePVP.Value(p.tx)
+		}
+	}
+	return src.Fire() && !world.GameRuleFireDamage.Value(p.tx)
+}
+
 // applyTotemEffects is an unexported function that is used to handle totem effects.
 func (p *Player) applyTotemEffects() {
 	p.addHealth(2 - p.Health())
@@ -903,8 +933,9 @@ func (p *Player) kill(src world.DamageSource) {
 
 	p.addHealth(-p.MaxHealth())
 
-	keepInv := false
+	keepInv := world.GameRuleKeepInventory.Value(p.tx)
 	p.Handler().HandleDeath(p, src, &keepInv)
+	p.broadcastDeathMessage(src)
 	p.StopSneaking()
 	p.StopSprinting()
 
@@ -995,7 +1026,7 @@ func (p *Player) respawn(f func(p *Player)) {
 		return
 	}
 
-	blockPos, w, spawnObstructed, _ := p.spawnLocation()
+	blockPos, w, spawnObstructed, worldSpawn := p.spawnLocation()
 	pos := blockPos.Vec3Middle()
 
 	if spawnObstructed {
@@ -1008,7 +1039,11 @@ func (p *Player) respawn(f func(p *Player)) {
 	p.Extinguish()
 	p.ResetFallDistance()
 
+	spawnPos, spawnWorld := pos, w
 	p.Handler().HandleRespawn(p, &pos, &w)
+	// Players without a spawn of their own are spawned at a random position
+	// around the spawn of the world, unless the Handler changed the position.
+	randomSpawn := worldSpawn && pos == spawnPos && w == spawnWorld
 
 	sess := p.session()
 	src := p.tx.World()
@@ -1024,6 +1059,10 @@ func (p *Player) respawn(f func(p *Player)) {
 		np.quit("respawn failed")
 	}
 	task := w.Do(func(tx *world.Tx) {
+		tx.SkipRecording()
+		if randomSpawn {
+			pos = tx.RandomSpawn().Vec3Middle()
+		}
 		np := tx.AddEntity(handle).(*Player)
 		np.Teleport(pos)
 		np.session().SendRespawn(pos, p)
@@ -1045,7 +1084,10 @@ func (p *Player) respawn(f func(p *Player)) {
 			return
 		}
 		// Fall back to the source world so the normal quit path still runs.
-		src.Do(restore).OnDone(func(err error) {
+		src.Do(func(tx *world.Tx) {
+			tx.SkipRecording()
+			restore(tx)
+		}).OnDone(func(err error) {
 			if err == nil || errors.Is(err, world.ErrTaskPanicked) {
 				return
 			}
@@ -1060,24 +1102,25 @@ func (p *Player) respawn(f func(p *Player)) {
 	})
 }
 
-// spawnLocation designates a players safe spawn location.
-func (p *Player) spawnLocation() (playerSpawn cube.Pos, w *world.World, spawnBlockBroken bool, previousDimension world.Dimension) {
+// spawnLocation designates a players safe spawn location. worldSpawn is true
+// if the player has no valid spawn of its own and spawns at the spawn of the
+// world returned instead.
+func (p *Player) spawnLocation() (playerSpawn cube.Pos, w *world.World, spawnBlockBroken, worldSpawn bool) {
 	tx := p.tx
 	w = tx.World()
-	previousDimension = w.Dimension()
 	playerSpawn = w.PlayerSpawn(p.UUID())
 	if b, ok := tx.Block(playerSpawn).(block.Bed); ok && b.CanRespawnOn() {
 		pos, ok := b.SafeSpawn(playerSpawn, tx)
 		if ok {
-			return pos, w, false, previousDimension
+			return pos, w, false, false
 		}
 	}
 
 	// We can use the principle here that returning through a portal of a specific dimension inside that dimension will
 	// always bring us back to the overworld.
 	w = w.PortalDestination(w.Dimension())
-	worldSpawn := w.Spawn()
-	return worldSpawn, w, playerSpawn != worldSpawn, previousDimension
+	spawn := w.Spawn()
+	return spawn, w, playerSpawn != spawn, true
 }
 
 // StartSprinting makes a player start sprinting, increasing the speed of the player by 30% and making
@@ -1855,6 +1898,9 @@ func (p *Player) AttackEntity(e world.Entity) bool {
 	if isLiving && living.Dead() {
 		return false
 	}
+	if _, ok := e.(*Player); ok && !world.GameRulePVP.Value(p.tx) {
+		return false
+	}
 
 	var (
 		force, height  = 0.45, 0.3608
@@ -1985,15 +2031,15 @@ func (p *Player) StartBreaking(pos cube.Pos, face cube.Face) {
 	if p.GameMode().CreativeInventory() {
 		return
 	}
-	p.lastBreakDuration = p.breakTime(pos)
+	p.lastBreakDuration = p.BreakDuration(pos)
 	for _, viewer := range p.viewers() {
 		viewer.ViewBlockAction(pos, block.StartCrackAction{BreakTime: p.lastBreakDuration})
 	}
 }
 
-// breakTime returns the time needed to break a block at the position passed, taking into account the item
+// BreakDuration returns the time needed to break a block at the position passed, taking into account the item
 // held, if the player is on the ground/underwater and if the player has any effects.
-func (p *Player) breakTime(pos cube.Pos) time.Duration {
+func (p *Player) BreakDuration(pos cube.Pos) time.Duration {
 	held, _ := p.HeldItems()
 	return block.BreakDuration(p.tx.Block(pos), held, p.breakContext())
 }
@@ -2063,7 +2109,7 @@ func (p *Player) ContinueBreaking(face cube.Face) {
 		// either. Every 5 ticks seems accurate.
 		p.tx.PlaySound(pos.Vec3(), sound.BlockBreaking{Block: b})
 	}
-	if breakTime := p.breakTime(pos); breakTime != p.lastBreakDuration {
+	if breakTime := p.BreakDuration(pos); breakTime != p.lastBreakDuration {
 		for _, viewer := range p.viewers() {
 			viewer.ViewBlockAction(pos, block.ContinueCrackAction{BreakTime: breakTime})
 		}
@@ -2092,8 +2138,9 @@ func (p *Player) placeBlock(pos cube.Pos, b world.Block, ignoreBBox bool) bool {
 		p.resendNearbyBlocks(pos, cube.Faces()...)
 		return false
 	}
-	if obstructed, selfOnly := p.obstructedPos(pos, b); obstructed && !ignoreBBox {
-		if !selfOnly {
+	if obstructed, other := p.obstructedPos(pos, b); obstructed && !ignoreBBox {
+		if other != nil {
+			p.ReportViolation(session.Violation{Type: session.ViolationBlockPlacement, Pos: pos, Entity: other})
 			// Only resend blocks if there were other entities blocking the
 			// placement than the player itself. Resending blocks placed inside
 			// the player itself leads to synchronisation issues.
@@ -2114,12 +2161,11 @@ func (p *Player) placeBlock(pos cube.Pos, b world.Block, ignoreBBox bool) bool {
 }
 
 // obstructedPos checks if the position passed is obstructed if the block
-// passed is attempted to be placed. The function returns true as the first
-// bool if there is an entity in the way that could prevent the block from
-// being placed.
-// If the only entity preventing the block from being placed is the player
-// itself, the second bool returned is true too.
-func (p *Player) obstructedPos(pos cube.Pos, b world.Block) (obstructed, selfOnly bool) {
+// passed is attempted to be placed. The function returns true if there is an
+// entity in the way that could prevent the block from being placed.
+// If an entity other than the player itself prevents the block from being
+// placed, its handle is returned too.
+func (p *Player) obstructedPos(pos cube.Pos, b world.Block) (obstructed bool, other *world.EntityHandle) {
 	blockBoxes := b.Model().BBox(pos, p.tx)
 	for i, box := range blockBoxes {
 		blockBoxes[i] = box.Translate(pos.Vec3())
@@ -2136,11 +2182,11 @@ func (p *Player) obstructedPos(pos cube.Pos, b world.Block) (obstructed, selfOnl
 				if e.H() == p.handle {
 					continue
 				}
-				return true, false
+				return true, e.H()
 			}
 		}
 	}
-	return obstructed, true
+	return obstructed, nil
 }
 
 // BreakBlock makes the player break a block in the world at a position passed. If the player is unable to
@@ -2212,6 +2258,9 @@ func (p *Player) drops(held item.Stack, b world.Block) []item.Stack {
 		t = item.ToolNone{}
 	}
 	var drops []item.Stack
+	if !world.GameRuleDoTileDrops.Value(p.tx) {
+		return drops
+	}
 	if breakable, ok := b.(block.Breakable); ok && !p.GameMode().CreativeInventory() {
 		if breakable.BreakInfo().Harvestable(t) {
 			drops = breakable.BreakInfo().Drops(t, held.Enchantments())
@@ -2276,8 +2325,10 @@ func (p *Player) PickBlock(pos cube.Pos) {
 }
 
 // Teleport teleports the player to a target position in the world. Unlike Move, it immediately changes the
-// position of the player, rather than showing an animation.
+// position of the player, rather than showing an animation. If the world has a border, the position is moved onto
+// its closest edge if it was outside of it.
 func (p *Player) Teleport(pos mgl64.Vec3) {
+	pos = p.tx.Border().Clamp(pos)
 	ctx := NewEventContext(p.tx, p)
 	if p.Handler().HandleTeleport(ctx, pos); ctx.Cancelled() {
 		return
@@ -2322,7 +2373,15 @@ func (p *Player) Move(deltaPos mgl64.Vec3, deltaYaw, deltaPitch float64) {
 	var (
 		pos         = p.Position()
 		res, resRot = pos.Add(deltaPos), p.Rotation().Add(cube.Rotation{deltaYaw, deltaPitch})
+		border      = p.tx.Border()
 	)
+	if !border.Within(res) && border.Distance(res) < border.Distance(pos) {
+		// The player attempted to move beyond the world border or further away from it if they were already outside.
+		if p.session() != session.Nop {
+			p.teleport(pos)
+		}
+		return
+	}
 	ctx := NewEventContext(p.tx, p)
 	if p.Handler().HandleMove(ctx, res, resRot); ctx.Cancelled() {
 		if p.session() != session.Nop && pos.ApproxEqual(p.Position()) {
@@ -2447,6 +2506,71 @@ func (p *Player) Collect(s item.Stack) (int, bool) {
 	return added, true
 }
 
+// UnlockRecipes unlocks the recipes passed for the player, so that they are shown in the recipe book of the player
+// and, if recipe unlocking is enabled on the server, may be crafted. Recipes that were already unlocked are ignored.
+// Recipes are unlocked automatically when one of their ingredients is added to the inventory or off-hand of the
+// player, for example by picking it up, crafting it or taking it out of a container.
+func (p *Player) UnlockRecipes(recipes ...recipe.Recipe) {
+	recipes = slices.DeleteFunc(slices.Clone(recipes), p.RecipeUnlocked)
+	if len(recipes) == 0 {
+		return
+	}
+	ctx := NewEventContext(p.tx, p)
+	if p.Handler().HandleRecipeUnlock(ctx, &recipes); ctx.Cancelled() || len(recipes) == 0 {
+		return
+	}
+	for _, r := range recipes {
+		p.unlockedRecipes[recipe.ID(r)] = struct{}{}
+	}
+	p.session().SendRecipeUnlocks(recipes, false)
+}
+
+// unlockIngredientRecipes unlocks the recipes that have the item of the stack after as ingredient if the item was
+// not present in the slot before.
+func (p *Player) unlockIngredientRecipes(before, after item.Stack) {
+	if after.Empty() || (!before.Empty() && before.Comparable(after)) {
+		return
+	}
+	p.UnlockRecipes(recipe.ByIngredient(after.Item())...)
+}
+
+// UnlockAllRecipes unlocks every recipe registered using recipe.Register for the player.
+func (p *Player) UnlockAllRecipes() {
+	p.UnlockRecipes(recipe.Recipes()...)
+}
+
+// LockRecipes locks the recipes passed for the player again, so that they are no longer shown in the recipe book of
+// the player and, if recipe unlocking is enabled on the server, may no longer be crafted.
+func (p *Player) LockRecipes(recipes ...recipe.Recipe) {
+	recipes = slices.DeleteFunc(slices.Clone(recipes), func(r recipe.Recipe) bool {
+		return !p.RecipeUnlocked(r)
+	})
+	if len(recipes) == 0 {
+		return
+	}
+	for _, r := range recipes {
+		delete(p.unlockedRecipes, recipe.ID(r))
+	}
+	p.session().SendRecipeLocks(recipes)
+}
+
+// RecipeUnlocked checks if the recipe passed was unlocked for the player.
+func (p *Player) RecipeUnlocked(r recipe.Recipe) bool {
+	_, ok := p.unlockedRecipes[recipe.ID(r)]
+	return ok
+}
+
+// UnlockedRecipes returns all recipes that were unlocked for the player.
+func (p *Player) UnlockedRecipes() []recipe.Recipe {
+	recipes := make([]recipe.Recipe, 0, len(p.unlockedRecipes))
+	for id := range p.unlockedRecipes {
+		if r, ok := recipe.ByID(id); ok {
+			recipes = append(recipes, r)
+		}
+	}
+	return recipes
+}
+
 // Experience returns the amount of experience the player has.
 func (p *Player) Experience() int {
 	return p.experience.Experience()
@@ -2603,6 +2727,20 @@ func (p *Player) OpenBlockContainer(pos cube.Pos, tx *world.Tx) {
 	}
 }
 
+// OpenMenu opens a menu.Menu for the Player. A fake container block is shown to the Player three blocks above its
+// feet, or at the top of the world if that is out of its range, to display the Menu, which is restored to the actual
+// block once the Menu is closed.
+func (p *Player) OpenMenu(m *menu.Menu) {
+	if p.session() == session.Nop {
+		return
+	}
+	pos := cube.PosFromVec3(p.Position()).Add(cube.Pos{0, 3})
+	if r := p.tx.Range(); pos[1] > r.Max() {
+		pos[1] = r.Max()
+	}
+	p.session().OpenMenu(m, pos, p.tx)
+}
+
 // HideEntity hides a world.Entity from the Player so that it can under no circumstance see it. Hidden entities can be
 // made visible again through a call to ShowEntity.
 func (p *Player) HideEntity(e world.Entity) {
@@ -2667,6 +2805,12 @@ func (p *Player) Tick(tx *world.Tx, current int64) {
 	if p.insideOfSolid() {
 		p.Hurt(1, entity.SuffocationDamageSource{})
 	}
+	if border := tx.Border(); border.Enabled() && current%20 == 0 {
+		if dmg := border.Damage(p.Position()); dmg > 0 {
+			p.Hurt(dmg, entity.WorldBorderDamageSource{})
+		}
+		p.showBorder(border)
+	}
 
 	if p.OnFireDuration() > 0 {
 		p.fireTicks -= 1
@@ -2798,6 +2942,41 @@ func (p *Player) tickAirSupply() {
 	}
 }
 
+// showBorder shows the edges of the world.Border passed that the player is within the warning distance of, by
+// showing a wall of particles around the player. The particles are only shown to the player itself and are spaced
+// two blocks apart, so that at most 15 particles are shown for every edge.
+func (p *Player) showBorder(b world.Border) {
+	if b.WarningDistance <= 0 || p.session() == session.Nop {
+		return
+	}
+	pos, minimum, maximum := p.Position(), b.Min(), b.Max()
+	edges := [4]struct {
+		// axis is the axis along which the edge extends: 0 for X and 2 for Z. The edge lies at the fixed value
+		// on the other axis.
+		axis  int
+		fixed float64
+	}{{2, minimum[0]}, {2, maximum[0]}, {0, minimum[1]}, {0, maximum[1]}}
+
+	dust := particle.Dust{Colour: color.RGBA{R: 0xff, G: 0x33, B: 0x33, A: 0xff}}
+	for _, edge := range edges {
+		other := 2 - edge.axis
+		if math.Abs(pos[other]-edge.fixed) > b.WarningDistance {
+			continue
+		}
+		lower, upper := minimum[edge.axis/2], maximum[edge.axis/2]
+		for along := math.Round(pos[edge.axis]) - 4; along <= math.Round(pos[edge.axis])+4; along += 2 {
+			if along < lower || along > upper {
+				continue
+			}
+			for y := math.Floor(pos[1]) - 1; y <= math.Floor(pos[1])+3; y += 2 {
+				var particlePos mgl64.Vec3
+				particlePos[edge.axis], particlePos[other], particlePos[1] = along, edge.fixed, y
+				p.ShowParticle(particlePos, dust)
+			}
+		}
+	}
+}
+
 // tickFood ticks food related functionality, such as the depletion of the food bar and regeneration if it
 // is full enough.
 func (p *Player) tickFood() {
@@ -2829,6 +3008,9 @@ func (p *Player) tickFood() {
 
 // regenerate attempts to regenerate half a heart of health, typically caused by a full food bar.
 func (p *Player) regenerate(exhaust bool) {
+	if !world.GameRuleNaturalRegeneration.Value(p.tx) {
+		return
+	}
 	if p.Health() == p.MaxHealth() {
 		return
 	}
@@ -3192,11 +3374,40 @@ func (p *Player) PunchAir() {
 	p.tx.PlaySound(p.Position(), sound.Attack{})
 }
 
+// AllowInventoryTransaction calls the Handler of the player with the inventory.Transaction passed. It returns false if
+// the Handler cancelled the transaction, in which case none of its actions should be performed.
+func (p *Player) AllowInventoryTransaction(t inventory.Transaction) bool {
+	ctx := NewEventContext(p.tx, p)
+	p.Handler().HandleInventoryTransaction(ctx, t)
+	return !ctx.Cancelled()
+}
+
 // UpdateDiagnostics updates the diagnostics of the player.
 func (p *Player) UpdateDiagnostics(d session.Diagnostics) {
 	p.Handler().HandleDiagnostics(p, d)
 }
 
+// ReportViolation reports a violation detected while validating the input of
+// the player's client to its Handler. False is returned if the Handler
+// cancelled the violation.
+func (p *Player) ReportViolation(v session.Violation) bool {
+	ctx := NewEventContext(p.tx, p)
+	p.Handler().HandleViolation(ctx, v)
+	return !ctx.Cancelled()
+}
+
+// ReportRateLimit reports the player's client exceeding the rate limit of a
+// category of packets to its Handler. The action to take against the client
+// is returned, or false if the Handler cancelled it.
+func (p *Player) ReportRateLimit(category session.PacketCategory, action session.RateLimitAction) (session.RateLimitAction, bool) {
+	ctx := NewEventContext(p.tx, p)
+	p.Handler().HandleRateLimit(ctx, category, &action)
+	return action, !ctx.Cancelled()
+}
+
+// Player takes part in the validation of the input of its client.
+var _ session.ValidatedControllable = (*Player)(nil)
+
 // ShowHudElement shows a HUD element to the player if it is not already shown.
 func (p *Player) ShowHudElement(e hud.Element) {
 	p.session().ShowHudElement(e)
@@ -3409,6 +3620,8 @@ func (p *Player) Data() Config {
 		FireTicks:           p.fireTicks,
 		FallDistance:        p.fallDistance,
 		Effects:             p.Effects(),
+		UnlockedRecipes:     slices.Collect(maps.Keys(p.unlockedRecipes)),
+		DeathMessages:       p.deathMessages,
 	}
 }
 
@@ -32,11 +32,15 @@ func (t ptype) Open(tx *world.Tx, handle *world.EntityHandle, data *world.Entity
 		pd.s.HandleInventories(tx, p, pd.inv, pd.offHand, pd.enderChest, pd.ui, pd.armour, pd.heldSlot)
 	} else {
 		pd.inv.SlotFunc(func(slot int, before, after item.Stack) {
+			p.unlockIngredientRecipes(before, after)
 			if slot == int(*p.heldSlot) {
 				p.broadcastItems(slot, before, after)
 			}
 		})
-		pd.offHand.SlotFunc(p.broadcastItems)
+		pd.offHand.SlotFunc(func(slot int, before, after item.Stack) {
+			p.unlockIngredientRecipes(before, after)
+			p.broadcastItems(slot, before, after)
+		})
 		pd.armour.Inventory().SlotFunc(p.broadcastArmour)
 	}
 	return p
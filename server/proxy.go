@@ -0,0 +1,616 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/df-mc/dragonfly/server/internal/proxyproto"
+	"github.com/df-mc/dragonfly/server/session"
+	"github.com/sandertv/go-raknet"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/login"
+)
+
+const (
+	// identityTLV is the type of the PROXY protocol TLV that holds the JSON
+	// encoded ForwardedIdentity of a forwarded connection.
+	identityTLV = 0xe0
+	// signatureTLV is the type of the PROXY protocol TLV that holds the
+	// HMAC-SHA256 signature of the ForwardedIdentity.
+	signatureTLV = 0xe1
+	// maxIdentityAge is the maximum difference between the time a
+	// ForwardedIdentity was issued at and the time it is verified.
+	maxIdentityAge = time.Second * 30
+	// maxFrameSize is the maximum size of a frame read from a TCP connection
+	// forwarded by a proxy.
+	maxFrameSize = 1024 * 1024 * 3
+)
+
+// ProxyConfig is the configuration of a Listener that accepts connections
+// forwarded by trusted proxies rather than from clients directly. Proxies
+// prefix forwarded connections with a version 2 PROXY protocol header holding
+// the address of the client and, if a Secret is set, the identity of the
+// client signed with the Secret. The address of the client is then returned
+// by session.Conn.RemoteAddr and passed to the Allower as a ForwardedAddr,
+// and the identity of the client overrides the identity data sent by the
+// client when logging in.
+//
+// ProxyConfig.Listener may be added to Config.Listeners to listen for
+// forwarded connections.
+type ProxyConfig struct {
+	// Address is the address to listen on for connections from proxies, such
+	// as ":19133".
+	Address string
+	// TCP specifies if connections are accepted over TCP instead of RakNet.
+	// Over TCP, proxies send the PROXY protocol header at the start of the
+	// connection, followed by batches of packets that are each prefixed with
+	// their length as a varuint32. Over RakNet, proxies prefix a datagram with
+	// the PROXY protocol header before the client connects.
+	TCP bool
+	// Secret is the secret shared with the proxies, used to verify the
+	// signatures of forwarded identities. If empty, proxies do not forward
+	// the identity of clients and only the address of the client is
+	// forwarded. Each forwarded identity holds a nonce that may only be used
+	// by a single proxy connection, so that a captured header cannot be
+	// replayed by anyone else.
+	Secret []byte
+	// TrustedProxies holds the networks that proxies may connect from.
+	// Connections and datagrams from other addresses are dropped. If empty,
+	// proxies may connect from any address and Secret must be set.
+	TrustedProxies []netip.Prefix
+	// HandshakeTimeout is the maximum duration that a proxy may take to send
+	// the PROXY protocol header after opening a TCP connection. If 0, a
+	// timeout of 5 seconds is used.
+	HandshakeTimeout time.Duration
+}
+
+// Listener creates a Listener that accepts connections forwarded by proxies
+// using the Config passed. Listener has the signature of the functions in
+// Config.Listeners so that it may be added to them directly.
+func (pc ProxyConfig) Listener(conf Config) (Listener, error) {
+	if len(pc.Secret) == 0 && len(pc.TrustedProxies) == 0 {
+		return nil, errors.New("create proxy listener: either Secret or TrustedProxies must be set")
+	}
+	cfg := listenConfig(conf)
+	cfg.Allow = func(addr net.Addr, d login.IdentityData, c login.ClientData) (string, bool) {
+		if forwarded, ok := addr.(ForwardedAddr); ok {
+			d = forwarded.identityData(d)
+		}
+		return conf.Allower.Allow(addr, d, c)
+	}
+	l, err := cfg.ListenNetwork(proxyNetwork{conf: pc, log: conf.Log, nonces: newNonceCache()}, pc.Address)
+	if err != nil {
+		return nil, fmt.Errorf("create proxy listener: %w", err)
+	}
+	conf.Log.Info("Proxy listener running.", "addr", l.Addr(), "tcp", pc.TCP)
+	return proxyListener{listener{l}}, nil
+}
+
+// Header returns a version 2 PROXY protocol header for a connection of the
+// client with the address and identity passed, signed using the Secret of
+// the ProxyConfig. Proxies implemented in Go may use Header to forward
+// connections to a Listener created using the same ProxyConfig.
+func (pc ProxyConfig) Header(src netip.AddrPort, id ForwardedIdentity) ([]byte, error) {
+	dst := netip.AddrPortFrom(netip.IPv4Unspecified(), 0)
+	if src.Addr().Is6() {
+		dst = netip.AddrPortFrom(netip.IPv6Unspecified(), 0)
+	}
+	h := proxyproto.Header{Source: src, Destination: dst, Datagram: !pc.TCP}
+	if len(pc.Secret) != 0 {
+		if id.IssuedAt == 0 {
+			id.IssuedAt = time.Now().Unix()
+		}
+		if id.Nonce == "" {
+			id.Nonce = crand.Text()
+		}
+		payload, err := json.Marshal(id)
+		if err != nil {
+			return nil, fmt.Errorf("encode forwarded identity: %w", err)
+		}
+		h.TLVs = map[byte][]byte{identityTLV: payload, signatureTLV: pc.sign(src, payload)}
+	}
+	return h.Append(nil), nil
+}
+
+// ForwardedIdentity is the identity of a client as authenticated by a proxy
+// and forwarded to the server, signed using the secret shared between them.
+type ForwardedIdentity struct {
+	// XUID is the XBOX Live user ID of the client. It is empty if the client
+	// was not authenticated with XBOX Live.
+	XUID string `json:"xuid"`
+	// Identity is the UUID of the client.
+	Identity string `json:"uuid"`
+	// DisplayName is the username of the client.
+	DisplayName string `json:"name"`
+	// IssuedAt is the Unix time in seconds at which the proxy issued the
+	// ForwardedIdentity. Identities issued too long before they are verified
+	// are refused.
+	IssuedAt int64 `json:"iat"`
+	// Nonce is a random value unique to the ForwardedIdentity, set by
+	// ProxyConfig.Header if empty. Identities with a nonce that was already
+	// used by another proxy connection are refused.
+	Nonce string `json:"nonce"`
+}
+
+// ForwardedAddr is the address of a client that connected through a proxy.
+// It is returned by session.Conn.RemoteAddr for connections accepted by a
+// Listener created using ProxyConfig.Listener and passed to the Allower.
+// ForwardedAddr embeds the address of the client, so that its Network and
+// String methods return those of the client.
+type ForwardedAddr struct {
+	net.Addr
+	// Proxy is the address of the proxy that forwarded the connection.
+	Proxy net.Addr
+	// Identity is the identity of the client forwarded by the proxy. It is
+	// nil if the ProxyConfig of the Listener had no Secret set.
+	Identity *ForwardedIdentity
+}
+
+// identityData returns the login.IdentityData passed with the identity
+// forwarded by the proxy replacing the identity claimed by the client.
+func (addr ForwardedAddr) identityData(d login.IdentityData) login.IdentityData {
+	if addr.Identity != nil {
+		d.XUID, d.Identity, d.DisplayName = addr.Identity.XUID, addr.Identity.Identity, addr.Identity.DisplayName
+	}
+	return d
+}
+
+// sign returns the HMAC-SHA256 signature of the identity payload passed,
+// forwarded for a client with the address passed.
+func (pc ProxyConfig) sign(src netip.AddrPort, payload []byte) []byte {
+	mac := hmac.New(sha256.New, pc.Secret)
+	mac.Write([]byte(src.String()))
+	mac.Write([]byte{0})
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// forwarded returns the ForwardedAddr of a connection forwarded by the proxy
+// with the address passed, verifying the identity in the header passed if a
+// Secret is set. The nonce of the identity is claimed for the proxy address
+// in the nonceCache passed.
+func (pc ProxyConfig) forwarded(h proxyproto.Header, proxy net.Addr, nonces *nonceCache) (ForwardedAddr, error) {
+	if h.Local() {
+		return ForwardedAddr{}, errors.New("forwarded: connection not forwarded for a client")
+	}
+	addr := ForwardedAddr{Addr: h.SourceAddr(), Proxy: proxy}
+	if len(pc.Secret) == 0 {
+		return addr, nil
+	}
+	payload, ok := h.TLVs[identityTLV]
+	if !ok {
+		return addr, errors.New("forwarded: missing identity")
+	}
+	if !hmac.Equal(h.TLVs[signatureTLV], pc.sign(h.Source, payload)) {
+		return addr, errors.New("forwarded: invalid identity signature")
+	}
+	addr.Identity = new(ForwardedIdentity)
+	if err := json.Unmarshal(payload, addr.Identity); err != nil {
+		return addr, fmt.Errorf("forwarded: decode identity: %w", err)
+	}
+	if age := time.Since(time.Unix(addr.Identity.IssuedAt, 0)); age > maxIdentityAge || age < -maxIdentityAge {
+		return addr, fmt.Errorf("forwarded: identity issued %v ago", age)
+	}
+	if addr.Identity.Nonce == "" {
+		return addr, errors.New("forwarded: missing identity nonce")
+	}
+	if !nonces.claim(addr.Identity.Nonce, proxy.String()) {
+		return addr, errors.New("forwarded: identity nonce already used")
+	}
+	return addr, nil
+}
+
+// nonceCache holds the nonces of forwarded identities that were used in the
+// last maxIdentityAge, along with the address of the proxy connection that
+// used them. It is safe for concurrent use.
+type nonceCache struct {
+	mu     sync.Mutex
+	used   map[string]nonceUse
+	pruned time.Time
+}
+
+// nonceUse is the use of a nonce by a proxy connection.
+type nonceUse struct {
+	proxy   string
+	expires time.Time
+}
+
+// newNonceCache returns an empty nonceCache.
+func newNonceCache() *nonceCache {
+	return &nonceCache{used: make(map[string]nonceUse)}
+}
+
+// claim claims the nonce passed for the proxy connection with the address
+// passed. False is returned if the nonce was already claimed by a different
+// proxy connection. A proxy connection may claim the same nonce more than
+// once, as proxies may send a header again over RakNet if a datagram was
+// lost.
+func (c *nonceCache) claim(nonce, proxy string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if now.Sub(c.pruned) > maxIdentityAge {
+		c.pruned = now
+		for n, use := range c.used {
+			if now.After(use.expires) {
+				delete(c.used, n)
+			}
+		}
+	}
+	if use, ok := c.used[nonce]; ok && now.Before(use.expires) {
+		return use.proxy == proxy
+	}
+	// Identities are refused once they are older than maxIdentityAge, and
+	// may be issued up to maxIdentityAge in the future, so the nonce must be
+	// kept for twice that duration.
+	c.used[nonce] = nonceUse{proxy: proxy, expires: now.Add(maxIdentityAge * 2)}
+	return true
+}
+
+// trusted checks if the address passed is within one of the TrustedProxies.
+func (pc ProxyConfig) trusted(addr net.Addr) bool {
+	if len(pc.TrustedProxies) == 0 {
+		return true
+	}
+	ap, err := netip.ParseAddrPort(addr.String())
+	if err != nil {
+		return false
+	}
+	for _, prefix := range pc.TrustedProxies {
+		if prefix.Contains(ap.Addr().Unmap()) {
+			return true
+		}
+	}
+	return false
+}
+
+// handshakeTimeout returns the HandshakeTimeout of the ProxyConfig or its
+// default.
+func (pc ProxyConfig) handshakeTimeout() time.Duration {
+	if pc.HandshakeTimeout <= 0 {
+		return time.Second * 5
+	}
+	return pc.HandshakeTimeout
+}
+
+// proxyListener is a Listener that returns connections with the identity
+// forwarded by the proxy replacing the identity claimed by the client.
+type proxyListener struct {
+	listener
+}
+
+// Accept blocks until the next connection is established and returns it. An error is returned if the Listener was
+// closed using Close.
+func (l proxyListener) Accept() (session.Conn, error) {
+	conn, err := l.listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	if forwarded, ok := conn.RemoteAddr().(ForwardedAddr); ok && forwarded.Identity != nil {
+		return identityConn{Conn: conn, d: forwarded.identityData(conn.IdentityData())}, nil
+	}
+	return conn, nil
+}
+
+// Disconnect disconnects a connection from the Listener with a reason.
+func (l proxyListener) Disconnect(conn session.Conn, reason string) error {
+	if c, ok := conn.(identityConn); ok {
+		conn = c.Conn
+	}
+	return l.listener.Disconnect(conn, reason)
+}
+
+// identityConn is a session.Conn with an identity forwarded by a proxy.
+type identityConn struct {
+	session.Conn
+	d login.IdentityData
+}
+
+// IdentityData returns the identity forwarded by the proxy.
+func (c identityConn) IdentityData() login.IdentityData {
+	return c.d
+}
+
+// proxyNetwork is a minecraft.Network that listens for connections forwarded
+// by proxies. It cannot be used to dial connections.
+type proxyNetwork struct {
+	conf   ProxyConfig
+	log    *slog.Logger
+	nonces *nonceCache
+}
+
+// DialContext always returns an error.
+func (proxyNetwork) DialContext(context.Context, string) (net.Conn, error) {
+	return nil, errors.New("proxy network: dialing is not supported")
+}
+
+// PingContext always returns an error.
+func (proxyNetwork) PingContext(context.Context, string) ([]byte, error) {
+	return nil, errors.New("proxy network: pinging is not supported")
+}
+
+// Listen listens for connections from proxies over TCP or RakNet, depending
+// on the ProxyConfig of the proxyNetwork.
+func (n proxyNetwork) Listen(address string) (minecraft.NetworkListener, error) {
+	if n.conf.TCP {
+		l, err := net.Listen("tcp", address)
+		if err != nil {
+			return nil, err
+		}
+		sl := &streamListener{Listener: l, conf: n.conf, log: n.log, nonces: n.nonces, id: rand.Int64(), incoming: make(chan net.Conn), closed: make(chan struct{})}
+		go sl.listen()
+		return sl, nil
+	}
+	upstream := &packetListener{conf: n.conf, log: n.log, nonces: n.nonces}
+	l, err := raknet.ListenConfig{ErrorLog: n.log.With("net origin", "raknet"), UpstreamPacketListener: upstream}.Listen(address)
+	if err != nil {
+		return nil, err
+	}
+	return datagramListener{Listener: l, conn: upstream.conn}, nil
+}
+
+// streamListener is a minecraft.NetworkListener that accepts TCP connections
+// from proxies, each starting with a PROXY protocol header.
+type streamListener struct {
+	net.Listener
+	conf   ProxyConfig
+	log    *slog.Logger
+	nonces *nonceCache
+	id     int64
+
+	incoming chan net.Conn
+	closed   chan struct{}
+	once     sync.Once
+}
+
+// listen accepts TCP connections until the streamListener is closed and
+// reads their headers in separate goroutines, so that proxies slow to send
+// a header do not delay other connections.
+func (l *streamListener) listen() {
+	defer l.Close()
+	for {
+		c, err := l.Listener.Accept()
+		if err != nil {
+			return
+		}
+		go l.handshake(c)
+	}
+}
+
+// handshake reads and verifies the PROXY protocol header of the connection
+// passed and passes the connection to Accept if successful.
+func (l *streamListener) handshake(c net.Conn) {
+	if !l.conf.trusted(c.RemoteAddr()) {
+		l.log.Debug("proxy listener: dropped connection from untrusted address", "raddr", c.RemoteAddr())
+		_ = c.Close()
+		return
+	}
+	_ = c.SetReadDeadline(time.Now().Add(l.conf.handshakeTimeout()))
+	r := bufio.NewReader(c)
+	h, err := proxyproto.Read(r)
+	var addr ForwardedAddr
+	if err == nil {
+		addr, err = l.conf.forwarded(h, c.RemoteAddr(), l.nonces)
+	}
+	if err != nil {
+		l.log.Debug("proxy listener: dropped connection", "raddr", c.RemoteAddr(), "err", err)
+		_ = c.Close()
+		return
+	}
+	_ = c.SetReadDeadline(time.Time{})
+
+	select {
+	case l.incoming <- &framedConn{Conn: c, r: r, addr: addr}:
+	case <-l.closed:
+		_ = c.Close()
+	}
+}
+
+// Accept blocks until the next connection forwarded by a proxy is accepted.
+func (l *streamListener) Accept() (net.Conn, error) {
+	select {
+	case c := <-l.incoming:
+		return c, nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close closes the streamListener.
+func (l *streamListener) Close() error {
+	err := net.ErrClosed
+	l.once.Do(func() {
+		close(l.closed)
+		err = l.Listener.Close()
+	})
+	return err
+}
+
+// ID returns the unique ID of the streamListener.
+func (l *streamListener) ID() int64 {
+	return l.id
+}
+
+// PongData is a no-op, as proxies do not ping over TCP.
+func (l *streamListener) PongData([]byte) {}
+
+// framedConn is a TCP connection forwarded by a proxy. Batches of packets
+// written to and read from a framedConn are each prefixed with their length
+// as a varuint32.
+type framedConn struct {
+	net.Conn
+	r    *bufio.Reader
+	addr ForwardedAddr
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+// RemoteAddr returns the ForwardedAddr of the client.
+func (c *framedConn) RemoteAddr() net.Addr {
+	return c.addr
+}
+
+// ReadPacket reads a single batch of packets from the framedConn.
+func (c *framedConn) ReadPacket() ([]byte, error) {
+	n, err := binary.ReadUvarint(c.r)
+	if err != nil {
+		return nil, err
+	}
+	if n > maxFrameSize {
+		return nil, fmt.Errorf("read frame: size %v exceeds maximum of %v", n, maxFrameSize)
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(c.r, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Read reads a single batch of packets from the framedConn into b.
+func (c *framedConn) Read(b []byte) (int, error) {
+	frame, err := c.ReadPacket()
+	if err != nil {
+		return 0, err
+	}
+	if len(frame) > len(b) {
+		return 0, io.ErrShortBuffer
+	}
+	return copy(b, frame), nil
+}
+
+// Write writes a single batch of packets to the framedConn.
+func (c *framedConn) Write(b []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.buf = append(binary.AppendUvarint(c.buf[:0], uint64(len(b))), b...)
+	if _, err := c.Conn.Write(c.buf); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// packetListener is a raknet.UpstreamPacketListener that listens for
+// datagrams from proxies.
+type packetListener struct {
+	conf   ProxyConfig
+	log    *slog.Logger
+	nonces *nonceCache
+	conn   *datagramConn
+}
+
+// ListenPacket listens for datagrams from proxies on the address passed.
+func (l *packetListener) ListenPacket(network, address string) (net.PacketConn, error) {
+	pc, err := net.ListenPacket(network, address)
+	if err != nil {
+		return nil, err
+	}
+	l.conn = &datagramConn{PacketConn: pc, conf: l.conf, log: l.log, nonces: l.nonces}
+	return l.conn, nil
+}
+
+// datagramConn is a net.PacketConn that drops datagrams from untrusted
+// addresses and strips PROXY protocol headers from datagrams, keeping track
+// of the client that each proxy address forwards.
+type datagramConn struct {
+	net.PacketConn
+	conf   ProxyConfig
+	log    *slog.Logger
+	nonces *nonceCache
+	// forwarded maps the string representation of proxy addresses to the
+	// ForwardedAddr of the client that they forward.
+	forwarded sync.Map
+}
+
+// ReadFrom reads the next datagram from a trusted proxy, stripping the PROXY
+// protocol header if present.
+func (c *datagramConn) ReadFrom(p []byte) (int, net.Addr, error) {
+	for {
+		n, addr, err := c.PacketConn.ReadFrom(p)
+		if err != nil {
+			return n, addr, err
+		}
+		if !c.conf.trusted(addr) {
+			continue
+		}
+		if !proxyproto.Is(p[:n]) {
+			return n, addr, nil
+		}
+		h, hn, err := proxyproto.Parse(p[:n])
+		var forwarded ForwardedAddr
+		if err == nil {
+			forwarded, err = c.conf.forwarded(h, addr, c.nonces)
+		}
+		if err != nil {
+			c.log.Debug("proxy listener: dropped datagram", "raddr", addr, "err", err)
+			continue
+		}
+		c.forwarded.Store(addr.String(), forwarded)
+		if hn == n {
+			// The datagram only held the header.
+			continue
+		}
+		return copy(p, p[hn:n]), addr, nil
+	}
+}
+
+// datagramListener is a minecraft.NetworkListener that accepts RakNet
+// connections from proxies that sent a PROXY protocol header.
+type datagramListener struct {
+	*raknet.Listener
+	conn *datagramConn
+}
+
+// Accept blocks until the next connection forwarded by a proxy is accepted.
+// Connections from proxies that did not send a PROXY protocol header are
+// closed.
+func (l datagramListener) Accept() (net.Conn, error) {
+	for {
+		c, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		key := c.RemoteAddr().String()
+		forwarded, ok := l.conn.forwarded.Load(key)
+		if !ok {
+			l.conn.log.Debug("proxy listener: dropped connection without header", "raddr", c.RemoteAddr())
+			_ = c.Close()
+			continue
+		}
+		return &raknetConn{Conn: c.(*raknet.Conn), addr: forwarded.(ForwardedAddr), forget: func() {
+			l.conn.forwarded.CompareAndDelete(key, forwarded)
+		}}, nil
+	}
+}
+
+// raknetConn is a RakNet connection forwarded by a proxy.
+type raknetConn struct {
+	*raknet.Conn
+	addr   ForwardedAddr
+	forget func()
+}
+
+// RemoteAddr returns the ForwardedAddr of the client.
+func (c *raknetConn) RemoteAddr() net.Addr {
+	return c.addr
+}
+
+// Close closes the connection and forgets the client forwarded by the proxy.
+func (c *raknetConn) Close() error {
+	c.forget()
+	return c.Conn.Close()
+}
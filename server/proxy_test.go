@@ -0,0 +1,54 @@
+package server
+
+import (
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/df-mc/dragonfly/server/internal/proxyproto"
+)
+
+func TestProxyForwardedIdentity(t *testing.T) {
+	pc := ProxyConfig{Secret: []byte("secret")}
+	src := netip.MustParseAddrPort("203.0.113.7:19132")
+	header := func(id ForwardedIdentity) proxyproto.Header {
+		b, err := pc.Header(src, id)
+		if err != nil {
+			t.Fatalf("Header() = %v, want nil", err)
+		}
+		h, _, err := proxyproto.Parse(b)
+		if err != nil {
+			t.Fatalf("Parse() = %v, want nil", err)
+		}
+		return h
+	}
+	proxy := &net.UDPAddr{IP: net.IPv4(198, 51, 100, 1), Port: 50000}
+	other := &net.UDPAddr{IP: net.IPv4(198, 51, 100, 2), Port: 50000}
+
+	nonces := newNonceCache()
+	h := header(ForwardedIdentity{XUID: "123", DisplayName: "Steve"})
+	addr, err := pc.forwarded(h, proxy, nonces)
+	if err != nil {
+		t.Fatalf("forwarded() = %v, want nil", err)
+	}
+	if addr.Identity == nil || addr.Identity.XUID != "123" || addr.String() != src.String() {
+		t.Fatalf("forwarded() = %+v, want identity with xuid 123 from %v", addr, src)
+	}
+	if _, err := pc.forwarded(h, proxy, nonces); err != nil {
+		t.Fatalf("forwarded() of header sent again by the same proxy = %v, want nil", err)
+	}
+	if _, err := pc.forwarded(h, other, nonces); err == nil {
+		t.Fatalf("forwarded() of header replayed from another address = nil, want error")
+	}
+
+	tampered := header(ForwardedIdentity{XUID: "123"})
+	tampered.TLVs[identityTLV] = []byte(`{"xuid":"456","iat":0,"nonce":"a"}`)
+	if _, err := pc.forwarded(tampered, proxy, nonces); err == nil {
+		t.Fatalf("forwarded() of tampered identity = nil, want error")
+	}
+	expired := header(ForwardedIdentity{IssuedAt: time.Now().Add(-maxIdentityAge * 2).Unix()})
+	if _, err := pc.forwarded(expired, proxy, nonces); err == nil {
+		t.Fatalf("forwarded() of expired identity = nil, want error")
+	}
+}
@@ -0,0 +1,142 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"net"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/sandertv/go-raknet"
+	"github.com/sandertv/gophertunnel/minecraft"
+)
+
+// connectionLimiter limits the rate at which connections are accepted from
+// a single IP address using a token bucket per address.
+type connectionLimiter struct {
+	rate, burst float64
+
+	mu        sync.Mutex
+	addresses map[netip.Addr]*connectionBucket
+	swept     time.Time
+}
+
+// connectionBucket is the token bucket of a single IP address.
+type connectionBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// newConnectionLimiter returns a connectionLimiter that accepts rate
+// connections per second on average and burst connections at once from a
+// single IP address. If burst is 0, it is rate rounded up.
+func newConnectionLimiter(rate float64, burst int) *connectionLimiter {
+	b := float64(burst)
+	if b <= 0 {
+		b = max(1, math.Ceil(rate))
+	}
+	return &connectionLimiter{rate: rate, burst: b, addresses: make(map[netip.Addr]*connectionBucket)}
+}
+
+// allow checks if a connection from the address passed may be accepted.
+func (l *connectionLimiter) allow(addr net.Addr) bool {
+	ap, err := netip.ParseAddrPort(addr.String())
+	if err != nil {
+		return true
+	}
+	ip, now := ap.Addr().Unmap(), time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if now.Sub(l.swept) > time.Minute {
+		l.sweep(now)
+	}
+	b, ok := l.addresses[ip]
+	if !ok {
+		b = &connectionBucket{tokens: l.burst}
+		l.addresses[ip] = b
+	} else {
+		b.tokens = min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// sweep removes the buckets of addresses that have been refilled completely,
+// as they are equal to the buckets of addresses not connected from before.
+func (l *connectionLimiter) sweep(now time.Time) {
+	l.swept = now
+	for ip, b := range l.addresses {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.addresses, ip)
+		}
+	}
+}
+
+// limitedNetwork is a minecraft.Network that closes connections accepted
+// from IP addresses that exceed the rate of a connectionLimiter, before they
+// log in.
+type limitedNetwork struct {
+	minecraft.Network
+	limiter *connectionLimiter
+	log     *slog.Logger
+}
+
+// Listen ...
+func (n limitedNetwork) Listen(address string) (minecraft.NetworkListener, error) {
+	l, err := n.Network.Listen(address)
+	if err != nil {
+		return nil, err
+	}
+	return limitedListener{NetworkListener: l, limiter: n.limiter, log: n.log}, nil
+}
+
+// limitedListener is the minecraft.NetworkListener of a limitedNetwork.
+type limitedListener struct {
+	minecraft.NetworkListener
+	limiter *connectionLimiter
+	log     *slog.Logger
+}
+
+// Accept accepts the next connection from an IP address that did not exceed
+// the connection rate. Other connections are closed.
+func (l limitedListener) Accept() (net.Conn, error) {
+	for {
+		c, err := l.NetworkListener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		if l.limiter.allow(c.RemoteAddr()) {
+			return c, nil
+		}
+		l.log.Debug("listener: connection rate exceeded", "raddr", c.RemoteAddr())
+		_ = c.Close()
+	}
+}
+
+// rakNet is a minecraft.Network implementation of RakNet, equal to the one
+// registered by gophertunnel, that may be wrapped by other networks.
+type rakNet struct {
+	log *slog.Logger
+}
+
+// DialContext ...
+func (r rakNet) DialContext(ctx context.Context, address string) (net.Conn, error) {
+	return raknet.Dialer{ErrorLog: r.log.With("net origin", "raknet")}.DialContext(ctx, address)
+}
+
+// PingContext ...
+func (r rakNet) PingContext(ctx context.Context, address string) ([]byte, error) {
+	return raknet.Dialer{ErrorLog: r.log.With("net origin", "raknet")}.PingContext(ctx, address)
+}
+
+// Listen ...
+func (r rakNet) Listen(address string) (minecraft.NetworkListener, error) {
+	return raknet.ListenConfig{ErrorLog: r.log.With("net origin", "raknet")}.Listen(address)
+}
@@ -17,6 +17,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/df-mc/dragonfly/server/block/cube"
 	"github.com/df-mc/dragonfly/server/internal/blockinternal"
 	"github.com/df-mc/dragonfly/server/internal/iteminternal"
 	"github.com/df-mc/dragonfly/server/internal/sliceutil"
@@ -47,6 +48,12 @@ type Server struct {
 
 	world, nether, end *world.World
 
+	wmu sync.Mutex
+	// worlds holds the worlds added using AddWorld by their lower case name.
+	worlds map[string]*managedWorld
+	// closing is closed when the Server starts closing.
+	closing chan struct{}
+
 	customBlocks     []protocol.BlockEntry
 	customItems      []protocol.ItemEntry
 	customDimensions []protocol.DimensionDefinition
@@ -112,6 +119,7 @@ func (srv *Server) Listen() {
 	srv.conf.Log.Info("Dragonfly server started.", "mc-version", protocol.CurrentVersion, "go-version", info.GoVersion, "commit", revision)
 	srv.startListening()
 	go srv.wait()
+	go srv.unloadEmptyWorlds()
 }
 
 // Accept accepts incoming players into the server, returning an iterator that
@@ -142,6 +150,7 @@ func (srv *Server) Accept() iter.Seq[*player.Player] {
 			srv.pmu.Unlock()
 
 			ret, err := world.Call(context.Background(), inc.w, func(tx *world.Tx) (bool, error) {
+				tx.SkipRecording()
 				p := tx.AddEntity(inc.p.handle).(*player.Player)
 				inc.s.Spawn(p, tx)
 				return !yield(p), nil
@@ -321,9 +330,11 @@ func (srv *Server) Close() error {
 // close stops the server, storing player and world data to disk.
 func (srv *Server) close() {
 	srv.conf.Log.Info("Server closing...")
+	close(srv.closing)
 
 	srv.conf.Log.Debug("Disconnecting players...")
 	for p := range srv.Players(nil) {
+		p.Tx().SkipRecording()
 		p.Disconnect(chat.MessageServerDisconnect.Resolve(p.Locale()))
 	}
 	srv.pwg.Wait()
@@ -334,6 +345,7 @@ func (srv *Server) close() {
 	}
 
 	srv.conf.Log.Debug("Closing worlds...")
+	srv.closeWorlds()
 	for _, w := range []*world.World{srv.end, srv.nether, srv.world} {
 		if err := w.Close(); err != nil {
 			srv.conf.Log.Error(fmt.Sprintf("Close dimension %v: ", w.Dimension()) + err.Error())
@@ -466,6 +478,14 @@ func (srv *Server) finaliseConn(ctx context.Context, conn session.Conn, l Listen
 	if err != nil {
 		w = srv.world
 		d.Position = w.Spawn().Vec3Centre()
+		// New players are spawned at a random position around the spawn of the
+		// world, as limited by world.GameRuleSpawnRadius.
+		if pos, err := world.Call(ctx, w, func(tx *world.Tx) (cube.Pos, error) {
+			tx.SkipRecording()
+			return tx.RandomSpawn(), nil
+		}); err == nil {
+			d.Position = pos.Vec3Middle()
+		}
 		d.GameMode = w.DefaultGameMode()
 	}
 
@@ -496,6 +516,12 @@ func (srv *Server) finaliseConn(ctx context.Context, conn session.Conn, l Listen
 // server.
 func (srv *Server) defaultGameData() minecraft.GameData {
 	gm, _ := world.GameModeID(srv.world.DefaultGameMode())
+	var rewindHistory int32
+	if srv.conf.ValidateMovement {
+		// Clients only process movement corrections for ticks still in their
+		// rewind history.
+		rewindHistory = 40
+	}
 	return minecraft.GameData{
 		// Entity runtime/unique ID for the player itself is always 1 in df.
 		EntityUniqueID:  1,
@@ -516,10 +542,13 @@ func (srv *Server) defaultGameData() minecraft.GameData {
 		GameRules: []protocol.GameRule{
 			{Name: "naturalregeneration", Value: false},
 			{Name: "locatorBar", Value: false},
+			{Name: "recipesunlock", Value: srv.conf.RecipeUnlocking},
+			{Name: "dolimitedcrafting", Value: srv.conf.RecipeUnlocking},
 		},
 
 		ServerAuthoritativeInventory: true,
 		PlayerMovementSettings: protocol.PlayerMovementSettings{
+			RewindHistorySize:                rewindHistory,
 			ServerAuthoritativeBlockBreaking: true,
 		},
 		Dimensions: srv.customDimensions,
@@ -568,13 +597,19 @@ func (srv *Server) createPlayer(id uuid.UUID, conn session.Conn, conf player.Con
 	srv.pwg.Add(1)
 
 	s := session.Config{
-		Log:            srv.conf.Log,
-		MaxChunkRadius: srv.conf.MaxChunkRadius,
-		EmoteChatMuted: srv.conf.MuteEmoteChat,
-		JoinMessage:    srv.conf.JoinMessage,
-		QuitMessage:    srv.conf.QuitMessage,
-		HandleStop:     srv.handleSessionClose,
-		BlockRegistry:  w.BlockRegistry(),
+		Log:              srv.conf.Log,
+		MaxChunkRadius:   srv.conf.MaxChunkRadius,
+		EmoteChatMuted:   srv.conf.MuteEmoteChat,
+		RecipeUnlocking:  srv.conf.RecipeUnlocking,
+		ValidateMovement: srv.conf.ValidateMovement,
+		ValidateCombat:   srv.conf.ValidateCombat,
+		ValidateBlocks:   srv.conf.ValidateBlocks,
+		Middleware:       srv.conf.PacketMiddleware,
+		RateLimits:       srv.conf.PacketRateLimits,
+		JoinMessage:      srv.conf.JoinMessage,
+		QuitMessage:      srv.conf.QuitMessage,
+		HandleStop:       srv.handleSessionClose,
+		BlockRegistry:    w.BlockRegistry(),
 	}.New(conn)
 
 	conf.Name = conn.IdentityData().DisplayName
@@ -583,6 +618,7 @@ func (srv *Server) createPlayer(id uuid.UUID, conn session.Conn, conf player.Con
 	conf.Locale, _ = language.Parse(strings.Replace(conn.ClientData().LanguageCode, "_", "-", 1))
 	conf.Skin = srv.parseSkin(conn.ClientData())
 	conf.Session = s
+	conf.DeathMessages = srv.conf.DeathMessages
 
 	handle := world.EntitySpawnOpts{Position: conf.Position, ID: id}.New(player.Type, conf)
 	s.SetHandle(handle, conf.Skin)
@@ -608,6 +644,8 @@ func (srv *Server) createWorld(dim world.Dimension, nether, end **world.World) *
 		ChunkLoadWorkers:    srv.conf.ChunkLoadWorkers,
 		Entities:            srv.conf.Entities,
 		Blocks:              srv.conf.Blocks,
+		Observer:            srv.conf.WorldObserver,
+		ActivationRanges:    srv.conf.ActivationRanges,
 		PortalDestination: func(dim world.Dimension) *world.World {
 			switch dim {
 			case world.Nether:
@@ -619,6 +657,9 @@ func (srv *Server) createWorld(dim world.Dimension, nether, end **world.World) *
 			}
 		},
 	}
+	if srv.conf.Recorder != nil {
+		conf.Recorder = srv.conf.Recorder(dim)
+	}
 	w := conf.New()
 	logger.Info("Opened dimension.", "name", w.Name())
 	return w
@@ -3,7 +3,6 @@ package session
 import (
 	"bytes"
 
-	"github.com/cespare/xxhash/v2"
 	"github.com/df-mc/dragonfly/server/block/cube"
 	"github.com/df-mc/dragonfly/server/world"
 	"github.com/df-mc/dragonfly/server/world/chunk"
@@ -61,6 +60,9 @@ func (s *Session) ViewSubChunks(centre world.SubChunkPos, offsets []protocol.Sub
 	})
 }
 
+// subChunkEntry returns the SubChunkEntry of the sub chunk at index ind of the column passed. The network encoding
+// of the sub chunk is cached by the chunk, so that it is shared by all sessions viewing it until a block in it
+// changes. If the client cache is enabled, the sub chunk is sent as a blob by its hash.
 func (s *Session) subChunkEntry(offset protocol.SubChunkOffset, ind int16, col *world.Column, transaction map[uint64]struct{}) protocol.SubChunkEntry {
 	chunkMap := col.HeightMap()
 	subMapType, subMap := byte(protocol.HeightMapDataHasData), make([]int8, 256)
@@ -101,7 +103,7 @@ func (s *Session) subChunkEntry(offset protocol.SubChunkOffset, ind int16, col *
 		}
 	}
 
-	serialisedSubChunk := chunk.EncodeSubChunk(col.Chunk, chunk.NetworkEncoding, int(ind))
+	serialisedSubChunk, hash := chunk.EncodeSubChunkHash(col.Chunk, int(ind))
 
 	blockEntityBuf := bytes.NewBuffer(nil)
 	enc := nbt.NewEncoderWithEncoding(blockEntityBuf, nbt.NetworkLittleEndian)
@@ -123,7 +125,7 @@ func (s *Session) subChunkEntry(offset protocol.SubChunkOffset, ind int16, col *
 		Offset:              offset,
 	}
 	if s.conn.ClientCacheEnabled() {
-		if hash := xxhash.Sum64(serialisedSubChunk); s.trackBlob(hash, serialisedSubChunk) {
+		if s.trackBlob(hash, serialisedSubChunk) {
 			transaction[hash] = struct{}{}
 
 			entry.BlobHash = protocol.Option(hash)
@@ -143,8 +145,7 @@ func (s *Session) dimensionID(dim world.Dimension) int32 {
 // data that the client doesn't yet have will be sent over the network.
 func (s *Session) sendBlobHashes(pos world.ChunkPos, dim world.Dimension, c *chunk.Chunk, blockEntities map[cube.Pos]world.Block) {
 	if subChunkRequests {
-		biomes := chunk.EncodeBiomes(c, chunk.NetworkEncoding)
-		if hash := xxhash.Sum64(biomes); s.trackBlob(hash, biomes) {
+		if biomes, hash := chunk.EncodeBiomesHash(c); s.trackBlob(hash, biomes) {
 			s.writePacket(&packet.LevelChunk{
 				Dimension:     s.dimensionID(dim),
 				SubChunkCount: 0,
@@ -159,15 +160,12 @@ func (s *Session) sendBlobHashes(pos world.ChunkPos, dim world.Dimension, c *chu
 	}
 
 	var (
-		data   = chunk.Encode(c, chunk.NetworkEncoding)
-		count  = uint32(len(data.SubChunks))
-		blobs  = append(data.SubChunks, data.Biomes)
-		hashes = make([]uint64, len(blobs))
-		m      = make(map[uint64]struct{}, len(blobs))
+		data  = chunk.EncodeNetwork(c)
+		count = uint32(len(data.SubChunks))
+		m     = make(map[uint64]struct{}, len(data.Hashes))
 	)
-	for i, blob := range blobs {
-		h := xxhash.Sum64(blob)
-		hashes[i], m[h] = h, struct{}{}
+	for _, h := range data.Hashes {
+		m[h] = struct{}{}
 	}
 
 	s.blobMu.Lock()
@@ -177,9 +175,10 @@ func (s *Session) sendBlobHashes(pos world.ChunkPos, dim world.Dimension, c *chu
 		s.conf.Log.Error("too many blobs pending", "n", l)
 		return
 	}
-	for i := range hashes {
-		s.blobs[hashes[i]] = blobs[i]
+	for i, sub := range data.SubChunks {
+		s.blobs[data.Hashes[i]] = sub
 	}
+	s.blobs[data.Hashes[count]] = data.Biomes
 	s.blobMu.Unlock()
 
 	// Length of 1 byte for the border block count.
@@ -198,7 +197,7 @@ func (s *Session) sendBlobHashes(pos world.ChunkPos, dim world.Dimension, c *chu
 		Position:      protocol.ChunkPos{pos.X(), pos.Z()},
 		SubChunkCount: count,
 		CacheEnabled:  true,
-		BlobHashes:    hashes,
+		BlobHashes:    data.Hashes,
 		RawPayload:    raw.Bytes(),
 	})
 }
@@ -216,12 +215,9 @@ func (s *Session) sendNetworkChunk(pos world.ChunkPos, dim world.Dimension, c *c
 		return
 	}
 
-	data := chunk.Encode(c, chunk.NetworkEncoding)
-	chunkBuf := bytes.NewBuffer(nil)
-	for _, s := range data.SubChunks {
-		_, _ = chunkBuf.Write(s)
-	}
-	_, _ = chunkBuf.Write(data.Biomes)
+	data := chunk.EncodeNetwork(c)
+	chunkBuf := bytes.NewBuffer(make([]byte, 0, len(data.Payload)+1))
+	_, _ = chunkBuf.Write(data.Payload)
 
 	// Length of 1 byte for the border block count.
 	chunkBuf.WriteByte(0)
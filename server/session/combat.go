@@ -0,0 +1,217 @@
+package session
+
+import (
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/block/cube/trace"
+	"github.com/df-mc/dragonfly/server/entity"
+	"github.com/df-mc/dragonfly/server/world"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+const (
+	// attackReach is the maximum distance in blocks from the eyes of a
+	// Controllable to the bounding box of an entity that it may attack.
+	attackReach = 3.0
+	// creativeAttackReach is the attackReach of Controllables in a game mode
+	// with a creative inventory.
+	creativeAttackReach = 6.0
+	// reachTolerance is the distance in blocks by which the bounding box of an
+	// entity is grown when checking reach to account for imprecision.
+	reachTolerance = 0.1
+	// maxClicksPerSecond is the maximum number of attacks and missed swings a
+	// client may send per second.
+	maxClicksPerSecond = 20
+	// positionHistoryDuration is the duration for which the positions of
+	// entities sent to the client are kept.
+	positionHistoryDuration = time.Second
+	// interpolationDelay is the additional duration that entities are rewound
+	// to account for the interpolation of entity movement by the client.
+	interpolationDelay = time.Millisecond * 150
+)
+
+// positionRecord is a position of an entity sent to the client at a specific
+// time.
+type positionRecord struct {
+	at  time.Time
+	pos mgl64.Vec3
+}
+
+// combatValidator validates the attacks of a Controllable. It keeps a history
+// of the positions of the entities sent to the client, so that the reach of an
+// attack may be checked against the position of the target at the time the
+// client saw it.
+type combatValidator struct {
+	mu      sync.Mutex
+	history map[*world.EntityHandle][]positionRecord
+	clicks  []time.Time
+}
+
+// newCombatValidator returns a new combatValidator.
+func newCombatValidator() *combatValidator {
+	return &combatValidator{history: make(map[*world.EntityHandle][]positionRecord)}
+}
+
+// record records the position of the entity passed as sent to the client.
+// record is a no-op if v is nil.
+func (v *combatValidator) record(handle *world.EntityHandle, pos mgl64.Vec3) {
+	if v == nil {
+		return
+	}
+	now := time.Now()
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	records := v.history[handle]
+	// Keep at least the latest record older than the history duration, as the
+	// entity was still at that position when the history starts.
+	i := 0
+	for i+1 < len(records) && now.Sub(records[i+1].at) > positionHistoryDuration {
+		i++
+	}
+	v.history[handle] = append(records[i:], positionRecord{at: now, pos: pos})
+}
+
+// forget removes the position history of the entity passed. forget is a no-op
+// if v is nil.
+func (v *combatValidator) forget(handle *world.EntityHandle) {
+	if v == nil {
+		return
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	delete(v.history, handle)
+}
+
+// positions returns the positions that the entity passed may have had
+// client-side at the moment the client attacked it, rewound by the latency
+// passed.
+func (v *combatValidator) positions(e world.Entity, latency time.Duration) []mgl64.Vec3 {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	records := v.history[e.H()]
+	if len(records) == 0 {
+		return []mgl64.Vec3{e.Position()}
+	}
+	since := time.Now().Add(-latency*2 - interpolationDelay)
+	positions := make([]mgl64.Vec3, 0, len(records))
+	for i, r := range records {
+		if r.at.Before(since) && i+1 < len(records) && !records[i+1].at.After(since) {
+			// The entity moved away from this position before the client
+			// could have seen it.
+			continue
+		}
+		positions = append(positions, r.pos)
+	}
+	return positions
+}
+
+// click registers a click of the client and checks if the client exceeded
+// the maximum clicks per second. The number of clicks in the last second is
+// returned.
+func (v *combatValidator) click() (clicks int, ok bool) {
+	now := time.Now()
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.clicks = slices.DeleteFunc(append(v.clicks, now), func(t time.Time) bool {
+		return now.Sub(t) >= time.Second
+	})
+	return len(v.clicks), len(v.clicks) <= maxClicksPerSecond
+}
+
+// validateClick registers a click of the client without a target, such as a
+// missed swing, and checks if the client did not exceed the maximum clicks
+// per second. validateClick returns true if v is nil.
+func (v *combatValidator) validateClick(c Controllable) bool {
+	if v == nil {
+		return true
+	}
+	if clicks, ok := v.click(); !ok {
+		return !reportViolation(c, Violation{Type: ViolationClickRate, Value: float64(clicks), Limit: maxClicksPerSecond})
+	}
+	return true
+}
+
+// validateAttack checks if the Controllable passed could have attacked the
+// entity passed, checking the click rate of the client and the reach and line
+// of sight to the positions of the entity rewound by the latency of the
+// Session. validateAttack returns true if v is nil.
+func (v *combatValidator) validateAttack(s *Session, tx *world.Tx, c Controllable, e world.Entity) bool {
+	if v == nil {
+		return true
+	}
+	if !v.validateClick(c) {
+		return false
+	}
+	reach := attackReach
+	if c.GameMode().CreativeInventory() {
+		reach = creativeAttackReach
+	}
+	var (
+		eyes    = entity.EyePosition(c)
+		box     = e.H().Type().BBox(e)
+		nearest = math.MaxFloat64
+	)
+	for _, pos := range v.positions(e, s.conn.Latency()) {
+		target := box.Translate(pos).Grow(reachTolerance)
+		dist := closestPoint(target, eyes).Sub(eyes).Len()
+		nearest = min(nearest, dist)
+		if dist <= reach && visible(tx, eyes, target) {
+			return true
+		}
+	}
+	if nearest > reach {
+		return !reportViolation(c, Violation{Type: ViolationReach, Value: nearest, Limit: reach, Entity: e.H()})
+	}
+	return !reportViolation(c, Violation{Type: ViolationLineOfSight, Value: nearest, Limit: reach, Entity: e.H()})
+}
+
+// closestPoint returns the point within the bounding box passed closest to
+// the position passed.
+func closestPoint(box cube.BBox, pos mgl64.Vec3) mgl64.Vec3 {
+	minPos, maxPos := box.Min(), box.Max()
+	return mgl64.Vec3{
+		mgl64.Clamp(pos[0], minPos[0], maxPos[0]),
+		mgl64.Clamp(pos[1], minPos[1], maxPos[1]),
+		mgl64.Clamp(pos[2], minPos[2], maxPos[2]),
+	}
+}
+
+// visible checks if any of the closest point, the centre or the top of the
+// bounding box passed is visible from the eye position passed without blocks
+// obstructing the view.
+func visible(tx *world.Tx, eyes mgl64.Vec3, box cube.BBox) bool {
+	minPos, maxPos := box.Min(), box.Max()
+	centre := minPos.Add(maxPos).Mul(0.5)
+	points := [...]mgl64.Vec3{
+		closestPoint(box.Grow(-reachTolerance), eyes),
+		centre,
+		{centre[0], maxPos[1] - reachTolerance, centre[2]},
+	}
+	for _, point := range points {
+		if unobstructed(tx, eyes, point) {
+			return true
+		}
+	}
+	return false
+}
+
+// unobstructed checks if no block intersects with the line from start to end,
+// ignoring the blocks at the positions passed.
+func unobstructed(tx *world.Tx, start, end mgl64.Vec3, ignore ...cube.Pos) bool {
+	if start.ApproxEqual(end) {
+		return true
+	}
+	ok := true
+	trace.TraverseBlocks(start, end, func(pos cube.Pos) bool {
+		ok = slices.Contains(ignore, pos) || !trace.BlockIntersects(pos, tx, tx.Block(pos), start, end)
+		return ok
+	})
+	return ok
+}
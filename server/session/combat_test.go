@@ -0,0 +1,88 @@
+package session
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/df-mc/dragonfly/server/world"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestCombatValidatorPositions(t *testing.T) {
+	now := time.Now()
+	a, b, c, current := mgl64.Vec3{1}, mgl64.Vec3{2}, mgl64.Vec3{3}, mgl64.Vec3{4}
+	e := combatTestEntity{h: &world.EntityHandle{}, pos: current}
+	newValidator := func() *combatValidator {
+		v := newCombatValidator()
+		v.history[e.h] = []positionRecord{
+			{at: now.Add(-time.Second * 2), pos: a},
+			{at: now.Add(-time.Second), pos: b},
+			{at: now.Add(-time.Millisecond * 100), pos: c},
+		}
+		return v
+	}
+
+	t.Run("no history", func(t *testing.T) {
+		if positions := newCombatValidator().positions(e, 0); !slices.Equal(positions, []mgl64.Vec3{current}) {
+			t.Fatalf("positions() without history = %v, want current position", positions)
+		}
+	})
+	t.Run("low latency", func(t *testing.T) {
+		// The entity is rewound by 2*50ms+interpolationDelay = 250ms: It was
+		// at b at that moment and moved to c afterward.
+		if positions := newValidator().positions(e, time.Millisecond*50); !slices.Equal(positions, []mgl64.Vec3{b, c}) {
+			t.Fatalf("positions() with low latency = %v, want %v", positions, []mgl64.Vec3{b, c})
+		}
+	})
+	t.Run("high latency", func(t *testing.T) {
+		if positions := newValidator().positions(e, time.Second); !slices.Equal(positions, []mgl64.Vec3{a, b, c}) {
+			t.Fatalf("positions() with high latency = %v, want %v", positions, []mgl64.Vec3{a, b, c})
+		}
+	})
+	t.Run("record", func(t *testing.T) {
+		v := newValidator()
+		v.record(e.h, current)
+		// The record at a is discarded, but b is kept as the entity was still
+		// there when the history starts.
+		records := v.history[e.h]
+		if len(records) != 3 || records[0].pos != b || records[2].pos != current {
+			t.Fatalf("history after record() = %v, want records at b, c and current position", records)
+		}
+		v.forget(e.h)
+		if _, ok := v.history[e.h]; ok {
+			t.Fatalf("history after forget() was not removed")
+		}
+	})
+	t.Run("nil", func(t *testing.T) {
+		var v *combatValidator
+		v.record(e.h, current)
+		v.forget(e.h)
+	})
+}
+
+func TestCombatValidatorClick(t *testing.T) {
+	v := newCombatValidator()
+	for i := range maxClicksPerSecond {
+		if clicks, ok := v.click(); !ok || clicks != i+1 {
+			t.Fatalf("click() %v = %v, %v, want %v, true", i+1, clicks, ok, i+1)
+		}
+	}
+	if _, ok := v.click(); ok {
+		t.Fatalf("click() beyond maximum = true, want false")
+	}
+	v.clicks = []time.Time{time.Now().Add(-time.Second * 2)}
+	if clicks, ok := v.click(); !ok || clicks != 1 {
+		t.Fatalf("click() after a second = %v, %v, want 1, true", clicks, ok)
+	}
+}
+
+// combatTestEntity is a world.Entity with a handle and position.
+type combatTestEntity struct {
+	world.Entity
+	h   *world.EntityHandle
+	pos mgl64.Vec3
+}
+
+func (e combatTestEntity) H() *world.EntityHandle { return e.h }
+func (e combatTestEntity) Position() mgl64.Vec3   { return e.pos }
@@ -1,6 +1,8 @@
 package session
 
 import (
+	"time"
+
 	"github.com/df-mc/dragonfly/server/block/cube"
 	"github.com/df-mc/dragonfly/server/cmd"
 	"github.com/df-mc/dragonfly/server/entity/effect"
@@ -127,3 +129,62 @@ type Controllable interface {
 
 	UpdateDiagnostics(Diagnostics)
 }
+
+// ValidatedControllable is a Controllable that takes part in the validation
+// of the input of its client. A Controllable that does not implement it is
+// validated using defaults: Violations and exceeded RateLimits are always
+// acted upon, inventory transactions are always allowed and block breaking is
+// not validated.
+type ValidatedControllable interface {
+	Controllable
+	// OnGround checks if the controllable is currently on the ground.
+	OnGround() bool
+	// BreakDuration returns the time needed by the controllable to break the
+	// block at the position passed.
+	BreakDuration(pos cube.Pos) time.Duration
+	// AllowInventoryTransaction returns false if the inventory.Transaction
+	// passed should be cancelled.
+	AllowInventoryTransaction(t inventory.Transaction) bool
+	// ReportViolation reports a Violation detected while validating the input
+	// of the client. False is returned if the Violation should be ignored, in
+	// which case the input of the client is accepted as is.
+	ReportViolation(v Violation) bool
+	// ReportRateLimit reports that the client exceeded the RateLimit of a
+	// PacketCategory and returns the RateLimitAction to take, which may differ
+	// from the one passed. False is returned if the packet exceeding the
+	// RateLimit should be handled as usual. ReportRateLimit is only called for
+	// the first packet dropped in a row and when the client is warned or
+	// disconnected: Other packets exceeding the RateLimit are dropped without
+	// being reported.
+	ReportRateLimit(category PacketCategory, action RateLimitAction) (RateLimitAction, bool)
+}
+
+// reportViolation reports the Violation passed to the Controllable if it
+// implements ValidatedControllable. True is returned if the Violation should
+// be acted upon.
+func reportViolation(c Controllable, v Violation) bool {
+	if vc, ok := c.(ValidatedControllable); ok {
+		return vc.ReportViolation(v)
+	}
+	return true
+}
+
+// reportRateLimit reports an exceeded RateLimit to the Controllable if it
+// implements ValidatedControllable. The RateLimitAction to take is returned,
+// along with false if the RateLimit should not be acted upon.
+func reportRateLimit(c Controllable, category PacketCategory, action RateLimitAction) (RateLimitAction, bool) {
+	if vc, ok := c.(ValidatedControllable); ok {
+		return vc.ReportRateLimit(category, action)
+	}
+	return action, true
+}
+
+// allowInventoryTransaction checks if the Controllable passed allows the
+// inventory.Transaction passed. Transactions are allowed if the Controllable
+// does not implement ValidatedControllable.
+func allowInventoryTransaction(c Controllable, t inventory.Transaction) bool {
+	if vc, ok := c.(ValidatedControllable); ok {
+		return vc.AllowInventoryTransaction(t)
+	}
+	return true
+}
@@ -0,0 +1,33 @@
+package session
+
+import (
+	"fmt"
+	"github.com/df-mc/dragonfly/server/item"
+	"github.com/df-mc/dragonfly/server/item/recipe"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+)
+
+// verifyBrewingPlacement checks if the item stack passed may be placed in the brewing stand slot passed. Only
+// brewing reagents registered through recipe.Potion may be placed in the ingredient slot, blaze powder in the fuel
+// slot and items that are the input of a brewing recipe, or glass bottles, in the three bottle slots. Slots of other
+// containers are always valid.
+func verifyBrewingPlacement(slot protocol.StackRequestSlotInfo, it item.Stack) error {
+	if it.Empty() {
+		return nil
+	}
+	switch slot.Container.ContainerID {
+	case protocol.ContainerBrewingStandInput:
+		if !recipe.ValidBrewingReagent(it.Item()) {
+			return fmt.Errorf("%v is not a brewing reagent", it)
+		}
+	case protocol.ContainerBrewingStandFuel:
+		if _, ok := it.Item().(item.BlazePowder); !ok {
+			return fmt.Errorf("%v is not brewing stand fuel", it)
+		}
+	case protocol.ContainerBrewingStandResult:
+		if _, ok := it.Item().(item.GlassBottle); !ok && !recipe.ValidBrewingInput(it.Item()) {
+			return fmt.Errorf("%v cannot be brewed", it)
+		}
+	}
+	return nil
+}
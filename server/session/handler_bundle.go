@@ -0,0 +1,136 @@
+package session
+
+import (
+	"fmt"
+
+	"github.com/df-mc/dragonfly/server/item"
+	"github.com/df-mc/dragonfly/server/item/inventory"
+	"github.com/df-mc/dragonfly/server/world"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+const (
+	// windowIDDynamic is the window ID used for dynamic containers, such as the contents of a bundle.
+	windowIDDynamic = 125
+	// bundleSlots is the number of slots of the dynamic container holding the contents of a bundle.
+	bundleSlots = item.BundleCapacity
+)
+
+// containerInv looks up the inventory of the container passed. Unlike Session.invByID, it also resolves dynamic
+// containers, which are used for the contents of bundles held by the player.
+func (h *ItemStackRequestHandler) containerInv(c protocol.FullContainerName, s *Session, tx *world.Tx) (*inventory.Inventory, bool) {
+	if c.ContainerID != protocol.ContainerDynamic {
+		return s.invByID(int32(c.ContainerID), tx)
+	}
+	id, _ := c.DynamicContainerID.Value()
+	if inv, ok := h.bundles[id]; ok {
+		return inv, true
+	}
+	_, it, ok := s.findBundle(id)
+	if !ok {
+		return nil, false
+	}
+	inv := inventory.New(bundleSlots, nil)
+	for slot, content := range it.Item().(item.Bundle).Contents() {
+		if slot < bundleSlots {
+			_ = inv.SetItem(slot, content)
+		}
+	}
+	h.bundles[id] = inv
+	return inv, true
+}
+
+// verifyBundlePlacement checks if the item.Stack passed may be placed in the slot passed if that slot is in the
+// contents of a bundle. An error is returned if the total weight of the bundle would exceed item.BundleCapacity.
+func (h *ItemStackRequestHandler) verifyBundlePlacement(slot protocol.StackRequestSlotInfo, it item.Stack, s *Session, tx *world.Tx) error {
+	if slot.Container.ContainerID != protocol.ContainerDynamic || it.Empty() {
+		return nil
+	}
+	inv, ok := h.containerInv(slot.Container, s, tx)
+	if !ok {
+		return fmt.Errorf("unable to find bundle with ID %v", slot.Container.DynamicContainerID)
+	}
+	if b, ok := it.Item().(item.Bundle); ok && b.ID() != 0 {
+		if id, _ := slot.Container.DynamicContainerID.Value(); b.ID() == id {
+			return fmt.Errorf("cannot place bundle inside itself")
+		}
+	}
+	weight := item.BundleWeight(it)
+	for i, other := range inv.Slots() {
+		if i != int(slot.Slot) {
+			weight += item.BundleWeight(other)
+		}
+	}
+	if weight > item.BundleCapacity {
+		return fmt.Errorf("bundle weight %v exceeds capacity %v", weight, item.BundleCapacity)
+	}
+	return nil
+}
+
+// writeBundle writes the contents of the dynamic container of the bundle with the ID passed back to the bundle item
+// held by the player.
+func (h *ItemStackRequestHandler) writeBundle(id uint32, contents *inventory.Inventory, s *Session, tx *world.Tx) {
+	slot, it, ok := s.findBundle(id)
+	if !ok {
+		return
+	}
+	b := it.Item().(item.Bundle).WithContents(contents.Slots()...)
+	h.setItemInSlot(slot, it.WithItem(b), s, tx)
+}
+
+// findBundle looks for the item.Bundle with the ID passed in the inventory, offhand inventory and cursor of the
+// player. The slot of the bundle is returned along with the item.Stack holding it.
+func (s *Session) findBundle(id uint32) (protocol.StackRequestSlotInfo, item.Stack, bool) {
+	matches := func(it item.Stack) bool {
+		b, ok := it.Item().(item.Bundle)
+		return ok && b.ID() == id
+	}
+	if slot, ok := s.inv.FirstFunc(matches); ok {
+		it, _ := s.inv.Item(slot)
+		return protocol.StackRequestSlotInfo{Container: protocol.FullContainerName{ContainerID: protocol.ContainerInventory}, Slot: byte(slot)}, it, true
+	}
+	if it, _ := s.offHand.Item(0); matches(it) {
+		return protocol.StackRequestSlotInfo{Container: protocol.FullContainerName{ContainerID: protocol.ContainerOffhand}, Slot: 1}, it, true
+	}
+	if it, _ := s.ui.Item(0); matches(it) {
+		return protocol.StackRequestSlotInfo{Container: protocol.FullContainerName{ContainerID: protocol.ContainerCursor}}, it, true
+	}
+	return protocol.StackRequestSlotInfo{}, item.Stack{}, false
+}
+
+// withBundleID assigns a unique ID to the item.Bundle in the item.Stack passed if it does not yet have one, so that
+// the client is able to view its contents.
+func withBundleID(it item.Stack) item.Stack {
+	if b, ok := it.Item().(item.Bundle); ok && b.ID() == 0 {
+		return it.WithItem(item.NewBundle(b.Colour).WithContents(b.Contents()...))
+	}
+	return it
+}
+
+// sendBundleContents sends the contents of the item.Bundle in the item.Stack passed to the client, if the item.Stack
+// holds a bundle.
+func (s *Session) sendBundleContents(it item.Stack) {
+	b, ok := it.Item().(item.Bundle)
+	if !ok || b.ID() == 0 {
+		return
+	}
+	contents := b.Contents()
+	pk := &packet.InventoryContent{
+		WindowID: windowIDDynamic,
+		Content:  make([]protocol.ItemInstance, 0, bundleSlots),
+		Container: protocol.FullContainerName{
+			ContainerID:        protocol.ContainerDynamic,
+			DynamicContainerID: protocol.Option(b.ID()),
+		},
+		StorageItem: instanceFromItem(s.br, it),
+	}
+	for slot := range bundleSlots {
+		var content item.Stack
+		if slot < len(contents) {
+			content = contents[slot]
+		}
+		pk.Content = append(pk.Content, instanceFromItem(s.br, content))
+	}
+	s.writePacket(pk)
+}
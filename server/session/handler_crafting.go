@@ -19,6 +19,9 @@ func (h *ItemStackRequestHandler) handleCraft(a *protocol.CraftRecipeStackReques
 		// Try dynamic recipes if no static recipe matches
 		return h.tryDynamicCraft(s, tx, int(a.NumberOfCrafts))
 	}
+	if s.recipeLocked(craft) {
+		return fmt.Errorf("recipe with network id %v is not unlocked", a.RecipeNetworkID)
+	}
 	_, shaped := craft.(recipe.Shaped)
 	_, shapeless := craft.(recipe.Shapeless)
 	if !shaped && !shapeless {
@@ -74,6 +77,9 @@ func (h *ItemStackRequestHandler) handleAutoCraft(a *protocol.AutoCraftRecipeSta
 		// Try dynamic recipes if no static recipe matches
 		return h.tryDynamicCraft(s, tx, int(a.NumberOfCrafts))
 	}
+	if s.recipeLocked(craft) {
+		return fmt.Errorf("recipe with network id %v is not unlocked", a.RecipeNetworkID)
+	}
 	_, shaped := craft.(recipe.Shaped)
 	_, shapeless := craft.(recipe.Shapeless)
 	if !shaped && !shapeless {
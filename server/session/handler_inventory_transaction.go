@@ -65,7 +65,7 @@ func (h *InventoryTransactionHandler) Handle(p packet.Packet, s *Session, tx *wo
 		if err = s.VerifyAndSetHeldSlot(int(data.HotBarSlot), stackToItem(s.br, data.HeldItem.Stack), c); err != nil {
 			return
 		}
-		return h.handleUseItemTransaction(data, s, c)
+		return h.handleUseItemTransaction(data, s, tx, c)
 	case *protocol.ReleaseItemTransactionData:
 		if err = s.VerifyAndSetHeldSlot(int(data.HotBarSlot), stackToItem(s.br, data.HeldItem.Stack), c); err != nil {
 			return
@@ -162,7 +162,7 @@ func (h *InventoryTransactionHandler) handleUseItemOnEntityTransaction(data *pro
 	case protocol.UseItemOnEntityActionInteract:
 		valid = c.UseItemOnEntity(e)
 	case protocol.UseItemOnEntityActionAttack:
-		valid = c.AttackEntity(e)
+		valid = s.combat.validateAttack(s, tx, c, e) && c.AttackEntity(e)
 	default:
 		return fmt.Errorf("unhandled UseItemOnEntity ActionType %v", data.ActionType)
 	}
@@ -175,7 +175,7 @@ func (h *InventoryTransactionHandler) handleUseItemOnEntityTransaction(data *pro
 }
 
 // handleUseItemTransaction ...
-func (h *InventoryTransactionHandler) handleUseItemTransaction(data *protocol.UseItemTransactionData, s *Session, c Controllable) error {
+func (h *InventoryTransactionHandler) handleUseItemTransaction(data *protocol.UseItemTransactionData, s *Session, tx *world.Tx, c Controllable) error {
 	pos := cube.Pos{int(data.BlockPosition[0]), int(data.BlockPosition[1]), int(data.BlockPosition[2])}
 	if data.ClientPrediction == protocol.ClientPredictionSuccess || data.ActionType == protocol.UseItemActionBreakBlock {
 		// Suppress echoing the swing animation only when the client has already predicted it locally.
@@ -189,11 +189,15 @@ func (h *InventoryTransactionHandler) handleUseItemTransaction(data *protocol.Us
 	// it's much easier to just resend the inventory.
 	h.resendInventories(s)
 
-	switch data.ActionType {
+	switch face := cube.Face(data.BlockFace); data.ActionType {
 	case protocol.UseItemActionBreakBlock:
-		c.BreakBlock(pos)
+		if s.blocks.validateInteraction(s, tx, c, pos, face) && s.blocks.validateBreak(s, tx, c, pos) {
+			c.BreakBlock(pos)
+		}
 	case protocol.UseItemActionClickBlock:
-		c.UseItemOnBlock(pos, cube.Face(data.BlockFace), vec32To64(data.ClickedPosition))
+		if s.blocks.validateInteraction(s, tx, c, pos, face) {
+			c.UseItemOnBlock(pos, face, vec32To64(data.ClickedPosition))
+		}
 	case protocol.UseItemActionClickAir:
 		c.UseItem()
 	default:
@@ -6,6 +6,7 @@ import (
 	"github.com/df-mc/dragonfly/server/entity"
 	"github.com/df-mc/dragonfly/server/event"
 	"github.com/df-mc/dragonfly/server/item"
+	"github.com/df-mc/dragonfly/server/item/creative"
 	"github.com/df-mc/dragonfly/server/item/inventory"
 	"github.com/df-mc/dragonfly/server/world"
 	"github.com/sandertv/gophertunnel/minecraft/protocol"
@@ -19,8 +20,10 @@ import (
 type ItemStackRequestHandler struct {
 	currentRequest int32
 
-	changes         map[byte]map[byte]changeInfo
+	changes         map[protocol.FullContainerName]map[byte]changeInfo
 	responseChanges map[int32]map[*inventory.Inventory]map[byte]responseChange
+	// bundles holds the inventories of the bundles whose contents were changed in the current packet.
+	bundles map[uint32]*inventory.Inventory
 
 	pendingResults []item.Stack
 
@@ -46,6 +49,7 @@ type changeInfo struct {
 func (h *ItemStackRequestHandler) Handle(p packet.Packet, s *Session, tx *world.Tx, c Controllable) error {
 	pk := p.(*packet.ItemStackRequest)
 	h.current = time.Now()
+	h.bundles = map[uint32]*inventory.Inventory{}
 
 	s.inTransaction.Store(true)
 	defer s.inTransaction.Store(false)
@@ -57,6 +61,7 @@ func (h *ItemStackRequestHandler) Handle(p packet.Packet, s *Session, tx *world.
 			s.conf.Log.Debug("process packet: ItemStackRequest: resolve item stack request: " + err.Error())
 		}
 	}
+	s.resendMenu()
 	return nil
 }
 
@@ -72,6 +77,9 @@ func (h *ItemStackRequestHandler) handleRequest(req protocol.ItemStackRequest, s
 		h.ignoreDestroy = false
 	}()
 
+	if err = h.handleTransaction(req, s, tx, c); err != nil {
+		return
+	}
 	for _, action := range req.Actions {
 		switch a := action.(type) {
 		case *protocol.TakeStackRequestAction:
@@ -130,6 +138,116 @@ func (h *ItemStackRequestHandler) handleRequest(req protocol.ItemStackRequest, s
 	return
 }
 
+// handleTransaction converts the actions of the item stack request passed to an inventory.Transaction and calls the
+// handlers of the Controllable and of all inventories involved with it. An error is returned if any of them cancelled
+// the transaction. The items of every action are those present after all actions before it in the request were
+// performed.
+func (h *ItemStackRequestHandler) handleTransaction(req protocol.ItemStackRequest, s *Session, tx *world.Tx, c Controllable) error {
+	t := inventory.Transaction{Actions: make([]inventory.Action, 0, len(req.Actions))}
+	location := func(slot protocol.StackRequestSlotInfo) inventory.Location {
+		inv, _ := h.containerInv(slot.Container, s, tx)
+		if inv == s.offHand {
+			return inventory.Location{Inventory: inv}
+		}
+		return inventory.Location{Inventory: inv, Slot: int(slot.Slot)}
+	}
+	// simulated holds the items in the slots changed by the actions converted so far, as the actions are only
+	// performed after the transaction is handled.
+	simulated := map[inventory.Location]item.Stack{}
+	itemAt := func(l inventory.Location) item.Stack {
+		if i, ok := simulated[l]; ok {
+			return i
+		}
+		if l.Inventory == nil {
+			return item.Stack{}
+		}
+		i, _ := l.Inventory.Item(l.Slot)
+		return i
+	}
+	transfer := func(typ inventory.ActionType, src, dst inventory.Location, count int) inventory.Action {
+		i := itemAt(src)
+		a := inventory.Action{Type: typ, Source: src, Destination: dst, Count: count}
+		if !i.Empty() {
+			a.Item = i.Grow(count - i.Count())
+		}
+		if src.Inventory != nil {
+			simulated[src] = i.Grow(-count)
+		}
+		if dst.Inventory != nil {
+			if d := itemAt(dst); !d.Empty() {
+				simulated[dst] = d.Grow(count)
+			} else {
+				simulated[dst] = a.Item
+			}
+		}
+		return a
+	}
+	created := inventory.Location{Inventory: s.ui, Slot: craftingResult}
+
+	for _, action := range req.Actions {
+		var a inventory.Action
+		switch act := action.(type) {
+		case *protocol.TakeStackRequestAction:
+			a = transfer(inventory.ActionTransfer, location(act.Source), location(act.Destination), int(act.Count))
+		case *protocol.PlaceStackRequestAction:
+			a = transfer(inventory.ActionTransfer, location(act.Source), location(act.Destination), int(act.Count))
+		case *protocol.SwapStackRequestAction:
+			src, dst := location(act.Source), location(act.Destination)
+			i, d := itemAt(src), itemAt(dst)
+			a = inventory.Action{Type: inventory.ActionSwap, Source: src, Destination: dst, Count: i.Count(), Item: i}
+			simulated[src], simulated[dst] = d, i
+		case *protocol.DropStackRequestAction:
+			a = transfer(inventory.ActionDrop, location(act.Source), inventory.Location{}, int(act.Count))
+		case *protocol.DestroyStackRequestAction:
+			a = transfer(inventory.ActionDestroy, location(act.Source), inventory.Location{}, int(act.Count))
+		case *protocol.CraftRecipeStackRequestAction:
+			a = inventory.Action{Type: inventory.ActionCraft, Destination: created, Count: int(act.NumberOfCrafts)}
+		case *protocol.AutoCraftRecipeStackRequestAction:
+			a = inventory.Action{Type: inventory.ActionCraft, Destination: created, Count: int(act.NumberOfCrafts)}
+		case *protocol.CraftRecipeOptionalStackRequestAction, *protocol.CraftGrindstoneRecipeStackRequestAction:
+			a = inventory.Action{Type: inventory.ActionCraft, Destination: created, Count: 1}
+		case *protocol.CraftLoomRecipeStackRequestAction:
+			a = inventory.Action{Type: inventory.ActionCraft, Destination: created, Count: int(act.TimesCrafted)}
+		case *protocol.CraftCreativeStackRequestAction:
+			a = inventory.Action{Type: inventory.ActionCreative, Destination: created, Count: int(act.NumberOfCrafts)}
+			if index := int(act.CreativeItemNetworkID) - 1; index >= 0 && index < len(creative.Items()) {
+				// The creative inventory always creates a full stack of the item.
+				it := creative.Items()[index].Stack
+				a.Item = it.Grow(a.Count - it.Count())
+				simulated[created] = it.Grow(it.MaxCount() - it.Count())
+			}
+		default:
+			// Other actions, such as consuming items or creating results, are part of the actions above and
+			// are not included in the transaction separately.
+			continue
+		}
+		if a.Type == inventory.ActionCraft {
+			// The result of crafting is not known until the recipe is resolved, so items taken from the created
+			// output slot afterward are unknown too.
+			simulated[created] = item.Stack{}
+		}
+		if a.Source.Inventory == nil && a.Destination.Inventory == nil {
+			// The action refers to a container that is not open: The action itself will fail later.
+			continue
+		}
+		t.Actions = append(t.Actions, a)
+	}
+	if len(t.Actions) == 0 {
+		return nil
+	}
+
+	ctx := event.C(inventory.Holder(c))
+	for _, inv := range t.Inventories() {
+		if inv.Handler().HandleTransaction(ctx, t); ctx.Cancelled() {
+			return fmt.Errorf("transaction was cancelled")
+		}
+	}
+	if !allowInventoryTransaction(c, t) {
+		return fmt.Errorf("transaction was cancelled")
+	}
+	return nil
+}
+
 // handleTake handles a Take stack request action.
 func (h *ItemStackRequestHandler) handleTake(a *protocol.TakeStackRequestAction, s *Session, tx *world.Tx, c Controllable) error {
 	return h.handleTransfer(a.Source, a.Destination, a.Count, s, tx, c)
@@ -159,9 +277,15 @@ func (h *ItemStackRequestHandler) handleTransfer(from, to protocol.StackRequestS
 	if dest.Empty() {
 		dest = i.Grow(-math.MaxInt32)
 	}
+	if err := verifyBrewingPlacement(to, i); err != nil {
+		return err
+	}
+	if err := h.verifyBundlePlacement(to, dest.Grow(int(count)), s, tx); err != nil {
+		return err
+	}
 
-	invA, _ := s.invByID(int32(from.Container.ContainerID), tx)
-	invB, _ := s.invByID(int32(to.Container.ContainerID), tx)
+	invA, _ := h.containerInv(from.Container, s, tx)
+	invB, _ := h.containerInv(to.Container, s, tx)
 
 	ctx := event.C(inventory.Holder(c))
 	_ = call(ctx, int(from.Slot), i.Grow(int(count)-i.Count()), invA.Handler().HandleTake)
@@ -183,9 +307,21 @@ func (h *ItemStackRequestHandler) handleSwap(a *protocol.SwapStackRequestAction,
 	}
 	i, _ := h.itemInSlot(a.Source, s, tx)
 	dest, _ := h.itemInSlot(a.Destination, s, tx)
+	if err := verifyBrewingPlacement(a.Destination, i); err != nil {
+		return err
+	}
+	if err := verifyBrewingPlacement(a.Source, dest); err != nil {
+		return err
+	}
+	if err := h.verifyBundlePlacement(a.Destination, i, s, tx); err != nil {
+		return err
+	}
+	if err := h.verifyBundlePlacement(a.Source, dest, s, tx); err != nil {
+		return err
+	}
 
-	invA, _ := s.invByID(int32(a.Source.Container.ContainerID), tx)
-	invB, _ := s.invByID(int32(a.Destination.Container.ContainerID), tx)
+	invA, _ := h.containerInv(a.Source.Container, s, tx)
+	invB, _ := h.containerInv(a.Destination.Container, s, tx)
 
 	ctx := event.C(inventory.Holder(c))
 	_ = call(ctx, int(a.Source.Slot), i, invA.Handler().HandleTake)
@@ -246,7 +382,7 @@ func (h *ItemStackRequestHandler) handleDrop(a *protocol.DropStackRequestAction,
 		return fmt.Errorf("client attempted to drop %v items, but only %v present", a.Count, i.Count())
 	}
 
-	inv, _ := s.invByID(int32(a.Source.Container.ContainerID), tx)
+	inv, _ := h.containerInv(a.Source.Container, s, tx)
 	if err := call(event.C(inventory.Holder(c)), int(a.Source.Slot), i.Grow(int(a.Count)-i.Count()), inv.Handler().HandleDrop); err != nil {
 		return err
 	}
@@ -327,7 +463,7 @@ func (h *ItemStackRequestHandler) verifySlot(slot protocol.StackRequestSlotInfo,
 	if len(h.responseChanges) > 256 {
 		return fmt.Errorf("too many unacknowledged request slot changes")
 	}
-	inv, _ := s.invByID(int32(slot.Container.ContainerID), tx)
+	inv, _ := h.containerInv(slot.Container, s, tx)
 
 	i, err := h.itemInSlot(slot, s, tx)
 	if err != nil {
@@ -371,7 +507,7 @@ func (h *ItemStackRequestHandler) resolveID(inv *inventory.Inventory, slot proto
 // info passed from the client has the right stack network ID in any of the stored slots. If this is the case,
 // that entry is removed, so that the maps are cleaned up eventually.
 func (h *ItemStackRequestHandler) tryAcknowledgeChanges(s *Session, tx *world.Tx, slot protocol.StackRequestSlotInfo) error {
-	inv, ok := s.invByID(int32(slot.Container.ContainerID), tx)
+	inv, ok := h.containerInv(slot.Container, s, tx)
 	if !ok {
 		return fmt.Errorf("could not find container with id %v", slot.Container.ContainerID)
 	}
@@ -396,7 +532,7 @@ func (h *ItemStackRequestHandler) tryAcknowledgeChanges(s *Session, tx *world.Tx
 
 // itemInSlot looks for the item in the slot as indicated by the slot info passed.
 func (h *ItemStackRequestHandler) itemInSlot(slot protocol.StackRequestSlotInfo, s *Session, tx *world.Tx) (item.Stack, error) {
-	inv, ok := s.invByID(int32(slot.Container.ContainerID), tx)
+	inv, ok := h.containerInv(slot.Container, s, tx)
 	if !ok {
 		return item.Stack{}, fmt.Errorf("unable to find container with ID %v", slot.Container.ContainerID)
 	}
@@ -415,7 +551,8 @@ func (h *ItemStackRequestHandler) itemInSlot(slot protocol.StackRequestSlotInfo,
 
 // setItemInSlot sets an item stack in the slot of a container present in the slot info.
 func (h *ItemStackRequestHandler) setItemInSlot(slot protocol.StackRequestSlotInfo, i item.Stack, s *Session, tx *world.Tx) {
-	inv, _ := s.invByID(int32(slot.Container.ContainerID), tx)
+	inv, _ := h.containerInv(slot.Container, s, tx)
+	i = withBundleID(i)
 
 	sl := int(slot.Slot)
 	if inv == s.offHand {
@@ -433,10 +570,10 @@ func (h *ItemStackRequestHandler) setItemInSlot(slot protocol.StackRequestSlotIn
 		DurabilityCorrection: int32(i.MaxDurability() - i.Durability()),
 	}
 
-	if h.changes[slot.Container.ContainerID] == nil {
-		h.changes[slot.Container.ContainerID] = map[byte]changeInfo{}
+	if h.changes[slot.Container] == nil {
+		h.changes[slot.Container] = map[byte]changeInfo{}
 	}
-	h.changes[slot.Container.ContainerID][slot.Slot] = changeInfo{
+	h.changes[slot.Container][slot.Slot] = changeInfo{
 		after:  respSlot,
 		before: before,
 	}
@@ -451,6 +588,11 @@ func (h *ItemStackRequestHandler) setItemInSlot(slot protocol.StackRequestSlotIn
 		id:        respSlot.StackNetworkID,
 		timestamp: h.current,
 	}
+
+	if id, ok := slot.Container.DynamicContainerID.Value(); ok && slot.Container.ContainerID == protocol.ContainerDynamic {
+		// The contents of a bundle changed, so the bundle item itself needs to be updated too.
+		h.writeBundle(id, inv, s, tx)
+	}
 }
 
 // resolve resolves the request with the ID passed.
@@ -462,7 +604,7 @@ func (h *ItemStackRequestHandler) resolve(id int32, s *Session) {
 			slots = append(slots, slot.after)
 		}
 		info = append(info, protocol.StackResponseContainerInfo{
-			Container: protocol.FullContainerName{ContainerID: container},
+			Container: container,
 			SlotInfo:  slots,
 		})
 	}
@@ -472,7 +614,7 @@ func (h *ItemStackRequestHandler) resolve(id int32, s *Session) {
 		ContainerInfo: info,
 	}}})
 
-	h.changes = map[byte]map[byte]changeInfo{}
+	h.changes = map[protocol.FullContainerName]map[byte]changeInfo{}
 	h.pendingResults = nil
 }
 
@@ -488,12 +630,12 @@ func (h *ItemStackRequestHandler) reject(id int32, s *Session, tx *world.Tx) {
 	// Revert changes that we already made for valid actions.
 	for container, slots := range h.changes {
 		for slot, info := range slots {
-			inv, _ := s.invByID(int32(container), tx)
+			inv, _ := h.containerInv(container, s, tx)
 			_ = inv.SetItem(int(slot), info.before)
 		}
 	}
 
-	h.changes = map[byte]map[byte]changeInfo{}
+	h.changes = map[protocol.FullContainerName]map[byte]changeInfo{}
 	h.pendingResults = nil
 }
 
@@ -0,0 +1,164 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/df-mc/dragonfly/server/item"
+	"github.com/df-mc/dragonfly/server/item/inventory"
+	"github.com/df-mc/dragonfly/server/player/menu"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+)
+
+func TestHandleTransaction(t *testing.T) {
+	slot := func(container byte, index byte) protocol.StackRequestSlotInfo {
+		return protocol.StackRequestSlotInfo{Container: protocol.FullContainerName{ContainerID: container}, Slot: index}
+	}
+	take := func(count byte, src, dst protocol.StackRequestSlotInfo) protocol.StackRequestAction {
+		a := &protocol.TakeStackRequestAction{}
+		a.Count, a.Source, a.Destination = count, src, dst
+		return a
+	}
+	place := func(count byte, src, dst protocol.StackRequestSlotInfo) protocol.StackRequestAction {
+		a := &protocol.PlaceStackRequestAction{}
+		a.Count, a.Source, a.Destination = count, src, dst
+		return a
+	}
+	drop := func(count byte, src protocol.StackRequestSlotInfo) protocol.StackRequestAction {
+		a := &protocol.DropStackRequestAction{}
+		a.Count, a.Source = count, src
+		return a
+	}
+	newSession := func() (*Session, *transactionTestHandler) {
+		s := &Session{inv: inventory.New(36, nil), ui: inventory.New(51, nil), offHand: inventory.New(1, nil)}
+		_ = s.inv.SetItem(0, item.NewStack(item.Stick{}, 16))
+
+		chest, h := inventory.New(27, nil), &transactionTestHandler{}
+		chest.Handle(h)
+		s.containerOpened.Store(true)
+		s.openedMenu.Store(menu.New(menu.Chest(), "Chest"))
+		s.openedWindow.Store(chest)
+		return s, h
+	}
+
+	t.Run("actions", func(t *testing.T) {
+		s, h := newSession()
+		c := &transactionTestControllable{allow: true}
+		// A stack split over two slots of a chest through the cursor: The
+		// items of later actions are those left after the earlier actions.
+		req := protocol.ItemStackRequest{Actions: []protocol.StackRequestAction{
+			take(16, slot(protocol.ContainerHotBar, 0), slot(protocol.ContainerCursor, 0)),
+			place(10, slot(protocol.ContainerCursor, 0), slot(protocol.ContainerLevelEntity, 3)),
+			place(6, slot(protocol.ContainerCursor, 0), slot(protocol.ContainerLevelEntity, 4)),
+			drop(4, slot(protocol.ContainerLevelEntity, 3)),
+		}}
+		if err := (&ItemStackRequestHandler{}).handleTransaction(req, s, nil, c); err != nil {
+			t.Fatalf("handleTransaction() = %v, want nil", err)
+		}
+		if h.calls != 1 || len(c.transactions) != 1 {
+			t.Fatalf("transactions handled = %v, %v, want 1, 1", h.calls, len(c.transactions))
+		}
+		chest := s.openedWindow.Load()
+		want := []inventory.Action{
+			{Type: inventory.ActionTransfer, Source: inventory.Location{Inventory: s.inv}, Destination: inventory.Location{Inventory: s.ui}, Count: 16, Item: item.NewStack(item.Stick{}, 16)},
+			{Type: inventory.ActionTransfer, Source: inventory.Location{Inventory: s.ui}, Destination: inventory.Location{Inventory: chest, Slot: 3}, Count: 10, Item: item.NewStack(item.Stick{}, 10)},
+			{Type: inventory.ActionTransfer, Source: inventory.Location{Inventory: s.ui}, Destination: inventory.Location{Inventory: chest, Slot: 4}, Count: 6, Item: item.NewStack(item.Stick{}, 6)},
+			{Type: inventory.ActionDrop, Source: inventory.Location{Inventory: chest, Slot: 3}, Count: 4, Item: item.NewStack(item.Stick{}, 4)},
+		}
+		actions := c.transactions[0].Actions
+		if len(actions) != len(want) {
+			t.Fatalf("len(Actions) = %v, want %v", len(actions), len(want))
+		}
+		for i, a := range actions {
+			w := want[i]
+			if a.Type != w.Type || a.Source != w.Source || a.Destination != w.Destination || a.Count != w.Count || !a.Item.Equal(w.Item) {
+				t.Fatalf("Actions[%v] = %+v, want %+v", i, a, w)
+			}
+		}
+		// The actions are only performed after the transaction is handled.
+		if it, _ := s.inv.Item(0); it.Count() != 16 {
+			t.Fatalf("item count after handleTransaction() = %v, want 16", it.Count())
+		}
+	})
+	t.Run("swap", func(t *testing.T) {
+		s, _ := newSession()
+		_ = s.offHand.SetItem(0, item.NewStack(item.Apple{}, 1))
+		c := &transactionTestControllable{allow: true}
+		req := protocol.ItemStackRequest{Actions: []protocol.StackRequestAction{
+			&protocol.SwapStackRequestAction{Source: slot(protocol.ContainerHotBar, 0), Destination: slot(protocol.ContainerOffhand, 1)},
+			take(1, slot(protocol.ContainerHotBar, 0), slot(protocol.ContainerCursor, 0)),
+		}}
+		if err := (&ItemStackRequestHandler{}).handleTransaction(req, s, nil, c); err != nil {
+			t.Fatalf("handleTransaction() = %v, want nil", err)
+		}
+		actions := c.transactions[0].Actions
+		// The off-hand only has a single slot, whatever slot the client sends.
+		if a := actions[0]; a.Type != inventory.ActionSwap || a.Destination != (inventory.Location{Inventory: s.offHand}) || !a.Item.Equal(item.NewStack(item.Stick{}, 16)) {
+			t.Fatalf("Actions[0] = %+v, want swap of sticks with off-hand", a)
+		}
+		if a := actions[1]; !a.Item.Equal(item.NewStack(item.Apple{}, 1)) {
+			t.Fatalf("Actions[1].Item = %v, want apple swapped into the slot", a.Item)
+		}
+	})
+	t.Run("cancel", func(t *testing.T) {
+		req := protocol.ItemStackRequest{Actions: []protocol.StackRequestAction{
+			place(1, slot(protocol.ContainerHotBar, 0), slot(protocol.ContainerLevelEntity, 0)),
+		}}
+		s, h := newSession()
+		h.cancel = true
+		c := &transactionTestControllable{allow: true}
+		if err := (&ItemStackRequestHandler{}).handleTransaction(req, s, nil, c); err == nil {
+			t.Fatalf("handleTransaction() cancelled by inventory handler = nil, want error")
+		}
+		if len(c.transactions) != 0 {
+			t.Fatalf("Controllable called after inventory handler cancelled transaction")
+		}
+
+		s, _ = newSession()
+		if err := (&ItemStackRequestHandler{}).handleTransaction(req, s, nil, &transactionTestControllable{}); err == nil {
+			t.Fatalf("handleTransaction() cancelled by Controllable = nil, want error")
+		}
+		// Controllables not implementing ValidatedControllable allow every
+		// transaction.
+		if err := (&ItemStackRequestHandler{}).handleTransaction(req, s, nil, &recipeTestControllable{}); err != nil {
+			t.Fatalf("handleTransaction() without ValidatedControllable = %v, want nil", err)
+		}
+	})
+	t.Run("closed container", func(t *testing.T) {
+		s, _ := newSession()
+		s.containerOpened.Store(false)
+		c := &transactionTestControllable{allow: true}
+		req := protocol.ItemStackRequest{Actions: []protocol.StackRequestAction{
+			drop(1, slot(protocol.ContainerLevelEntity, 0)),
+		}}
+		if err := (&ItemStackRequestHandler{}).handleTransaction(req, s, nil, c); err != nil {
+			t.Fatalf("handleTransaction() = %v, want nil", err)
+		}
+		if len(c.transactions) != 0 {
+			t.Fatalf("transaction of closed container handled, want none")
+		}
+	})
+}
+
+type transactionTestHandler struct {
+	inventory.NopHandler
+	calls  int
+	cancel bool
+}
+
+func (h *transactionTestHandler) HandleTransaction(ctx *inventory.Context, _ inventory.Transaction) {
+	h.calls++
+	if h.cancel {
+		ctx.Cancel()
+	}
+}
+
+type transactionTestControllable struct {
+	ValidatedControllable
+	allow        bool
+	transactions []inventory.Transaction
+}
+
+func (c *transactionTestControllable) AllowInventoryTransaction(t inventory.Transaction) bool {
+	c.transactions = append(c.transactions, t)
+	return c.allow
+}
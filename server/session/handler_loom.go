@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"github.com/df-mc/dragonfly/server/block"
 	"github.com/df-mc/dragonfly/server/item"
+	"github.com/df-mc/dragonfly/server/item/recipe"
 	"github.com/df-mc/dragonfly/server/world"
 	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"slices"
 )
 
 const (
@@ -64,21 +66,25 @@ func (h *ItemStackRequestHandler) handleLoomCraft(a *protocol.CraftLoomRecipeSta
 		return fmt.Errorf("unknown banner pattern id %q", a.Pattern)
 	}
 
-	// Some banner patterns have equivalent banner pattern items that are required to craft the pattern. If the expected
-	// pattern has a pattern item, check if the player input the correct pattern item.
+	// Some banner patterns have equivalent banner pattern items that are required to craft the pattern. Loom recipes
+	// may register additional (custom) items for a pattern. If the expected pattern requires a pattern item, check if
+	// the player input the correct pattern item.
 	pattern, _ := h.itemInSlot(protocol.StackRequestSlotInfo{
 		Container: protocol.FullContainerName{ContainerID: protocol.ContainerLoomMaterial},
 		Slot:      loomPatternSlot,
 	}, s, tx)
-	if expectedPatternItem, hasPatternItem := expectedPattern.Item(); hasPatternItem {
+	custom := recipe.LoomRecipes(a.Pattern)
+	if expectedPatternItem, hasPatternItem := expectedPattern.Item(); hasPatternItem || len(custom) > 0 {
 		if pattern.Empty() {
 			return fmt.Errorf("pattern item is empty but the pattern is required")
 		}
-		p, ok := pattern.Item().(item.BannerPattern)
-		if !ok {
-			return fmt.Errorf("pattern item is not a banner pattern")
+		matches := slices.ContainsFunc(custom, func(l recipe.Loom) bool {
+			return matchingStacks(pattern, l.Input()[0])
+		})
+		if p, ok := pattern.Item().(item.BannerPattern); ok && hasPatternItem && expectedPatternItem == p.Type {
+			matches = true
 		}
-		if expectedPatternItem != p.Type {
+		if !matches {
 			return fmt.Errorf("pattern item does not match the expected pattern")
 		}
 	}
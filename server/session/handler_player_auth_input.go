@@ -18,14 +18,15 @@ type PlayerAuthInputHandler struct{}
 // Handle ...
 func (h PlayerAuthInputHandler) Handle(p packet.Packet, s *Session, tx *world.Tx, c Controllable) error {
 	pk := p.(*packet.PlayerAuthInput)
-	if err := h.handleMovement(pk, s, c); err != nil {
+	if err := h.handleMovement(pk, s, tx, c); err != nil {
 		return err
 	}
+	s.blocks.tick(c, pk.Tick)
 	return h.handleActions(pk, s, tx, c)
 }
 
 // handleMovement handles the movement part of the packet.PlayerAuthInput.
-func (h PlayerAuthInputHandler) handleMovement(pk *packet.PlayerAuthInput, s *Session, c Controllable) error {
+func (h PlayerAuthInputHandler) handleMovement(pk *packet.PlayerAuthInput, s *Session, tx *world.Tx, c Controllable) error {
 	yaw, pitch := c.Rotation().Elem()
 	pos := c.Position()
 
@@ -60,6 +61,10 @@ func (h PlayerAuthInputHandler) handleMovement(pk *packet.PlayerAuthInput, s *Se
 			s.teleportPos.Store(nil)
 		}
 	}
+	if s.movement != nil {
+		newPos = s.movement.validate(pk, newPos, s, tx, c)
+		deltaPos = newPos.Sub(pos)
+	}
 
 	s.moving = true
 	c.Move(deltaPos, deltaYaw, deltaPitch)
@@ -73,7 +78,7 @@ func (h PlayerAuthInputHandler) handleActions(pk *packet.PlayerAuthInput, s *Ses
 		if !ok {
 			return fmt.Errorf("item interaction flag set without item interaction data")
 		}
-		if err := h.handleUseItemData(data, s, c); err != nil {
+		if err := h.handleUseItemData(data, s, tx, c); err != nil {
 			return err
 		}
 	}
@@ -82,7 +87,7 @@ func (h PlayerAuthInputHandler) handleActions(pk *packet.PlayerAuthInput, s *Ses
 		if !ok {
 			return fmt.Errorf("block actions flag set without block actions")
 		}
-		if err := h.handleBlockActions(actions, s, c); err != nil {
+		if err := h.handleBlockActions(actions, s, tx, c); err != nil {
 			return err
 		}
 	}
@@ -143,7 +148,7 @@ func (h PlayerAuthInputHandler) handleInputFlags(flags protocol.InputFlags, s *S
 	if flags.Load(packet.InputFlagStopCrawling) {
 		c.StopCrawling()
 	}
-	if flags.Load(packet.InputFlagMissedSwing) {
+	if flags.Load(packet.InputFlagMissedSwing) && s.combat.validateClick(c) {
 		s.swingingArm.Store(true)
 		defer s.swingingArm.Store(false)
 		c.PunchAir()
@@ -162,7 +167,7 @@ func (h PlayerAuthInputHandler) handleInputFlags(flags protocol.InputFlags, s *S
 }
 
 // handleUseItemData handles the protocol.UseItemTransactionData found in a packet.PlayerAuthInput.
-func (h PlayerAuthInputHandler) handleUseItemData(data protocol.UseItemTransactionData, s *Session, c Controllable) error {
+func (h PlayerAuthInputHandler) handleUseItemData(data protocol.UseItemTransactionData, s *Session, tx *world.Tx, c Controllable) error {
 	s.swingingArm.Store(true)
 	defer s.swingingArm.Store(false)
 
@@ -176,7 +181,9 @@ func (h PlayerAuthInputHandler) handleUseItemData(data protocol.UseItemTransacti
 	// Seems like this is only used for breaking blocks at the moment.
 	switch data.ActionType {
 	case protocol.UseItemActionBreakBlock:
-		c.BreakBlock(pos)
+		if s.blocks.validateInteraction(s, tx, c, pos, cube.Face(data.BlockFace)) && s.blocks.validateBreak(s, tx, c, pos) {
+			c.BreakBlock(pos)
+		}
 	default:
 		return fmt.Errorf("unhandled UseItem ActionType for PlayerAuthInput packet %v", data.ActionType)
 	}
@@ -184,9 +191,9 @@ func (h PlayerAuthInputHandler) handleUseItemData(data protocol.UseItemTransacti
 }
 
 // handleBlockActions handles a slice of protocol.PlayerBlockAction present in a PlayerAuthInput packet.
-func (h PlayerAuthInputHandler) handleBlockActions(a []protocol.PlayerBlockAction, s *Session, c Controllable) error {
+func (h PlayerAuthInputHandler) handleBlockActions(a []protocol.PlayerBlockAction, s *Session, tx *world.Tx, c Controllable) error {
 	for _, action := range a {
-		if err := handlePlayerAction(action.Action, action.Face, action.BlockPos, selfEntityRuntimeID, s, c); err != nil {
+		if err := handlePlayerAction(action.Action, action.Face, action.BlockPos, selfEntityRuntimeID, s, tx, c); err != nil {
 			return err
 		}
 	}
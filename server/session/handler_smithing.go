@@ -24,6 +24,9 @@ func (h *ItemStackRequestHandler) handleSmithing(a *protocol.CraftRecipeStackReq
 	if !ok {
 		return fmt.Errorf("recipe with network id %v does not exist", a.RecipeNetworkID)
 	}
+	if s.recipeLocked(craft) {
+		return fmt.Errorf("recipe with network id %v is not unlocked", a.RecipeNetworkID)
+	}
 	if craft.Block() != "smithing_table" {
 		return fmt.Errorf("recipe with network id %v is not a smithing table recipe", a.RecipeNetworkID)
 	}
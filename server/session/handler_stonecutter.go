@@ -16,10 +16,17 @@ func (h *ItemStackRequestHandler) handleStonecutting(a *protocol.CraftRecipeStac
 	if !ok {
 		return fmt.Errorf("recipe with network id %v does not exist", a.RecipeNetworkID)
 	}
-	if _, shapeless := craft.(recipe.Shapeless); !shapeless {
-		return fmt.Errorf("recipe with network id %v is not a shapeless recipe", a.RecipeNetworkID)
+	if s.recipeLocked(craft) {
+		return fmt.Errorf("recipe with network id %v is not unlocked", a.RecipeNetworkID)
 	}
-	if craft.Block() != "stonecutter" {
+	// Shapeless recipes registered for the stonecutter are accepted as well, as these were used to add stonecutter
+	// recipes before recipe.Stonecutter existed.
+	switch craft.(type) {
+	case recipe.Stonecutter, recipe.Shapeless:
+	default:
+		return fmt.Errorf("recipe with network id %v is not a stonecutter recipe", a.RecipeNetworkID)
+	}
+	if craft.Block() != "stonecutter" || len(craft.Input()) != 1 || len(craft.Output()) != 1 {
 		return fmt.Errorf("recipe with network id %v is not a stonecutter recipe", a.RecipeNetworkID)
 	}
 
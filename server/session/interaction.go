@@ -0,0 +1,229 @@
+package session
+
+import (
+	"math"
+	"time"
+
+	"github.com/df-mc/dragonfly/server/block"
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/entity"
+	"github.com/df-mc/dragonfly/server/world"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+const (
+	// blockReach is the maximum distance in blocks from the eyes of a
+	// Controllable to a block that it may break or interact with.
+	blockReach = 6.0
+	// creativeBlockReach is the blockReach of Controllables in a game mode
+	// with a creative inventory.
+	creativeBlockReach = 12.0
+	// breakTickSlack is the number of ticks by which a client may finish
+	// breaking a block early, accounting for the tick at which it started.
+	breakTickSlack = 2
+)
+
+// blockValidator validates the breaking of and interaction with blocks by a
+// Controllable. It keeps track of the progress of breaking a block, which is
+// advanced every client tick using the break duration of the block at that
+// time.
+type blockValidator struct {
+	breaking bool
+	pos      cube.Pos
+	progress float64
+
+	// started is the time at which the Controllable started breaking the
+	// block and ticks the number of client ticks counted since then. The
+	// number of ticks counted is limited by the time passed, so that clients
+	// cannot speed up breaking by skipping ticks.
+	started time.Time
+	ticks   int64
+	// lastTick is the client tick of the last PlayerAuthInput packet.
+	lastTick uint64
+}
+
+// tick advances the break progress of the block currently being broken by
+// the number of ticks passed since the previous client tick. Packets sent
+// multiple times for the same tick do not advance the progress. tick is a
+// no-op if v is nil.
+func (v *blockValidator) tick(c Controllable, clientTick uint64) {
+	if v == nil {
+		return
+	}
+	last := v.lastTick
+	v.lastTick = clientTick
+	if !v.breaking || clientTick <= last {
+		return
+	}
+	n := int64(clientTick - last)
+	if allowed := int64(time.Since(v.started)/(time.Second/20)) + breakTickSlack - v.ticks; n > allowed {
+		// The client claims more ticks to have passed than possible since it
+		// started breaking the block.
+		n = allowed
+	}
+	if n <= 0 {
+		return
+	}
+	v.ticks += n
+	v.progress += tickProgress(c, v.pos) * float64(n)
+}
+
+// start starts tracking the break progress of the block at the position
+// passed. start is a no-op if v is nil.
+func (v *blockValidator) start(pos cube.Pos) {
+	if v == nil {
+		return
+	}
+	v.breaking, v.pos, v.progress = true, pos, 0
+	v.started, v.ticks = time.Now(), 0
+}
+
+// resume starts tracking the break progress of the block at the position
+// passed, unless the block is already being broken, such as when the client
+// turns to another face of the block while breaking it. resume returns true
+// if tracking was started again, which is always the case if v is nil.
+func (v *blockValidator) resume(pos cube.Pos) bool {
+	if v == nil {
+		return true
+	}
+	if v.breaking && v.pos == pos {
+		return false
+	}
+	v.start(pos)
+	return true
+}
+
+// abort stops tracking the break progress of the block being broken. abort is
+// a no-op if v is nil.
+func (v *blockValidator) abort() {
+	if v == nil {
+		return
+	}
+	v.breaking = false
+}
+
+// validateBreak checks if the Controllable passed has been breaking the block
+// at the position passed for long enough to break it. If not, the block is
+// resent to the client. validateBreak returns true if v is nil.
+func (v *blockValidator) validateBreak(s *Session, tx *world.Tx, c Controllable, pos cube.Pos) bool {
+	if v == nil || c.GameMode().CreativeInventory() {
+		return true
+	}
+	if _, air := tx.Block(pos).(block.Air); air {
+		// Nothing is broken, for example because the client already finished
+		// breaking the block in an earlier action.
+		v.breaking = false
+		return true
+	}
+	progress, perTick := 0.0, tickProgress(c, pos)
+	if v.breaking && v.pos == pos {
+		progress = v.progress
+	}
+	v.breaking = false
+	if progress+perTick*breakTickSlack >= 1 {
+		return true
+	}
+	return v.reject(s, tx, c, Violation{Type: ViolationBreakTime, Value: progress, Limit: 1, Pos: pos})
+}
+
+// validateInteraction checks if the Controllable passed is able to reach the
+// block at the position passed and see its face passed. If not, the block is
+// resent to the client. validateInteraction returns true if v is nil.
+func (v *blockValidator) validateInteraction(s *Session, tx *world.Tx, c Controllable, pos cube.Pos, face cube.Face) bool {
+	if v == nil {
+		return true
+	}
+	reach := blockReach
+	if c.GameMode().CreativeInventory() {
+		reach = creativeBlockReach
+	}
+	eyes := entity.EyePosition(c)
+	// Reach is checked first, so that no blocks are looked up for positions
+	// far away from the Controllable.
+	if dist := closestPoint(cube.Box(0, 0, 0, 1, 1, 1).Translate(pos.Vec3()), eyes).Sub(eyes).Len(); dist > reach {
+		return v.reject(s, tx, c, Violation{Type: ViolationBlockReach, Value: dist, Limit: reach, Pos: pos})
+	}
+	normal := pos.Side(face).Sub(pos).Vec3()
+	if facing := eyes.Sub(pos.Vec3Centre()).Dot(normal); facing <= -0.5 {
+		// The eyes are behind the plane of the face, so the face cannot be
+		// seen.
+		return v.reject(s, tx, c, Violation{Type: ViolationBlockFace, Value: facing, Limit: -0.5, Pos: pos})
+	}
+	if !faceVisible(tx, eyes, pos, normal) {
+		return v.reject(s, tx, c, Violation{Type: ViolationLineOfSight, Value: eyes.Sub(pos.Vec3Centre()).Len(), Limit: reach, Pos: pos})
+	}
+	return true
+}
+
+// reject reports the Violation passed to the Controllable and resends the
+// block the Violation refers to if the Violation was not cancelled. reject
+// returns true if the Violation was cancelled.
+func (v *blockValidator) reject(s *Session, tx *world.Tx, c Controllable, viol Violation) bool {
+	if !reportViolation(c, viol) {
+		return true
+	}
+	s.resendBlocks(tx, c, viol.Pos)
+	return false
+}
+
+// tickProgress returns the break progress of the block at the position passed
+// made by the Controllable passed in a single tick. Blocks are broken
+// instantly if the Controllable does not implement ValidatedControllable.
+func tickProgress(c Controllable, pos cube.Pos) float64 {
+	vc, ok := c.(ValidatedControllable)
+	if !ok {
+		return math.Inf(1)
+	}
+	d := vc.BreakDuration(pos)
+	if d <= 0 {
+		return math.Inf(1)
+	}
+	return float64(time.Second/20) / float64(d)
+}
+
+// faceVisible checks if any of the centre or the corners of the face with the
+// normal passed of the block at the position passed is visible from the eye
+// position passed without other blocks obstructing the view.
+func faceVisible(tx *world.Tx, eyes mgl64.Vec3, pos cube.Pos, normal mgl64.Vec3) bool {
+	// The points checked are slightly inset into the block, so that the
+	// blocks adjacent to the face are not considered to obstruct it.
+	centre := pos.Vec3Centre().Add(normal.Mul(0.49))
+	var tangents []mgl64.Vec3
+	for i := range 3 {
+		if normal[i] == 0 {
+			var t mgl64.Vec3
+			t[i] = 0.4
+			tangents = append(tangents, t)
+		}
+	}
+	points := []mgl64.Vec3{
+		centre,
+		centre.Add(tangents[0]).Add(tangents[1]),
+		centre.Add(tangents[0]).Sub(tangents[1]),
+		centre.Sub(tangents[0]).Add(tangents[1]),
+		centre.Sub(tangents[0]).Sub(tangents[1]),
+	}
+	for _, point := range points {
+		if unobstructed(tx, eyes, point, pos) {
+			return true
+		}
+	}
+	return false
+}
+
+// resendBlocks resends the blocks at the positions passed to the client, so
+// that blocks changed client-side by rejected input are reverted. Blocks
+// outside the chunk radius of the Session are not resent.
+func (s *Session) resendBlocks(tx *world.Tx, c Controllable, positions ...cube.Pos) {
+	for _, pos := range positions {
+		if pos.Vec3Centre().Sub(c.Position()).Len() > float64(s.chunkRadius*16) {
+			continue
+		}
+		b := tx.Block(pos)
+		s.ViewBlockUpdate(pos, b, 0)
+		if _, ok := b.(world.LiquidDisplacer); ok {
+			liq, _ := tx.Liquid(pos)
+			s.ViewBlockUpdate(pos, liq, 1)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package session
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/df-mc/dragonfly/server/block/cube"
+)
+
+func TestBlockValidatorTick(t *testing.T) {
+	// A block that takes a second to break is broken in 20 ticks.
+	c := breakTestControllable{d: time.Second}
+	pos := cube.Pos{1, 2, 3}
+
+	t.Run("client ticks", func(t *testing.T) {
+		v := &blockValidator{lastTick: 100}
+		v.start(pos)
+		v.started = time.Now().Add(-time.Second)
+		for tick := uint64(101); tick <= 110; tick++ {
+			v.tick(c, tick)
+		}
+		if math.Abs(v.progress-0.5) > 1e-9 {
+			t.Fatalf("progress after 10 ticks = %v, want 0.5", v.progress)
+		}
+	})
+	t.Run("repeated ticks", func(t *testing.T) {
+		v := &blockValidator{lastTick: 100}
+		v.start(pos)
+		v.started = time.Now().Add(-time.Second)
+		for range 20 {
+			v.tick(c, 101)
+		}
+		if math.Abs(v.progress-0.05) > 1e-9 {
+			t.Fatalf("progress after 20 packets for the same tick = %v, want 0.05", v.progress)
+		}
+	})
+	t.Run("skipped ticks", func(t *testing.T) {
+		v := &blockValidator{lastTick: 100}
+		v.start(pos)
+		v.started = time.Now().Add(-time.Second / 4)
+		v.tick(c, 200)
+		// Only 5 ticks passed since breaking started, plus breakTickSlack.
+		if want := float64(5+breakTickSlack) / 20; math.Abs(v.progress-want) > 1e-9 {
+			t.Fatalf("progress after skipping 100 ticks = %v, want %v", v.progress, want)
+		}
+		v.tick(c, 300)
+		if want := float64(5+breakTickSlack) / 20; math.Abs(v.progress-want) > 1e-9 {
+			t.Fatalf("progress after skipping another 100 ticks = %v, want %v", v.progress, want)
+		}
+	})
+	t.Run("not breaking", func(t *testing.T) {
+		v := &blockValidator{lastTick: 100}
+		v.tick(c, 101)
+		v.start(pos)
+		v.abort()
+		v.tick(c, 102)
+		if v.progress != 0 || v.lastTick != 102 {
+			t.Fatalf("progress = %v at tick %v, want 0 at tick 102", v.progress, v.lastTick)
+		}
+	})
+	t.Run("face change", func(t *testing.T) {
+		v := &blockValidator{lastTick: 100}
+		v.start(pos)
+		v.started = time.Now().Add(-time.Second)
+		for tick := uint64(101); tick <= 110; tick++ {
+			v.tick(c, tick)
+		}
+		// The client continues breaking the same block at another face.
+		if v.resume(pos) {
+			t.Fatalf("resume() of block being broken = true, want false")
+		}
+		for tick := uint64(111); tick <= 120; tick++ {
+			v.tick(c, tick)
+		}
+		if math.Abs(v.progress-1) > 1e-9 {
+			t.Fatalf("progress after 20 ticks with a face change = %v, want 1", v.progress)
+		}
+		if !v.resume(pos.Side(cube.FaceUp)) || v.progress != 0 {
+			t.Fatalf("resume() of another block did not restart breaking")
+		}
+	})
+	t.Run("nil", func(t *testing.T) {
+		var v *blockValidator
+		if !v.resume(pos) {
+			t.Fatalf("resume() of nil validator = false, want true")
+		}
+		v.start(pos)
+		v.tick(c, 1)
+		v.abort()
+	})
+}
+
+type breakTestControllable struct {
+	ValidatedControllable
+	d time.Duration
+}
+
+func (c breakTestControllable) BreakDuration(cube.Pos) time.Duration { return c.d }
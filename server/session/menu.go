@@ -0,0 +1,93 @@
+package session
+
+import (
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/player/menu"
+	"github.com/df-mc/dragonfly/server/world"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// OpenMenu opens a menu.Menu for the client. A fake block of the menu.Type of the Menu is sent at the position
+// passed (and the block east of it for double chests), which is restored to the actual block in the world when the
+// Menu is closed.
+func (s *Session) OpenMenu(m *menu.Menu, pos cube.Pos, tx *world.Tx) {
+	s.closeCurrentContainer(tx, false)
+
+	name, properties := m.Type().Block()
+	rid, ok := s.br.StateToRuntimeID(name, properties)
+	if !ok {
+		s.conf.Log.Debug("open menu: no runtime ID for menu block", "block", name)
+		return
+	}
+	positions := []cube.Pos{pos}
+	if m.Type().Double() {
+		positions = append(positions, pos.Side(cube.FaceEast))
+	}
+	for i, p := range positions {
+		blockPos := protocol.BlockPos{int32(p[0]), int32(p[1]), int32(p[2])}
+		s.writePacket(&packet.UpdateBlock{
+			Position:          blockPos,
+			NewBlockRuntimeID: rid,
+			Flags:             packet.BlockUpdateNetwork,
+		})
+		nbtData := map[string]any{
+			"id": m.Type().BlockActorID(),
+			"x":  blockPos.X(), "y": blockPos.Y(), "z": blockPos.Z(),
+		}
+		if m.Name() != "" {
+			nbtData["CustomName"] = m.Name()
+		}
+		if len(positions) == 2 {
+			pair := positions[1-i]
+			nbtData["pairx"], nbtData["pairz"], nbtData["pairlead"] = int32(pair[0]), int32(pair[2]), boolByte(i == 0)
+		}
+		s.writePacket(&packet.BlockActorData{Position: blockPos, NBTData: nbtData})
+	}
+
+	m.AddViewer(s)
+	nextID := s.nextWindowID()
+	s.containerOpened.Store(true)
+	s.openedWindow.Store(m.Inventory())
+	s.openedPos.Store(&pos)
+	s.openedMenu.Store(m)
+
+	containerType := m.Type().ContainerType()
+	s.openedContainerID.Store(uint32(containerType))
+	s.writePacket(&packet.ContainerOpen{
+		WindowID:                nextID,
+		ContainerType:           containerType,
+		ContainerPosition:       protocol.BlockPos{int32(pos[0]), int32(pos[1]), int32(pos[2])},
+		ContainerEntityUniqueID: -1,
+	})
+	s.sendInv(m.Inventory(), uint32(nextID))
+}
+
+// closeMenu removes the viewer of the menu.Menu currently opened and restores the fake blocks sent to display it.
+// false is returned if no menu.Menu was open.
+func (s *Session) closeMenu(tx *world.Tx) bool {
+	m := s.openedMenu.Swap(nil)
+	if m == nil {
+		return false
+	}
+	m.RemoveViewer(s)
+
+	pos := *s.openedPos.Load()
+	s.ViewBlockUpdate(pos, tx.Block(pos), 0)
+	if m.Type().Double() {
+		s.ViewBlockUpdate(pos.Side(cube.FaceEast), tx.Block(pos.Side(cube.FaceEast)), 0)
+	}
+	if c, ok := s.ent.Entity(tx); ok {
+		m.Close(c)
+	}
+	return true
+}
+
+// resendMenu sends the full contents of the menu.Menu currently opened, if any, to the client. It is used after
+// item stack requests, during which ClickFuncs of the Menu may have changed its contents without the client being
+// notified.
+func (s *Session) resendMenu() {
+	if m := s.openedMenu.Load(); m != nil && s.containerOpened.Load() {
+		s.sendInv(m.Inventory(), s.openedWindowID.Load())
+	}
+}
@@ -0,0 +1,156 @@
+package session
+
+import (
+	"slices"
+
+	"github.com/df-mc/dragonfly/server/world"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// Middleware intercepts packets received from and sent to the client of a
+// Session. Middleware added to a Session is called in the order it was added,
+// for both inbound and outbound packets. Middleware may observe, modify,
+// replace or drop packets.
+type Middleware interface {
+	// HandleInbound is called for every packet read from the client before it
+	// is handled by the Session. The packet pointed to by pk may be modified
+	// or replaced. ctx.Cancel() may be called to drop the packet, in which
+	// case it is not passed to Middleware later in the chain either.
+	HandleInbound(ctx *PacketContext, pk *packet.Packet)
+	// HandleOutbound is called for every packet sent to the client before it
+	// is written to the connection. The packet pointed to by pk may be
+	// modified or replaced. ctx.Cancel() may be called to drop the packet.
+	// HandleOutbound may be called from any goroutine and must be safe for
+	// concurrent use.
+	HandleOutbound(ctx *PacketContext, pk *packet.Packet)
+}
+
+// NopMiddleware implements the Middleware interface but does not intercept
+// any packets. It may be embedded in a Middleware implementation to only
+// implement one of its methods.
+type NopMiddleware struct{}
+
+// Compile time check to make sure NopMiddleware implements Middleware.
+var _ Middleware = NopMiddleware{}
+
+func (NopMiddleware) HandleInbound(*PacketContext, *packet.Packet)  {}
+func (NopMiddleware) HandleOutbound(*PacketContext, *packet.Packet) {}
+
+// PacketContext is the context of a packet passed to a Middleware.
+type PacketContext struct {
+	s      *Session
+	tx     *world.Tx
+	c      Controllable
+	cancel bool
+}
+
+// Session returns the Session that the packet was received by or is sent by.
+func (ctx *PacketContext) Session() *Session {
+	return ctx.s
+}
+
+// Handle returns the world.EntityHandle of the Controllable of the Session.
+func (ctx *PacketContext) Handle() *world.EntityHandle {
+	return ctx.s.ent
+}
+
+// Controllable returns the Controllable of the Session, which is generally a
+// *player.Player, and the transaction that it may be used in. Controllable
+// returns false for outbound packets, which may be sent outside of a
+// transaction.
+func (ctx *PacketContext) Controllable() (*world.Tx, Controllable, bool) {
+	return ctx.tx, ctx.c, ctx.c != nil
+}
+
+// Cancel cancels the packet, dropping it.
+func (ctx *PacketContext) Cancel() {
+	ctx.cancel = true
+}
+
+// Cancelled checks if the packet was cancelled.
+func (ctx *PacketContext) Cancelled() bool {
+	return ctx.cancel
+}
+
+// InboundFunc returns a Middleware that calls f for every packet of type T
+// received from the client, such as *packet.Text. Other packets are passed on
+// unchanged.
+func InboundFunc[T packet.Packet](f func(ctx *PacketContext, pk T)) Middleware {
+	return typedMiddleware[T]{inbound: f}
+}
+
+// OutboundFunc returns a Middleware that calls f for every packet of type T
+// sent to the client, such as *packet.Text. Other packets are passed on
+// unchanged. f may be called from any goroutine.
+func OutboundFunc[T packet.Packet](f func(ctx *PacketContext, pk T)) Middleware {
+	return typedMiddleware[T]{outbound: f}
+}
+
+// typedMiddleware is a Middleware that calls functions for packets of type T.
+type typedMiddleware[T packet.Packet] struct {
+	inbound, outbound func(ctx *PacketContext, pk T)
+}
+
+// HandleInbound ...
+func (m typedMiddleware[T]) HandleInbound(ctx *PacketContext, pk *packet.Packet) {
+	if v, ok := (*pk).(T); ok && m.inbound != nil {
+		m.inbound(ctx, v)
+	}
+}
+
+// HandleOutbound ...
+func (m typedMiddleware[T]) HandleOutbound(ctx *PacketContext, pk *packet.Packet) {
+	if v, ok := (*pk).(T); ok && m.outbound != nil {
+		m.outbound(ctx, v)
+	}
+}
+
+// Use adds Middleware to the end of the chain of Middleware of the Session.
+// Use may be called at any time and from any goroutine.
+func (s *Session) Use(m ...Middleware) {
+	s.middlewareMu.Lock()
+	defer s.middlewareMu.Unlock()
+	chain := slices.Concat(s.middlewareChain(), m)
+	s.middleware.Store(&chain)
+}
+
+// middlewareChain returns the current chain of Middleware of the Session.
+func (s *Session) middlewareChain() []Middleware {
+	if chain := s.middleware.Load(); chain != nil {
+		return *chain
+	}
+	return nil
+}
+
+// interceptInbound passes a packet received from the client through the
+// chain of Middleware. The packet to handle is returned, or false if it was
+// dropped.
+func (s *Session) interceptInbound(pk packet.Packet, tx *world.Tx, c Controllable) (packet.Packet, bool) {
+	chain := s.middlewareChain()
+	if len(chain) == 0 {
+		return pk, true
+	}
+	ctx := &PacketContext{s: s, tx: tx, c: c}
+	for _, m := range chain {
+		if m.HandleInbound(ctx, &pk); ctx.Cancelled() || pk == nil {
+			return nil, false
+		}
+	}
+	return pk, true
+}
+
+// interceptOutbound passes a packet sent to the client through the chain of
+// Middleware. The packet to send is returned, or false if it was dropped.
+func (s *Session) interceptOutbound(pk packet.Packet) (packet.Packet, bool) {
+	chain := s.middlewareChain()
+	if len(chain) == 0 {
+		return pk, true
+	}
+	ctx := &PacketContext{s: s}
+	for _, m := range chain {
+		if m.HandleOutbound(ctx, &pk); ctx.Cancelled() || pk == nil {
+			return nil, false
+		}
+	}
+	return pk, true
+}
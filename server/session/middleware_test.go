@@ -0,0 +1,173 @@
+package session
+
+import (
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+func TestMiddlewareInbound(t *testing.T) {
+	var order []string
+	record := func(name string) Middleware {
+		return InboundFunc(func(ctx *PacketContext, pk *packet.Text) {
+			order = append(order, name)
+		})
+	}
+
+	t.Run("order", func(t *testing.T) {
+		order = nil
+		s := &Session{}
+		s.Use(record("a"), record("b"))
+		s.Use(record("c"))
+		if _, ok := s.interceptInbound(&packet.Text{}, nil, nil); !ok {
+			t.Fatalf("interceptInbound() = false, want true")
+		}
+		if want := []string{"a", "b", "c"}; !slices.Equal(order, want) {
+			t.Fatalf("order = %v, want %v", order, want)
+		}
+	})
+	t.Run("rewrite", func(t *testing.T) {
+		s := &Session{}
+		s.Use(InboundFunc(func(ctx *PacketContext, pk *packet.Text) {
+			pk.Message = "rewritten"
+		}), InboundFunc(func(ctx *PacketContext, pk *packet.Text) {
+			if pk.Message != "rewritten" {
+				t.Fatalf("Message in next middleware = %q, want %q", pk.Message, "rewritten")
+			}
+		}))
+		pk, ok := s.interceptInbound(&packet.Text{Message: "original"}, nil, nil)
+		if !ok {
+			t.Fatalf("interceptInbound() = false, want true")
+		}
+		if msg := pk.(*packet.Text).Message; msg != "rewritten" {
+			t.Fatalf("Message = %q, want %q", msg, "rewritten")
+		}
+	})
+	t.Run("drop", func(t *testing.T) {
+		order = nil
+		s := &Session{}
+		s.Use(record("a"), InboundFunc(func(ctx *PacketContext, pk *packet.Text) {
+			ctx.Cancel()
+		}), record("b"))
+		if _, ok := s.interceptInbound(&packet.Text{}, nil, nil); ok {
+			t.Fatalf("interceptInbound() = true, want false")
+		}
+		if want := []string{"a"}; !slices.Equal(order, want) {
+			t.Fatalf("order = %v, want %v", order, want)
+		}
+		// Packets of other types are passed on unchanged.
+		if _, ok := s.interceptInbound(&packet.Animate{}, nil, nil); !ok {
+			t.Fatalf("interceptInbound() of other packet = false, want true")
+		}
+	})
+}
+
+func TestMiddlewareOutbound(t *testing.T) {
+	newSession := func(m ...Middleware) (*Session, *middlewareTestConn) {
+		conn := &middlewareTestConn{}
+		s := &Session{conn: conn, packets: make(chan packet.Packet, 16), closeBackground: make(chan struct{})}
+		s.Use(m...)
+		go s.sendPackets()
+		t.Cleanup(func() { close(s.closeBackground) })
+		return s, conn
+	}
+
+	t.Run("order", func(t *testing.T) {
+		var order []string
+		record := func(name string) Middleware {
+			return OutboundFunc(func(ctx *PacketContext, pk *packet.Text) {
+				order = append(order, name)
+			})
+		}
+		s, conn := newSession(record("a"), record("b"))
+		s.writePacket(&packet.Text{Message: "first"})
+		s.writePacket(&packet.Text{Message: "second"})
+		s.flush()
+		if want := []string{"a", "b", "a", "b"}; !slices.Equal(order, want) {
+			t.Fatalf("order = %v, want %v", order, want)
+		}
+		if msgs, want := conn.messages(), []string{"first", "second"}; !slices.Equal(msgs, want) {
+			t.Fatalf("written = %v, want %v", msgs, want)
+		}
+	})
+	t.Run("rewrite", func(t *testing.T) {
+		s, conn := newSession(OutboundFunc(func(ctx *PacketContext, pk *packet.Text) {
+			pk.Message += " rewritten"
+		}))
+		s.writePacket(&packet.Text{Message: "message"})
+		s.flush()
+		if msgs, want := conn.messages(), []string{"message rewritten"}; !slices.Equal(msgs, want) {
+			t.Fatalf("written = %v, want %v", msgs, want)
+		}
+	})
+	t.Run("drop", func(t *testing.T) {
+		s, conn := newSession(OutboundFunc(func(ctx *PacketContext, pk *packet.Text) {
+			if pk.Message == "drop" {
+				ctx.Cancel()
+			}
+		}))
+		s.writePacket(&packet.Text{Message: "drop"})
+		s.writePacket(&packet.Text{Message: "keep"})
+		s.flush()
+		if msgs, want := conn.messages(), []string{"keep"}; !slices.Equal(msgs, want) {
+			t.Fatalf("written = %v, want %v", msgs, want)
+		}
+	})
+	t.Run("disconnect", func(t *testing.T) {
+		// Disconnect passes through the middleware and is written after the
+		// packets sent before it.
+		s, conn := newSession(OutboundFunc(func(ctx *PacketContext, pk *packet.Disconnect) {
+			pk.Message = "rewritten"
+		}))
+		s.writePacket(&packet.Text{Message: "before"})
+		s.Disconnect("kicked")
+
+		conn.mu.Lock()
+		defer conn.mu.Unlock()
+		if len(conn.written) != 2 {
+			t.Fatalf("len(written) = %v, want 2", len(conn.written))
+		}
+		if pk, ok := conn.written[1].(*packet.Disconnect); !ok || pk.Message != "rewritten" {
+			t.Fatalf("written[1] = %#v, want rewritten Disconnect", conn.written[1])
+		}
+		if conn.flushed == 0 {
+			t.Fatalf("connection was not flushed")
+		}
+	})
+}
+
+type middlewareTestConn struct {
+	Conn
+	mu      sync.Mutex
+	written []packet.Packet
+	flushed int
+}
+
+func (c *middlewareTestConn) WritePacket(pk packet.Packet) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.written = append(c.written, pk)
+	return nil
+}
+
+func (c *middlewareTestConn) Flush() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.flushed++
+	return nil
+}
+
+// messages returns the messages of all Text packets written to the connection.
+func (c *middlewareTestConn) messages() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var msgs []string
+	for _, pk := range c.written {
+		if pk, ok := pk.(*packet.Text); ok {
+			msgs = append(msgs, pk.Message)
+		}
+	}
+	return msgs
+}
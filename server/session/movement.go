@@ -0,0 +1,494 @@
+package session
+
+import (
+	"math"
+	"sync/atomic"
+
+	"github.com/df-mc/dragonfly/server/block"
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/entity/effect"
+	"github.com/df-mc/dragonfly/server/item/enchantment"
+	"github.com/df-mc/dragonfly/server/item/inventory"
+	"github.com/df-mc/dragonfly/server/world"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/go-gl/mathgl/mgl64"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+const (
+	// movementThreshold is the maximum distance in blocks between the position
+	// predicted by a movementSimulator and the position claimed by the client,
+	// accumulated over multiple ticks, before the movement of the client is
+	// corrected.
+	movementThreshold = 0.3
+	// driftDecay is the factor by which the deviation accumulated over
+	// previous ticks decays every tick. Small deviations caused by the
+	// precision of the client decay before reaching movementThreshold, while
+	// a client deviating in the same direction every tick is corrected. This
+	// limits the distance a client may gain per tick to
+	// movementThreshold*(1-driftDecay).
+	driftDecay = 0.95
+	// correctionTimeout is the number of client ticks after which a correction
+	// is sent again if the client has not moved back to the corrected position
+	// yet.
+	correctionTimeout = 40
+	// stepHeight is the maximum height in blocks that players automatically
+	// step up when walking into a block.
+	stepHeight = 0.6
+)
+
+// movementSimulator predicts the movement of a Controllable from the input
+// flags its client sends in PlayerAuthInput packets. The predicted position
+// is compared to the position claimed by the client and the client is
+// corrected if the two diverge.
+type movementSimulator struct {
+	pos, vel             mgl64.Vec3
+	onGround             bool
+	collidedHorizontally bool
+	// drift is the deviation between the predicted and claimed positions
+	// accumulated over previous ticks, decaying by driftDecay every tick.
+	drift float64
+	// spinAttack specifies if the client is performing a spin attack, such as
+	// when using a trident enchanted with riptide.
+	spinAttack bool
+
+	// synced specifies if the state of the simulator is in sync with the
+	// client. It is cleared when the Controllable is teleported, after which
+	// the simulator resumes from the next position the client claims.
+	synced atomic.Bool
+	// knockBack holds velocity sent to the client, such as when it is knocked
+	// back. It is applied at the start of the next tick simulated.
+	knockBack atomic.Pointer[mgl64.Vec3]
+
+	// correcting specifies if a correction was sent to the client that it has
+	// not yet processed. correctedAt holds the client tick of the correction.
+	correcting  bool
+	correctedAt uint64
+}
+
+// validate simulates the movement in the PlayerAuthInput packet passed and
+// compares the predicted position to the position claimed by the client. The
+// position that the Controllable should be moved to is returned.
+func (m *movementSimulator) validate(pk *packet.PlayerAuthInput, claimed mgl64.Vec3, s *Session, tx *world.Tx, c Controllable) mgl64.Vec3 {
+	if !m.synced.Load() || m.exempt(pk, s, tx, c) {
+		// Movement that isn't simulated is trusted, but the simulator must
+		// resume from the claimed state once it becomes simulated again.
+		m.sync(claimed, vec32To64(pk.Delta), m.groundState(c))
+		return claimed
+	}
+	m.simulate(pk, tx, c)
+
+	deviation := m.pos.Sub(claimed).Len()
+	if drift := m.drift*driftDecay + deviation; drift <= movementThreshold {
+		// Small differences are expected due to the precision of the client,
+		// so accept the claimed position to prevent these from accumulating
+		// in the prediction. The deviation is remembered so that a client
+		// cannot move slightly further than predicted every tick.
+		m.pos, m.drift, m.correcting = claimed, drift, false
+		return claimed
+	}
+	deviation = m.drift*driftDecay + deviation
+	if m.correcting && pk.Tick-m.correctedAt < correctionTimeout {
+		// The client has not yet processed the correction sent and is still
+		// sending movement based on its own position.
+		return m.pos
+	}
+	if !reportViolation(c, Violation{Type: ViolationMovement, Value: deviation, Limit: movementThreshold}) {
+		m.sync(claimed, vec32To64(pk.Delta), m.groundState(c))
+		return claimed
+	}
+	m.correcting, m.correctedAt, m.drift = true, pk.Tick, 0
+	s.writePacket(&packet.CorrectPlayerMovePrediction{
+		PredictionType: packet.PredictionTypePlayer,
+		Position:       vec64To32(m.pos.Add(mgl64.Vec3{0, 1.62})),
+		Delta:          vec64To32(m.vel),
+		Rotation:       mgl32.Vec2{pk.Pitch, pk.Yaw},
+		OnGround:       m.onGround,
+		Tick:           pk.Tick,
+	})
+	return m.pos
+}
+
+// sync resets the state of the simulator to the position, velocity and
+// ground state passed.
+func (m *movementSimulator) sync(pos, vel mgl64.Vec3, onGround bool) {
+	m.pos, m.vel, m.onGround = pos, vel, onGround
+	m.collidedHorizontally, m.correcting, m.drift = false, false, 0
+	m.knockBack.Store(nil)
+	m.synced.Store(true)
+}
+
+// groundState returns if the Controllable passed is on the ground. If the
+// Controllable does not implement ValidatedControllable, the ground state
+// predicted by the simulator is returned.
+func (m *movementSimulator) groundState(c Controllable) bool {
+	if vc, ok := c.(ValidatedControllable); ok {
+		return vc.OnGround()
+	}
+	return m.onGround
+}
+
+// exempt checks if the movement of the Controllable passed is exempt from
+// validation, for example because it is flying or gliding, or because it is
+// in a state that the simulator does not model, such as riding an entity.
+func (m *movementSimulator) exempt(pk *packet.PlayerAuthInput, s *Session, tx *world.Tx, c Controllable) bool {
+	if pk.InputData.Load(packet.InputFlagStartSpinAttack) {
+		m.spinAttack = true
+	} else if pk.InputData.Load(packet.InputFlagStopSpinAttack) {
+		m.spinAttack = false
+	}
+	if c.Dead() || c.Flying() || c.Gliding() || c.Swimming() || !c.GameMode().HasCollision() ||
+		s.changingDimension.Load() || m.spinAttack || pk.InputData.Load(packet.InputFlagClientPredictedVehicle) {
+		return true
+	}
+	// The box is grown to include the block below the Controllable and the
+	// blocks next to it, so that standing on a bed or sliding down the side of
+	// a honey block is exempt too.
+	box := c.H().Type().BBox(c).Translate(c.Position()).Grow(0.1)
+	return m.intersects(tx, box, unmodelled)
+}
+
+// unmodelled checks if the block passed affects movement in a way that the
+// movementSimulator does not model. Blocks that don't exist in this server
+// are matched by name, so that they are exempt when registered as custom
+// blocks.
+func unmodelled(b world.Block) bool {
+	switch b := b.(type) {
+	case block.Bed:
+		return true
+	case block.Water:
+		// The current of flowing water pushes entities in it.
+		return b.Depth < 8 || b.Falling
+	}
+	switch name, _ := b.EncodeBlock(); name {
+	case "minecraft:honey_block", "minecraft:powder_snow", "minecraft:bubble_column":
+		return true
+	}
+	return false
+}
+
+// simulate predicts the position and velocity of the Controllable after the
+// tick of the PlayerAuthInput packet passed.
+func (m *movementSimulator) simulate(pk *packet.PlayerAuthInput, tx *world.Tx, c Controllable) {
+	if vel := m.knockBack.Swap(nil); vel != nil {
+		m.vel = *vel
+	}
+	var (
+		flags     = pk.InputData
+		sprinting = (c.Sprinting() || flags.Load(packet.InputFlagStartSprinting)) && !flags.Load(packet.InputFlagStopSprinting)
+		sneaking  = flags.Load(packet.InputFlagSneaking)
+		jumping   = flags.Load(packet.InputFlagJumping) || flags.Load(packet.InputFlagStartJumping)
+		yaw       = mgl64.DegToRad(float64(pk.Yaw))
+
+		jumpBoost, levitation int
+		slowFalling           bool
+	)
+	for _, e := range c.Effects() {
+		switch e.Type() {
+		case effect.JumpBoost:
+			jumpBoost = e.Level()
+		case effect.Levitation:
+			levitation = e.Level()
+		case effect.SlowFalling:
+			slowFalling = true
+		}
+	}
+
+	box := c.H().Type().BBox(c)
+	liquid, lava := m.liquid(tx, box.Translate(m.pos))
+	climbing := m.climbing(tx)
+
+	if jumping {
+		if liquid {
+			m.vel[1] += 0.04
+		} else if m.onGround {
+			m.vel[1] = 0.42 + float64(jumpBoost)*0.1
+			if sprinting {
+				m.vel[0] -= math.Sin(yaw) * 0.2
+				m.vel[2] += math.Cos(yaw) * 0.2
+			}
+		}
+	}
+
+	friction, speed := 0.91, 0.02
+	switch {
+	case liquid:
+		friction = 0.8
+		if lava {
+			friction = 0.5
+		}
+	case m.onGround:
+		blockFriction := 0.6
+		if f, ok := tx.Block(cube.PosFromVec3(m.pos).Side(cube.FaceDown)).(block.Frictional); ok {
+			blockFriction = f.Friction()
+		}
+		friction = blockFriction * 0.91
+		speed = c.Speed() * (0.16277136 / (friction * friction * friction))
+		if sprinting && !c.Sprinting() {
+			// The Controllable starts sprinting this tick, but its speed is only
+			// updated once the input flags are handled.
+			speed *= 1.3
+		}
+	case sprinting:
+		speed = 0.026
+	}
+	m.moveRelative(pk.MoveVector, speed, yaw)
+	if !liquid {
+		if factor := m.speedFactor(tx, c); factor != 1 {
+			m.vel[0] *= factor
+			m.vel[2] *= factor
+		}
+	}
+
+	if climbing {
+		m.vel[0] = mgl64.Clamp(m.vel[0], -0.15, 0.15)
+		m.vel[2] = mgl64.Clamp(m.vel[2], -0.15, 0.15)
+		m.vel[1] = math.Max(m.vel[1], -0.15)
+		if sneaking && m.vel[1] < 0 {
+			m.vel[1] = 0
+		}
+	}
+
+	motion := m.vel
+	cobweb := m.intersects(tx, box.Translate(m.pos), func(b world.Block) bool {
+		_, ok := b.(block.Cobweb)
+		return ok
+	})
+	if cobweb {
+		motion = mgl64.Vec3{motion[0] * 0.25, motion[1] * 0.05, motion[2] * 0.25}
+	}
+	m.move(tx, box, motion, sneaking && !liquid && !climbing)
+	if cobweb {
+		m.vel = mgl64.Vec3{}
+	}
+
+	if climbing && (m.collidedHorizontally || jumping) {
+		m.vel[1] = 0.2
+	}
+
+	switch {
+	case liquid:
+		m.vel = m.vel.Mul(friction)
+		m.vel[1] -= 0.02
+		return
+	case levitation > 0:
+		m.vel[1] += (0.05*float64(levitation) - m.vel[1]) * 0.2
+	case slowFalling && m.vel[1] <= 0:
+		m.vel[1] -= 0.01
+	default:
+		m.vel[1] -= 0.08
+	}
+	m.vel[0] *= friction
+	m.vel[1] *= 0.98
+	m.vel[2] *= friction
+}
+
+// moveRelative accelerates the simulator in the direction of the move vector
+// passed, relative to the yaw in radians.
+func (m *movementSimulator) moveRelative(vec mgl32.Vec2, speed, yaw float64) {
+	strafe, forward := float64(vec.X())*0.98, float64(vec.Y())*0.98
+	f := strafe*strafe + forward*forward
+	if f < 1e-4 {
+		return
+	}
+	f = speed / math.Max(math.Sqrt(f), 1)
+	strafe, forward = strafe*f, forward*f
+
+	sin, cos := math.Sin(yaw), math.Cos(yaw)
+	m.vel[0] += strafe*cos - forward*sin
+	m.vel[2] += forward*cos + strafe*sin
+}
+
+// speedFactor returns the factor by which the horizontal velocity of the
+// Controllable is multiplied by the block it is standing on, such as soul
+// sand. Like vanilla, the block at the feet is used, or the block below if the
+// block at the feet does not slow down movement.
+func (m *movementSimulator) speedFactor(tx *world.Tx, c Controllable) float64 {
+	factor := blockSpeedFactor(tx.Block(cube.PosFromVec3(m.pos)))
+	if factor == 1 {
+		factor = blockSpeedFactor(tx.Block(cube.PosFromVec3(m.pos.Sub(mgl64.Vec3{0, 0.5001}))))
+	}
+	if factor != 1 {
+		if a, ok := c.(interface{ Armour() *inventory.Armour }); ok {
+			if _, ok := a.Armour().Boots().Enchantment(enchantment.SoulSpeed); ok {
+				return 1
+			}
+		}
+	}
+	return factor
+}
+
+// blockSpeedFactor returns the factor by which the horizontal velocity of an
+// entity is multiplied when standing on the block passed.
+func blockSpeedFactor(b world.Block) float64 {
+	switch b.(type) {
+	case block.SoulSand:
+		return 0.4
+	}
+	return 1
+}
+
+// move moves the bounding box passed by the motion passed, colliding with the
+// blocks around it and stepping up blocks if on the ground. If sneaking, the
+// motion is limited so that the box does not move off the edge of a block.
+func (m *movementSimulator) move(tx *world.Tx, box cube.BBox, motion mgl64.Vec3, sneaking bool) {
+	start := box.Translate(m.pos)
+	boxes := blockBBoxesAround(tx, start.Extend(motion).Extend(mgl64.Vec3{0, stepHeight}).Extend(mgl64.Vec3{0, -stepHeight}))
+	if sneaking && m.onGround && motion[1] <= 0 {
+		motion = clipEdges(boxes, start, motion)
+	}
+	delta, _ := collide(boxes, start, motion)
+
+	horizontal := !mgl64.FloatEqual(delta[0], motion[0]) || !mgl64.FloatEqual(delta[2], motion[2])
+	if horizontal && (m.onGround || (motion[1] < 0 && !mgl64.FloatEqual(delta[1], motion[1]))) {
+		// Try stepping up onto the block collided with and keep the result if
+		// it moves the player further horizontally.
+		up, stepped := collide(boxes, start, mgl64.Vec3{motion[0], stepHeight, motion[2]})
+		down, _ := collide(boxes, stepped, mgl64.Vec3{0, -stepHeight + motion[1]})
+		if up = up.Add(down); up[0]*up[0]+up[2]*up[2] > delta[0]*delta[0]+delta[2]*delta[2]+1e-7 {
+			delta = up
+		}
+	}
+
+	m.collidedHorizontally = !mgl64.FloatEqual(delta[0], motion[0]) || !mgl64.FloatEqual(delta[2], motion[2])
+	collidedVertically := !mgl64.FloatEqual(delta[1], motion[1])
+	m.onGround = collidedVertically && motion[1] < 0
+
+	if !mgl64.FloatEqual(delta[0], motion[0]) {
+		m.vel[0] = 0
+	}
+	if !mgl64.FloatEqual(delta[2], motion[2]) {
+		m.vel[2] = 0
+	}
+	if collidedVertically {
+		m.vel[1] = 0
+		if _, ok := tx.Block(cube.PosFromVec3(m.pos.Add(delta)).Side(cube.FaceDown)).(block.Slime); ok && m.onGround && !sneaking && motion[1] < 0 {
+			m.vel[1] = -motion[1]
+		}
+	}
+	m.pos = m.pos.Add(delta)
+}
+
+// liquid checks if the bounding box passed intersects with a liquid and if
+// that liquid is lava.
+func (m *movementSimulator) liquid(tx *world.Tx, box cube.BBox) (liquid, lava bool) {
+	liquid = m.intersects(tx, box, func(b world.Block) bool {
+		if _, ok := b.(world.Liquid); !ok {
+			return false
+		}
+		if _, ok := b.(block.Lava); ok {
+			lava = true
+		}
+		return true
+	})
+	return liquid, lava
+}
+
+// climbing checks if the simulator is currently in a block that can be
+// climbed, such as a ladder or vines.
+func (m *movementSimulator) climbing(tx *world.Tx) bool {
+	switch tx.Block(cube.PosFromVec3(m.pos)).(type) {
+	case block.Ladder, block.Vines:
+		return true
+	}
+	return false
+}
+
+// intersects checks if any of the blocks intersecting with the bounding box
+// passed satisfy the function passed. Liquids are checked in addition to the
+// block at each position.
+func (m *movementSimulator) intersects(tx *world.Tx, box cube.BBox, f func(b world.Block) bool) bool {
+	box = box.Grow(-0.001)
+	minPos, maxPos := cube.PosFromVec3(box.Min()), cube.PosFromVec3(box.Max())
+	for x := minPos[0]; x <= maxPos[0]; x++ {
+		for y := minPos[1]; y <= maxPos[1]; y++ {
+			for z := minPos[2]; z <= maxPos[2]; z++ {
+				pos := cube.Pos{x, y, z}
+				if f(tx.Block(pos)) {
+					return true
+				}
+				if l, ok := tx.Liquid(pos); ok && f(l) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// edgeStep is the distance in blocks by which the motion of a sneaking player
+// is reduced at a time until it no longer moves the player off an edge.
+const edgeStep = 0.05
+
+// clipEdges reduces the horizontal motion passed so that the box passed, which
+// is standing on the ground, keeps standing on one of the boxes passed within
+// stepHeight below it after moving, similar to vanilla clients preventing
+// sneaking players from falling off the edge of a block.
+func clipEdges(boxes []cube.BBox, box cube.BBox, motion mgl64.Vec3) mgl64.Vec3 {
+	supported := func(dx, dz float64) bool {
+		moved := box.Translate(mgl64.Vec3{dx, -stepHeight, dz})
+		for _, b := range boxes {
+			if b.IntersectsWith(moved) {
+				return true
+			}
+		}
+		return false
+	}
+	approach := func(v float64) float64 {
+		if math.Abs(v) < edgeStep {
+			return 0
+		}
+		return v - math.Copysign(edgeStep, v)
+	}
+	dx, dz := motion[0], motion[2]
+	for dx != 0 && !supported(dx, 0) {
+		dx = approach(dx)
+	}
+	for dz != 0 && !supported(0, dz) {
+		dz = approach(dz)
+	}
+	for dx != 0 && dz != 0 && !supported(dx, dz) {
+		dx, dz = approach(dx), approach(dz)
+	}
+	return mgl64.Vec3{dx, motion[1], dz}
+}
+
+// collide moves the bounding box passed by the motion passed, first on the Y
+// axis, then on the X axis and finally on the Z axis, while colliding with the
+// boxes passed. The distance actually moved and the moved box are returned.
+func collide(boxes []cube.BBox, box cube.BBox, motion mgl64.Vec3) (mgl64.Vec3, cube.BBox) {
+	dx, dy, dz := motion[0], motion[1], motion[2]
+	for _, b := range boxes {
+		dy = box.YOffset(b, dy)
+	}
+	box = box.Translate(mgl64.Vec3{0, dy})
+	for _, b := range boxes {
+		dx = box.XOffset(b, dx)
+	}
+	box = box.Translate(mgl64.Vec3{dx})
+	for _, b := range boxes {
+		dz = box.ZOffset(b, dz)
+	}
+	box = box.Translate(mgl64.Vec3{0, 0, dz})
+	return mgl64.Vec3{dx, dy, dz}, box
+}
+
+// blockBBoxesAround returns the bounding boxes of all blocks that intersect
+// with the box passed, translated to the position of the block.
+func blockBBoxesAround(tx *world.Tx, box cube.BBox) []cube.BBox {
+	grown := box.Grow(0.25)
+	minPos, maxPos := cube.PosFromVec3(grown.Min()), cube.PosFromVec3(grown.Max())
+
+	var boxes []cube.BBox
+	for x := minPos[0]; x <= maxPos[0]; x++ {
+		for y := minPos[1]; y <= maxPos[1]; y++ {
+			for z := minPos[2]; z <= maxPos[2]; z++ {
+				pos := cube.Pos{x, y, z}
+				for _, b := range tx.Block(pos).Model().BBox(pos, tx) {
+					boxes = append(boxes, b.Translate(pos.Vec3()))
+				}
+			}
+		}
+	}
+	return boxes
+}
@@ -0,0 +1,193 @@
+package session
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/df-mc/dragonfly/server/block"
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/entity/effect"
+	"github.com/df-mc/dragonfly/server/world"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/go-gl/mathgl/mgl64"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+func TestMovementSimulatorFlatGround(t *testing.T) {
+	movementTest(t, func(tx *world.Tx, c Controllable) {
+		floor(tx, block.Stone{})
+		m := newTestSimulator()
+		for range 40 {
+			m.simulate(movementInput(mgl32.Vec2{0, 1}), tx, c)
+		}
+		if !m.onGround || m.pos[1] != 1 {
+			t.Fatalf("simulate() ended at y = %v, on ground %v, want y = 1 on ground", m.pos[1], m.onGround)
+		}
+		// The velocity of a walking player with a movement speed of 0.1 converges
+		// to roughly 0.2158 blocks per tick.
+		before := m.pos
+		m.simulate(movementInput(mgl32.Vec2{0, 1}), tx, c)
+		if moved := m.pos.Sub(before); math.Abs(moved[2]-0.2158) > 0.005 || math.Abs(moved[0]) > 1e-9 {
+			t.Fatalf("simulate() moved %v, want about 0.2158 on the z axis", moved)
+		}
+	})
+}
+
+func TestMovementSimulatorJump(t *testing.T) {
+	movementTest(t, func(tx *world.Tx, c Controllable) {
+		floor(tx, block.Stone{})
+		m := newTestSimulator()
+		m.simulate(movementInput(mgl32.Vec2{}, packet.InputFlagJumping), tx, c)
+		peak := m.pos[1]
+		for range 20 {
+			m.simulate(movementInput(mgl32.Vec2{}), tx, c)
+			peak = max(peak, m.pos[1])
+		}
+		if height := peak - 1; math.Abs(height-1.2522) > 0.01 {
+			t.Fatalf("simulate() jumped %v blocks high, want about 1.2522", height)
+		}
+		if !m.onGround || m.pos[1] != 1 {
+			t.Fatalf("simulate() ended at y = %v, on ground %v, want y = 1 on ground", m.pos[1], m.onGround)
+		}
+	})
+}
+
+func TestMovementSimulatorStep(t *testing.T) {
+	t.Run("slab", func(t *testing.T) {
+		movementTest(t, func(tx *world.Tx, c Controllable) {
+			floor(tx, block.Stone{})
+			wall(tx, block.Slab{Block: block.Stone{}})
+			m := newTestSimulator()
+			for range 20 {
+				m.simulate(movementInput(mgl32.Vec2{0, 1}), tx, c)
+			}
+			if m.pos[1] != 1.5 || m.pos[2] < 3 {
+				t.Fatalf("simulate() ended at %v, want on top of the slab", m.pos)
+			}
+		})
+	})
+	t.Run("full block", func(t *testing.T) {
+		movementTest(t, func(tx *world.Tx, c Controllable) {
+			floor(tx, block.Stone{})
+			wall(tx, block.Stone{})
+			m := newTestSimulator()
+			for range 20 {
+				m.simulate(movementInput(mgl32.Vec2{0, 1}), tx, c)
+			}
+			if m.pos[1] != 1 || m.pos[2] > 2.7+1e-9 || !m.collidedHorizontally {
+				t.Fatalf("simulate() ended at %v, want against the block", m.pos)
+			}
+		})
+	})
+}
+
+func TestMovementSimulatorSneakEdge(t *testing.T) {
+	movementTest(t, func(tx *world.Tx, c Controllable) {
+		for x := -4; x <= 4; x++ {
+			for z := -4; z <= 0; z++ {
+				tx.SetBlock(cube.Pos{x, 0, z}, block.Stone{}, nil)
+			}
+		}
+		m := newTestSimulator()
+		for range 40 {
+			m.simulate(movementInput(mgl32.Vec2{0, 1}, packet.InputFlagSneaking), tx, c)
+		}
+		if !m.onGround || m.pos[1] != 1 || m.pos[2] > 1.3 {
+			t.Fatalf("simulate() ended at %v, want on the edge of the block", m.pos)
+		}
+	})
+}
+
+func TestMovementSimulatorSoulSand(t *testing.T) {
+	var stone, soulSand float64
+	for _, f := range []struct {
+		b     world.Block
+		moved *float64
+	}{{block.Stone{}, &stone}, {block.SoulSand{}, &soulSand}} {
+		movementTest(t, func(tx *world.Tx, c Controllable) {
+			floor(tx, f.b)
+			m := newTestSimulator()
+			for range 10 {
+				m.simulate(movementInput(mgl32.Vec2{0, 1}), tx, c)
+			}
+			*f.moved = m.pos[2] - 0.5
+		})
+	}
+	if soulSand >= stone*0.5 {
+		t.Fatalf("simulate() moved %v on soul sand and %v on stone, want slower on soul sand", soulSand, stone)
+	}
+}
+
+// movementTest runs the function passed in a transaction of a new world with
+// a Controllable to simulate the movement of.
+func movementTest(t *testing.T, f func(tx *world.Tx, c Controllable)) {
+	t.Helper()
+	w := world.Config{}.New()
+	t.Cleanup(func() { _ = w.Close() })
+	c := movementTestControllable{h: world.NewEntity(movementTestEntityType{}, movementTestEntityConfig{})}
+	if err := w.Do(func(tx *world.Tx) { f(tx, c) }).Wait(context.Background()); err != nil {
+		t.Fatalf("run transaction: %v", err)
+	}
+}
+
+// newTestSimulator returns a movementSimulator standing on the ground at the
+// middle of the block at 0, 1, 0.
+func newTestSimulator() *movementSimulator {
+	m := &movementSimulator{}
+	m.sync(mgl64.Vec3{0.5, 1, 0.5}, mgl64.Vec3{}, true)
+	return m
+}
+
+// movementInput returns a PlayerAuthInput packet with the move vector and
+// input flags passed, looking along the z axis.
+func movementInput(move mgl32.Vec2, flags ...int) *packet.PlayerAuthInput {
+	pk := &packet.PlayerAuthInput{MoveVector: move, InputData: protocol.NewInputFlags(packet.InputFlagCount)}
+	for _, flag := range flags {
+		pk.InputData.Set(flag)
+	}
+	return pk
+}
+
+// floor fills the blocks around 0, 0, 0 with the block passed.
+func floor(tx *world.Tx, b world.Block) {
+	for x := -4; x <= 4; x++ {
+		for z := -4; z <= 8; z++ {
+			tx.SetBlock(cube.Pos{x, 0, z}, b, nil)
+		}
+	}
+}
+
+// wall places a row of the block passed on the floor at z = 3.
+func wall(tx *world.Tx, b world.Block) {
+	for x := -4; x <= 4; x++ {
+		tx.SetBlock(cube.Pos{x, 1, 3}, b, nil)
+	}
+}
+
+type movementTestControllable struct {
+	Controllable
+	h *world.EntityHandle
+}
+
+func (c movementTestControllable) H() *world.EntityHandle { return c.h }
+func (movementTestControllable) Sprinting() bool          { return false }
+func (movementTestControllable) Effects() []effect.Effect { return nil }
+func (movementTestControllable) Speed() float64           { return 0.1 }
+
+type movementTestEntityConfig struct{}
+
+func (movementTestEntityConfig) Apply(*world.EntityData) {}
+
+type movementTestEntityType struct{}
+
+func (movementTestEntityType) Open(*world.Tx, *world.EntityHandle, *world.EntityData) world.Entity {
+	return nil
+}
+func (movementTestEntityType) EncodeEntity() string { return "dragonfly:movement_test" }
+func (movementTestEntityType) BBox(world.Entity) cube.BBox {
+	return cube.Box(-0.3, 0, -0.3, 0.3, 1.8, 0.3)
+}
+func (movementTestEntityType) DecodeNBT(map[string]any, *world.EntityData) {}
+func (movementTestEntityType) EncodeNBT(*world.EntityData) map[string]any  { return nil }
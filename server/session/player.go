@@ -28,7 +28,6 @@ import (
 	"github.com/df-mc/dragonfly/server/world/sound"
 	"github.com/go-gl/mathgl/mgl32"
 	"github.com/go-gl/mathgl/mgl64"
-	"github.com/google/uuid"
 	"github.com/sandertv/gophertunnel/minecraft/protocol"
 	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
 )
@@ -70,6 +69,9 @@ func (s *Session) closeCurrentContainer(tx *world.Tx, clientRequested bool) {
 	if !s.closeWindow(clientRequested) {
 		return
 	}
+	if s.closeMenu(tx) {
+		return
+	}
 
 	pos := *s.openedPos.Load()
 	b := tx.Block(pos)
@@ -130,16 +132,45 @@ func (s *Session) sendRecipes() {
 		switch i := i.(type) {
 		case recipe.Shapeless:
 			shapelessRecipes = append(shapelessRecipes, protocol.ShapelessRecipe{
-				RecipeID:        uuid.New().String(),
+				RecipeID:        recipe.ID(i),
 				Priority:        int32(i.Priority()),
 				Input:           stacksToIngredientItems(s.br, i.Input()),
 				Output:          stacksToRecipeStacks(s.br, i.Output()),
 				Block:           i.Block(),
 				RecipeNetworkID: networkID,
 			})
+		case recipe.Stonecutter:
+			shapelessRecipes = append(shapelessRecipes, protocol.ShapelessRecipe{
+				RecipeID:        recipe.ID(i),
+				Priority:        int32(i.Priority()),
+				Input:           stacksToIngredientItems(s.br, i.Input()),
+				Output:          stacksToRecipeStacks(s.br, i.Output()),
+				Block:           i.Block(),
+				RecipeNetworkID: networkID,
+			})
+		case recipe.Loom:
+			// The client has no loom recipes, so the recipe is sent as a shapeless recipe showing a banner with the
+			// pattern, so that it is listed in the recipe book.
+			pattern, ok := block.BannerPatternByID(i.Pattern())
+			if !ok {
+				continue
+			}
+			input := append([]recipe.Item{
+				item.NewStack(block.Banner{Colour: item.ColourWhite()}, 1),
+				item.NewStack(item.Dye{Colour: item.ColourBlack()}, 1),
+			}, i.Input()...)
+			output := item.NewStack(block.Banner{Colour: item.ColourWhite(), Patterns: []block.BannerPatternLayer{{Type: pattern, Colour: item.ColourBlack()}}}, 1)
+			shapelessRecipes = append(shapelessRecipes, protocol.ShapelessRecipe{
+				RecipeID:        recipe.ID(i),
+				Priority:        int32(i.Priority()),
+				Input:           stacksToIngredientItems(s.br, input),
+				Output:          stacksToRecipeStacks(s.br, []item.Stack{output}),
+				Block:           i.Block(),
+				RecipeNetworkID: networkID,
+			})
 		case recipe.UserDataShapeless:
 			userDataShapelessRecipes = append(userDataShapelessRecipes, protocol.UserDataShapelessRecipe{ShapelessRecipe: protocol.ShapelessRecipe{
-				RecipeID:        uuid.New().String(),
+				RecipeID:        recipe.ID(i),
 				Priority:        int32(i.Priority()),
 				Input:           stacksToIngredientItems(s.br, i.Input()),
 				Output:          stacksToRecipeStacks(s.br, i.Output()),
@@ -153,7 +184,7 @@ func (s *Session) sendRecipes() {
 			})
 		case recipe.Shaped:
 			shapedRecipes = append(shapedRecipes, protocol.ShapedRecipe{
-				RecipeID:        uuid.New().String(),
+				RecipeID:        recipe.ID(i),
 				Priority:        int32(i.Priority()),
 				Width:           int32(i.Shape().Width()),
 				Height:          int32(i.Shape().Height()),
@@ -166,7 +197,7 @@ func (s *Session) sendRecipes() {
 		case recipe.SmithingTransform:
 			input, output := stacksToIngredientItems(s.br, i.Input()), stacksToRecipeStacks(s.br, i.Output())
 			smithingTransformRecipes = append(smithingTransformRecipes, protocol.SmithingTransformRecipe{
-				RecipeID:        uuid.New().String(),
+				RecipeID:        recipe.ID(i),
 				Base:            input[0],
 				Addition:        input[1],
 				Template:        input[2],
@@ -177,7 +208,7 @@ func (s *Session) sendRecipes() {
 		case recipe.SmithingTrim:
 			input := stacksToIngredientItems(s.br, i.Input())
 			smithingTrimRecipes = append(smithingTrimRecipes, protocol.SmithingTrimRecipe{
-				RecipeID:        uuid.New().String(),
+				RecipeID:        recipe.ID(i),
 				Base:            input[0],
 				Addition:        input[1],
 				Template:        input[2],
@@ -223,6 +254,56 @@ func (s *Session) sendRecipes() {
 	})
 }
 
+// SendRecipeUnlocks sends the recipes passed to the client as unlocked, so that they show up in its recipe book.
+// If the initial bool is true, the recipes passed are the recipes that were unlocked before the player joined.
+func (s *Session) SendRecipeUnlocks(recipes []recipe.Recipe, initial bool) {
+	if s == Nop {
+		return
+	}
+	ids := make([]string, 0, len(recipes))
+	for _, r := range recipes {
+		id := recipe.ID(r)
+		s.unlockedRecipes[id] = struct{}{}
+		ids = append(ids, id)
+	}
+	if !s.conf.RecipeUnlocking {
+		return
+	}
+	typ := uint32(packet.UnlockedRecipesTypeNewlyUnlocked)
+	if initial {
+		typ = packet.UnlockedRecipesTypeInitiallyUnlocked
+	}
+	s.writePacket(&packet.UnlockedRecipes{UnlockType: typ, Recipes: ids})
+}
+
+// SendRecipeLocks removes the recipes passed from the recipes unlocked for the client, so that they no longer show
+// up in its recipe book.
+func (s *Session) SendRecipeLocks(recipes []recipe.Recipe) {
+	if s == Nop {
+		return
+	}
+	ids := make([]string, 0, len(recipes))
+	for _, r := range recipes {
+		id := recipe.ID(r)
+		delete(s.unlockedRecipes, id)
+		ids = append(ids, id)
+	}
+	if !s.conf.RecipeUnlocking {
+		return
+	}
+	s.writePacket(&packet.UnlockedRecipes{UnlockType: packet.UnlockedRecipesTypeRemoveUnlocked, Recipes: ids})
+}
+
+// recipeLocked checks if the recipe passed may not be crafted by the client because recipe unlocking is enabled and
+// the recipe was not yet unlocked.
+func (s *Session) recipeLocked(r recipe.Recipe) bool {
+	if !s.conf.RecipeUnlocking {
+		return false
+	}
+	_, ok := s.unlockedRecipes[recipe.ID(r)]
+	return !ok
+}
+
 // sendArmourTrimData sends the armour trim data.
 func (s *Session) sendArmourTrimData() {
 	var trimPatterns []protocol.TrimPattern
@@ -264,6 +345,9 @@ func (s *Session) sendInv(inv *inventory.Inventory, windowID uint32) {
 		pk.Content = append(pk.Content, instanceFromItem(s.br, i))
 	}
 	s.writePacket(pk)
+	for _, i := range inv.Slots() {
+		s.sendBundleContents(i)
+	}
 }
 
 // sendItem sends the item stack passed to the client with the window ID and slot passed.
@@ -273,6 +357,7 @@ func (s *Session) sendItem(item item.Stack, slot int, windowID uint32) {
 		Slot:     uint32(slot),
 		NewItem:  instanceFromItem(s.br, item),
 	})
+	s.sendBundleContents(item)
 }
 
 const (
@@ -283,6 +368,25 @@ const (
 	craftingResult          = 50
 )
 
+// recipeUnlocker is implemented by Controllables that unlock recipes, such as
+// the player.Player.
+type recipeUnlocker interface {
+	UnlockRecipes(recipes ...recipe.Recipe)
+}
+
+// unlockIngredientRecipes unlocks the recipes that have the item of after as
+// ingredient if the Controllable passed implements recipeUnlocker and the item
+// was not present in the slot before, so that recipes are unlocked regardless
+// of how the item ended up in the inventory.
+func unlockIngredientRecipes(c Controllable, before, after item.Stack) {
+	if after.Empty() || (!before.Empty() && before.Comparable(after)) {
+		return
+	}
+	if u, ok := c.(recipeUnlocker); ok {
+		u.UnlockRecipes(recipe.ByIngredient(after.Item())...)
+	}
+}
+
 // smelter is an interface representing a block used to smelt items.
 type smelter interface {
 	// ResetExperience resets the collected experience of the smelter, and returns the amount of experience that was reset.
@@ -308,6 +412,14 @@ func (s *Session) invByID(id int32, tx *world.Tx) (*inventory.Inventory, bool) {
 		if !s.containerOpened.Load() {
 			return nil, false
 		}
+		if s.openedMenu.Load() != nil {
+			// Menus only have a single inventory, and the actual block at the position of the menu should not be
+			// used to look up inventories.
+			if id == protocol.ContainerLevelEntity {
+				return s.openedWindow.Load(), true
+			}
+			return nil, false
+		}
 		switch id {
 		case protocol.ContainerLevelEntity:
 			return s.openedWindow.Load(), true
@@ -362,15 +474,14 @@ func (s *Session) invByID(id int32, tx *world.Tx) (*inventory.Inventory, bool) {
 }
 
 // Disconnect disconnects the client and ultimately closes the session. If the message passed is non-empty,
-// it will be shown to the client.
+// it will be shown to the client. Disconnect blocks until the Disconnect packet and all packets sent before it were
+// written to the connection.
 func (s *Session) Disconnect(message string) {
-	if s != Nop {
-		_ = s.conn.WritePacket(&packet.Disconnect{
-			HideDisconnectionScreen: message == "",
-			Message:                 message,
-		})
-		_ = s.conn.Flush()
-	}
+	s.writePacket(&packet.Disconnect{
+		HideDisconnectionScreen: message == "",
+		Message:                 message,
+	})
+	s.flush()
 }
 
 // SendSpeed sends the speed of the player in an UpdateAttributes packet, so that it is updated client-side.
@@ -652,7 +763,8 @@ func (s *Session) HandleInventories(tx *world.Tx, c Controllable, inv, offHand,
 }
 
 func (s *Session) broadcastInvFunc(tx *world.Tx, c Controllable) inventory.SlotFunc {
-	return func(slot int, _, after item.Stack) {
+	return func(slot int, before, after item.Stack) {
+		unlockIngredientRecipes(c, before, after)
 		if slot == int(*s.heldSlot) {
 			for _, viewer := range tx.Viewers(c.Position()) {
 				viewer.ViewEntityItems(c)
@@ -675,7 +787,8 @@ func (s *Session) broadcastEnderChestFunc(tx *world.Tx, _ Controllable) inventor
 }
 
 func (s *Session) broadcastOffHandFunc(tx *world.Tx, c Controllable) inventory.SlotFunc {
-	return func(slot int, _, after item.Stack) {
+	return func(slot int, before, after item.Stack) {
+		unlockIngredientRecipes(c, before, after)
 		for _, viewer := range tx.Viewers(c.Position()) {
 			viewer.ViewEntityItems(c)
 		}
@@ -685,6 +798,7 @@ func (s *Session) broadcastOffHandFunc(tx *world.Tx, c Controllable) inventory.S
 				WindowID: protocol.WindowIDOffHand,
 				Content:  []protocol.ItemInstance{instanceFromItem(s.br, i)},
 			})
+			s.sendBundleContents(i)
 		}
 	}
 }
@@ -1010,6 +1124,12 @@ func stackFromItem(br world.BlockRegistry, it item.Stack) protocol.ItemStack {
 
 	rid, meta, _ := world.ItemRuntimeID(it.Item())
 
+	nbtData := item.WriteNBT(it, false)
+	if b, ok := it.Item().(item.Bundle); ok {
+		// The bundle ID links the item to the dynamic container holding its contents.
+		nbtData["bundle_id"] = int32(b.ID())
+	}
+
 	return protocol.ItemStack{
 		ItemType: protocol.ItemType{
 			NetworkID:     rid,
@@ -1017,7 +1137,7 @@ func stackFromItem(br world.BlockRegistry, it item.Stack) protocol.ItemStack {
 		},
 		Count:          uint16(it.Count()),
 		BlockRuntimeID: int32(blockRuntimeID),
-		NBTData:        item.WriteNBT(it, false),
+		NBTData:        nbtData,
 	}
 }
 
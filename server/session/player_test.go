@@ -0,0 +1,48 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/df-mc/dragonfly/server/item"
+	"github.com/df-mc/dragonfly/server/item/recipe"
+)
+
+func TestUnlockIngredientRecipes(t *testing.T) {
+	stick := item.NewStack(item.Stick{}, 1)
+	recipe.Register(recipe.NewShapeless([]recipe.Item{stick, item.NewStack(item.Flint{}, 1)}, item.NewStack(item.Arrow{}, 1), "crafting_table"))
+	want := len(recipe.ByIngredient(item.Stick{}))
+
+	tests := []struct {
+		name          string
+		before, after item.Stack
+		want          int
+	}{
+		{name: "added", before: item.Stack{}, after: stick, want: want},
+		{name: "replaced", before: item.NewStack(item.Apple{}, 1), after: stick, want: want},
+		{name: "grown", before: stick, after: stick.Grow(1), want: 0},
+		{name: "removed", before: stick, after: item.Stack{}, want: 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := &recipeTestControllable{}
+			unlockIngredientRecipes(c, test.before, test.after)
+			if len(c.unlocked) != test.want {
+				t.Fatalf("len(unlocked) = %v, want %v", len(c.unlocked), test.want)
+			}
+		})
+	}
+
+	t.Run("no unlocker", func(t *testing.T) {
+		// Controllables that do not unlock recipes are left alone.
+		unlockIngredientRecipes(breakTestControllable{}, item.Stack{}, stick)
+	})
+}
+
+type recipeTestControllable struct {
+	Controllable
+	unlocked []recipe.Recipe
+}
+
+func (c *recipeTestControllable) UnlockRecipes(recipes ...recipe.Recipe) {
+	c.unlocked = append(c.unlocked, recipes...)
+}
@@ -0,0 +1,286 @@
+package session
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"github.com/sandertv/gophertunnel/minecraft/text"
+)
+
+// PacketCategory is a category of packets sent by a client that may be
+// limited using a RateLimit.
+type PacketCategory int
+
+const (
+	// PacketCategoryAll is the category of all packets sent by a client. A
+	// packet in any other category is also in PacketCategoryAll.
+	PacketCategoryAll PacketCategory = iota
+	// PacketCategoryChat is the category of chat messages.
+	PacketCategoryChat
+	// PacketCategoryCommand is the category of command executions.
+	PacketCategoryCommand
+	// PacketCategoryForm is the category of form submissions.
+	PacketCategoryForm
+	// PacketCategoryItemStackRequest is the category of item stack requests,
+	// which clients send to move items between inventories.
+	PacketCategoryItemStackRequest
+)
+
+// String returns the name of the PacketCategory.
+func (c PacketCategory) String() string {
+	switch c {
+	case PacketCategoryAll:
+		return "packets"
+	case PacketCategoryChat:
+		return "chat messages"
+	case PacketCategoryCommand:
+		return "commands"
+	case PacketCategoryForm:
+		return "form submissions"
+	case PacketCategoryItemStackRequest:
+		return "item stack requests"
+	}
+	return "unknown"
+}
+
+// RateLimitAction is an action taken against a client that exceeded a
+// RateLimit. Actions escalate with the number of packets dropped in a row.
+type RateLimitAction int
+
+const (
+	// RateLimitDrop drops the packet that exceeded the RateLimit.
+	RateLimitDrop RateLimitAction = iota
+	// RateLimitWarn drops the packet and warns the client with a message.
+	RateLimitWarn
+	// RateLimitKick drops the packet and disconnects the client.
+	RateLimitKick
+)
+
+// String returns the name of the RateLimitAction.
+func (a RateLimitAction) String() string {
+	switch a {
+	case RateLimitDrop:
+		return "drop"
+	case RateLimitWarn:
+		return "warn"
+	case RateLimitKick:
+		return "kick"
+	}
+	return "unknown"
+}
+
+// RateLimit limits the rate at which a client may send packets of a
+// PacketCategory using a token bucket: Every packet takes a token from the
+// bucket, which is refilled at Rate tokens per second up to Burst tokens.
+// Packets sent while the bucket is empty are dropped.
+type RateLimit struct {
+	// Rate is the number of packets per second that a client may send on
+	// average.
+	Rate float64
+	// Burst is the maximum number of packets that a client may send at once.
+	// If 0, Burst is Rate rounded up.
+	Burst int
+	// WarnAfter is the number of packets dropped in a row after which the
+	// client is warned. Packets are dropped in a row if no more than a second
+	// passes between them. If 0, the client is never warned.
+	WarnAfter int
+	// KickAfter is the number of packets dropped in a row after which the
+	// client is disconnected. If 0, the client is never disconnected.
+	KickAfter int
+}
+
+// tokenBucket is the state of a RateLimit for a single client.
+type tokenBucket struct {
+	limit          RateLimit
+	tokens         float64
+	last, lastDrop time.Time
+	dropped        int
+}
+
+// take takes a token from the tokenBucket. If the tokenBucket is empty, the
+// RateLimitAction to take is returned, along with false. report is true if
+// the RateLimitAction should be reported to the Controllable: This is the
+// case for the first packet dropped in a row and whenever the client is
+// warned or disconnected, so that a client flooding packets does not cause a
+// report for every packet.
+func (b *tokenBucket) take(now time.Time) (action RateLimitAction, report, ok bool) {
+	burst := float64(b.limit.Burst)
+	if burst <= 0 {
+		burst = math.Ceil(b.limit.Rate)
+	}
+	if b.last.IsZero() {
+		b.tokens = burst
+	} else {
+		b.tokens = min(burst, b.tokens+now.Sub(b.last).Seconds()*b.limit.Rate)
+	}
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return RateLimitDrop, false, true
+	}
+
+	if now.Sub(b.lastDrop) > time.Second {
+		b.dropped = 0
+	}
+	b.lastDrop = now
+	b.dropped++
+	switch {
+	case b.limit.KickAfter > 0 && b.dropped >= b.limit.KickAfter:
+		return RateLimitKick, true, false
+	case b.limit.WarnAfter > 0 && b.dropped == b.limit.WarnAfter:
+		return RateLimitWarn, true, false
+	}
+	return RateLimitDrop, b.dropped == 1, false
+}
+
+// rateLimiter applies the RateLimits of a Session to the packets sent by its
+// client. It is only used on the goroutine that handles packets.
+type rateLimiter struct {
+	buckets map[PacketCategory]*tokenBucket
+}
+
+// newRateLimiter returns a rateLimiter for the RateLimits passed, or nil if
+// no RateLimits are passed.
+func newRateLimiter(limits map[PacketCategory]RateLimit) *rateLimiter {
+	if len(limits) == 0 {
+		return nil
+	}
+	l := &rateLimiter{buckets: make(map[PacketCategory]*tokenBucket, len(limits))}
+	for category, limit := range limits {
+		if limit.Rate > 0 {
+			l.buckets[category] = &tokenBucket{limit: limit}
+		}
+	}
+	return l
+}
+
+// rateLimitExceeded is a RateLimit exceeded by a packet.
+type rateLimitExceeded struct {
+	category PacketCategory
+	action   RateLimitAction
+	report   bool
+}
+
+// limit takes tokens for the packet passed from the tokenBuckets of its
+// PacketCategories. It is called before the packet is handled, without a
+// transaction. The RateLimits exceeded by the packet are returned. limit
+// returns nil if l is nil.
+func (l *rateLimiter) limit(pk packet.Packet) []rateLimitExceeded {
+	if l == nil {
+		return nil
+	}
+	now := time.Now()
+	var exceeded []rateLimitExceeded
+	take := func(category PacketCategory) {
+		if b, ok := l.buckets[category]; ok {
+			if action, report, ok := b.take(now); !ok {
+				exceeded = append(exceeded, rateLimitExceeded{category: category, action: action, report: report})
+			}
+		}
+	}
+	take(PacketCategoryAll)
+	if category, ok := packetCategory(pk); ok {
+		take(category)
+	}
+	return exceeded
+}
+
+// reported checks if any of the RateLimits exceeded passed must be reported
+// to the Controllable, which requires a transaction.
+func reported(exceeded []rateLimitExceeded) bool {
+	for _, e := range exceeded {
+		if e.report {
+			return true
+		}
+	}
+	return false
+}
+
+// report reports the RateLimits exceeded passed to the Controllable and
+// takes the RateLimitActions that were not cancelled. The packet to handle is
+// returned, which is nil if the packet was dropped. An error is returned if
+// the client should be disconnected.
+func (l *rateLimiter) report(exceeded []rateLimitExceeded, pk packet.Packet, s *Session, c Controllable) (packet.Packet, error) {
+	remaining := exceeded[:0]
+	for _, e := range exceeded {
+		if e.report {
+			var ok bool
+			if e.action, ok = reportRateLimit(c, e.category, e.action); !ok {
+				continue
+			}
+		}
+		switch e.action {
+		case RateLimitWarn:
+			s.SendMessage(text.Colourf("<red>You are sending %v too fast.</red>", e.category))
+		case RateLimitKick:
+			s.Disconnect(fmt.Sprintf("Kicked for sending %v too fast.", e.category))
+			return nil, fmt.Errorf("rate limit of %v exceeded", e.category)
+		}
+		remaining = append(remaining, e)
+	}
+	return l.drop(remaining, pk, s), nil
+}
+
+// drop drops the packet passed for exceeding the RateLimits passed. Item
+// stack requests dropped are rejected, so that they are reverted by the
+// client. The packet to handle is returned: A PlayerAuthInput packet that
+// only exceeded the RateLimit of PacketCategoryItemStackRequest is only
+// stripped of its item stack request, as dropping the input itself would stop
+// the movement of the client. Other packets are dropped and nil is returned.
+func (l *rateLimiter) drop(exceeded []rateLimitExceeded, pk packet.Packet, s *Session) packet.Packet {
+	if len(exceeded) == 0 {
+		return pk
+	}
+	switch pk := pk.(type) {
+	case *packet.ItemStackRequest:
+		for _, req := range pk.Requests {
+			rejectItemStackRequest(req.RequestID, s)
+		}
+	case *packet.PlayerAuthInput:
+		req, ok := pk.ItemStackRequest.Value()
+		if ok && pk.InputData.Load(packet.InputFlagPerformItemStackRequest) {
+			rejectItemStackRequest(req.RequestID, s)
+		}
+		for _, e := range exceeded {
+			if e.category == PacketCategoryAll {
+				return nil
+			}
+		}
+		pk.InputData.Unset(packet.InputFlagPerformItemStackRequest)
+		pk.ItemStackRequest = protocol.Optional[protocol.ItemStackRequest]{}
+		return pk
+	}
+	return nil
+}
+
+// rejectItemStackRequest rejects the item stack request with the ID passed,
+// so that it is reverted by the client.
+func rejectItemStackRequest(id int32, s *Session) {
+	s.writePacket(&packet.ItemStackResponse{
+		Responses: []protocol.ItemStackResponse{{
+			Status:    protocol.ItemStackResponseStatusError,
+			RequestID: id,
+		}},
+	})
+}
+
+// packetCategory returns the PacketCategory of the packet passed, other than
+// PacketCategoryAll. False is returned if the packet has no such category.
+func packetCategory(pk packet.Packet) (PacketCategory, bool) {
+	switch pk := pk.(type) {
+	case *packet.Text:
+		return PacketCategoryChat, true
+	case *packet.CommandRequest:
+		return PacketCategoryCommand, true
+	case *packet.ModalFormResponse:
+		return PacketCategoryForm, true
+	case *packet.ItemStackRequest:
+		return PacketCategoryItemStackRequest, true
+	case *packet.PlayerAuthInput:
+		return PacketCategoryItemStackRequest, pk.InputData.Load(packet.InputFlagPerformItemStackRequest)
+	}
+	return 0, false
+}
@@ -0,0 +1,111 @@
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+func TestTokenBucketTake(t *testing.T) {
+	now := time.Now()
+	t.Run("burst", func(t *testing.T) {
+		b := &tokenBucket{limit: RateLimit{Rate: 2, Burst: 5}}
+		for i := range 5 {
+			if _, _, ok := b.take(now); !ok {
+				t.Fatalf("take() of token %v = false, want true", i)
+			}
+		}
+		if action, report, ok := b.take(now); ok || action != RateLimitDrop || !report {
+			t.Fatalf("take() of empty bucket = %v, %v, %v, want drop, reported", action, report, ok)
+		}
+		if _, report, ok := b.take(now); ok || report {
+			t.Fatalf("take() of second packet in a row = %v, %v, want dropped without report", report, ok)
+		}
+	})
+	t.Run("default burst", func(t *testing.T) {
+		b := &tokenBucket{limit: RateLimit{Rate: 2.5}}
+		for i := range 3 {
+			if _, _, ok := b.take(now); !ok {
+				t.Fatalf("take() of token %v = false, want true", i)
+			}
+		}
+		if _, _, ok := b.take(now); ok {
+			t.Fatalf("take() of fourth token = true, want false")
+		}
+	})
+	t.Run("refill", func(t *testing.T) {
+		b := &tokenBucket{limit: RateLimit{Rate: 4, Burst: 1}}
+		b.take(now)
+		if _, _, ok := b.take(now.Add(time.Second / 8)); ok {
+			t.Fatalf("take() after half a token = true, want false")
+		}
+		if _, _, ok := b.take(now.Add(time.Second / 4)); !ok {
+			t.Fatalf("take() after a token = false, want true")
+		}
+		if _, _, ok := b.take(now.Add(time.Hour)); !ok {
+			t.Fatalf("take() after an hour = false, want true")
+		}
+		if _, _, ok := b.take(now.Add(time.Hour)); ok {
+			t.Fatalf("take() beyond burst = true, want false")
+		}
+	})
+	t.Run("escalation", func(t *testing.T) {
+		b := &tokenBucket{limit: RateLimit{Rate: 1, WarnAfter: 3, KickAfter: 5}}
+		b.take(now)
+		want := []RateLimitAction{RateLimitDrop, RateLimitDrop, RateLimitWarn, RateLimitDrop, RateLimitKick}
+		for i, w := range want {
+			if action, _, ok := b.take(now); ok || action != w {
+				t.Fatalf("take() of dropped packet %v = %v, %v, want %v", i+1, action, ok, w)
+			}
+		}
+	})
+	t.Run("reset", func(t *testing.T) {
+		b := &tokenBucket{limit: RateLimit{Rate: 0.1, WarnAfter: 2}}
+		b.take(now)
+		b.take(now)
+		// More than a second passes between the drops, so they are not in a row.
+		if action, report, _ := b.take(now.Add(time.Second * 2)); action != RateLimitDrop || !report {
+			t.Fatalf("take() after a pause = %v, reported %v, want drop, reported", action, report)
+		}
+	})
+}
+
+func TestRateLimiterLimit(t *testing.T) {
+	if exceeded := (*rateLimiter)(nil).limit(&packet.Text{}); exceeded != nil {
+		t.Fatalf("limit() of nil rateLimiter = %v, want nil", exceeded)
+	}
+	if l := newRateLimiter(map[PacketCategory]RateLimit{PacketCategoryChat: {}}); len(l.buckets) != 0 {
+		t.Fatalf("newRateLimiter() with zero rate has %v buckets, want 0", len(l.buckets))
+	}
+
+	l := newRateLimiter(map[PacketCategory]RateLimit{
+		PacketCategoryAll:              {Rate: 1, Burst: 3},
+		PacketCategoryItemStackRequest: {Rate: 1, Burst: 1},
+	})
+	input := func(request bool) *packet.PlayerAuthInput {
+		pk := &packet.PlayerAuthInput{InputData: protocol.NewInputFlags(packet.InputFlagCount)}
+		if request {
+			pk.InputData.Set(packet.InputFlagPerformItemStackRequest)
+		}
+		return pk
+	}
+	if exceeded := l.limit(input(true)); len(exceeded) != 0 {
+		t.Fatalf("limit() of first request = %v, want none exceeded", exceeded)
+	}
+	if exceeded := l.limit(input(false)); len(exceeded) != 0 {
+		t.Fatalf("limit() of input without request = %v, want none exceeded", exceeded)
+	}
+	exceeded := l.limit(input(true))
+	if len(exceeded) != 1 || exceeded[0].category != PacketCategoryItemStackRequest || !reported(exceeded) {
+		t.Fatalf("limit() of second request = %v, want item stack requests exceeded and reported", exceeded)
+	}
+	exceeded = l.limit(input(true))
+	if len(exceeded) != 2 || exceeded[0].category != PacketCategoryAll || !reported(exceeded) {
+		t.Fatalf("limit() of fourth packet = %v, want all packets and item stack requests exceeded", exceeded)
+	}
+	if exceeded = l.limit(input(true)); len(exceeded) != 2 || reported(exceeded) {
+		t.Fatalf("limit() of fifth packet = %v, want exceeded without report", exceeded)
+	}
+}
@@ -0,0 +1,56 @@
+package session
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/df-mc/dragonfly/server/world"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// clientPool is used to decode packets recorded as player input.
+var clientPool = packet.NewClientPool()
+
+// recordInput records the packet passed as input of the Controllable if the world of the transaction has a
+// world.Recorder.
+func recordInput(tx *world.Tx, c Controllable, pk packet.Packet) {
+	if !tx.Recording() {
+		return
+	}
+	buf := bytes.NewBuffer(make([]byte, 0, 64))
+	id := pk.ID()
+	w := protocol.NewWriter(buf, 0)
+	w.Varuint32(&id)
+	pk.Marshal(w)
+	tx.RecordInput(c.UUID(), buf.Bytes())
+}
+
+// HandleInput handles a packet recorded as player input by a world.Recorder as if it was received from the
+// client of the Session. It may be passed to world.Replay.Run to replay the input of players with a Session.
+func (s *Session) HandleInput(tx *world.Tx, c Controllable, data []byte) (err error) {
+	pk, err := decodeInput(data)
+	if err != nil {
+		return err
+	}
+	return s.handlePacket(pk, tx, c)
+}
+
+// decodeInput decodes a packet recorded using recordInput.
+func decodeInput(data []byte) (pk packet.Packet, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("decode input: %v", r)
+		}
+	}()
+	var id uint32
+	r := protocol.NewReader(bytes.NewBuffer(data), 0, false)
+	r.Varuint32(&id)
+	f, ok := clientPool[id]
+	if !ok {
+		return nil, fmt.Errorf("decode input: unknown packet ID %v", id)
+	}
+	pk = f()
+	pk.Marshal(r)
+	return pk, nil
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/df-mc/dragonfly/server/player/debug"
 	"github.com/df-mc/dragonfly/server/player/form"
 	"github.com/df-mc/dragonfly/server/player/hud"
+	"github.com/df-mc/dragonfly/server/player/menu"
 	"github.com/df-mc/dragonfly/server/player/skin"
 	"github.com/df-mc/dragonfly/server/world"
 	"github.com/go-gl/mathgl/mgl64"
@@ -50,6 +51,22 @@ type Session struct {
 	emoteChatMuted bool
 
 	teleportPos atomic.Pointer[mgl64.Vec3]
+	// movement is the movementSimulator used to validate the movement of the
+	// Controllable. It is nil if Config.ValidateMovement is false.
+	movement *movementSimulator
+	// combat is the combatValidator used to validate the attacks of the
+	// Controllable. It is nil if Config.ValidateCombat is false.
+	combat *combatValidator
+	// blocks is the blockValidator used to validate the breaking of and
+	// interaction with blocks by the Controllable. It is nil if
+	// Config.ValidateBlocks is false.
+	blocks *blockValidator
+	// limiter is the rateLimiter used to limit the rate at which the client
+	// sends packets. It is nil if Config.RateLimits is empty.
+	limiter *rateLimiter
+
+	middlewareMu sync.Mutex
+	middleware   atomic.Pointer[[]Middleware]
 
 	entityMutex sync.RWMutex
 	// currentEntityRuntimeID holds the runtime ID assigned to the last entity. It is incremented for every
@@ -77,6 +94,7 @@ type Session struct {
 	openedContainerID              atomic.Uint32
 	openedWindow                   atomic.Pointer[inventory.Inventory]
 	openedPos                      atomic.Pointer[cube.Pos]
+	openedMenu                     atomic.Pointer[menu.Menu]
 	swingingArm                    atomic.Bool
 	changingSlot                   atomic.Bool
 	changingDimension              atomic.Bool
@@ -84,7 +102,8 @@ type Session struct {
 
 	lastChunkPos world.ChunkPos
 
-	recipes map[uint32]recipe.Recipe
+	recipes         map[uint32]recipe.Recipe
+	unlockedRecipes map[string]struct{}
 
 	blobMu                sync.Mutex
 	blobs                 map[uint64][]byte
@@ -165,10 +184,40 @@ type Config struct {
 
 	JoinMessage, QuitMessage chat.Translation
 
+	// RecipeUnlocking specifies if the recipe book of the client should only
+	// show recipes unlocked by the Controllable, and if crafting recipes that
+	// are not unlocked should be prevented.
+	RecipeUnlocking bool
+
+	// ValidateMovement specifies if the movement of the Controllable should be
+	// simulated server-side from the input of the client. If the position
+	// claimed by the client diverges from the predicted position, a Violation
+	// is reported and the client is corrected.
+	ValidateMovement bool
+	// ValidateCombat specifies if attacks of the Controllable should be
+	// checked for reach, line of sight and click rate. Entities are rewound by
+	// the latency of the client for these checks.
+	ValidateCombat bool
+	// ValidateBlocks specifies if blocks broken by the Controllable should be
+	// checked for break time, and if blocks broken or interacted with should
+	// be checked for reach, face visibility and, when placing blocks,
+	// collision with entities.
+	ValidateBlocks bool
+	// RateLimits holds the RateLimit of the packets of every PacketCategory
+	// that the client may send. Packets exceeding a RateLimit are dropped, and
+	// the client is warned or disconnected if it continues sending them. Note
+	// that clients send a PlayerAuthInput packet every tick, so a RateLimit of
+	// PacketCategoryAll should allow at least 20 packets per second.
+	RateLimits map[PacketCategory]RateLimit
+
 	// HandleStop is called once when the Session is closed. The transaction is
 	// nil if the Controllable could not be restored to any world, such as when
 	// both its current world and respawn destination closed during teardown.
 	HandleStop func(*world.Tx, Controllable)
+	// Middleware is the chain of Middleware that packets received from and
+	// sent to the client pass through. More Middleware may be added using
+	// Session.Use.
+	Middleware []Middleware
 	// BlockRegistry overrides the registry used for network serialization. If nil, world.DefaultBlockRegistry is used.
 	BlockRegistry world.BlockRegistry
 }
@@ -177,7 +226,6 @@ func (conf Config) New(conn Conn) *Session {
 	r := conn.ChunkRadius()
 	if r > conf.MaxChunkRadius {
 		r = conf.MaxChunkRadius
-		_ = conn.WritePacket(&packet.ChunkRadiusUpdated{ChunkRadius: int32(r)})
 	}
 	if conf.Log == nil {
 		conf.Log = slog.Default()
@@ -201,6 +249,7 @@ func (conf Config) New(conn Conn) *Session {
 		currentEntityRuntimeID: 1,
 		heldSlot:               new(uint32),
 		recipes:                make(map[uint32]recipe.Recipe),
+		unlockedRecipes:        make(map[string]struct{}),
 		conf:                   conf,
 		hudUpdates:             make(map[hud.Element]bool),
 		hiddenHud:              make(map[hud.Element]struct{}),
@@ -208,6 +257,20 @@ func (conf Config) New(conn Conn) *Session {
 		debugShapeUpdates:      make([]debugShapeUpdate, 0, 256),
 	}
 	s.viewLayer = world.NewViewLayer(s)
+	s.Use(conf.Middleware...)
+	if r != conn.ChunkRadius() {
+		s.writePacket(&packet.ChunkRadiusUpdated{ChunkRadius: int32(r)})
+	}
+	if conf.ValidateMovement {
+		s.movement = &movementSimulator{}
+	}
+	if conf.ValidateCombat {
+		s.combat = newCombatValidator()
+	}
+	if conf.ValidateBlocks {
+		s.blocks = &blockValidator{}
+	}
+	s.limiter = newRateLimiter(conf.RateLimits)
 	s.openedWindow.Store(inventory.New(1, nil))
 	s.openedPos.Store(&cube.Pos{})
 
@@ -229,17 +292,26 @@ func (conf Config) New(conn Conn) *Session {
 	s.sendRecipes()
 	s.sendArmourTrimData()
 	s.SendSpeed(0.1)
-	go func() {
-		for {
-			select {
-			case <-s.closeBackground:
-				return
-			case pk := <-s.packets:
-				_ = conn.WritePacket(pk)
+	go s.sendPackets()
+	return s
+}
+
+// sendPackets continuously writes the packets queued using writePacket to the connection until the connection is
+// closed.
+func (s *Session) sendPackets() {
+	for {
+		select {
+		case <-s.closeBackground:
+			return
+		case pk := <-s.packets:
+			if req, ok := pk.(flushRequest); ok {
+				_ = s.conn.Flush()
+				close(req.done)
+				continue
 			}
+			_ = s.conn.WritePacket(pk)
 		}
-	}()
-	return s
+	}
 }
 
 // SetHandle sets the world.EntityHandle of the Session and attaches a skin to
@@ -280,6 +352,9 @@ func (s *Session) Spawn(c Controllable, tx *world.Tx) {
 	s.sendInv(s.ui, protocol.WindowIDUI)
 	s.sendInv(s.offHand, protocol.WindowIDOffHand)
 	s.sendInv(s.armour.Inventory(), protocol.WindowIDArmour)
+	if r, ok := c.(interface{ UnlockedRecipes() []recipe.Recipe }); ok {
+		s.SendRecipeUnlocks(r.UnlockedRecipes(), true)
+	}
 
 	chat.Global.Subscribe(c)
 	if !s.conf.JoinMessage.Zero() {
@@ -368,6 +443,10 @@ func (s *Session) Latency() time.Duration {
 // *world.Tx should use it directly instead.
 func (s *Session) withControllable(ctx context.Context, f func(tx *world.Tx, c Controllable) error) error {
 	_, err := world.CallRef(ctx, world.NewEntityRef[Controllable](s.ent), func(tx *world.Tx, c Controllable) (struct{}, error) {
+		// Transactions of the Session are replayed through the packets
+		// recorded as input of the Controllable, if any, so they are not
+		// recorded themselves.
+		tx.SkipRecording()
 		return struct{}{}, f(tx, c)
 	})
 	return err
@@ -411,7 +490,28 @@ func (s *Session) handlePackets() {
 		if err != nil {
 			return
 		}
+		// Rate limits are applied before entering a transaction, so that
+		// packets flooded by a client are dropped without involving the world
+		// unless the Controllable must be notified.
+		exceeded := s.limiter.limit(pk)
+		if len(exceeded) > 0 && !reported(exceeded) {
+			if pk = s.limiter.drop(exceeded, pk, s); pk == nil {
+				continue
+			}
+			exceeded = nil
+		}
 		err = s.withControllable(context.Background(), func(tx *world.Tx, c Controllable) error {
+			if len(exceeded) > 0 {
+				var err error
+				if pk, err = s.limiter.report(exceeded, pk, s, c); pk == nil || err != nil {
+					return err
+				}
+			}
+			pk, ok := s.interceptInbound(pk, tx, c)
+			if !ok {
+				return nil
+			}
+			recordInput(tx, c, pk)
 			return s.handlePacket(pk, tx, c)
 		})
 		if err != nil {
@@ -596,7 +696,7 @@ func (s *Session) registerHandlers() {
 		packet.IDFilterText:                nil,
 		packet.IDInteract:                  &InteractHandler{},
 		packet.IDInventoryTransaction:      &InventoryTransactionHandler{},
-		packet.IDItemStackRequest:          &ItemStackRequestHandler{changes: map[byte]map[byte]changeInfo{}, responseChanges: map[int32]map[*inventory.Inventory]map[byte]responseChange{}},
+		packet.IDItemStackRequest:          &ItemStackRequestHandler{changes: map[protocol.FullContainerName]map[byte]changeInfo{}, responseChanges: map[int32]map[*inventory.Inventory]map[byte]responseChange{}},
 		packet.IDLecternUpdate:             &LecternUpdateHandler{},
 		packet.IDMobEquipment:              &MobEquipmentHandler{},
 		packet.IDModalFormResponse:         &ModalFormResponseHandler{forms: make(map[uint32]form.Form)},
@@ -621,12 +721,43 @@ func (s *Session) writePacket(pk packet.Packet) {
 	if s == Nop {
 		return
 	}
+	pk, ok := s.interceptOutbound(pk)
+	if !ok {
+		return
+	}
 	select {
 	case s.packets <- pk:
 	case <-s.closeBackground:
 	}
 }
 
+// flush blocks until all packets queued using writePacket were written to the session's connection and the
+// connection was flushed, or until the connection is closed.
+func (s *Session) flush() {
+	if s == Nop {
+		return
+	}
+	req := flushRequest{done: make(chan struct{})}
+	select {
+	case s.packets <- req:
+	case <-s.closeBackground:
+		return
+	}
+	select {
+	case <-req.done:
+	case <-s.closeBackground:
+	}
+}
+
+// flushRequest is queued in the packets of a Session by flush to flush the connection once all packets queued
+// before it were written. It is never written to the connection itself.
+type flushRequest struct {
+	done chan struct{}
+}
+
+func (flushRequest) ID() uint32          { return 0 }
+func (flushRequest) Marshal(protocol.IO) {}
+
 // actorIdentifier represents the structure of an actor identifier sent over the network.
 type actorIdentifier struct {
 	// ID is a unique namespaced identifier for the entity.
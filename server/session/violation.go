@@ -0,0 +1,86 @@
+package session
+
+import (
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/world"
+)
+
+// ViolationType is the type of a Violation.
+type ViolationType int
+
+const (
+	// ViolationMovement is a Violation of a client moving to a position other
+	// than the one predicted by the server from its input.
+	ViolationMovement ViolationType = iota
+	// ViolationReach is a Violation of a client attacking an entity that was
+	// out of reach.
+	ViolationReach
+	// ViolationLineOfSight is a Violation of a client attacking an entity or
+	// interacting with a block that was obstructed by other blocks.
+	ViolationLineOfSight
+	// ViolationClickRate is a Violation of a client attacking or swinging its
+	// arm more often per second than allowed.
+	ViolationClickRate
+	// ViolationBreakTime is a Violation of a client breaking a block faster
+	// than possible with the item it holds and the effects it has.
+	ViolationBreakTime
+	// ViolationBlockReach is a Violation of a client breaking or interacting
+	// with a block that was out of reach.
+	ViolationBlockReach
+	// ViolationBlockFace is a Violation of a client breaking or interacting
+	// with a face of a block that was facing away from it.
+	ViolationBlockFace
+	// ViolationBlockPlacement is a Violation of a client placing a block that
+	// intersects with an entity. It is only reported when a block is actually
+	// placed, and the block is never placed, even if the Violation is
+	// cancelled.
+	ViolationBlockPlacement
+)
+
+// String returns the name of the ViolationType.
+func (t ViolationType) String() string {
+	switch t {
+	case ViolationMovement:
+		return "movement"
+	case ViolationReach:
+		return "reach"
+	case ViolationLineOfSight:
+		return "line of sight"
+	case ViolationClickRate:
+		return "click rate"
+	case ViolationBreakTime:
+		return "break time"
+	case ViolationBlockReach:
+		return "block reach"
+	case ViolationBlockFace:
+		return "block face"
+	case ViolationBlockPlacement:
+		return "block placement"
+	}
+	return "unknown"
+}
+
+// Violation is a violation of the rules of the game detected while
+// validating the input sent by the client of a Session. A Violation is
+// reported to the Controllable of the Session, which may choose to ignore
+// it.
+type Violation struct {
+	// Type is the type of the Violation.
+	Type ViolationType
+	// Value is the value measured for the input of the client, such as the
+	// distance in blocks between the position predicted by the server and the
+	// position claimed by the client. Value and Limit are 0 for violations
+	// that are not measured, such as ViolationBlockPlacement.
+	Value float64
+	// Limit is the bound of Value that the input of the client crossed, such
+	// as the maximum reach or the minimum progress of breaking a block.
+	Limit float64
+	// Entity is the handle of the entity targeted by the input of the client,
+	// such as the entity attacked. It is nil if the input did not target an
+	// entity.
+	Entity *world.EntityHandle
+	// Pos is the position of the block targeted by the input of the client,
+	// such as the block broken. It is only set for violations of the input
+	// of the client related to blocks.
+	Pos cube.Pos
+}
@@ -72,6 +72,7 @@ func (s *Session) ViewEntity(e world.Entity) {
 		s.entities[runtimeID] = e.H()
 	}
 	s.entityMutex.Unlock()
+	s.combat.record(e.H(), e.Position())
 
 	yaw, pitch := e.Rotation().Elem()
 	metadata := s.entityMetadata(e)
@@ -188,6 +189,7 @@ func (s *Session) HideEntity(e world.Entity) {
 		delete(s.entities, id)
 	}
 	s.entityMutex.Unlock()
+	s.combat.forget(e.H())
 	if !ok {
 		// The entity was already removed some other way. We don't need to send a packet.
 		return
@@ -214,6 +216,9 @@ func (s *Session) ViewEntityDisplacement(e world.Entity, pos mgl64.Vec3, rot cub
 }
 
 func (s *Session) viewEntityAbsoluteMovement(id uint64, e world.Entity, pos mgl64.Vec3, rot cube.Rotation, onGround, authoritative bool) {
+	if id != selfEntityRuntimeID {
+		s.combat.record(e.H(), pos)
+	}
 	flags := byte(0)
 	if onGround {
 		flags |= packet.MoveFlagOnGround
@@ -234,8 +239,12 @@ func (s *Session) ViewEntityVelocity(e world.Entity, velocity mgl64.Vec3) {
 	if s.entityHidden(e) {
 		return
 	}
+	id := s.entityRuntimeID(e)
+	if id == selfEntityRuntimeID && s.movement != nil {
+		s.movement.knockBack.Store(&velocity)
+	}
 	s.writePacket(&packet.SetActorMotion{
-		EntityRuntimeID: s.entityRuntimeID(e),
+		EntityRuntimeID: id,
 		Velocity:        vec64To32(velocity),
 	})
 }
@@ -258,6 +267,18 @@ func (s *Session) ViewTimeCycle(doDayLightCycle bool) {
 	s.sendGameRules([]protocol.GameRule{{Name: "dodaylightcycle", Value: doDayLightCycle}})
 }
 
+// ViewGameRules ...
+func (s *Session) ViewGameRules(rules map[string]any) {
+	gameRules := make([]protocol.GameRule, 0, len(rules))
+	for name, v := range rules {
+		if i, ok := v.(int); ok {
+			v = uint32(i)
+		}
+		gameRules = append(gameRules, protocol.GameRule{Name: name, Value: v})
+	}
+	s.sendGameRules(gameRules)
+}
+
 // ViewEntityTeleport ...
 func (s *Session) ViewEntityTeleport(e world.Entity, position mgl64.Vec3) {
 	id := s.entityRuntimeID(e)
@@ -268,6 +289,11 @@ func (s *Session) ViewEntityTeleport(e world.Entity, position mgl64.Vec3) {
 	yaw, pitch := e.Rotation().Elem()
 	if id == selfEntityRuntimeID {
 		s.teleportPos.Store(&position)
+		if s.movement != nil {
+			s.movement.synced.Store(false)
+		}
+	} else {
+		s.combat.record(e.H(), position)
 	}
 
 	s.writePacket(&packet.SetActorMotion{EntityRuntimeID: id})
@@ -1167,7 +1193,7 @@ func (s *Session) ViewEntityAnimation(e world.Entity, a world.EntityAnimation) {
 
 // OpenBlockContainer ...
 func (s *Session) OpenBlockContainer(pos cube.Pos, tx *world.Tx) {
-	if s.containerOpened.Load() && *s.openedPos.Load() == pos {
+	if s.containerOpened.Load() && *s.openedPos.Load() == pos && s.openedMenu.Load() == nil {
 		return
 	}
 	s.closeCurrentContainer(tx, false)
@@ -1273,6 +1299,7 @@ func (s *Session) ViewSlotChange(slot int, newItem item.Stack) {
 		Slot:     uint32(slot),
 		NewItem:  instanceFromItem(s.br, newItem),
 	})
+	s.sendBundleContents(newItem)
 }
 
 // ViewBlockAction ...
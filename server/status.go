@@ -1,7 +1,17 @@
 package server
 
 import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
 	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
 )
 
 // statusProvider handles the way the server shows up in the server list. The
@@ -20,3 +30,252 @@ func (s statusProvider) ServerStatus(playerCount, maxPlayers int) minecraft.Serv
 		MaxPlayers:  maxPlayers,
 	}
 }
+
+// PingStatusProvider is a minecraft.ServerStatusProvider that may show a
+// different status to every address pinging the server. If the StatusProvider
+// of a Config implements PingStatusProvider, the default listener responds to
+// pings using PingStatus.
+type PingStatusProvider interface {
+	minecraft.ServerStatusProvider
+	// PingStatus returns the status shown to the address passed.
+	PingStatus(addr net.Addr) minecraft.ServerStatus
+}
+
+// PlayerCounter provides the player count shown in the server list, such as
+// the number of players online on all servers of a network.
+type PlayerCounter interface {
+	// PlayerCount returns the number of players online. PlayerCount is called
+	// on a separate goroutine and may block until ctx is done.
+	PlayerCount(ctx context.Context) (int, error)
+}
+
+// PlayerCounterFunc is a PlayerCounter implemented as a function.
+type PlayerCounterFunc func(ctx context.Context) (int, error)
+
+// PlayerCount calls f.
+func (f PlayerCounterFunc) PlayerCount(ctx context.Context) (int, error) {
+	return f(ctx)
+}
+
+// StatusConfig holds the configuration of a StatusProvider.
+type StatusConfig struct {
+	// MOTD holds the lines shown as the name of the server in the server list.
+	// The lines are shown in turn, switching every Interval, so that the MOTD
+	// may be rotated or animated.
+	MOTD []string
+	// SubMOTD holds the lines shown as the sub-name of the server, in the
+	// friend list and on LAN. The lines are shown in turn like MOTD.
+	SubMOTD []string
+	// Interval is the time after which the next line of MOTD and SubMOTD is
+	// shown. Interval defaults to 5 seconds.
+	Interval time.Duration
+	// PlayerCounter provides the player count shown. If nil, the number of
+	// players connected to the listener is shown.
+	PlayerCounter PlayerCounter
+	// PlayerCountInterval is the time for which a player count returned by
+	// PlayerCounter is shown before it is updated. Until a first count is
+	// returned, or if PlayerCounter keeps failing, the number of players
+	// connected to the listener is shown. PlayerCountInterval defaults to 5
+	// seconds.
+	PlayerCountInterval time.Duration
+	// MaxPlayers is the maximum player count shown. If 0, the maximum number of
+	// players of the listener is shown.
+	MaxPlayers int
+	// Overrides hold the status shown to specific addresses. The first
+	// StatusOverride holding an address pinging the server is used.
+	Overrides []StatusOverride
+}
+
+// StatusOverride overrides the status shown to the addresses within any of
+// its Prefixes.
+type StatusOverride struct {
+	// Prefixes are the IP address ranges that the StatusOverride applies to.
+	Prefixes []netip.Prefix
+	// MOTD and SubMOTD replace the lines of StatusConfig.MOTD and
+	// StatusConfig.SubMOTD. If empty, the lines of the StatusConfig are shown.
+	MOTD, SubMOTD []string
+}
+
+// StatusProvider is a PingStatusProvider that shows a rotating MOTD and
+// sub-MOTD, an optional player count from a PlayerCounter and a different
+// MOTD to specific addresses. The MOTD may be changed at any time.
+type StatusProvider struct {
+	conf  StatusConfig
+	start time.Time
+
+	motd, subMOTD      atomic.Pointer[[]string]
+	online, maxPlayers atomic.Int64
+
+	mu       sync.Mutex
+	count    int
+	counted  time.Time
+	hasCount bool
+	counting bool
+}
+
+// NewStatusProvider returns a StatusProvider using the StatusConfig passed.
+func NewStatusProvider(conf StatusConfig) *StatusProvider {
+	if conf.Interval <= 0 {
+		conf.Interval = time.Second * 5
+	}
+	if conf.PlayerCountInterval <= 0 {
+		conf.PlayerCountInterval = time.Second * 5
+	}
+	s := &StatusProvider{conf: conf, start: time.Now()}
+	s.SetMOTD(conf.MOTD...)
+	s.SetSubMOTD(conf.SubMOTD...)
+	return s
+}
+
+// SetMOTD sets the lines shown as the name of the server in the server list,
+// which are shown in turn.
+func (s *StatusProvider) SetMOTD(lines ...string) {
+	s.motd.Store(&lines)
+}
+
+// SetSubMOTD sets the lines shown as the sub-name of the server, which are
+// shown in turn.
+func (s *StatusProvider) SetSubMOTD(lines ...string) {
+	s.subMOTD.Store(&lines)
+}
+
+// ServerStatus returns the status shown to addresses not matching any
+// StatusOverride. The player count and maximum players passed are shown
+// unless overridden by the StatusConfig.
+func (s *StatusProvider) ServerStatus(playerCount, maxPlayers int) minecraft.ServerStatus {
+	s.online.Store(int64(playerCount))
+	s.maxPlayers.Store(int64(maxPlayers))
+	return s.PingStatus(nil)
+}
+
+// PingStatus returns the status shown to the address passed, using the player
+// count and maximum players last passed to ServerStatus. If addr is nil, the
+// status shown to addresses not matching any StatusOverride is returned.
+func (s *StatusProvider) PingStatus(addr net.Addr) minecraft.ServerStatus {
+	motd, subMOTD := *s.motd.Load(), *s.subMOTD.Load()
+	if o, ok := s.override(addr); ok {
+		if len(o.MOTD) > 0 {
+			motd = o.MOTD
+		}
+		if len(o.SubMOTD) > 0 {
+			subMOTD = o.SubMOTD
+		}
+	}
+	status := minecraft.ServerStatus{
+		ServerName:    s.line(motd),
+		ServerSubName: s.line(subMOTD),
+		PlayerCount:   s.playerCount(),
+		MaxPlayers:    int(s.maxPlayers.Load()),
+	}
+	if s.conf.MaxPlayers != 0 {
+		status.MaxPlayers = s.conf.MaxPlayers
+	}
+	return status
+}
+
+// line returns the line of the lines passed that is currently shown.
+func (s *StatusProvider) line(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return lines[int(time.Since(s.start)/s.conf.Interval)%len(lines)]
+}
+
+// override returns the StatusOverride applying to the address passed.
+func (s *StatusProvider) override(addr net.Addr) (StatusOverride, bool) {
+	if addr == nil || len(s.conf.Overrides) == 0 {
+		return StatusOverride{}, false
+	}
+	ap, err := netip.ParseAddrPort(addr.String())
+	if err != nil {
+		return StatusOverride{}, false
+	}
+	ip := ap.Addr().Unmap()
+	for _, o := range s.conf.Overrides {
+		for _, prefix := range o.Prefixes {
+			if prefix.Contains(ip) {
+				return o, true
+			}
+		}
+	}
+	return StatusOverride{}, false
+}
+
+// playerCount returns the player count shown. If the count of the
+// PlayerCounter is outdated, it is updated on a separate goroutine while the
+// outdated count is returned.
+func (s *StatusProvider) playerCount() int {
+	if s.conf.PlayerCounter == nil {
+		return int(s.online.Load())
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.counting && time.Since(s.counted) >= s.conf.PlayerCountInterval {
+		s.counting = true
+		go s.updatePlayerCount()
+	}
+	if !s.hasCount {
+		return int(s.online.Load())
+	}
+	return s.count
+}
+
+// updatePlayerCount updates the player count using the PlayerCounter. If the
+// PlayerCounter fails, the previous count is kept.
+func (s *StatusProvider) updatePlayerCount() {
+	ctx, cancel := context.WithTimeout(context.Background(), s.conf.PlayerCountInterval)
+	defer cancel()
+	n, err := s.conf.PlayerCounter.PlayerCount(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counting, s.counted = false, time.Now()
+	if err == nil {
+		s.count, s.hasCount = n, true
+	}
+}
+
+// statusNetwork is a minecraft.Network that responds to the pings of every
+// address using the status returned by a PingStatusProvider.
+type statusNetwork struct {
+	minecraft.Network
+	provider PingStatusProvider
+}
+
+// Listen ...
+func (n statusNetwork) Listen(address string) (minecraft.NetworkListener, error) {
+	l, err := n.Network.Listen(address)
+	if err != nil {
+		return nil, err
+	}
+	if pl, ok := l.(interface {
+		PongDataFunc(f func(addr net.Addr) []byte)
+	}); ok {
+		pl.PongDataFunc(func(addr net.Addr) []byte {
+			return pongData(l, n.provider.PingStatus(addr))
+		})
+	}
+	return l, nil
+}
+
+// pongData returns the pong data of the minecraft.NetworkListener passed,
+// showing the minecraft.ServerStatus passed, in the same format as the pong
+// data set by a minecraft.Listener.
+func pongData(l minecraft.NetworkListener, status minecraft.ServerStatus) []byte {
+	if status.MaxPlayers == 0 {
+		status.MaxPlayers = status.PlayerCount + 1
+	}
+	var port uint16
+	if a, ok := l.Addr().(interface {
+		AddrPort() netip.AddrPort
+	}); ok {
+		port = a.AddrPort().Port()
+	}
+	// The pong data is separated by semicolons, so they must not occur in
+	// the name of the server.
+	r := strings.NewReplacer(";", "")
+	return fmt.Appendf(nil, "MCPE;%v;%v;%v;%v;%v;%v;%v;%v;%v;%v;%v;%v;",
+		r.Replace(status.ServerName), protocol.CurrentProtocol, protocol.CurrentVersion, status.PlayerCount,
+		status.MaxPlayers, l.ID(), r.Replace(status.ServerSubName), "Creative", 1, port, port, 0,
+	)
+}
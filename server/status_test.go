@@ -0,0 +1,214 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+)
+
+func TestStatusProviderMOTD(t *testing.T) {
+	s := NewStatusProvider(StatusConfig{MOTD: []string{"a", "b", "c"}, SubMOTD: []string{"sub"}, Interval: time.Hour})
+	tests := []struct {
+		elapsed time.Duration
+		want    string
+	}{
+		{elapsed: 0, want: "a"},
+		{elapsed: time.Hour + time.Minute, want: "b"},
+		{elapsed: time.Hour*2 + time.Minute, want: "c"},
+		// The lines are shown in turn, starting with the first line again
+		// after the last.
+		{elapsed: time.Hour*3 + time.Minute, want: "a"},
+	}
+	for _, test := range tests {
+		s.start = time.Now().Add(-test.elapsed)
+		status := s.ServerStatus(1, 10)
+		if status.ServerName != test.want || status.ServerSubName != "sub" {
+			t.Fatalf("ServerStatus() after %v = %q, %q, want %q, %q", test.elapsed, status.ServerName, status.ServerSubName, test.want, "sub")
+		}
+	}
+
+	s.start = time.Now()
+	s.SetMOTD("changed")
+	s.SetSubMOTD()
+	if status := s.ServerStatus(1, 10); status.ServerName != "changed" || status.ServerSubName != "" {
+		t.Fatalf("ServerStatus() after SetMOTD() = %q, %q, want %q, %q", status.ServerName, status.ServerSubName, "changed", "")
+	}
+}
+
+func TestStatusProviderOverrides(t *testing.T) {
+	s := NewStatusProvider(StatusConfig{
+		MOTD:    []string{"public"},
+		SubMOTD: []string{"public sub"},
+		Overrides: []StatusOverride{
+			{Prefixes: []netip.Prefix{netip.MustParsePrefix("192.168.0.0/16")}, MOTD: []string{"local"}},
+			{Prefixes: []netip.Prefix{netip.MustParsePrefix("192.168.1.0/24"), netip.MustParsePrefix("2001:db8::/32")}, MOTD: []string{"second"}, SubMOTD: []string{"second sub"}},
+		},
+	})
+	tests := []struct {
+		addr          net.Addr
+		motd, subMOTD string
+	}{
+		{addr: nil, motd: "public", subMOTD: "public sub"},
+		{addr: statusTestAddr("1.1.1.1:19132"), motd: "public", subMOTD: "public sub"},
+		// The first override matching is used and lines that it does not
+		// override are those of the StatusConfig.
+		{addr: statusTestAddr("192.168.1.5:19132"), motd: "local", subMOTD: "public sub"},
+		{addr: statusTestAddr("[::ffff:192.168.1.5]:19132"), motd: "local", subMOTD: "public sub"},
+		{addr: statusTestAddr("[2001:db8::1]:19132"), motd: "second", subMOTD: "second sub"},
+		{addr: statusTestAddr("[2001:db9::1]:19132"), motd: "public", subMOTD: "public sub"},
+		{addr: statusTestAddr("invalid"), motd: "public", subMOTD: "public sub"},
+		{addr: &net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 19132}, motd: "local", subMOTD: "public sub"},
+	}
+	for _, test := range tests {
+		status := s.PingStatus(test.addr)
+		if status.ServerName != test.motd || status.ServerSubName != test.subMOTD {
+			t.Fatalf("PingStatus(%v) = %q, %q, want %q, %q", test.addr, status.ServerName, status.ServerSubName, test.motd, test.subMOTD)
+		}
+	}
+}
+
+func TestStatusProviderPlayerCount(t *testing.T) {
+	var (
+		count atomic.Int64
+		fail  atomic.Bool
+		calls atomic.Int64
+	)
+	count.Store(42)
+	s := NewStatusProvider(StatusConfig{
+		PlayerCounter: PlayerCounterFunc(func(ctx context.Context) (int, error) {
+			defer calls.Add(1)
+			if fail.Load() {
+				return 0, errors.New("unavailable")
+			}
+			return int(count.Load()), nil
+		}),
+		PlayerCountInterval: time.Millisecond * 10,
+		MaxPlayers:          100,
+	})
+	waitCount := func(want int) {
+		t.Helper()
+		deadline := time.Now().Add(time.Second * 5)
+		for s.ServerStatus(3, 10).PlayerCount != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("PlayerCount = %v, want %v", s.ServerStatus(3, 10).PlayerCount, want)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	// The player count of the listener is shown until the PlayerCounter
+	// returned a first count.
+	status := s.ServerStatus(3, 10)
+	if status.PlayerCount != 3 && status.PlayerCount != 42 {
+		t.Fatalf("PlayerCount = %v, want 3 or 42", status.PlayerCount)
+	}
+	if status.MaxPlayers != 100 {
+		t.Fatalf("MaxPlayers = %v, want 100", status.MaxPlayers)
+	}
+	waitCount(42)
+
+	count.Store(50)
+	waitCount(50)
+
+	// The previous count is kept while the PlayerCounter fails.
+	fail.Store(true)
+	before := calls.Load()
+	deadline := time.Now().Add(time.Second * 5)
+	for calls.Load() < before+2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("PlayerCounter was not called again")
+		}
+		s.ServerStatus(3, 10)
+		time.Sleep(time.Millisecond)
+	}
+	if n := s.ServerStatus(3, 10).PlayerCount; n != 50 {
+		t.Fatalf("PlayerCount after failure = %v, want 50", n)
+	}
+
+	t.Run("no counter", func(t *testing.T) {
+		s := NewStatusProvider(StatusConfig{})
+		if status := s.ServerStatus(7, 20); status.PlayerCount != 7 || status.MaxPlayers != 20 {
+			t.Fatalf("ServerStatus() = %v/%v, want 7/20", status.PlayerCount, status.MaxPlayers)
+		}
+	})
+}
+
+func TestPongData(t *testing.T) {
+	l := statusTestListener{addr: &net.UDPAddr{IP: net.IPv4zero, Port: 19133}, id: 1234}
+	tests := []struct {
+		status minecraft.ServerStatus
+		want   string
+	}{
+		{
+			status: minecraft.ServerStatus{ServerName: "Name", ServerSubName: "Sub", PlayerCount: 2, MaxPlayers: 20},
+			want:   fmt.Sprintf("MCPE;Name;%v;%v;2;20;1234;Sub;Creative;1;19133;19133;0;", protocol.CurrentProtocol, protocol.CurrentVersion),
+		},
+		{
+			// Semicolons are removed from the names and the maximum player
+			// count is one more than the player count if not set.
+			status: minecraft.ServerStatus{ServerName: "a;b", ServerSubName: ";", PlayerCount: 5},
+			want:   fmt.Sprintf("MCPE;ab;%v;%v;5;6;1234;;Creative;1;19133;19133;0;", protocol.CurrentProtocol, protocol.CurrentVersion),
+		},
+	}
+	for _, test := range tests {
+		if got := string(pongData(l, test.status)); got != test.want {
+			t.Fatalf("pongData() = %q, want %q", got, test.want)
+		}
+	}
+}
+
+func TestDefaultStatusProvider(t *testing.T) {
+	conf := newWorldsTestServer(t, Config{Name: "Server"}).conf
+	if _, ok := conf.StatusProvider.(PingStatusProvider); ok {
+		t.Fatalf("default StatusProvider is a PingStatusProvider")
+	}
+	if status := conf.StatusProvider.ServerStatus(1, 2); status.ServerName != "Server" {
+		t.Fatalf("ServerName = %q, want %q", status.ServerName, "Server")
+	}
+
+	uc := DefaultConfig()
+	if p, err := uc.statusProvider(); p != nil || err != nil {
+		t.Fatalf("statusProvider() of empty Status = %v, %v, want nil, nil", p, err)
+	}
+	uc.Status.Overrides = append(uc.Status.Overrides, struct {
+		Addresses     []string
+		MOTD, SubMOTD []string
+	}{Addresses: []string{"10.0.0.1", "192.168.0.0/16"}, MOTD: []string{"local"}})
+	p, err := uc.statusProvider()
+	if err != nil {
+		t.Fatalf("statusProvider() = %v, want nil", err)
+	}
+	if motd := p.PingStatus(statusTestAddr("10.0.0.1:19132")).ServerName; motd != "local" {
+		t.Fatalf("ServerName of override = %q, want %q", motd, "local")
+	}
+	if motd := p.PingStatus(nil).ServerName; motd != uc.Server.Name {
+		t.Fatalf("ServerName = %q, want %q", motd, uc.Server.Name)
+	}
+	uc.Status.Overrides[0].Addresses = []string{"invalid"}
+	if _, err := uc.statusProvider(); err == nil {
+		t.Fatalf("statusProvider() with invalid address = nil, want error")
+	}
+}
+
+// statusTestAddr is a net.Addr with the string form held.
+type statusTestAddr string
+
+func (a statusTestAddr) Network() string { return "udp" }
+func (a statusTestAddr) String() string  { return string(a) }
+
+type statusTestListener struct {
+	minecraft.NetworkListener
+	addr net.Addr
+	id   int64
+}
+
+func (l statusTestListener) Addr() net.Addr { return l.addr }
+func (l statusTestListener) ID() int64      { return l.id }
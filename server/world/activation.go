@@ -0,0 +1,143 @@
+package world
+
+import (
+	"maps"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// ActivationCategory is a category of entities that share an ActivationRange.
+// The ActivationCategory of an entity is obtained from its EntityType if it
+// implements ActivatedEntityType.
+type ActivationCategory uint8
+
+const (
+	// ActivationAlways is the ActivationCategory of entities that are ticked
+	// every tick regardless of their distance to players. It is used for all
+	// entities with an EntityType that does not implement
+	// ActivatedEntityType, such as players.
+	ActivationAlways ActivationCategory = iota
+	// ActivationItems is the ActivationCategory of item entities.
+	ActivationItems
+	// ActivationExperienceOrbs is the ActivationCategory of experience orbs.
+	ActivationExperienceOrbs
+	// ActivationFallingBlocks is the ActivationCategory of falling blocks.
+	ActivationFallingBlocks
+	// ActivationProjectiles is the ActivationCategory of projectiles, such as
+	// arrows.
+	ActivationProjectiles
+	// ActivationMisc is the ActivationCategory of other entities that may
+	// be ticked less frequently when far away from players.
+	ActivationMisc
+)
+
+// ActivatedEntityType is an EntityType of entities that are subject to the
+// ActivationRange of their ActivationCategory.
+type ActivatedEntityType interface {
+	EntityType
+	// ActivationCategory returns the ActivationCategory of entities of the
+	// EntityType.
+	ActivationCategory() ActivationCategory
+}
+
+// ActivationRange specifies how entities of an ActivationCategory are ticked
+// when they are far away from players. Entities within Range of any player in
+// the World are active and ticked every tick. Other entities are inactive and
+// are ticked once every Interval ticks, or not at all if Interval is 0.
+type ActivationRange struct {
+	// Range is the distance in blocks to the nearest player within which
+	// entities are active. If Range is 0 or lower, entities are always active.
+	Range float64
+	// Interval is the number of ticks between ticks of inactive entities. If
+	// 0, inactive entities are frozen until a player comes within Range.
+	Interval int64
+}
+
+// CatchUpTicker is a TickerEntity that is able to catch up on ticks skipped
+// while it was inactive. CatchUp is called right before Tick when an entity is
+// ticked after one or more ticks were skipped, so that, for example, the
+// entity still falls to the ground and expires on time.
+type CatchUpTicker interface {
+	TickerEntity
+	// CatchUp advances the entity by the number of ticks passed, which were
+	// skipped because the entity was inactive.
+	CatchUp(tx *Tx, ticks int64)
+}
+
+// activationPruneInterval is the interval in ticks at which the inactive
+// entities of a World are pruned of entities that are no longer in it.
+const activationPruneInterval = 100
+
+// activation holds the positions of players used to check if entities are
+// active during a single tick.
+type activation struct {
+	ranges  map[ActivationCategory]ActivationRange
+	players []mgl64.Vec3
+}
+
+// newActivation returns the activation used to check if entities are active
+// during the current tick. Nil is returned if the World has no
+// ActivationRanges, in which case all entities are active.
+func newActivation(tx *Tx, tick int64) *activation {
+	w := tx.World()
+	if len(w.conf.ActivationRanges) == 0 {
+		return nil
+	}
+	if tick%activationPruneInterval == 0 {
+		maps.DeleteFunc(w.inactive, func(handle *EntityHandle, _ int64) bool {
+			_, ok := w.entities[handle]
+			return !ok
+		})
+	}
+	a := &activation{ranges: w.conf.ActivationRanges}
+	for p := range tx.Players() {
+		a.players = append(a.players, p.Position())
+	}
+	return a
+}
+
+// shouldTick checks if the entity of the EntityHandle passed, located at pos,
+// should be ticked during the tick passed. If the entity was inactive and
+// skipped ticks, the number of ticks skipped is returned.
+func (a *activation) shouldTick(w *World, handle *EntityHandle, pos mgl64.Vec3, tick int64) (skipped int64, ok bool) {
+	if a == nil {
+		return 0, true
+	}
+	t, ok := handle.t.(ActivatedEntityType)
+	if !ok {
+		return 0, true
+	}
+	r, ok := a.ranges[t.ActivationCategory()]
+	if !ok || r.Range <= 0 {
+		return 0, true
+	}
+	last, inactive := w.inactive[handle]
+	if a.withinRange(pos, r.Range) {
+		if !inactive {
+			return 0, true
+		}
+		delete(w.inactive, handle)
+		return max(tick-last-1, 0), true
+	}
+	if !inactive {
+		// The entity just became inactive. It was ticked during the previous
+		// tick, so start counting from there.
+		last = tick - 1
+		w.inactive[handle] = last
+	}
+	if r.Interval <= 0 || tick-last < r.Interval {
+		return 0, false
+	}
+	w.inactive[handle] = tick
+	return max(tick-last-1, 0), true
+}
+
+// withinRange checks if any player is within a distance r of pos.
+func (a *activation) withinRange(pos mgl64.Vec3, r float64) bool {
+	for _, p := range a.players {
+		if p.Sub(pos).LenSqr() <= r*r {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package world
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestActivationShouldTick(t *testing.T) {
+	w := &World{inactive: make(map[*EntityHandle]int64)}
+	h := NewEntity(activationTestEntityType{}, taskTestEntityConfig{})
+	a := &activation{
+		ranges:  map[ActivationCategory]ActivationRange{ActivationItems: {Range: 16, Interval: 5}},
+		players: []mgl64.Vec3{{}},
+	}
+	near, far := mgl64.Vec3{8, 0, 0}, mgl64.Vec3{64, 0, 0}
+
+	steps := []struct {
+		tick    int64
+		pos     mgl64.Vec3
+		ok      bool
+		skipped int64
+	}{
+		{tick: 1, pos: near, ok: true},
+		{tick: 2, pos: far},
+		{tick: 3, pos: far},
+		{tick: 4, pos: far},
+		{tick: 5, pos: far},
+		{tick: 6, pos: far, ok: true, skipped: 4},
+		{tick: 7, pos: far},
+		{tick: 9, pos: near, ok: true, skipped: 2},
+		{tick: 10, pos: near, ok: true},
+	}
+	for _, s := range steps {
+		skipped, ok := a.shouldTick(w, h, s.pos, s.tick)
+		if ok != s.ok || skipped != s.skipped {
+			t.Fatalf("shouldTick() at tick %v = %v, %v, want %v, %v", s.tick, skipped, ok, s.skipped, s.ok)
+		}
+	}
+	if _, inactive := w.inactive[h]; inactive {
+		t.Fatalf("entity still inactive after coming within range")
+	}
+}
+
+func TestActivationShouldTickFrozen(t *testing.T) {
+	w := &World{inactive: make(map[*EntityHandle]int64)}
+	h := NewEntity(activationTestEntityType{}, taskTestEntityConfig{})
+	a := &activation{
+		ranges:  map[ActivationCategory]ActivationRange{ActivationItems: {Range: 16}},
+		players: []mgl64.Vec3{{}},
+	}
+	for tick := int64(1); tick <= 100; tick++ {
+		if _, ok := a.shouldTick(w, h, mgl64.Vec3{64, 0, 0}, tick); ok {
+			t.Fatalf("shouldTick() at tick %v = true, want frozen entity", tick)
+		}
+	}
+	if skipped, ok := a.shouldTick(w, h, mgl64.Vec3{}, 101); !ok || skipped != 100 {
+		t.Fatalf("shouldTick() after becoming active = %v, %v, want 100, true", skipped, ok)
+	}
+
+	other := NewEntity(taskTestEntityType{}, taskTestEntityConfig{})
+	if skipped, ok := a.shouldTick(w, other, mgl64.Vec3{64, 0, 0}, 102); !ok || skipped != 0 {
+		t.Fatalf("shouldTick() of entity without category = %v, %v, want 0, true", skipped, ok)
+	}
+}
+
+type activationTestEntityType struct{ taskTestEntityType }
+
+func (activationTestEntityType) ActivationCategory() ActivationCategory { return ActivationItems }
@@ -0,0 +1,186 @@
+package world
+
+import (
+	"math"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// Border is a square area around a centre that players and entities in a World are unable to leave. Chunks fully
+// outside the Border are not loaded for viewers, and players outside the Border take damage. A Border with a Size
+// of 0 is disabled.
+type Border struct {
+	// Centre is the centre of the Border on the X and Z axes.
+	Centre mgl64.Vec2
+	// Size is the current width of the Border in blocks. If Size is 0, the Border is disabled.
+	Size float64
+	// TargetSize is the size that the Border is resizing towards. It is reached after ResizeTicks ticks.
+	TargetSize float64
+	// ResizeTicks is the number of ticks left until the Border reaches TargetSize. If 0, the Border is not
+	// resizing.
+	ResizeTicks int64
+	// DamageBuffer is the distance in blocks that players may be outside the Border before taking damage.
+	DamageBuffer float64
+	// DamagePerBlock is the damage dealt to players every second for every block that they are beyond the
+	// DamageBuffer of the Border.
+	DamagePerBlock float64
+	// WarningDistance is the distance in blocks from the Border at which players are shown the Border.
+	WarningDistance float64
+}
+
+// Enabled checks if the Border is enabled, which is the case if it has a Size higher than 0.
+func (b Border) Enabled() bool {
+	return b.Size > 0
+}
+
+// Min returns the minimum X and Z coordinates within the Border.
+func (b Border) Min() mgl64.Vec2 {
+	return b.Centre.Sub(mgl64.Vec2{b.Size / 2, b.Size / 2})
+}
+
+// Max returns the maximum X and Z coordinates within the Border.
+func (b Border) Max() mgl64.Vec2 {
+	return b.Centre.Add(mgl64.Vec2{b.Size / 2, b.Size / 2})
+}
+
+// Within checks if the position passed is within the Border. Within always returns true if the Border is disabled.
+func (b Border) Within(pos mgl64.Vec3) bool {
+	return b.Distance(pos) >= 0
+}
+
+// Distance returns the distance from the position passed to the closest edge of the Border. The distance is
+// negative if the position is outside the Border and positive infinity if the Border is disabled.
+func (b Border) Distance(pos mgl64.Vec3) float64 {
+	if !b.Enabled() {
+		return math.Inf(1)
+	}
+	minimum, maximum := b.Min(), b.Max()
+	return min(pos[0]-minimum[0], maximum[0]-pos[0], pos[2]-minimum[1], maximum[1]-pos[2])
+}
+
+// Clamp returns the position passed, moved onto the closest edge of the Border if it was outside of it.
+func (b Border) Clamp(pos mgl64.Vec3) mgl64.Vec3 {
+	if !b.Enabled() {
+		return pos
+	}
+	minimum, maximum := b.Min(), b.Max()
+	pos[0] = mgl64.Clamp(pos[0], minimum[0], maximum[0])
+	pos[2] = mgl64.Clamp(pos[2], minimum[1], maximum[1])
+	return pos
+}
+
+// Damage returns the damage that a player at the position passed should take every second. Damage returns 0 if the
+// position is within the DamageBuffer of the Border.
+func (b Border) Damage(pos mgl64.Vec3) float64 {
+	beyond := -b.Distance(pos) - b.DamageBuffer
+	if beyond <= 0 || b.DamagePerBlock <= 0 {
+		return 0
+	}
+	return max(1, math.Floor(beyond*b.DamagePerBlock))
+}
+
+// chunkWithin checks if any part of the chunk at the ChunkPos passed is within the Border.
+func (b Border) chunkWithin(pos ChunkPos) bool {
+	if !b.Enabled() {
+		return true
+	}
+	minimum, maximum := b.Min(), b.Max()
+	x, z := float64(pos[0]<<4), float64(pos[1]<<4)
+	return x+16 > minimum[0] && x < maximum[0] && z+16 > minimum[1] && z < maximum[1]
+}
+
+// tick moves the size of the Border one step closer to its TargetSize if it is resizing.
+func (b *Border) tick() {
+	if b.ResizeTicks <= 0 {
+		return
+	}
+	b.Size += (b.TargetSize - b.Size) / float64(b.ResizeTicks)
+	if b.ResizeTicks--; b.ResizeTicks == 0 {
+		b.Size = b.TargetSize
+	}
+}
+
+// Border returns the current Border of the World. Every dimension has its own Border, also if Worlds of different
+// dimensions share the same Settings. Tx.Border should be used in transactions instead.
+func (w *World) Border() Border {
+	if w == nil {
+		return Border{}
+	}
+	w.set.Lock()
+	defer w.set.Unlock()
+	return w.set.Borders[w.borderID()]
+}
+
+// SetBorder changes the Border of the World. Chunks already shown to viewers remain visible, but no new chunks
+// outside the Border are loaded for them.
+func (w *World) SetBorder(b Border) {
+	if w == nil {
+		return
+	}
+	w.set.Lock()
+	defer w.set.Unlock()
+	w.setBorder(b)
+}
+
+// ResizeBorder gradually changes the size of the Border of the World to the size passed over the duration d. If d
+// is 0 or less, the size is changed immediately. ResizeBorder has no effect if the Border is disabled.
+func (w *World) ResizeBorder(size float64, d time.Duration) {
+	if w == nil {
+		return
+	}
+	w.set.Lock()
+	defer w.set.Unlock()
+	b := w.set.Borders[w.borderID()]
+	if !b.Enabled() {
+		return
+	}
+	b.TargetSize, b.ResizeTicks = size, d.Milliseconds()/50
+	if b.ResizeTicks <= 0 {
+		b.Size, b.ResizeTicks = size, 0
+	}
+	w.setBorder(b)
+}
+
+// Border returns the current Border of the World of the transaction. Unlike World.Border, Border does not need to
+// lock the Settings of the World, as the Border is copied from the Settings every time it is changed.
+func (tx *Tx) Border() Border {
+	return tx.World().currentBorder()
+}
+
+// currentBorder returns the copy of the Border of the World, refreshing it if the Border was changed since it was
+// last copied. currentBorder must only be called in a transaction.
+func (w *World) currentBorder() Border {
+	if version := w.set.bordersVersion.Load(); version != w.borderVersion {
+		w.set.Lock()
+		w.border = w.set.Borders[w.borderID()]
+		w.set.Unlock()
+		w.borderVersion = version
+	}
+	return w.border
+}
+
+// tickBorder moves the Border of the World one step closer to its TargetSize if it is resizing. w.set must be locked
+// when calling tickBorder.
+func (w *World) tickBorder() {
+	if b := w.set.Borders[w.borderID()]; b.ResizeTicks > 0 {
+		b.tick()
+		w.setBorder(b)
+	}
+}
+
+// setBorder stores the Border passed as the Border of the World in its Settings. w.set must be locked when calling
+// setBorder.
+func (w *World) setBorder(b Border) {
+	if w.set.Borders == nil {
+		w.set.Borders = make(map[int]Border)
+	}
+	w.set.Borders[w.borderID()] = b
+	w.set.bordersVersion.Add(1)
+}
+
+// borderID returns the key of the Border of the World in Settings.Borders, which is the ID of its Dimension.
+func (w *World) borderID() int {
+	id, _ := DimensionID(w.Dimension())
+	return id
+}
@@ -0,0 +1,166 @@
+package world
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestBorderWithin(t *testing.T) {
+	b := Border{Centre: mgl64.Vec2{10, -10}, Size: 20}
+	tests := []struct {
+		pos  mgl64.Vec3
+		want bool
+	}{
+		{pos: mgl64.Vec3{10, 64, -10}, want: true},
+		{pos: mgl64.Vec3{0, 64, -20}, want: true},
+		{pos: mgl64.Vec3{20, -64, 0}, want: true},
+		{pos: mgl64.Vec3{-0.1, 64, -10}, want: false},
+		{pos: mgl64.Vec3{10, 64, 0.1}, want: false},
+		{pos: mgl64.Vec3{100, 64, 100}, want: false},
+	}
+	for _, test := range tests {
+		if got := b.Within(test.pos); got != test.want {
+			t.Fatalf("Within(%v) = %v, want %v", test.pos, got, test.want)
+		}
+	}
+	if d := b.Distance(mgl64.Vec3{12, 0, -10}); d != 8 {
+		t.Fatalf("Distance() = %v, want 8", d)
+	}
+	if d := b.Distance(mgl64.Vec3{25, 0, -10}); d != -5 {
+		t.Fatalf("Distance() outside = %v, want -5", d)
+	}
+
+	disabled := Border{}
+	if !disabled.Within(mgl64.Vec3{1e9, 0, -1e9}) {
+		t.Fatalf("Within() of disabled Border = false, want true")
+	}
+	if d := disabled.Distance(mgl64.Vec3{}); !math.IsInf(d, 1) {
+		t.Fatalf("Distance() of disabled Border = %v, want +Inf", d)
+	}
+}
+
+func TestBorderClamp(t *testing.T) {
+	b := Border{Size: 20}
+	tests := []struct {
+		pos, want mgl64.Vec3
+	}{
+		{pos: mgl64.Vec3{5, 64, -5}, want: mgl64.Vec3{5, 64, -5}},
+		{pos: mgl64.Vec3{15, 64, 0}, want: mgl64.Vec3{10, 64, 0}},
+		{pos: mgl64.Vec3{-30, 200, 30}, want: mgl64.Vec3{-10, 200, 10}},
+	}
+	for _, test := range tests {
+		if got := b.Clamp(test.pos); got != test.want {
+			t.Fatalf("Clamp(%v) = %v, want %v", test.pos, got, test.want)
+		}
+	}
+	if pos := (mgl64.Vec3{1e9, 0, 0}); (Border{}).Clamp(pos) != pos {
+		t.Fatalf("Clamp() of disabled Border changed position")
+	}
+}
+
+func TestBorderDamage(t *testing.T) {
+	b := Border{Size: 20, DamageBuffer: 5, DamagePerBlock: 0.2}
+	tests := []struct {
+		x, want float64
+	}{
+		{x: 0, want: 0},
+		{x: 14, want: 0},
+		{x: 15, want: 0},
+		// Damage is at least 1 once beyond the DamageBuffer.
+		{x: 16, want: 1},
+		{x: 30, want: 3},
+	}
+	for _, test := range tests {
+		if got := b.Damage(mgl64.Vec3{test.x, 64, 0}); got != test.want {
+			t.Fatalf("Damage() at x = %v = %v, want %v", test.x, got, test.want)
+		}
+	}
+	b.DamagePerBlock = 0
+	if got := b.Damage(mgl64.Vec3{100, 64, 0}); got != 0 {
+		t.Fatalf("Damage() without DamagePerBlock = %v, want 0", got)
+	}
+}
+
+func TestBorderChunkWithin(t *testing.T) {
+	b := Border{Size: 32}
+	tests := []struct {
+		pos  ChunkPos
+		want bool
+	}{
+		{pos: ChunkPos{0, 0}, want: true},
+		{pos: ChunkPos{-1, -1}, want: true},
+		{pos: ChunkPos{1, 0}, want: false},
+		{pos: ChunkPos{0, -2}, want: false},
+	}
+	for _, test := range tests {
+		if got := b.chunkWithin(test.pos); got != test.want {
+			t.Fatalf("chunkWithin(%v) = %v, want %v", test.pos, got, test.want)
+		}
+	}
+	// A chunk only partly within the Border is within it.
+	if !(Border{Size: 8}).chunkWithin(ChunkPos{-1, 0}) {
+		t.Fatalf("chunkWithin() of chunk partly within Border = false, want true")
+	}
+	if !(Border{}).chunkWithin(ChunkPos{1000, 1000}) {
+		t.Fatalf("chunkWithin() of disabled Border = false, want true")
+	}
+}
+
+func TestWorldBorder(t *testing.T) {
+	s := defaultSettings()
+	overworld := Config{Synchronous: true, Provider: NopProvider{Set: s}}.New()
+	defer overworld.Close()
+	nether := Config{Synchronous: true, Dim: Nether, Provider: NopProvider{Set: s}}.New()
+	defer nether.Close()
+
+	t.Run("dimensions", func(t *testing.T) {
+		overworld.SetBorder(Border{Size: 100})
+		if b := nether.Border(); b.Enabled() {
+			t.Fatalf("Border() of nether sharing Settings = %+v, want disabled", b)
+		}
+		nether.SetBorder(Border{Size: 20})
+		if size := overworld.Border().Size; size != 100 {
+			t.Fatalf("Border().Size of overworld after SetBorder on nether = %v, want 100", size)
+		}
+	})
+	t.Run("transaction", func(t *testing.T) {
+		overworld.Do(func(tx *Tx) {
+			if size := tx.Border().Size; size != 100 {
+				t.Fatalf("Tx.Border().Size = %v, want 100", size)
+			}
+		})
+		overworld.SetBorder(Border{Size: 50})
+		overworld.Do(func(tx *Tx) {
+			if size := tx.Border().Size; size != 50 {
+				t.Fatalf("Tx.Border().Size after SetBorder = %v, want 50", size)
+			}
+		})
+	})
+	t.Run("resize", func(t *testing.T) {
+		nether.ResizeBorder(30, time.Second/2)
+		for range 5 {
+			nether.AdvanceTick()
+		}
+		nether.Do(func(tx *Tx) {
+			if size := tx.Border().Size; size != 25 {
+				t.Fatalf("Tx.Border().Size halfway through resize = %v, want 25", size)
+			}
+		})
+		for range 5 {
+			nether.AdvanceTick()
+		}
+		if b := nether.Border(); b.Size != 30 || b.ResizeTicks != 0 {
+			t.Fatalf("Border() after resize = %+v, want Size 30 and no ResizeTicks", b)
+		}
+		if size := overworld.Border().Size; size != 50 {
+			t.Fatalf("Border().Size of overworld after resizing nether = %v, want 50", size)
+		}
+		overworld.ResizeBorder(10, 0)
+		if size := overworld.Border().Size; size != 10 {
+			t.Fatalf("Border().Size after instant resize = %v, want 10", size)
+		}
+	})
+}
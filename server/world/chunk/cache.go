@@ -0,0 +1,66 @@
+package chunk
+
+import (
+	"slices"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// encodingCache holds the network encoding of a list of PalettedStorages, such as the layers of a SubChunk or
+// the biomes of a Chunk. The encoding is shared by all viewers of a chunk, so that a chunk viewed by many
+// players is only encoded once every time it changes.
+type encodingCache struct {
+	// storages and versions hold the PalettedStorages encoded and their versions at the time of encoding.
+	storages []*PalettedStorage
+	versions []uint32
+	// data holds the encoded PalettedStorages. Its capacity is equal to its length, so that appending to it
+	// never changes the cached data.
+	data []byte
+	// hash is the xxhash of data, which clients with the client cache enabled use to identify it as a blob.
+	hash uint64
+}
+
+// newEncodingCache returns an encodingCache holding the encoding of the storages passed.
+func newEncodingCache(storages []*PalettedStorage, data []byte) *encodingCache {
+	c := &encodingCache{storages: slices.Clone(storages), versions: make([]uint32, len(storages)), data: slices.Clip(data), hash: xxhash.Sum64(data)}
+	for i, storage := range storages {
+		c.versions[i] = storage.version
+	}
+	return c
+}
+
+// valid checks if the encodingCache holds the current encoding of the storages passed. This is the case if
+// the storages are the same as those encoded and none of them were changed since.
+func (c *encodingCache) valid(storages []*PalettedStorage) bool {
+	if c == nil || len(c.storages) != len(storages) {
+		return false
+	}
+	for i, storage := range storages {
+		if c.storages[i] != storage || c.versions[i] != storage.version {
+			return false
+		}
+	}
+	return true
+}
+
+// columnCache holds the NetworkData of a Chunk, built from the encodingCaches of its sub chunks and biomes.
+type columnCache struct {
+	subs   []*encodingCache
+	biomes *encodingCache
+	data   *NetworkData
+}
+
+// valid checks if the columnCache holds the current NetworkData of the Chunk passed. This is the case if the
+// encodingCaches it was built from are still the current and valid caches of the Chunk.
+func (c *columnCache) valid(chunk *Chunk) bool {
+	if c == nil || len(c.subs) != len(chunk.sub) {
+		return false
+	}
+	for i, sub := range chunk.sub {
+		if cache := sub.network.Load(); cache != c.subs[i] || !cache.valid(sub.storages) {
+			return false
+		}
+	}
+	cache := chunk.networkBiomes.Load()
+	return cache == c.biomes && cache.valid(chunk.biomes)
+}
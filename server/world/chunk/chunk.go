@@ -2,6 +2,7 @@ package chunk
 
 import (
 	"slices"
+	"sync/atomic"
 
 	"github.com/df-mc/dragonfly/server/block/cube"
 )
@@ -26,6 +27,10 @@ type Chunk struct {
 	sub []*SubChunk
 	// biomes is an array of biome IDs. There is one biome ID for every column in the chunk.
 	biomes []*PalettedStorage
+	// networkBiomes holds the network encoding of the biomes last returned by EncodeBiomes.
+	networkBiomes atomic.Pointer[encodingCache]
+	// network holds the network encoding of the chunk last returned by EncodeNetwork.
+	network atomic.Pointer[columnCache]
 }
 
 // New initialises a new chunk and returns it, so that it may be used.
@@ -42,21 +42,89 @@ type (
 	}
 )
 
+// NetworkData holds the network encoding of a Chunk, as returned by EncodeNetwork.
+type NetworkData struct {
+	SerialisedData
+	// Hashes holds the xxhash of every sub chunk in SubChunks, followed by that of Biomes. Clients with the
+	// client cache enabled use these hashes to identify the data as blobs.
+	Hashes []uint64
+	// Payload holds all SubChunks followed by Biomes, as sent to clients without the client cache enabled.
+	Payload []byte
+}
+
 // Encode encodes Chunk to an intermediate representation SerialisedData. An Encoding may be passed to encode either for
 // network or disk purposed, the most notable difference being that the network encoding generally uses varints and no
-// NBT.
+// NBT. The network encoding of a Chunk is cached until it is changed, so the data returned must not be modified.
 func Encode(c *Chunk, e Encoding) SerialisedData {
+	if e == Encoding(NetworkEncoding) {
+		return EncodeNetwork(c).SerialisedData
+	}
 	d := SerialisedData{SubChunks: make([][]byte, len(c.sub))}
 	for i := range c.sub {
-		d.SubChunks[i] = EncodeSubChunk(c, e, i)
+		d.SubChunks[i] = encodeSubChunk(c, e, i)
+	}
+	d.Biomes = encodeBiomes(c, e)
+	return d
+}
+
+// EncodeNetwork encodes a Chunk using the NetworkEncoding. The NetworkData returned is cached and shared by all
+// viewers of the Chunk until it is changed, so it must not be modified.
+func EncodeNetwork(c *Chunk) *NetworkData {
+	if cache := c.network.Load(); cache.valid(c) {
+		return cache.data
+	}
+	cache := &columnCache{subs: make([]*encodingCache, len(c.sub)), biomes: networkBiomes(c)}
+	d := &NetworkData{SerialisedData: SerialisedData{SubChunks: make([][]byte, len(c.sub))}, Hashes: make([]uint64, len(c.sub)+1)}
+	size := len(cache.biomes.data)
+	for i := range c.sub {
+		sub := networkSubChunk(c, i)
+		cache.subs[i], d.SubChunks[i], d.Hashes[i] = sub, sub.data, sub.hash
+		size += len(sub.data)
+	}
+	d.Biomes, d.Hashes[len(c.sub)] = cache.biomes.data, cache.biomes.hash
+
+	d.Payload = make([]byte, 0, size)
+	for _, sub := range d.SubChunks {
+		d.Payload = append(d.Payload, sub...)
 	}
-	d.Biomes = EncodeBiomes(c, e)
+	d.Payload = append(d.Payload, d.Biomes...)
+	cache.data = d
+	c.network.Store(cache)
 	return d
 }
 
 // EncodeSubChunk encodes a sub-chunk from a chunk into bytes. An Encoding may be passed to encode either for network or
 // disk purposed, the most notable difference being that the network encoding generally uses varints and no NBT.
+// The network encoding of a sub-chunk is cached until a block in it is changed, so the bytes returned must not be
+// modified.
 func EncodeSubChunk(c *Chunk, e Encoding, ind int) []byte {
+	if e == Encoding(NetworkEncoding) {
+		return networkSubChunk(c, ind).data
+	}
+	return encodeSubChunk(c, e, ind)
+}
+
+// EncodeSubChunkHash returns the network encoding of a sub-chunk from a chunk, as returned by EncodeSubChunk, along
+// with its xxhash, which clients with the client cache enabled use to identify it as a blob.
+func EncodeSubChunkHash(c *Chunk, ind int) ([]byte, uint64) {
+	cache := networkSubChunk(c, ind)
+	return cache.data, cache.hash
+}
+
+// networkSubChunk returns the encodingCache holding the network encoding of a sub-chunk from a chunk, encoding the
+// sub-chunk if its cached encoding is no longer valid.
+func networkSubChunk(c *Chunk, ind int) *encodingCache {
+	s := c.sub[ind]
+	if cache := s.network.Load(); cache.valid(s.storages) {
+		return cache
+	}
+	cache := newEncodingCache(s.storages, encodeSubChunk(c, NetworkEncoding, ind))
+	s.network.Store(cache)
+	return cache
+}
+
+// encodeSubChunk encodes a sub-chunk from a chunk into bytes using the Encoding passed, without using the cache.
+func encodeSubChunk(c *Chunk, e Encoding, ind int) []byte {
 	buf := pool.Get().(*bytes.Buffer)
 	defer func() {
 		buf.Reset()
@@ -75,7 +143,35 @@ func EncodeSubChunk(c *Chunk, e Encoding, ind int) []byte {
 
 // EncodeBiomes encodes the biomes of a chunk into bytes. An Encoding may be passed to encode either for network or
 // disk purposed, the most notable difference being that the network encoding generally uses varints and no NBT.
+// The network encoding of the biomes is cached until a biome is changed, so the bytes returned must not be
+// modified.
 func EncodeBiomes(c *Chunk, e Encoding) []byte {
+	if e == Encoding(NetworkEncoding) {
+		return networkBiomes(c).data
+	}
+	return encodeBiomes(c, e)
+}
+
+// EncodeBiomesHash returns the network encoding of the biomes of a chunk, as returned by EncodeBiomes, along with its
+// xxhash, which clients with the client cache enabled use to identify it as a blob.
+func EncodeBiomesHash(c *Chunk) ([]byte, uint64) {
+	cache := networkBiomes(c)
+	return cache.data, cache.hash
+}
+
+// networkBiomes returns the encodingCache holding the network encoding of the biomes of a chunk, encoding the
+// biomes if their cached encoding is no longer valid.
+func networkBiomes(c *Chunk) *encodingCache {
+	if cache := c.networkBiomes.Load(); cache.valid(c.biomes) {
+		return cache
+	}
+	cache := newEncodingCache(c.biomes, encodeBiomes(c, NetworkEncoding))
+	c.networkBiomes.Store(cache)
+	return cache
+}
+
+// encodeBiomes encodes the biomes of a chunk into bytes using the Encoding passed, without using the cache.
+func encodeBiomes(c *Chunk, e Encoding) []byte {
 	buf := pool.Get().(*bytes.Buffer)
 	defer func() {
 		buf.Reset()
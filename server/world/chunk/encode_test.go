@@ -0,0 +1,62 @@
+package chunk
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/df-mc/dragonfly/server/block/cube"
+)
+
+func TestEncodeNetworkCache(t *testing.T) {
+	c := New(testBlockRegistry{}, cube.Range{-64, 319})
+	sub := EncodeSubChunk(c, NetworkEncoding, 4)
+	if again := EncodeSubChunk(c, NetworkEncoding, 4); &again[0] != &sub[0] {
+		t.Fatalf("EncodeSubChunk() of unchanged sub chunk was encoded again")
+	}
+	d := EncodeNetwork(c)
+	if again := EncodeNetwork(c); again != d {
+		t.Fatalf("EncodeNetwork() of unchanged chunk was encoded again")
+	}
+	if !bytes.Equal(d.SubChunks[4], sub) {
+		t.Fatalf("EncodeNetwork() sub chunk = %v, want %v", d.SubChunks[4], sub)
+	}
+
+	c.SetBlock(1, 2, 3, 0, 1)
+	changed := EncodeSubChunk(c, NetworkEncoding, 4)
+	if bytes.Equal(changed, sub) {
+		t.Fatalf("EncodeSubChunk() after SetBlock returned the cached encoding")
+	}
+	if !bytes.Equal(changed, encodeSubChunk(c, NetworkEncoding, 4)) {
+		t.Fatalf("EncodeSubChunk() after SetBlock = %v, want %v", changed, encodeSubChunk(c, NetworkEncoding, 4))
+	}
+	if data, hash := EncodeSubChunkHash(c, 4); hash != xxhash.Sum64(data) {
+		t.Fatalf("EncodeSubChunkHash() hash = %v, want %v", hash, xxhash.Sum64(data))
+	}
+
+	after := EncodeNetwork(c)
+	if after == d || !bytes.Equal(after.SubChunks[4], changed) {
+		t.Fatalf("EncodeNetwork() after SetBlock returned the cached encoding")
+	}
+	for i, sub := range after.SubChunks {
+		if after.Hashes[i] != xxhash.Sum64(sub) {
+			t.Fatalf("EncodeNetwork() hash of sub chunk %v = %v, want %v", i, after.Hashes[i], xxhash.Sum64(sub))
+		}
+	}
+	if !bytes.Equal(after.Payload, append(bytes.Join(after.SubChunks, nil), after.Biomes...)) {
+		t.Fatalf("EncodeNetwork() payload is not the concatenation of sub chunks and biomes")
+	}
+
+	c.SetBiome(1, 2, 3, 5)
+	if biomes := EncodeNetwork(c); biomes == after || bytes.Equal(biomes.Biomes, after.Biomes) {
+		t.Fatalf("EncodeNetwork() after SetBiome returned the cached biomes")
+	}
+	if !bytes.Equal(EncodeBiomes(c, NetworkEncoding), encodeBiomes(c, NetworkEncoding)) {
+		t.Fatalf("EncodeBiomes() after SetBiome returned the cached biomes")
+	}
+}
+
+// testBlockRegistry is a BlockRegistry in which air has runtime ID 0.
+type testBlockRegistry struct{ BlockRegistry }
+
+func (testBlockRegistry) AirRuntimeID() uint32 { return 0 }
@@ -38,6 +38,10 @@ type PalettedStorage struct {
 	// indices contains all indices in the PalettedStorage. This slice has a variable size, but may not be changed
 	// unless the whole PalettedStorage is resized, including the Palette.
 	indices []uint32
+
+	// version is incremented every time a value is set in the PalettedStorage, so that cached encodings of the
+	// PalettedStorage may be invalidated.
+	version uint32
 }
 
 // newPalettedStorage creates a new block storage using the uint32 slice as the indices and the palette passed.
@@ -91,6 +95,7 @@ func (storage *PalettedStorage) Set(x, y, z byte, v uint32) {
 		index = storage.addNew(v)
 	}
 	storage.setPaletteIndex(x&15, y&15, z&15, uint16(index))
+	storage.version++
 }
 
 // Equal checks if two PalettedStorages are equal value wise. False is returned
@@ -168,7 +173,8 @@ func (storage *PalettedStorage) resize(newPaletteSize paletteSize) {
 			}
 		}
 	}
-	// Set the new storage.
+	// Set the new storage, keeping the version so that cached encodings remain invalidated.
+	newStorage.version = storage.version
 	*storage = *newStorage
 }
 
@@ -238,5 +244,6 @@ func (storage *PalettedStorage) compact() {
 			}
 		}
 	}
+	newStorage.version = storage.version + 1
 	*storage = *newStorage
 }
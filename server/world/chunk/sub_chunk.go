@@ -1,6 +1,9 @@
 package chunk
 
-import "slices"
+import (
+	"slices"
+	"sync/atomic"
+)
 
 // SubChunk is a cube of blocks located in a chunk. It has a size of 16x16x16 blocks and forms part of a stack
 // that forms a Chunk.
@@ -9,6 +12,9 @@ type SubChunk struct {
 	storages   []*PalettedStorage
 	blockLight []uint8
 	skyLight   []uint8
+
+	// network holds the network encoding of the SubChunk last returned by EncodeSubChunk.
+	network atomic.Pointer[encodingCache]
 }
 
 // Equals returns if the sub chunk passed is equal to the current one.
@@ -52,7 +52,12 @@ func (r *chunkRequest) doImmediate(tx *Tx) *Column {
 func (r *chunkRequest) load(w *World) {
 	r.col, r.err = w.loadChunk(r.pos)
 	close(r.done)
-	w.Do(r.signal)
+	w.Do(func(tx *Tx) {
+		// The chunk is loaded again from the Provider when replaying, so
+		// adding it does not need to be recorded.
+		tx.SkipRecording()
+		r.signal(tx)
+	})
 }
 
 // abort cancels a request that will never be carried out because the world is
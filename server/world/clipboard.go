@@ -0,0 +1,203 @@
+package world
+
+import (
+	"math"
+	"reflect"
+
+	"github.com/df-mc/dragonfly/server/block/cube"
+)
+
+// Clipboard holds a copy of the blocks in a region of a World, created using Tx.Copy. A Clipboard may be pasted
+// into any World using Tx.Paste, optionally after being rotated or mirrored. Clipboard implements Structure, so it
+// may also be built using Tx.BuildStructure.
+type Clipboard struct {
+	reg BlockRegistry
+	// size holds the width, height and length of the Clipboard.
+	size [3]int
+	// origin is the position of the origin of the Clipboard relative to its lowest corner. When pasted, the
+	// origin of the Clipboard is placed at the position passed to Tx.Paste.
+	origin cube.Pos
+	// palette holds every distinct block in the Clipboard. blocks and liquids hold indices into the palette for
+	// the first and second layer of every position in the Clipboard.
+	palette         []Block
+	blocks, liquids []uint32
+	// entities holds the blocks with block entity data in the Clipboard, indexed by their index in blocks.
+	entities map[int]Block
+}
+
+// newClipboard returns an empty Clipboard with the size and origin passed.
+func newClipboard(reg BlockRegistry, size [3]int, origin cube.Pos) *Clipboard {
+	n := size[0] * size[1] * size[2]
+	return &Clipboard{
+		reg:      reg,
+		size:     size,
+		origin:   origin,
+		blocks:   make([]uint32, n),
+		liquids:  make([]uint32, n),
+		entities: make(map[int]Block),
+	}
+}
+
+// Dimensions returns the width, height and length of the Clipboard.
+func (c *Clipboard) Dimensions() [3]int {
+	return c.size
+}
+
+// Origin returns the position of the origin of the Clipboard relative to its lowest corner.
+func (c *Clipboard) Origin() cube.Pos {
+	return c.origin
+}
+
+// At returns the block and liquid at a position in the Clipboard, relative to its lowest corner. Liquid is nil if
+// there is no liquid at the position.
+func (c *Clipboard) At(x, y, z int, _ func(x, y, z int) Block) (Block, Liquid) {
+	i := c.index(x, y, z)
+	b := c.palette[c.blocks[i]]
+	if be, ok := c.entities[i]; ok {
+		b = cloneBlockEntity(be)
+	}
+	liq, _ := c.palette[c.liquids[i]].(Liquid)
+	return b, liq
+}
+
+// Rotate returns a copy of the Clipboard rotated around its origin by the yaw of the cube.Rotation passed, rounded
+// to a multiple of 90 degrees. Blocks with a direction, such as stairs, are rotated along with it.
+func (c *Clipboard) Rotate(r cube.Rotation) *Clipboard {
+	turns := ((int(math.Round(r.Yaw()/90)) % 4) + 4) % 4
+	for range turns {
+		c = c.transform(func(p cube.Pos) cube.Pos {
+			return cube.Pos{-p[2], p[1], p[0]}
+		}, func(b Block) Block {
+			return transformBlock(b, c.reg, rotateField)
+		})
+	}
+	return c
+}
+
+// Mirror returns a copy of the Clipboard mirrored around its origin along the cube.Axis passed, which must be
+// either cube.X or cube.Z. Blocks with a direction, such as stairs, are mirrored along with it.
+func (c *Clipboard) Mirror(a cube.Axis) *Clipboard {
+	if a == cube.Y {
+		return c
+	}
+	return c.transform(func(p cube.Pos) cube.Pos {
+		if a == cube.X {
+			p[0] = -p[0]
+		} else {
+			p[2] = -p[2]
+		}
+		return p
+	}, func(b Block) Block {
+		return transformBlock(b, c.reg, func(v reflect.Value) (reflect.Value, bool) {
+			return mirrorField(v, a)
+		})
+	})
+}
+
+// transform returns a copy of the Clipboard with every position moved using pos, relative to the origin, and every
+// block changed using block.
+func (c *Clipboard) transform(pos func(p cube.Pos) cube.Pos, block func(b Block) Block) *Clipboard {
+	corners := [2]cube.Pos{
+		pos(cube.Pos{}.Sub(c.origin)),
+		pos(cube.Pos{c.size[0] - 1, c.size[1] - 1, c.size[2] - 1}.Sub(c.origin)),
+	}
+	lo, hi := cube.Min(corners[0], corners[1]), cube.Max(corners[0], corners[1])
+	res := newClipboard(c.reg, [3]int{hi[0] - lo[0] + 1, hi[1] - lo[1] + 1, hi[2] - lo[2] + 1}, cube.Pos{}.Sub(lo))
+
+	res.palette = make([]Block, len(c.palette))
+	for i, b := range c.palette {
+		res.palette[i] = block(b)
+	}
+	for y := range c.size[1] {
+		for z := range c.size[2] {
+			for x := range c.size[0] {
+				i := c.index(x, y, z)
+				p := pos(cube.Pos{x, y, z}.Sub(c.origin)).Add(res.origin)
+				j := res.index(p[0], p[1], p[2])
+				res.blocks[j], res.liquids[j] = c.blocks[i], c.liquids[i]
+				if be, ok := c.entities[i]; ok {
+					res.entities[j] = block(be)
+				}
+			}
+		}
+	}
+	return res
+}
+
+// index returns the index in the blocks and liquids of the Clipboard of a position relative to its lowest corner.
+func (c *Clipboard) index(x, y, z int) int {
+	return x + c.size[0]*(z+c.size[2]*y)
+}
+
+// cloneBlockEntity returns a copy of the block entity passed with its data encoded and decoded again, so that the
+// copy does not share data, such as an inventory, with the original.
+func cloneBlockEntity(b Block) Block {
+	if n, ok := b.(NBTer); ok {
+		if res, ok := n.DecodeNBT(n.EncodeNBT()).(Block); ok {
+			return res
+		}
+	}
+	return b
+}
+
+// transformBlock changes the exported fields of the Block passed using f. If the resulting block state does not
+// exist, the Block passed is returned unchanged.
+func transformBlock(b Block, reg BlockRegistry, f func(v reflect.Value) (reflect.Value, bool)) Block {
+	v := reflect.ValueOf(b)
+	if !v.IsValid() || v.Kind() != reflect.Struct {
+		return b
+	}
+	res, changed := reflect.New(v.Type()).Elem(), false
+	res.Set(v)
+	for i := range res.NumField() {
+		if !v.Type().Field(i).IsExported() {
+			continue
+		}
+		if nv, ok := f(res.Field(i)); ok {
+			res.Field(i).Set(nv)
+			changed = true
+		}
+	}
+	if !changed {
+		return b
+	}
+	nb, ok := res.Interface().(Block)
+	if !ok {
+		return b
+	}
+	if _, ok := reg.BlockByName(nb.EncodeBlock()); !ok {
+		return b
+	}
+	return nb
+}
+
+// rotateField rotates the value passed 90 degrees clockwise if its type has a RotateRight method returning the same
+// type, such as cube.Direction, cube.Face and cube.Axis.
+func rotateField(v reflect.Value) (reflect.Value, bool) {
+	m := v.MethodByName("RotateRight")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 || m.Type().Out(0) != v.Type() {
+		return v, false
+	}
+	return m.Call(nil)[0], true
+}
+
+// mirrorField mirrors the value passed along the cube.Axis passed if it is a cube.Direction, cube.Face or
+// cube.Orientation.
+func mirrorField(v reflect.Value, a cube.Axis) (reflect.Value, bool) {
+	switch val := v.Interface().(type) {
+	case cube.Direction:
+		if val.Face().Axis() == a {
+			return reflect.ValueOf(val.Opposite()), true
+		}
+	case cube.Face:
+		if val.Axis() == a {
+			return reflect.ValueOf(val.Opposite()), true
+		}
+	case cube.Orientation:
+		if a == cube.X {
+			return reflect.ValueOf(cube.OrientationFromYaw(360 - val.Yaw())), true
+		}
+		return reflect.ValueOf(cube.OrientationFromYaw(540 - val.Yaw())), true
+	}
+	return v, false
+}
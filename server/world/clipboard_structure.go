@@ -0,0 +1,150 @@
+package world
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/world/chunk"
+	"github.com/sandertv/gophertunnel/minecraft/nbt"
+)
+
+// structureFormatVersion is the format version of .mcstructure files written by Clipboard.WriteStructure.
+const structureFormatVersion = 1
+
+// mcstructure is the layout of a .mcstructure file, which is the format used by structure blocks of Bedrock
+// Edition.
+type mcstructure struct {
+	FormatVersion int32   `nbt:"format_version"`
+	Size          []int32 `nbt:"size"`
+	Structure     struct {
+		BlockIndices [][]int32      `nbt:"block_indices"`
+		Entities     []any          `nbt:"entities"`
+		Palette      map[string]any `nbt:"palette"`
+	} `nbt:"structure"`
+	WorldOrigin []int32 `nbt:"structure_world_origin"`
+}
+
+// WriteStructure writes the Clipboard to w in the .mcstructure format used by structure blocks. The origin of the
+// Clipboard is stored as the origin of the structure, so that it is kept when read using ReadStructure.
+func (c *Clipboard) WriteStructure(w io.Writer) error {
+	enc := chunk.BlockPaletteEncoding{Blocks: c.reg}
+	palette, rids := make([]any, len(c.palette)), make([]uint32, len(c.palette))
+	for i, b := range c.palette {
+		rids[i] = c.reg.BlockRuntimeID(b)
+		palette[i] = enc.EncodeBlockState(rids[i])
+	}
+	positionData := make(map[string]any, len(c.entities))
+
+	var s mcstructure
+	s.FormatVersion = structureFormatVersion
+	s.Size = []int32{int32(c.size[0]), int32(c.size[1]), int32(c.size[2])}
+	s.WorldOrigin = []int32{int32(-c.origin[0]), int32(-c.origin[1]), int32(-c.origin[2])}
+	s.Structure.Entities = []any{}
+	s.Structure.BlockIndices = [][]int32{make([]int32, len(c.blocks)), make([]int32, len(c.liquids))}
+
+	for x := range c.size[0] {
+		for y := range c.size[1] {
+			for z := range c.size[2] {
+				i, j := c.index(x, y, z), structureIndex(c.size, x, y, z)
+				s.Structure.BlockIndices[0][j] = int32(c.blocks[i])
+				s.Structure.BlockIndices[1][j] = int32(c.liquids[i])
+				if rids[c.liquids[i]] == c.reg.AirRuntimeID() {
+					// The second layer holds no block, which is written as -1 in structures.
+					s.Structure.BlockIndices[1][j] = -1
+				}
+				if n, ok := c.entities[i].(NBTer); ok {
+					positionData[strconv.Itoa(j)] = map[string]any{"block_entity_data": n.EncodeNBT()}
+				}
+			}
+		}
+	}
+	s.Structure.Palette = map[string]any{
+		"default": map[string]any{"block_palette": palette, "block_position_data": positionData},
+	}
+	return nbt.NewEncoderWithEncoding(w, nbt.LittleEndian).Encode(s)
+}
+
+// ReadStructure reads a Clipboard in the .mcstructure format from r. Blocks in the structure are looked up in the
+// BlockRegistry passed, or in DefaultBlockRegistry if nil. An error is returned if the structure could not be
+// decoded or if it holds blocks that do not exist.
+func ReadStructure(r io.Reader, reg BlockRegistry) (*Clipboard, error) {
+	if reg == nil {
+		reg = DefaultBlockRegistry
+	}
+	var s mcstructure
+	if err := nbt.NewDecoderWithEncoding(r, nbt.LittleEndian).Decode(&s); err != nil {
+		return nil, fmt.Errorf("decode structure: %w", err)
+	}
+	if len(s.Size) != 3 || len(s.WorldOrigin) != 3 || len(s.Structure.BlockIndices) == 0 {
+		return nil, fmt.Errorf("decode structure: invalid size, origin or block indices")
+	}
+	size := [3]int{int(s.Size[0]), int(s.Size[1]), int(s.Size[2])}
+	if size[0] <= 0 || size[1] <= 0 || size[2] <= 0 || size[0]*size[1]*size[2] != len(s.Structure.BlockIndices[0]) {
+		return nil, fmt.Errorf("decode structure: size %v does not match block indices", size)
+	}
+	c := newClipboard(reg, size, cube.Pos{-int(s.WorldOrigin[0]), -int(s.WorldOrigin[1]), -int(s.WorldOrigin[2])})
+
+	def, _ := s.Structure.Palette["default"].(map[string]any)
+	entries, _ := def["block_palette"].([]any)
+	positionData, _ := def["block_position_data"].(map[string]any)
+
+	enc := chunk.BlockPaletteEncoding{Blocks: reg}
+	// The last entry of the palette is air, which is used for positions that hold no block (-1) in the structure.
+	c.palette = make([]Block, 0, len(entries)+1)
+	for _, e := range entries {
+		m, _ := e.(map[string]any)
+		rid, err := enc.DecodeBlockState(m)
+		if err != nil {
+			return nil, fmt.Errorf("decode structure: %w", err)
+		}
+		c.palette = append(c.palette, reg.BlockByRuntimeIDOrAir(rid))
+	}
+	airIndex := uint32(len(c.palette))
+	c.palette = append(c.palette, reg.Air())
+
+	layers := [2][]uint32{c.blocks, c.liquids}
+	for layer, indices := range s.Structure.BlockIndices[:min(len(s.Structure.BlockIndices), 2)] {
+		if len(indices) != len(c.blocks) {
+			return nil, fmt.Errorf("decode structure: expected %v block indices, got %v", len(c.blocks), len(indices))
+		}
+		for x := range size[0] {
+			for y := range size[1] {
+				for z := range size[2] {
+					i, j := c.index(x, y, z), structureIndex(size, x, y, z)
+					layers[layer][i] = airIndex
+					if v := indices[j]; v >= 0 && int(v) < len(entries) {
+						layers[layer][i] = uint32(v)
+					}
+				}
+			}
+		}
+	}
+	if len(s.Structure.BlockIndices) == 1 {
+		for i := range c.liquids {
+			c.liquids[i] = airIndex
+		}
+	}
+	for key, v := range positionData {
+		j, err := strconv.Atoi(key)
+		data, _ := v.(map[string]any)
+		blockEntity, _ := data["block_entity_data"].(map[string]any)
+		if err != nil || blockEntity == nil || j < 0 || j >= len(c.blocks) {
+			continue
+		}
+		x, y, z := j/(size[1]*size[2]), (j/size[2])%size[1], j%size[2]
+		i := c.index(x, y, z)
+		if n, ok := c.palette[c.blocks[i]].(NBTer); ok {
+			if b, ok := n.DecodeNBT(blockEntity).(Block); ok {
+				c.entities[i] = b
+			}
+		}
+	}
+	return c, nil
+}
+
+// structureIndex returns the index of a position in the block indices of a .mcstructure file with the size passed.
+func structureIndex(size [3]int, x, y, z int) int {
+	return (x*size[1]+y)*size[2] + z
+}
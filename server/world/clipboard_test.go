@@ -0,0 +1,144 @@
+package world_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/df-mc/dragonfly/server/block"
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/entity"
+	"github.com/df-mc/dragonfly/server/item"
+	"github.com/df-mc/dragonfly/server/world"
+)
+
+// copyTestClipboard copies a 3x1x2 region with its origin at its lowest
+// corner, holding stone at (0, 0, 0), stairs facing east at (2, 0, 0) and a
+// named chest holding an item at (0, 0, 1).
+func copyTestClipboard(t *testing.T) *world.Clipboard {
+	w := world.Config{Synchronous: true, Entities: entity.DefaultRegistry}.New()
+	t.Cleanup(func() { _ = w.Close() })
+
+	var c *world.Clipboard
+	w.Do(func(tx *world.Tx) {
+		chest := block.NewChest()
+		chest.CustomName = "Loot"
+		_ = chest.Inventory(tx, cube.Pos{}).SetItem(0, item.NewStack(item.Diamond{}, 3))
+
+		tx.SetBlock(cube.Pos{0, 0, 0}, block.Stone{}, nil)
+		tx.SetBlock(cube.Pos{2, 0, 0}, testStairs(cube.East), nil)
+		tx.SetBlock(cube.Pos{0, 0, 1}, chest, nil)
+		c = tx.Copy(cube.Box(0, 0, 0, 3, 1, 2), cube.Pos{})
+	})
+	if c == nil {
+		t.Fatalf("Copy() = nil, want clipboard")
+	}
+	return c
+}
+
+func testStairs(facing cube.Direction) block.Stairs {
+	return block.Stairs{Block: block.Planks{Wood: block.OakWood()}, Facing: facing}
+}
+
+// clipboardBlock returns the block at a position of a Clipboard relative to
+// its lowest corner.
+func clipboardBlock(c *world.Clipboard, pos cube.Pos) world.Block {
+	b, _ := c.At(pos[0], pos[1], pos[2], nil)
+	return b
+}
+
+func TestClipboardRotate(t *testing.T) {
+	c := copyTestClipboard(t)
+
+	r := c.Rotate(cube.Rotation{90, 0})
+	if dim := r.Dimensions(); dim != [3]int{2, 1, 3} {
+		t.Fatalf("Dimensions() after rotating 90 degrees = %v, want [2 1 3]", dim)
+	}
+	if origin := r.Origin(); origin != (cube.Pos{1, 0, 0}) {
+		t.Fatalf("Origin() after rotating 90 degrees = %v, want (1, 0, 0)", origin)
+	}
+	// (x, y, z) relative to the origin is moved to (-z, y, x).
+	if b := clipboardBlock(r, cube.Pos{1, 0, 0}); b != (block.Stone{}) {
+		t.Fatalf("block at origin after rotating = %v, want stone", b)
+	}
+	if b := clipboardBlock(r, cube.Pos{1, 0, 2}); b != testStairs(cube.South) {
+		t.Fatalf("stairs after rotating = %v, want stairs facing south", b)
+	}
+	if b, ok := clipboardBlock(r, cube.Pos{0, 0, 0}).(block.Chest); !ok || b.Facing != block.NewChest().Facing.RotateRight() {
+		t.Fatalf("chest after rotating = %v, want chest rotated right", b)
+	}
+
+	full := c.Rotate(cube.Rotation{360, 0})
+	if full.Dimensions() != c.Dimensions() || clipboardBlock(full, cube.Pos{2, 0, 0}) != testStairs(cube.East) {
+		t.Fatalf("Rotate() by 360 degrees changed the clipboard")
+	}
+	if back := r.Rotate(cube.Rotation{-90, 0}); clipboardBlock(back, cube.Pos{2, 0, 0}) != testStairs(cube.East) {
+		t.Fatalf("Rotate() by -90 degrees did not undo rotating by 90 degrees")
+	}
+}
+
+func TestClipboardMirror(t *testing.T) {
+	c := copyTestClipboard(t)
+
+	m := c.Mirror(cube.X)
+	if dim := m.Dimensions(); dim != [3]int{3, 1, 2} {
+		t.Fatalf("Dimensions() after mirroring = %v, want [3 1 2]", dim)
+	}
+	if origin := m.Origin(); origin != (cube.Pos{2, 0, 0}) {
+		t.Fatalf("Origin() after mirroring = %v, want (2, 0, 0)", origin)
+	}
+	if b := clipboardBlock(m, cube.Pos{0, 0, 0}); b != testStairs(cube.West) {
+		t.Fatalf("stairs after mirroring along x = %v, want stairs facing west", b)
+	}
+	if b := clipboardBlock(m, cube.Pos{2, 0, 0}); b != (block.Stone{}) {
+		t.Fatalf("block at origin after mirroring = %v, want stone", b)
+	}
+
+	// Stairs facing east are not changed when mirrored along z.
+	if b := clipboardBlock(c.Mirror(cube.Z), cube.Pos{2, 0, 1}); b != testStairs(cube.East) {
+		t.Fatalf("stairs after mirroring along z = %v, want stairs facing east", b)
+	}
+	if y := c.Mirror(cube.Y); y != c {
+		t.Fatalf("Mirror() along y returned a different clipboard")
+	}
+}
+
+func TestClipboardStructure(t *testing.T) {
+	c := copyTestClipboard(t).Rotate(cube.Rotation{90, 0})
+
+	var buf bytes.Buffer
+	if err := c.WriteStructure(&buf); err != nil {
+		t.Fatalf("WriteStructure() = %v, want nil", err)
+	}
+	read, err := world.ReadStructure(&buf, nil)
+	if err != nil {
+		t.Fatalf("ReadStructure() = %v, want nil", err)
+	}
+	if read.Dimensions() != c.Dimensions() || read.Origin() != c.Origin() {
+		t.Fatalf("ReadStructure() size and origin = %v, %v, want %v, %v", read.Dimensions(), read.Origin(), c.Dimensions(), c.Origin())
+	}
+	dim := c.Dimensions()
+	for x := range dim[0] {
+		for z := range dim[2] {
+			pos := cube.Pos{x, 0, z}
+			want, got := clipboardBlock(c, pos), clipboardBlock(read, pos)
+			if _, ok := want.(block.Chest); ok {
+				continue
+			}
+			if want != got {
+				t.Fatalf("block at %v after round trip = %v, want %v", pos, got, want)
+			}
+		}
+	}
+
+	chest, ok := clipboardBlock(read, cube.Pos{0, 0, 0}).(block.Chest)
+	if !ok || chest.CustomName != "Loot" {
+		t.Fatalf("chest after round trip = %v, want chest named Loot", chest)
+	}
+	if it, _ := chest.Inventory(nil, cube.Pos{}).Item(0); it.Count() != 3 {
+		t.Fatalf("item in chest after round trip = %v, want 3 diamonds", it)
+	}
+
+	if _, err := world.ReadStructure(bytes.NewReader([]byte{1, 2, 3}), nil); err == nil {
+		t.Fatalf("ReadStructure() of invalid data = nil, want error")
+	}
+}
@@ -76,6 +76,21 @@ type Config struct {
 	// use NewBlockRegistry(), register blocks/states, and call Finalize().
 	Blocks BlockRegistry
 
+	// Recorder records the ticks, transactions and player inputs of the World
+	// so that they may be replayed using a Replay. If nil, nothing is recorded.
+	// If RandSource is also set, the random numbers of the World cannot be
+	// reproduced when replaying.
+	Recorder *Recorder
+	// ActivationRanges holds the ActivationRange for every ActivationCategory
+	// of entities that should be ticked less frequently, or not at all, when
+	// far away from players. Entities of categories without an
+	// ActivationRange are ticked every tick.
+	ActivationRanges map[ActivationCategory]ActivationRange
+	// Observer observes the time spent on ticks and on loading, generating
+	// and saving chunks, for example to export metrics. If nil, Observer is
+	// set to NopObserver.
+	Observer Observer
+
 	// Synchronous removes the World's own background goroutines. Immediate tasks
 	// from World.Do and Call run on the calling goroutine, the World is not saved
 	// or unloaded automatically, and time only passes on explicit
@@ -123,6 +138,9 @@ func (conf Config) New() *World {
 	if conf.Blocks == nil {
 		conf.Blocks = DefaultBlockRegistry
 	}
+	if conf.Observer == nil {
+		conf.Observer = NopObserver{}
+	}
 
 	// Initialize the passed block registry and also initialize the default block registry which
 	// is used in some vanilla paths.
@@ -132,9 +150,13 @@ func (conf Config) New() *World {
 		provider.SetBlockRegistry(conf.Blocks)
 	}
 
+	header := recordHeader{Version: recordingVersion}
 	if conf.RandSource == nil {
 		t := uint64(time.Now().UnixNano())
 		conf.RandSource = rand.NewPCG(t, t)
+		header.Seeded, header.Seed = true, [2]uint64{t, t}
+	} else if conf.Recorder != nil {
+		conf.Log.Warn("world recording: RandSource set in config, random numbers will not be reproducible")
 	}
 	s := conf.Provider.Settings()
 
@@ -149,6 +171,7 @@ func (conf Config) New() *World {
 		scheduledUpdates: newScheduledTickQueue(s.CurrentTick),
 		redstone:         newRedstoneEngine(s.CurrentTick),
 		entities:         make(map[*EntityHandle]ChunkPos),
+		inactive:         make(map[*EntityHandle]int64),
 		viewers:          make(map[*Loader]Viewer),
 		chunks:           make(map[ChunkPos]*Column),
 		chunkRequests:    make(map[ChunkPos]*chunkRequest),
@@ -162,6 +185,9 @@ func (conf Config) New() *World {
 		ra:               conf.Dim.Range(),
 		set:              s,
 	}
+	s.Lock()
+	w.border, w.borderVersion = s.Borders[w.borderID()], s.bordersVersion.Load()
+	s.Unlock()
 	w.chunkWorkers = newChunkWorkerPool(w)
 	w.weather = weather{w: w}
 	var h Handler = NopHandler{}
@@ -182,5 +208,10 @@ func (conf Config) New() *World {
 	}
 
 	<-w.exec(t.tick)
+	if conf.Recorder != nil {
+		// Recording starts after the first tick, which a replaying World runs
+		// when it is created too.
+		conf.Recorder.start(header)
+	}
 	return w
 }
@@ -37,11 +37,12 @@ func (e *EntityHandle) schedule(f func(tx *Tx, e Entity) error) *Task {
 	if !task.pending() {
 		return task
 	}
+	caller := e.recordCaller()
 	run := func() {
 		if w != nil {
 			defer w.scheduling.Done()
 		}
-		e.runScheduled(task, f, w)
+		e.runScheduled(task, f, w, caller)
 	}
 	if e.currentWorldSynchronous() {
 		run()
@@ -64,6 +65,7 @@ func (e *EntityHandle) scheduleAfter(delay time.Duration, f func(tx *Tx, e Entit
 		return task
 	}
 	task.setCancel(e.wakeScheduled)
+	caller := e.recordCaller()
 	go func() {
 		timer := time.NewTimer(delay)
 		defer timer.Stop()
@@ -75,7 +77,7 @@ func (e *EntityHandle) scheduleAfter(delay time.Duration, f func(tx *Tx, e Entit
 					task.failIfPending(ErrWorldClosed)
 					return
 				}
-				e.runScheduled(task, f, nil)
+				e.runScheduled(task, f, nil, caller)
 				return
 			case <-task.Done():
 				return
@@ -157,16 +159,40 @@ func (e *EntityHandle) currentWorldClosing() bool {
 	return cancelled(closeStarted)
 }
 
+// recordCaller returns the location of the code scheduling a task for the
+// entity if its current world has a Recorder, to be recorded if the task
+// cannot be replayed.
+func (e *EntityHandle) recordCaller() string {
+	e.cond.L.Lock()
+	recording := e.w != nil && e.w != closeWorld && e.w.conf.Recorder != nil
+	e.cond.L.Unlock()
+	if !recording {
+		return ""
+	}
+	return externalCaller()
+}
+
 // runScheduled executes the scheduled entity callback via execWorld with the
 // same completion model as scheduledTransaction: run, drain deferred work,
 // finish the task.
-func (e *EntityHandle) runScheduled(task *Task, f func(tx *Tx, e Entity) error, allowedCloseWorld *World) {
+func (e *EntityHandle) runScheduled(task *Task, f func(tx *Tx, e Entity) error, allowedCloseWorld *World, caller string) {
 	run := e.execWorld(func(tx *Tx, ent Entity) {
 		if !task.begin() {
 			return
 		}
+		recording := tx.w.conf.Recorder != nil
+		var prev *transactionRecord
+		if recording {
+			if caller == "" {
+				caller = "unknown"
+			}
+			prev = tx.w.beginRecord(&transactionRecord{caller: caller})
+		}
 		err := executeWithRecovery(tx.w, func() error { return f(tx, ent) })
 		tx.runDeferred()
+		if recording {
+			tx.w.endRecord(prev)
+		}
 		task.finish(err)
 	}, false, task.Done(), allowedCloseWorld)
 	if !run || task.pending() {
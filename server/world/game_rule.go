@@ -0,0 +1,238 @@
+package world
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// GameRuleValue is a type that the value of a GameRule may have.
+type GameRuleValue interface {
+	bool | int
+}
+
+// GameRule is a typed rule that changes the behaviour of a World, such as whether players keep their inventory when
+// they die or whether fire spreads. The value of a GameRule is stored per World in its Settings, so that it may be
+// persisted by a Provider. New GameRules may be registered using RegisterGameRule.
+type GameRule[T GameRuleValue] struct {
+	name string
+	def  T
+	// client specifies if the GameRule is known by the client and should be sent to viewers of the World.
+	client bool
+	// field is an optional function returning a pointer to the field in Settings that holds the value of the
+	// GameRule. If nil, the value is stored in Settings.GameRules.
+	field func(s *Settings) *T
+}
+
+// gameRule is the type-erased form of a GameRule, used to store GameRules of different types in a single registry.
+type gameRule interface {
+	Name() string
+	value(s *Settings) any
+	set(s *Settings, v any) bool
+	sentToClient() bool
+}
+
+// gameRules holds all GameRules registered, indexed by their name.
+var gameRules = map[string]gameRule{}
+
+// RegisterGameRule registers a custom GameRule with the name and default value passed and returns it. The name
+// should be unique and must not be the name of any of the built-in GameRules: RegisterGameRule panics if a GameRule
+// with the same name was already registered. Custom GameRules are not sent to clients. RegisterGameRule should be
+// called before any World is created, typically in an init function.
+func RegisterGameRule[T GameRuleValue](name string, def T) GameRule[T] {
+	return registerGameRule(GameRule[T]{name: name, def: def})
+}
+
+// registerGameRule registers the GameRule passed and returns it.
+func registerGameRule[T GameRuleValue](r GameRule[T]) GameRule[T] {
+	if _, ok := gameRules[r.name]; ok {
+		panic(fmt.Sprintf("game rule %v is already registered", r.name))
+	}
+	gameRules[r.name] = r
+	return r
+}
+
+// GameRules returns the names of all GameRules registered, in alphabetical order.
+func GameRules() []string {
+	return slices.Sorted(maps.Keys(gameRules))
+}
+
+// Name returns the name of the GameRule, such as 'keepinventory'.
+func (r GameRule[T]) Name() string {
+	return r.name
+}
+
+// Default returns the value of the GameRule in worlds where it was not changed.
+func (r GameRule[T]) Default() T {
+	return r.def
+}
+
+// Value returns the value of the GameRule in the World of the transaction passed. Value does not lock the Settings
+// of the World: The values of all GameRules are copied into the World when any of them changes.
+func (r GameRule[T]) Value(tx *Tx) T {
+	if tx == nil {
+		return r.def
+	}
+	if v, ok := tx.World().gameRuleValues()[r.name].(T); ok {
+		return v
+	}
+	return r.def
+}
+
+// Set changes the value of the GameRule in the World passed. If the GameRule is known by the client, the new value
+// is sent to all viewers of the World.
+func (r GameRule[T]) Set(w *World, v T) {
+	if w == nil {
+		return
+	}
+	w.set.Lock()
+	r.set(w.set, v)
+	w.set.rulesVersion.Add(1)
+	w.set.Unlock()
+
+	if r.client {
+		viewers, _ := w.allViewers()
+		for _, viewer := range viewers {
+			viewer.ViewGameRules(map[string]any{r.name: v})
+		}
+	}
+}
+
+// value returns the value of the GameRule in the Settings passed. The Settings must be locked.
+func (r GameRule[T]) value(s *Settings) any {
+	if r.field != nil {
+		return *r.field(s)
+	}
+	if v, ok := s.GameRules[r.name].(T); ok {
+		return v
+	}
+	return r.def
+}
+
+// set sets the value of the GameRule in the Settings passed. The Settings must be locked. False is returned if the
+// value was not of the type of the GameRule.
+func (r GameRule[T]) set(s *Settings, v any) bool {
+	val, ok := v.(T)
+	if !ok {
+		return false
+	}
+	if r.field != nil {
+		*r.field(s) = val
+		return true
+	}
+	if s.GameRules == nil {
+		s.GameRules = make(map[string]any)
+	}
+	s.GameRules[r.name] = val
+	return true
+}
+
+// sentToClient checks if the GameRule should be sent to viewers.
+func (r GameRule[T]) sentToClient() bool {
+	return r.client
+}
+
+// GameRules returns the values of all GameRules registered in the World, indexed by their name.
+func (w *World) GameRules() map[string]any {
+	m := make(map[string]any, len(gameRules))
+	if w == nil {
+		return m
+	}
+	w.set.Lock()
+	defer w.set.Unlock()
+	for name, r := range gameRules {
+		m[name] = r.value(w.set)
+	}
+	return m
+}
+
+// SetGameRule changes the value of the GameRule with the name passed. It returns false if no GameRule with that
+// name exists or if the value passed is not of the type of the GameRule. SetGameRule is mainly useful for changing
+// GameRules by name, such as in commands. GameRule.Set should be used otherwise.
+func (w *World) SetGameRule(name string, v any) bool {
+	r, ok := gameRules[name]
+	if !ok || w == nil {
+		return false
+	}
+	w.set.Lock()
+	if ok = r.set(w.set, v); ok {
+		w.set.rulesVersion.Add(1)
+	}
+	w.set.Unlock()
+
+	if ok && r.sentToClient() {
+		viewers, _ := w.allViewers()
+		for _, viewer := range viewers {
+			viewer.ViewGameRules(map[string]any{name: v})
+		}
+	}
+	return ok
+}
+
+// gameRuleValues returns the values of all GameRules in the World, indexed by their name. The values are copied from
+// the Settings of the World every time a GameRule is changed, so that GameRule.Value does not need to lock the
+// Settings. gameRuleValues must only be called in a transaction and the map returned must not be modified.
+func (w *World) gameRuleValues() map[string]any {
+	if version := w.set.rulesVersion.Load(); w.rules == nil || version != w.rulesVersion {
+		w.set.Lock()
+		rules := make(map[string]any, len(gameRules))
+		for name, r := range gameRules {
+			rules[name] = r.value(w.set)
+		}
+		w.set.Unlock()
+		w.rules, w.rulesVersion = rules, version
+	}
+	return w.rules
+}
+
+// clientGameRules returns the values of all GameRules in the World that are known by the client.
+func (w *World) clientGameRules() map[string]any {
+	w.set.Lock()
+	defer w.set.Unlock()
+	m := make(map[string]any, len(gameRules))
+	for name, r := range gameRules {
+		if r.sentToClient() {
+			m[name] = r.value(w.set)
+		}
+	}
+	return m
+}
+
+// noinspection SpellCheckingInspection
+var (
+	// GameRuleDoDaylightCycle specifies if the time of the World advances. It is equal to Settings.TimeCycle.
+	GameRuleDoDaylightCycle = registerGameRule(GameRule[bool]{name: "dodaylightcycle", def: true, client: true, field: func(s *Settings) *bool { return &s.TimeCycle }})
+	// GameRuleDoWeatherCycle specifies if the weather of the World changes. It is equal to Settings.WeatherCycle.
+	GameRuleDoWeatherCycle = registerGameRule(GameRule[bool]{name: "doweathercycle", def: true, client: true, field: func(s *Settings) *bool { return &s.WeatherCycle }})
+	// GameRuleKeepInventory specifies if players keep their inventory and experience when they die.
+	GameRuleKeepInventory = registerGameRule(GameRule[bool]{name: "keepinventory", def: false, client: true})
+	// GameRuleDoFireTick specifies if fire spreads and burns out naturally.
+	GameRuleDoFireTick = registerGameRule(GameRule[bool]{name: "dofiretick", def: true, client: true})
+	// GameRuleMobGriefing specifies if explosions caused by entities other than TNT destroy blocks.
+	GameRuleMobGriefing = registerGameRule(GameRule[bool]{name: "mobgriefing", def: true, client: true})
+	// GameRuleTNTExplodes specifies if TNT explodes when ignited.
+	GameRuleTNTExplodes = registerGameRule(GameRule[bool]{name: "tntexplodes", def: true, client: true})
+	// GameRuleNaturalRegeneration specifies if players regenerate health when their food bar is full enough. The
+	// value of this GameRule is not sent to clients, which would otherwise predict regeneration themselves.
+	GameRuleNaturalRegeneration = registerGameRule(GameRule[bool]{name: "naturalregeneration", def: true})
+	// GameRuleShowCoordinates specifies if the coordinates of players are shown on their screen.
+	GameRuleShowCoordinates = registerGameRule(GameRule[bool]{name: "showcoordinates", def: false, client: true})
+	// GameRuleDoImmediateRespawn specifies if players respawn immediately without the death screen being shown.
+	GameRuleDoImmediateRespawn = registerGameRule(GameRule[bool]{name: "doimmediaterespawn", def: false, client: true})
+	// GameRuleFallDamage specifies if players take fall damage.
+	GameRuleFallDamage = registerGameRule(GameRule[bool]{name: "falldamage", def: true, client: true})
+	// GameRuleFireDamage specifies if players take damage from fire, lava and burning.
+	GameRuleFireDamage = registerGameRule(GameRule[bool]{name: "firedamage", def: true, client: true})
+	// GameRuleDrowningDamage specifies if players take damage from drowning.
+	GameRuleDrowningDamage = registerGameRule(GameRule[bool]{name: "drowningdamage", def: true, client: true})
+	// GameRulePVP specifies if players are able to attack other players.
+	GameRulePVP = registerGameRule(GameRule[bool]{name: "pvp", def: true, client: true})
+	// GameRuleDoTileDrops specifies if blocks drop items when broken.
+	GameRuleDoTileDrops = registerGameRule(GameRule[bool]{name: "dotiledrops", def: true, client: true})
+	// GameRuleDoEntityDrops specifies if entities, such as minecarts, drop items when destroyed.
+	GameRuleDoEntityDrops = registerGameRule(GameRule[bool]{name: "doentitydrops", def: true, client: true})
+	// GameRuleShowDeathMessages specifies if death messages are shown in the chat.
+	GameRuleShowDeathMessages = registerGameRule(GameRule[bool]{name: "showdeathmessages", def: true, client: true})
+	// GameRuleSpawnRadius is the radius around the spawn position of the World in which new players are spawned.
+	GameRuleSpawnRadius = registerGameRule(GameRule[int]{name: "spawnradius", def: 5, client: true})
+)
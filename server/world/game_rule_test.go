@@ -0,0 +1,52 @@
+package world
+
+import (
+	"testing"
+)
+
+func TestGameRuleValue(t *testing.T) {
+	w := Config{Synchronous: true}.New()
+	defer w.Close()
+
+	w.Do(func(tx *Tx) {
+		if !GameRuleMobGriefing.Value(tx) || !GameRuleDoDaylightCycle.Value(tx) {
+			t.Fatalf("Value() of unchanged game rules = false, want default true")
+		}
+	})
+
+	GameRuleMobGriefing.Set(w, false)
+	if !w.SetGameRule(GameRuleSpawnRadius.Name(), 10) {
+		t.Fatalf("SetGameRule() = false, want true")
+	}
+	if w.SetGameRule(GameRuleSpawnRadius.Name(), true) {
+		t.Fatalf("SetGameRule() with value of wrong type = true, want false")
+	}
+	w.StopTime()
+	w.Do(func(tx *Tx) {
+		if GameRuleMobGriefing.Value(tx) {
+			t.Fatalf("Value() after Set = true, want false")
+		}
+		if v := GameRuleSpawnRadius.Value(tx); v != 10 {
+			t.Fatalf("Value() after SetGameRule = %v, want 10", v)
+		}
+		if GameRuleDoDaylightCycle.Value(tx) {
+			t.Fatalf("Value() after StopTime = true, want false")
+		}
+	})
+
+	if v := GameRuleMobGriefing.Value(nil); !v {
+		t.Fatalf("Value() without transaction = %v, want default true", v)
+	}
+}
+
+func TestRandomSpawn(t *testing.T) {
+	w := Config{Synchronous: true}.New()
+	defer w.Close()
+
+	GameRuleSpawnRadius.Set(w, 0)
+	w.Do(func(tx *Tx) {
+		if pos := tx.RandomSpawn(); pos != w.Spawn() {
+			t.Fatalf("RandomSpawn() with radius 0 = %v, want %v", pos, w.Spawn())
+		}
+	})
+}
@@ -234,7 +234,7 @@ func (l *Loader) populateLoadQueue() {
 	// what precedence it should have), and put them in the loadQueue in that order.
 	queue := map[int32][]ChunkPos{}
 
-	r := int32(l.r)
+	r, border := int32(l.r), l.w.Border()
 	for x := -r; x <= r; x++ {
 		for z := -r; z <= r; z++ {
 			pos := ChunkPos{x + l.pos[0], z + l.pos[1]}
@@ -243,6 +243,10 @@ func (l *Loader) populateLoadQueue() {
 				// The chunk was outside the chunk radius.
 				continue
 			}
+			if !border.chunkWithin(pos) {
+				// The chunk is fully outside the world border, so it should never be loaded.
+				continue
+			}
 			if _, ok := l.loaded[pos]; ok {
 				// The chunk was already loaded, so we don't need to do anything.
 				continue
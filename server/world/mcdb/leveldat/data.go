@@ -2,10 +2,13 @@ package leveldat
 
 import (
 	"math"
+	"reflect"
+	"strconv"
 	"time"
 
 	"github.com/df-mc/dragonfly/server/block/cube"
 	"github.com/df-mc/dragonfly/server/world"
+	"github.com/go-gl/mathgl/mgl64"
 	"github.com/sandertv/gophertunnel/minecraft/protocol"
 )
 
@@ -154,6 +157,25 @@ type Data struct {
 	AllowAnonymousBlockDropsInEditorWorlds bool           `nbt:"allowAnonymousBlockDropsInEditorWorlds"`
 	PlayerWaypoints                        int32          `nbt:"playerwaypoints"`
 	ServerEditorConnectionPolicy           int32          `nbt:"serverEditorConnectionPolicy"`
+	// CustomGameRules holds the values of game rules registered using world.RegisterGameRule, which have no field
+	// in Data. Bool values are stored as uint8 and int values as int32.
+	CustomGameRules map[string]any `nbt:"dragonflyGameRules"`
+	// Borders holds the world borders of the dimensions of the world, indexed by the ID of the dimension. Vanilla
+	// Bedrock Edition has no world border, so these values are only used by Dragonfly. A missing Border results in
+	// a disabled world border.
+	Borders map[string]Border `nbt:"dragonflyBorders"`
+}
+
+// Border holds the world border of a single dimension stored in Data.
+type Border struct {
+	CentreX         float64 `nbt:"CentreX"`
+	CentreZ         float64 `nbt:"CentreZ"`
+	Size            float64 `nbt:"Size"`
+	TargetSize      float64 `nbt:"TargetSize"`
+	ResizeTicks     int64   `nbt:"ResizeTicks"`
+	DamageBuffer    float64 `nbt:"DamageBuffer"`
+	DamagePerBlock  float64 `nbt:"DamagePerBlock"`
+	WarningDistance float64 `nbt:"WarningDistance"`
 }
 
 // FillDefault fills out d with all the default level.dat values.
@@ -234,6 +256,7 @@ func (d *Data) Settings() *world.Settings {
 	difficulty, _ := world.DifficultyByID(int(d.Difficulty))
 	mode, _ := world.GameModeByID(int(d.GameType))
 	return &world.Settings{
+		GameRules:       d.gameRules(),
 		Name:            d.LevelName,
 		Spawn:           cube.Pos{int(d.SpawnX), int(d.SpawnY), int(d.SpawnZ)},
 		Time:            d.Time,
@@ -247,6 +270,7 @@ func (d *Data) Settings() *world.Settings {
 		DefaultGameMode: mode,
 		Difficulty:      difficulty,
 		TickRange:       d.ServerChunkTickRange,
+		Borders:         d.borders(),
 	}
 }
 
@@ -272,4 +296,126 @@ func (d *Data) PutSettings(s *world.Settings) {
 	d.GameType = int32(mode)
 	difficulty, _ := world.DifficultyID(s.Difficulty)
 	d.Difficulty = int32(difficulty)
+	d.putGameRules(s.GameRules)
+
+	d.putBorders(s.Borders)
+}
+
+// borders returns the world borders stored in d, indexed by the ID of their dimension.
+func (d *Data) borders() map[int]world.Border {
+	borders := make(map[int]world.Border, len(d.Borders))
+	for k, b := range d.Borders {
+		id, err := strconv.Atoi(k)
+		if err != nil {
+			continue
+		}
+		borders[id] = world.Border{
+			Centre:          mgl64.Vec2{b.CentreX, b.CentreZ},
+			Size:            b.Size,
+			TargetSize:      b.TargetSize,
+			ResizeTicks:     b.ResizeTicks,
+			DamageBuffer:    b.DamageBuffer,
+			DamagePerBlock:  b.DamagePerBlock,
+			WarningDistance: b.WarningDistance,
+		}
+	}
+	return borders
+}
+
+// putBorders updates d with the world borders passed, indexed by the ID of their dimension.
+func (d *Data) putBorders(borders map[int]world.Border) {
+	d.Borders = make(map[string]Border, len(borders))
+	for id, b := range borders {
+		d.Borders[strconv.Itoa(id)] = Border{
+			CentreX:         b.Centre[0],
+			CentreZ:         b.Centre[1],
+			Size:            b.Size,
+			TargetSize:      b.TargetSize,
+			ResizeTicks:     b.ResizeTicks,
+			DamageBuffer:    b.DamageBuffer,
+			DamagePerBlock:  b.DamagePerBlock,
+			WarningDistance: b.WarningDistance,
+		}
+	}
+}
+
+// gameRuleFields maps the names of game rules to the index of the field in Data that holds their value.
+var gameRuleFields = func() map[string]int {
+	m := make(map[string]int)
+	t := reflect.TypeFor[Data]()
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if k := f.Type.Kind(); k != reflect.Bool && k != reflect.Int32 {
+			continue
+		}
+		if name := f.Tag.Get("nbt"); name != "" {
+			m[name] = i
+		}
+	}
+	return m
+}()
+
+// gameRules returns the values of all game rules registered in the world package, read from d. The day light cycle
+// and weather cycle are not included, as they are stored in separate fields of world.Settings.
+func (d *Data) gameRules() map[string]any {
+	v := reflect.ValueOf(d).Elem()
+	rules := make(map[string]any)
+	for _, name := range world.GameRules() {
+		if name == world.GameRuleDoDaylightCycle.Name() || name == world.GameRuleDoWeatherCycle.Name() {
+			continue
+		}
+		if i, ok := gameRuleFields[name]; ok {
+			switch f := v.Field(i); f.Kind() {
+			case reflect.Bool:
+				rules[name] = f.Bool()
+			case reflect.Int32:
+				rules[name] = int(f.Int())
+			}
+			continue
+		}
+		switch val := d.CustomGameRules[name].(type) {
+		case uint8:
+			rules[name] = val == 1
+		case int32:
+			rules[name] = int(val)
+		}
+	}
+	return rules
+}
+
+// putGameRules updates d with the values of the game rules passed.
+func (d *Data) putGameRules(rules map[string]any) {
+	v := reflect.ValueOf(d).Elem()
+	for name, val := range rules {
+		if i, ok := gameRuleFields[name]; ok {
+			switch f := v.Field(i); val := val.(type) {
+			case bool:
+				if f.Kind() == reflect.Bool {
+					f.SetBool(val)
+				}
+			case int:
+				if f.Kind() == reflect.Int32 {
+					f.SetInt(int64(val))
+				}
+			}
+			continue
+		}
+		if d.CustomGameRules == nil {
+			d.CustomGameRules = make(map[string]any)
+		}
+		switch val := val.(type) {
+		case bool:
+			d.CustomGameRules[name] = boolByte(val)
+		case int:
+			d.CustomGameRules[name] = int32(val)
+		}
+	}
+}
+
+// boolByte returns 1 if the bool passed is true, or 0 if it is false.
+func boolByte(b bool) uint8 {
+	if b {
+		return 1
+	}
+	return 0
 }
@@ -0,0 +1,30 @@
+package world
+
+import "time"
+
+// Observer observes the time spent on work performed by a World, for example
+// to export it as metrics. An Observer is set to a World using
+// Config.Observer. Its methods are called from the goroutines performing the
+// work and must be safe for concurrent use.
+type Observer interface {
+	// ObserveTick is called after every tick of the World with the time the
+	// tick took.
+	ObserveTick(w *World, d time.Duration)
+	// ObserveChunkLoad is called after a chunk was loaded from the Provider
+	// of the World. If the chunk did not exist and was generated using the
+	// Generator of the World instead, generated is true.
+	ObserveChunkLoad(w *World, d time.Duration, generated bool)
+	// ObserveChunkSave is called after a chunk was stored in the Provider of
+	// the World.
+	ObserveChunkSave(w *World, d time.Duration)
+}
+
+// NopObserver is an Observer that does nothing.
+type NopObserver struct{}
+
+// Compile time check to make sure NopObserver implements Observer.
+var _ Observer = NopObserver{}
+
+func (NopObserver) ObserveTick(*World, time.Duration)            {}
+func (NopObserver) ObserveChunkLoad(*World, time.Duration, bool) {}
+func (NopObserver) ObserveChunkSave(*World, time.Duration)       {}
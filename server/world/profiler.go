@@ -0,0 +1,316 @@
+package world
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ProfileCategory is a category of work performed during a tick of a World
+// that time is attributed to by the profiler.
+type ProfileCategory uint8
+
+const (
+	// ProfileScheduledUpdates is the time spent on scheduled block updates,
+	// see ScheduledTicker.
+	ProfileScheduledUpdates ProfileCategory = iota
+	// ProfileRandomTicks is the time spent on random block ticks, see
+	// RandomTicker.
+	ProfileRandomTicks
+	// ProfileBlockEntities is the time spent ticking blocks with block
+	// entities, see TickerBlock.
+	ProfileBlockEntities
+	// ProfileNeighbourUpdates is the time spent on block updates caused by
+	// neighbouring blocks changing, see NeighbourUpdateTicker.
+	ProfileNeighbourUpdates
+	// ProfileEntities is the time spent ticking entities, see TickerEntity.
+	ProfileEntities
+	// ProfileRedstone is the time spent evaluating redstone networks.
+	ProfileRedstone
+	// ProfileBroadcast is the time spent sending the time, weather and
+	// entities moving between chunks to viewers.
+	ProfileBroadcast
+
+	profileCategories
+)
+
+// ProfileCategories returns all ProfileCategories.
+func ProfileCategories() []ProfileCategory {
+	c := make([]ProfileCategory, profileCategories)
+	for i := range c {
+		c[i] = ProfileCategory(i)
+	}
+	return c
+}
+
+// String returns the name of the ProfileCategory.
+func (c ProfileCategory) String() string {
+	switch c {
+	case ProfileScheduledUpdates:
+		return "scheduled_updates"
+	case ProfileRandomTicks:
+		return "random_ticks"
+	case ProfileBlockEntities:
+		return "block_entities"
+	case ProfileNeighbourUpdates:
+		return "neighbour_updates"
+	case ProfileEntities:
+		return "entities"
+	case ProfileRedstone:
+		return "redstone"
+	case ProfileBroadcast:
+		return "broadcast"
+	}
+	return "unknown"
+}
+
+// ProfileTimes holds the time spent on each ProfileCategory, indexed by the
+// ProfileCategory.
+type ProfileTimes [profileCategories]time.Duration
+
+// Total returns the total time spent on all ProfileCategories.
+func (t ProfileTimes) Total() time.Duration {
+	var total time.Duration
+	for _, d := range t {
+		total += d
+	}
+	return total
+}
+
+// ChunkProfile holds the time spent on a single chunk while profiling.
+type ChunkProfile struct {
+	// Pos is the position of the chunk.
+	Pos ChunkPos
+	// Times holds the time spent on the chunk per ProfileCategory.
+	Times ProfileTimes
+}
+
+// EntityProfile holds the time spent ticking entities of a single
+// EntityType while profiling.
+type EntityProfile struct {
+	// Type is the name of the EntityType, as returned by
+	// EntityType.EncodeEntity.
+	Type string
+	// Ticks is the number of times an entity of the type was ticked.
+	Ticks int
+	// Time is the total time spent ticking entities of the type.
+	Time time.Duration
+}
+
+// ProfileReport is a report of the time spent ticking a World, returned by
+// World.Profile and World.StopProfiling.
+type ProfileReport struct {
+	// Start is the time at which profiling started and Duration the time
+	// profiled.
+	Start    time.Time
+	Duration time.Duration
+	// Ticks is the number of ticks profiled and TickTime the total time spent
+	// on these ticks. TickTime may exceed the sum of Categories: Work that is
+	// not attributed to a ProfileCategory, such as loading chunks, is only
+	// included in TickTime.
+	Ticks    int
+	TickTime time.Duration
+	// Categories holds the total time spent on each ProfileCategory.
+	Categories ProfileTimes
+	// Chunks holds the time spent on each chunk, sorted by the total time
+	// spent, starting with the chunk that took longest.
+	Chunks []ChunkProfile
+	// Entities holds the time spent ticking entities per EntityType, sorted by
+	// time, starting with the EntityType that took longest.
+	Entities []EntityProfile
+}
+
+// HotChunks returns up to n chunks that took the longest to tick.
+func (r ProfileReport) HotChunks(n int) []ChunkProfile {
+	return slices.Clone(r.Chunks[:min(max(n, 0), len(r.Chunks))])
+}
+
+// AverageTick returns the average time spent per tick.
+func (r ProfileReport) AverageTick() time.Duration {
+	if r.Ticks == 0 {
+		return 0
+	}
+	return r.TickTime / time.Duration(r.Ticks)
+}
+
+// profiler attributes time spent ticking a World to chunks and entity types.
+// Methods of a nil *profiler are no-ops, so that the ticker does not need to
+// check if profiling is enabled.
+type profiler struct {
+	start time.Time
+
+	mu         sync.Mutex
+	ticks      int
+	tickTime   time.Duration
+	categories ProfileTimes
+	chunks     map[ChunkPos]*ProfileTimes
+	entities   map[string]*EntityProfile
+}
+
+// newProfiler returns a new profiler that starts profiling right away.
+func newProfiler() *profiler {
+	return &profiler{
+		start:    time.Now(),
+		chunks:   make(map[ChunkPos]*ProfileTimes),
+		entities: make(map[string]*EntityProfile),
+	}
+}
+
+// now returns the current time if p is not nil. The zero time.Time is
+// returned otherwise to avoid the cost of time.Now.
+func (p *profiler) now() time.Time {
+	if p == nil {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+// tick records a full tick that took the duration passed.
+func (p *profiler) tick(d time.Duration) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.ticks++
+	p.tickTime += d
+}
+
+// add attributes the time since start to the ProfileCategory passed without
+// attributing it to a chunk.
+func (p *profiler) add(c ProfileCategory, start time.Time) {
+	if p == nil {
+		return
+	}
+	d := time.Since(start)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.categories[c] += d
+}
+
+// addChunk attributes the time since start to the ProfileCategory passed and
+// the chunk at the ChunkPos passed.
+func (p *profiler) addChunk(c ProfileCategory, pos ChunkPos, start time.Time) {
+	if p == nil {
+		return
+	}
+	d := time.Since(start)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.addChunkLocked(c, pos, d)
+}
+
+// addEntity attributes the time since start to ProfileEntities, the chunk at
+// the ChunkPos passed and the EntityType passed.
+func (p *profiler) addEntity(t EntityType, pos ChunkPos, start time.Time) {
+	if p == nil {
+		return
+	}
+	d, name := time.Since(start), t.EncodeEntity()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.addChunkLocked(ProfileEntities, pos, d)
+	e, ok := p.entities[name]
+	if !ok {
+		e = &EntityProfile{Type: name}
+		p.entities[name] = e
+	}
+	e.Ticks++
+	e.Time += d
+}
+
+// addChunkLocked attributes d to the ProfileCategory and chunk passed. p.mu
+// must be held.
+func (p *profiler) addChunkLocked(c ProfileCategory, pos ChunkPos, d time.Duration) {
+	p.categories[c] += d
+	t, ok := p.chunks[pos]
+	if !ok {
+		t = &ProfileTimes{}
+		p.chunks[pos] = t
+	}
+	t[c] += d
+}
+
+// report returns a ProfileReport of the time profiled so far.
+func (p *profiler) report() ProfileReport {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	r := ProfileReport{
+		Start:      p.start,
+		Duration:   time.Since(p.start),
+		Ticks:      p.ticks,
+		TickTime:   p.tickTime,
+		Categories: p.categories,
+		Chunks:     make([]ChunkProfile, 0, len(p.chunks)),
+		Entities:   make([]EntityProfile, 0, len(p.entities)),
+	}
+	for pos, t := range p.chunks {
+		r.Chunks = append(r.Chunks, ChunkProfile{Pos: pos, Times: *t})
+	}
+	for _, e := range p.entities {
+		r.Entities = append(r.Entities, *e)
+	}
+	slices.SortFunc(r.Chunks, func(a, b ChunkProfile) int {
+		if c := cmp.Compare(b.Times.Total(), a.Times.Total()); c != 0 {
+			return c
+		}
+		return cmp.Or(cmp.Compare(a.Pos[0], b.Pos[0]), cmp.Compare(a.Pos[1], b.Pos[1]))
+	})
+	slices.SortFunc(r.Entities, func(a, b EntityProfile) int {
+		return cmp.Or(cmp.Compare(b.Time, a.Time), cmp.Compare(a.Type, b.Type))
+	})
+	return r
+}
+
+// StartProfiling starts profiling the ticks of the World, attributing the time
+// spent to chunks and entity types. Results of earlier profiling are
+// discarded. Profiling adds overhead to every tick and should only be enabled
+// while investigating performance.
+func (w *World) StartProfiling() {
+	if w == nil {
+		return
+	}
+	w.profiler.Store(newProfiler())
+}
+
+// StopProfiling stops profiling the ticks of the World and returns the
+// resulting ProfileReport. False is returned if the World was not being
+// profiled.
+func (w *World) StopProfiling() (ProfileReport, bool) {
+	if w == nil {
+		return ProfileReport{}, false
+	}
+	if p := w.profiler.Swap(nil); p != nil {
+		return p.report(), true
+	}
+	return ProfileReport{}, false
+}
+
+// Profile returns a ProfileReport of the ticks of the World profiled since
+// StartProfiling was called, without stopping profiling. False is returned if
+// the World is not being profiled.
+func (w *World) Profile() (ProfileReport, bool) {
+	if w == nil {
+		return ProfileReport{}, false
+	}
+	if p := w.profiler.Load(); p != nil {
+		return p.report(), true
+	}
+	return ProfileReport{}, false
+}
+
+// HotChunks returns up to n chunks that took the longest to tick since
+// StartProfiling was called. Nil is returned if the World is not being
+// profiled.
+func (w *World) HotChunks(n int) []ChunkProfile {
+	r, ok := w.Profile()
+	if !ok {
+		return nil
+	}
+	return r.HotChunks(n)
+}
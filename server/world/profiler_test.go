@@ -0,0 +1,117 @@
+package world
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProfilerReport(t *testing.T) {
+	p := newProfiler()
+	p.addChunkLocked(ProfileRandomTicks, ChunkPos{0, 0}, time.Millisecond)
+	p.addChunkLocked(ProfileEntities, ChunkPos{0, 0}, time.Millisecond*2)
+	p.addChunkLocked(ProfileRedstone, ChunkPos{1, 0}, time.Millisecond*5)
+	p.addChunkLocked(ProfileRandomTicks, ChunkPos{-1, 2}, time.Millisecond)
+	p.categories[ProfileBroadcast] += time.Millisecond * 4
+	p.entities["a"] = &EntityProfile{Type: "a", Ticks: 1, Time: time.Millisecond}
+	p.entities["b"] = &EntityProfile{Type: "b", Ticks: 2, Time: time.Millisecond * 3}
+	r := p.report()
+
+	want := ProfileTimes{ProfileRandomTicks: time.Millisecond * 2, ProfileEntities: time.Millisecond * 2, ProfileRedstone: time.Millisecond * 5, ProfileBroadcast: time.Millisecond * 4}
+	if r.Categories != want {
+		t.Fatalf("Categories = %v, want %v", r.Categories, want)
+	}
+	if total := r.Categories.Total(); total != time.Millisecond*13 {
+		t.Fatalf("Categories.Total() = %v, want %v", total, time.Millisecond*13)
+	}
+	// Chunks that took equally long are sorted by their position.
+	order := []ChunkPos{{1, 0}, {0, 0}, {-1, 2}}
+	if len(r.Chunks) != len(order) {
+		t.Fatalf("len(Chunks) = %v, want %v", len(r.Chunks), len(order))
+	}
+	for i, pos := range order {
+		if r.Chunks[i].Pos != pos {
+			t.Fatalf("Chunks[%v].Pos = %v, want %v", i, r.Chunks[i].Pos, pos)
+		}
+	}
+	if r.Entities[0].Type != "b" || r.Entities[1].Type != "a" {
+		t.Fatalf("Entities = %v, want b before a", r.Entities)
+	}
+
+	tests := []struct {
+		n, want int
+	}{
+		{n: -1, want: 0},
+		{n: 0, want: 0},
+		{n: 2, want: 2},
+		{n: 10, want: 3},
+	}
+	for _, test := range tests {
+		hot := r.HotChunks(test.n)
+		if len(hot) != test.want {
+			t.Fatalf("len(HotChunks(%v)) = %v, want %v", test.n, len(hot), test.want)
+		}
+		if test.want > 0 && hot[0].Pos != (ChunkPos{1, 0}) {
+			t.Fatalf("HotChunks(%v)[0].Pos = %v, want %v", test.n, hot[0].Pos, ChunkPos{1, 0})
+		}
+	}
+}
+
+func TestWorldProfile(t *testing.T) {
+	w := Config{Synchronous: true}.New()
+	defer w.Close()
+
+	h := NewEntity(profileTestEntityType{}, taskTestEntityConfig{})
+	<-w.exec(func(tx *Tx) { tx.AddEntity(h) })
+
+	if _, ok := w.Profile(); ok {
+		t.Fatalf("Profile() before StartProfiling() = true, want false")
+	}
+	w.StartProfiling()
+	for range 3 {
+		w.AdvanceTick()
+	}
+	r, ok := w.Profile()
+	if !ok {
+		t.Fatalf("Profile() = false, want true")
+	}
+	if r.Ticks != 3 {
+		t.Fatalf("Ticks = %v, want 3", r.Ticks)
+	}
+	if len(r.Entities) != 1 || r.Entities[0].Type != "dragonfly:profile_test_entity" || r.Entities[0].Ticks != 3 {
+		t.Fatalf("Entities = %+v, want 3 ticks of dragonfly:profile_test_entity", r.Entities)
+	}
+	if d := r.Categories[ProfileEntities]; d < profileTestTickTime*3 || d < r.Entities[0].Time {
+		t.Fatalf("Categories[ProfileEntities] = %v, want at least %v", d, profileTestTickTime*3)
+	}
+	if r.TickTime < r.Categories.Total() {
+		t.Fatalf("TickTime = %v, want at least Categories.Total() = %v", r.TickTime, r.Categories.Total())
+	}
+	if hot := w.HotChunks(1); len(hot) != 1 || hot[0].Pos != (ChunkPos{}) {
+		t.Fatalf("HotChunks(1) = %+v, want chunk of entity", hot)
+	}
+
+	if _, ok := w.StopProfiling(); !ok {
+		t.Fatalf("StopProfiling() = false, want true")
+	}
+	if _, ok := w.StopProfiling(); ok {
+		t.Fatalf("StopProfiling() after StopProfiling() = true, want false")
+	}
+	if hot := w.HotChunks(1); hot != nil {
+		t.Fatalf("HotChunks() after StopProfiling() = %v, want nil", hot)
+	}
+}
+
+// profileTestTickTime is the time that ticking a profileTestEntity takes.
+const profileTestTickTime = time.Millisecond
+
+type profileTestEntityType struct{ taskTestEntityType }
+
+func (profileTestEntityType) Open(tx *Tx, handle *EntityHandle, _ *EntityData) Entity {
+	return profileTestEntity{taskTestEntity{h: handle, tx: tx}}
+}
+
+func (profileTestEntityType) EncodeEntity() string { return "dragonfly:profile_test_entity" }
+
+type profileTestEntity struct{ taskTestEntity }
+
+func (profileTestEntity) Tick(*Tx, int64) { time.Sleep(profileTestTickTime) }
@@ -0,0 +1,72 @@
+package protect
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Flag is an action that a Region may allow or deny within its area.
+type Flag uint8
+
+const (
+	// FlagBuild controls breaking and placing blocks by players. Members of a Region may always build in it, and
+	// players that are not members may not build in it unless the flag is explicitly allowed.
+	FlagBuild Flag = iota
+	// FlagInteract controls players using items on blocks, such as opening doors and chests. Like FlagBuild, it is
+	// always allowed for members and denied for others unless explicitly allowed.
+	FlagInteract
+	// FlagPVP controls players attacking other players.
+	FlagPVP
+	// FlagExplosions controls blocks being destroyed by explosions.
+	FlagExplosions
+	// FlagFireSpread controls fire spreading to and burning blocks.
+	FlagFireSpread
+	// FlagLiquidFlow controls liquids flowing into blocks.
+	FlagLiquidFlow
+	// FlagEntry controls players entering the Region. Members of the Region may always enter it.
+	FlagEntry
+	// FlagExit controls players leaving the Region. Members of the Region may always leave it.
+	FlagExit
+)
+
+// flagNames holds the names of all flags, indexed by their value.
+var flagNames = [...]string{"build", "interact", "pvp", "explosions", "fire-spread", "liquid-flow", "entry", "exit"}
+
+// Flags returns all Flags that exist.
+func Flags() []Flag {
+	flags := make([]Flag, len(flagNames))
+	for i := range flags {
+		flags[i] = Flag(i)
+	}
+	return flags
+}
+
+// FlagByName returns the Flag with the name passed, such as 'build' or 'fire-spread'. False is returned if no Flag
+// with the name exists.
+func FlagByName(name string) (Flag, bool) {
+	for i, n := range flagNames {
+		if strings.EqualFold(n, name) {
+			return Flag(i), true
+		}
+	}
+	return 0, false
+}
+
+// String returns the name of the Flag.
+func (f Flag) String() string {
+	if int(f) < len(flagNames) {
+		return flagNames[f]
+	}
+	return fmt.Sprintf("Flag(%d)", uint8(f))
+}
+
+// memberOnly checks if the Flag is always allowed for members of a Region and denied for other players unless
+// explicitly allowed.
+func (f Flag) memberOnly() bool {
+	return f == FlagBuild || f == FlagInteract
+}
+
+// membersBypass checks if members of a Region are never affected by the Flag.
+func (f Flag) membersBypass() bool {
+	return f.memberOnly() || f == FlagEntry || f == FlagExit
+}
@@ -0,0 +1,241 @@
+package protect
+
+import (
+	"slices"
+	"time"
+
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/entity"
+	"github.com/df-mc/dragonfly/server/item"
+	"github.com/df-mc/dragonfly/server/player"
+	"github.com/df-mc/dragonfly/server/world"
+	"github.com/go-gl/mathgl/mgl64"
+	"github.com/google/uuid"
+)
+
+// WorldHandler returns a world.Handler that enforces the FlagExplosions, FlagFireSpread and FlagLiquidFlow flags
+// of the Regions in the Set passed. The Set must hold the Regions of the world that the handler is set to. Events
+// that are not cancelled are passed to h, which may be nil.
+func WorldHandler(s *Set, h world.Handler) world.Handler {
+	if h == nil {
+		h = world.NopHandler{}
+	}
+	return worldHandler{Handler: h, s: s}
+}
+
+// worldHandler implements world.Handler for a Set.
+type worldHandler struct {
+	world.Handler
+	s *Set
+}
+
+// HandleLiquidFlow cancels liquids flowing into blocks where FlagLiquidFlow is denied.
+func (h worldHandler) HandleLiquidFlow(ctx *world.Context, from, into cube.Pos, liquid world.Liquid, replaced world.Block) {
+	if !h.s.Allowed(into, FlagLiquidFlow, uuid.Nil) {
+		ctx.Cancel()
+		return
+	}
+	h.Handler.HandleLiquidFlow(ctx, from, into, liquid, replaced)
+}
+
+// HandleFireSpread cancels fire spreading to blocks where FlagFireSpread is denied.
+func (h worldHandler) HandleFireSpread(ctx *world.Context, from, to cube.Pos) {
+	if !h.s.Allowed(to, FlagFireSpread, uuid.Nil) {
+		ctx.Cancel()
+		return
+	}
+	h.Handler.HandleFireSpread(ctx, from, to)
+}
+
+// HandleBlockBurn cancels blocks burning where FlagFireSpread is denied.
+func (h worldHandler) HandleBlockBurn(ctx *world.Context, pos cube.Pos) {
+	if !h.s.Allowed(pos, FlagFireSpread, uuid.Nil) {
+		ctx.Cancel()
+		return
+	}
+	h.Handler.HandleBlockBurn(ctx, pos)
+}
+
+// HandleExplosion prevents explosions from destroying blocks where FlagExplosions is denied.
+func (h worldHandler) HandleExplosion(ctx *world.Context, src world.ExplosionSource, entities *[]world.Entity, blocks *[]cube.Pos, itemDropChance *float64, spawnFire *bool) {
+	*blocks = slices.DeleteFunc(*blocks, func(pos cube.Pos) bool {
+		return !h.s.Allowed(pos, FlagExplosions, uuid.Nil)
+	})
+	h.Handler.HandleExplosion(ctx, src, entities, blocks, itemDropChance, spawnFire)
+}
+
+// PlayerHandler returns a player.Handler that enforces the FlagBuild, FlagInteract, FlagPVP, FlagEntry and FlagExit
+// flags. sets is called to find the Set holding the Regions of the world a player is in and may return nil for
+// worlds without Regions. Events that are not cancelled are passed to h, which may be nil.
+func PlayerHandler(sets func(w *world.World) *Set, h player.Handler) player.Handler {
+	if h == nil {
+		h = player.NopHandler{}
+	}
+	return playerHandler{Handler: h, sets: sets}
+}
+
+// playerHandler implements player.Handler for the Sets returned by sets.
+type playerHandler struct {
+	player.Handler
+	sets func(w *world.World) *Set
+}
+
+// HandleMove cancels movement of a player into or out of Regions that it may not enter or leave.
+func (h playerHandler) HandleMove(ctx *player.Context, newPos mgl64.Vec3, newRot cube.Rotation) {
+	if !h.mayMove(ctx, ctx.Player().Position(), newPos) {
+		ctx.Cancel()
+		return
+	}
+	h.Handler.HandleMove(ctx, newPos, newRot)
+}
+
+// HandleTeleport cancels teleportation of a player into or out of Regions that it may not enter or leave.
+func (h playerHandler) HandleTeleport(ctx *player.Context, pos mgl64.Vec3) {
+	if !h.mayMove(ctx, ctx.Player().Position(), pos) {
+		ctx.Cancel()
+		return
+	}
+	h.Handler.HandleTeleport(ctx, pos)
+}
+
+// HandleStartBreak cancels players starting to break blocks where they may not build.
+func (h playerHandler) HandleStartBreak(ctx *player.Context, pos cube.Pos) {
+	if !h.allowed(ctx, pos, FlagBuild) {
+		ctx.Cancel()
+		return
+	}
+	h.Handler.HandleStartBreak(ctx, pos)
+}
+
+// HandleBlockBreak cancels players breaking blocks where they may not build.
+func (h playerHandler) HandleBlockBreak(ctx *player.Context, pos cube.Pos, drops *[]item.Stack, xp *int) {
+	if !h.allowed(ctx, pos, FlagBuild) {
+		ctx.Cancel()
+		return
+	}
+	h.Handler.HandleBlockBreak(ctx, pos, drops, xp)
+}
+
+// HandleBlockPlace cancels players placing blocks where they may not build.
+func (h playerHandler) HandleBlockPlace(ctx *player.Context, pos cube.Pos, b world.Block) {
+	if !h.allowed(ctx, pos, FlagBuild) {
+		ctx.Cancel()
+		return
+	}
+	h.Handler.HandleBlockPlace(ctx, pos, b)
+}
+
+// HandleItemUseOnBlock cancels players using items on blocks where they may not interact. Using buckets also
+// requires the player to be allowed to build, as buckets change blocks without placing them.
+func (h playerHandler) HandleItemUseOnBlock(ctx *player.Context, pos cube.Pos, face cube.Face, clickPos mgl64.Vec3) {
+	held, _ := ctx.Player().HeldItems()
+	_, bucket := held.Item().(item.Bucket)
+	if !h.allowed(ctx, pos, FlagInteract) || (bucket && (!h.allowed(ctx, pos, FlagBuild) || !h.allowed(ctx, pos.Side(face), FlagBuild))) {
+		ctx.Cancel()
+		return
+	}
+	h.Handler.HandleItemUseOnBlock(ctx, pos, face, clickPos)
+}
+
+// HandleFireExtinguish cancels players extinguishing fire where they may not build.
+func (h playerHandler) HandleFireExtinguish(ctx *player.Context, pos cube.Pos) {
+	if !h.allowed(ctx, pos, FlagBuild) {
+		ctx.Cancel()
+		return
+	}
+	h.Handler.HandleFireExtinguish(ctx, pos)
+}
+
+// HandleSignEdit cancels players editing signs where they may not build.
+func (h playerHandler) HandleSignEdit(ctx *player.Context, pos cube.Pos, frontSide bool, oldText, newText string) {
+	if !h.allowed(ctx, pos, FlagBuild) {
+		ctx.Cancel()
+		return
+	}
+	h.Handler.HandleSignEdit(ctx, pos, frontSide, oldText, newText)
+}
+
+// HandleLecternPageTurn cancels players turning pages of lecterns where they may not interact.
+func (h playerHandler) HandleLecternPageTurn(ctx *player.Context, pos cube.Pos, oldPage int, newPage *int) {
+	if !h.allowed(ctx, pos, FlagInteract) {
+		ctx.Cancel()
+		return
+	}
+	h.Handler.HandleLecternPageTurn(ctx, pos, oldPage, newPage)
+}
+
+// HandleAttackEntity cancels players attacking other players where FlagPVP is denied.
+func (h playerHandler) HandleAttackEntity(ctx *player.Context, e world.Entity, force, height *float64, critical *bool) {
+	if target, ok := e.(*player.Player); ok && !h.pvpAllowed(ctx, ctx.Player(), target) {
+		ctx.Cancel()
+		return
+	}
+	h.Handler.HandleAttackEntity(ctx, e, force, height, critical)
+}
+
+// HandleHurt cancels damage dealt to a player by another player, directly or using a projectile, where FlagPVP is
+// denied.
+func (h playerHandler) HandleHurt(ctx *player.Context, damage *float64, immune bool, attackImmunity *time.Duration, src world.DamageSource) {
+	var attacker world.Entity
+	switch src := src.(type) {
+	case entity.AttackDamageSource:
+		attacker = src.Attacker
+	case entity.ProjectileDamageSource:
+		attacker = src.Owner
+	}
+	if attacker, ok := attacker.(*player.Player); ok && !h.pvpAllowed(ctx, attacker, ctx.Player()) {
+		ctx.Cancel()
+		return
+	}
+	h.Handler.HandleHurt(ctx, damage, immune, attackImmunity, src)
+}
+
+// allowed checks if the Flag passed is allowed at the position passed for the player of the context.
+func (h playerHandler) allowed(ctx *player.Context, pos cube.Pos, f Flag) bool {
+	s := h.sets(ctx.World())
+	return s == nil || s.Allowed(pos, f, ctx.Player().UUID())
+}
+
+// pvpAllowed checks if FlagPVP is allowed at the positions of both the attacker and the target.
+func (h playerHandler) pvpAllowed(ctx *player.Context, attacker, target *player.Player) bool {
+	s := h.sets(ctx.World())
+	if s == nil {
+		return true
+	}
+	return s.Allowed(cube.PosFromVec3(attacker.Position()), FlagPVP, uuid.Nil) &&
+		s.Allowed(cube.PosFromVec3(target.Position()), FlagPVP, uuid.Nil)
+}
+
+// mayMove checks if the player of the context may move from one position to another, taking FlagEntry and
+// FlagExit of the Regions entered and left into account.
+func (h playerHandler) mayMove(ctx *player.Context, from, to mgl64.Vec3) bool {
+	s := h.sets(ctx.World())
+	if s == nil {
+		return true
+	}
+	fromPos, toPos := cube.PosFromVec3(from), cube.PosFromVec3(to)
+	if fromPos == toPos {
+		return true
+	}
+	before, after, id := s.At(fromPos), s.At(toPos), ctx.Player().UUID()
+	for _, r := range after {
+		if !slices.Contains(before, r) && !mayPass(r, FlagEntry, id) {
+			return false
+		}
+	}
+	for _, r := range before {
+		if !slices.Contains(after, r) && !mayPass(r, FlagExit, id) {
+			return false
+		}
+	}
+	return true
+}
+
+// mayPass checks if the player with the UUID passed may enter or leave the Region passed.
+func mayPass(r *Region, f Flag, id uuid.UUID) bool {
+	if r.IsMember(id) {
+		return true
+	}
+	allow, ok := r.Flag(f)
+	return allow || !ok
+}
@@ -0,0 +1,193 @@
+// Package protect implements protection of areas of a world using Regions. Regions are grouped per world in a
+// Set, which allows or denies actions such as building and PvP at a position using Flags. The handlers returned
+// by WorldHandler and PlayerHandler enforce the Flags of a Set.
+package protect
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/google/uuid"
+)
+
+// Region is a named area of a world protected by a set of Flags. Regions are added to a Set to be enforced. The
+// methods of a Region are safe for concurrent use.
+//
+// A Region may have a parent. Flags that are not set on a Region are inherited from its parent, and the owners
+// and members of the parent are also owners and members of the Region.
+type Region struct {
+	name     string
+	shape    Shape
+	priority int
+
+	mu      sync.RWMutex
+	parent  *Region
+	flags   map[Flag]bool
+	owners  map[uuid.UUID]struct{}
+	members map[uuid.UUID]struct{}
+}
+
+// NewRegion returns a new Region with the name, Shape and priority passed. When Regions overlap, the flags of the
+// Region with the highest priority take precedence. If priorities are equal, a Region takes precedence over its
+// parents.
+func NewRegion(name string, shape Shape, priority int) *Region {
+	return &Region{
+		name:     name,
+		shape:    shape,
+		priority: priority,
+		flags:    make(map[Flag]bool),
+		owners:   make(map[uuid.UUID]struct{}),
+		members:  make(map[uuid.UUID]struct{}),
+	}
+}
+
+// Name returns the name of the Region.
+func (r *Region) Name() string {
+	return r.name
+}
+
+// Shape returns the Shape of the area covered by the Region.
+func (r *Region) Shape() Shape {
+	return r.shape
+}
+
+// Priority returns the priority of the Region.
+func (r *Region) Priority() int {
+	return r.priority
+}
+
+// Contains checks if the block at the position passed is within the Region.
+func (r *Region) Contains(pos cube.Pos) bool {
+	return r.shape.Contains(pos)
+}
+
+// Parent returns the parent of the Region, or nil if it has none.
+func (r *Region) Parent() *Region {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.parent
+}
+
+// SetParent sets the parent of the Region. The parent may be nil to remove it. An error is returned if the Region
+// passed is the Region itself or one of its descendants.
+func (r *Region) SetParent(parent *Region) error {
+	for p := parent; p != nil; p = p.Parent() {
+		if p == r {
+			return errors.New("protect: region cannot be its own ancestor")
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.parent = parent
+	return nil
+}
+
+// SetFlag explicitly allows or denies the Flag passed in the Region.
+func (r *Region) SetFlag(f Flag, allow bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.flags[f] = allow
+}
+
+// UnsetFlag removes the Flag passed from the Region, so that it is inherited from the parent again.
+func (r *Region) UnsetFlag(f Flag) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.flags, f)
+}
+
+// Flag returns whether the Flag passed is allowed in the Region. If the Flag is not set on the Region, it is
+// looked up in its parents. False is returned for ok if neither the Region nor its parents set the Flag.
+func (r *Region) Flag(f Flag) (allow, ok bool) {
+	r.mu.RLock()
+	allow, ok = r.flags[f]
+	parent := r.parent
+	r.mu.RUnlock()
+
+	if !ok && parent != nil {
+		return parent.Flag(f)
+	}
+	return allow, ok
+}
+
+// AddOwner adds the player with the UUID passed as an owner of the Region.
+func (r *Region) AddOwner(id uuid.UUID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.owners[id] = struct{}{}
+}
+
+// RemoveOwner removes the player with the UUID passed as an owner of the Region.
+func (r *Region) RemoveOwner(id uuid.UUID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.owners, id)
+}
+
+// Owners returns the UUIDs of the owners of the Region, excluding those inherited from its parent.
+func (r *Region) Owners() []uuid.UUID {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.Collect(maps.Keys(r.owners))
+}
+
+// IsOwner checks if the player with the UUID passed is an owner of the Region or of one of its parents.
+func (r *Region) IsOwner(id uuid.UUID) bool {
+	r.mu.RLock()
+	_, ok := r.owners[id]
+	parent := r.parent
+	r.mu.RUnlock()
+
+	if !ok && parent != nil {
+		return parent.IsOwner(id)
+	}
+	return ok
+}
+
+// AddMember adds the player with the UUID passed as a member of the Region.
+func (r *Region) AddMember(id uuid.UUID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.members[id] = struct{}{}
+}
+
+// RemoveMember removes the player with the UUID passed as a member of the Region.
+func (r *Region) RemoveMember(id uuid.UUID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.members, id)
+}
+
+// Members returns the UUIDs of the members of the Region, excluding its owners and those inherited from its parent.
+func (r *Region) Members() []uuid.UUID {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.Collect(maps.Keys(r.members))
+}
+
+// IsMember checks if the player with the UUID passed is a member or owner of the Region or of one of its parents.
+func (r *Region) IsMember(id uuid.UUID) bool {
+	r.mu.RLock()
+	_, member := r.members[id]
+	_, owner := r.owners[id]
+	parent := r.parent
+	r.mu.RUnlock()
+
+	if !member && !owner && parent != nil {
+		return parent.IsMember(id)
+	}
+	return member || owner
+}
+
+// descendsFrom checks if the Region passed is one of the parents of r.
+func (r *Region) descendsFrom(ancestor *Region) bool {
+	for p := r.Parent(); p != nil; p = p.Parent() {
+		if p == ancestor {
+			return true
+		}
+	}
+	return false
+}
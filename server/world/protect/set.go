@@ -0,0 +1,178 @@
+package protect
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/google/uuid"
+)
+
+const (
+	// cellShift is the base 2 logarithm of the width and length of the cells of the spatial index of a Set.
+	cellShift = 6
+	// maxCells is the maximum number of cells that a Region is added to in the spatial index of a Set. Regions
+	// covering more cells, such as a Region covering an entire world, are checked for every lookup instead.
+	maxCells = 4096
+)
+
+// cell is the position of a column of 64x64 blocks in the spatial index of a Set.
+type cell [2]int
+
+// Set holds the Regions of a single world. It indexes Regions by their position, so that the Regions at a
+// position can be found quickly. A Set is safe for concurrent use.
+type Set struct {
+	mu      sync.RWMutex
+	regions map[string]*Region
+	cells   map[cell][]*Region
+	// large holds the Regions that cover too many cells to be added to cells.
+	large []*Region
+}
+
+// NewSet returns a new, empty Set.
+func NewSet() *Set {
+	return &Set{regions: make(map[string]*Region), cells: make(map[cell][]*Region)}
+}
+
+// Add adds a Region to the Set. An error is returned if the Set already holds a Region with the same name.
+func (s *Set) Add(r *Region) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := strings.ToLower(r.Name())
+	if _, ok := s.regions[key]; ok {
+		return fmt.Errorf("protect: region %v already exists", r.Name())
+	}
+	s.regions[key] = r
+
+	lo, hi := regionCells(r)
+	if (hi[0]-lo[0]+1)*(hi[1]-lo[1]+1) > maxCells {
+		s.large = append(s.large, r)
+		return nil
+	}
+	for x := lo[0]; x <= hi[0]; x++ {
+		for z := lo[1]; z <= hi[1]; z++ {
+			s.cells[cell{x, z}] = append(s.cells[cell{x, z}], r)
+		}
+	}
+	return nil
+}
+
+// Remove removes the Region with the name passed from the Set. False is returned if the Set did not hold a Region
+// with the name.
+func (s *Set) Remove(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := strings.ToLower(name)
+	r, ok := s.regions[key]
+	if !ok {
+		return false
+	}
+	delete(s.regions, key)
+
+	if i := slices.Index(s.large, r); i != -1 {
+		s.large = slices.Delete(s.large, i, i+1)
+		return true
+	}
+	lo, hi := regionCells(r)
+	for x := lo[0]; x <= hi[0]; x++ {
+		for z := lo[1]; z <= hi[1]; z++ {
+			c := cell{x, z}
+			if s.cells[c] = slices.DeleteFunc(s.cells[c], func(other *Region) bool { return other == r }); len(s.cells[c]) == 0 {
+				delete(s.cells, c)
+			}
+		}
+	}
+	return true
+}
+
+// Region returns the Region in the Set with the name passed. Names are not case-sensitive.
+func (s *Set) Region(name string) (*Region, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	r, ok := s.regions[strings.ToLower(name)]
+	return r, ok
+}
+
+// Regions returns all Regions in the Set, sorted by name.
+func (s *Set) Regions() []*Region {
+	s.mu.RLock()
+	regions := make([]*Region, 0, len(s.regions))
+	for _, r := range s.regions {
+		regions = append(regions, r)
+	}
+	s.mu.RUnlock()
+
+	slices.SortFunc(regions, func(a, b *Region) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	return regions
+}
+
+// At returns all Regions in the Set that contain the position passed, ordered so that the Region that takes
+// precedence comes first.
+func (s *Set) At(pos cube.Pos) []*Region {
+	s.mu.RLock()
+	var regions []*Region
+	for _, r := range s.cells[cell{pos[0] >> cellShift, pos[2] >> cellShift}] {
+		if r.Contains(pos) {
+			regions = append(regions, r)
+		}
+	}
+	for _, r := range s.large {
+		if r.Contains(pos) {
+			regions = append(regions, r)
+		}
+	}
+	s.mu.RUnlock()
+
+	if len(regions) > 1 {
+		slices.SortFunc(regions, compareRegions)
+	}
+	return regions
+}
+
+// Allowed checks if the Flag passed is allowed at the position passed for the player with the UUID passed. For
+// checks not caused by a player, such as explosions, uuid.Nil should be passed.
+//
+// The Flag is looked up in the Regions at the position in order of precedence, and the first Region that sets
+// the Flag, either directly or through its parents, decides if it is allowed. If no Region sets the Flag, it is
+// allowed. FlagBuild and FlagInteract are an exception: Unless set by the Region that takes precedence, they are
+// denied to players that are not members of that Region.
+func (s *Set) Allowed(pos cube.Pos, f Flag, id uuid.UUID) bool {
+	for _, r := range s.At(pos) {
+		if f.membersBypass() && id != uuid.Nil && r.IsMember(id) {
+			return true
+		}
+		if allow, ok := r.Flag(f); ok {
+			return allow
+		}
+		if f.memberOnly() {
+			return false
+		}
+	}
+	return true
+}
+
+// compareRegions compares two Regions so that the Region that takes precedence comes first. Regions with a higher
+// priority take precedence, and if priorities are equal, a Region takes precedence over its parents. Other Regions
+// are sorted by name.
+func compareRegions(a, b *Region) int {
+	switch {
+	case a.Priority() != b.Priority():
+		return cmp.Compare(b.Priority(), a.Priority())
+	case a.descendsFrom(b):
+		return -1
+	case b.descendsFrom(a):
+		return 1
+	}
+	return strings.Compare(a.Name(), b.Name())
+}
+
+// regionCells returns the lowest and highest cells that the Region passed covers.
+func regionCells(r *Region) (lo, hi cell) {
+	a, b := r.Shape().Bounds()
+	return cell{a[0] >> cellShift, a[2] >> cellShift}, cell{b[0] >> cellShift, b[2] >> cellShift}
+}
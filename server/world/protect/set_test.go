@@ -0,0 +1,115 @@
+package protect
+
+import (
+	"testing"
+
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/google/uuid"
+)
+
+func TestSetAllowed(t *testing.T) {
+	s := NewSet()
+	low := NewRegion("low", NewCuboid(cube.Pos{0, 0, 0}, cube.Pos{100, 100, 100}), 0)
+	low.SetFlag(FlagPVP, false)
+	high := NewRegion("high", NewCuboid(cube.Pos{0, 0, 0}, cube.Pos{10, 10, 10}), 10)
+	high.SetFlag(FlagPVP, true)
+	for _, r := range []*Region{low, high} {
+		if err := s.Add(r); err != nil {
+			t.Fatalf("Add() = %v, want nil", err)
+		}
+	}
+	if err := s.Add(NewRegion("LOW", NewCuboid(cube.Pos{}, cube.Pos{}), 0)); err == nil {
+		t.Fatalf("Add() of region with existing name = nil, want error")
+	}
+
+	t.Run("priority", func(t *testing.T) {
+		if !s.Allowed(cube.Pos{5, 5, 5}, FlagPVP, uuid.Nil) {
+			t.Fatalf("Allowed() in region with higher priority = false, want true")
+		}
+		if s.Allowed(cube.Pos{50, 5, 50}, FlagPVP, uuid.Nil) {
+			t.Fatalf("Allowed() outside region with higher priority = true, want false")
+		}
+		if !s.Allowed(cube.Pos{500, 5, 500}, FlagPVP, uuid.Nil) {
+			t.Fatalf("Allowed() outside regions = false, want true")
+		}
+		// high does not set FlagExplosions, so it is decided by low.
+		low.SetFlag(FlagExplosions, false)
+		if s.Allowed(cube.Pos{5, 5, 5}, FlagExplosions, uuid.Nil) {
+			t.Fatalf("Allowed() of flag not set by region with higher priority = true, want false")
+		}
+	})
+	t.Run("parent", func(t *testing.T) {
+		parent := NewRegion("parent", NewCuboid(cube.Pos{200, 0, 200}, cube.Pos{300, 100, 300}), 0)
+		child := NewRegion("a-child", NewCuboid(cube.Pos{200, 0, 200}, cube.Pos{210, 10, 210}), 0)
+		if err := child.SetParent(parent); err != nil {
+			t.Fatalf("SetParent() = %v, want nil", err)
+		}
+		if err := parent.SetParent(child); err == nil {
+			t.Fatalf("SetParent() of descendant = nil, want error")
+		}
+		_ = s.Add(parent)
+		_ = s.Add(child)
+		defer s.Remove("parent")
+		defer s.Remove("a-child")
+
+		pos := cube.Pos{205, 5, 205}
+		if at := s.At(pos); len(at) != 2 || at[0] != child {
+			t.Fatalf("At() = %v, want child before its parent", at)
+		}
+		parent.SetFlag(FlagFireSpread, false)
+		if s.Allowed(pos, FlagFireSpread, uuid.Nil) {
+			t.Fatalf("Allowed() of flag inherited from parent = true, want false")
+		}
+		child.SetFlag(FlagFireSpread, true)
+		if !s.Allowed(pos, FlagFireSpread, uuid.Nil) {
+			t.Fatalf("Allowed() of flag set by child = false, want true")
+		}
+
+		id := uuid.New()
+		parent.AddOwner(id)
+		if !child.IsMember(id) || !s.Allowed(pos, FlagBuild, id) {
+			t.Fatalf("owner of parent is not a member of child")
+		}
+	})
+	t.Run("members", func(t *testing.T) {
+		member, other := uuid.New(), uuid.New()
+		high.AddMember(member)
+		pos := cube.Pos{5, 5, 5}
+		if !s.Allowed(pos, FlagBuild, member) {
+			t.Fatalf("Allowed() of build for member = false, want true")
+		}
+		if s.Allowed(pos, FlagBuild, other) {
+			t.Fatalf("Allowed() of build for other player = true, want false")
+		}
+		high.SetFlag(FlagBuild, true)
+		defer high.UnsetFlag(FlagBuild)
+		if !s.Allowed(pos, FlagBuild, other) {
+			t.Fatalf("Allowed() of build explicitly allowed = false, want true")
+		}
+	})
+	t.Run("large", func(t *testing.T) {
+		world := NewRegion("world", NewCuboid(cube.Pos{-1 << 20, -64, -1 << 20}, cube.Pos{1 << 20, 320, 1 << 20}), -1)
+		world.SetFlag(FlagLiquidFlow, false)
+		_ = s.Add(world)
+		if len(s.large) != 1 {
+			t.Fatalf("region covering the world was not added as a large region")
+		}
+		if s.Allowed(cube.Pos{-5000, 0, 5000}, FlagLiquidFlow, uuid.Nil) {
+			t.Fatalf("Allowed() in large region = true, want false")
+		}
+		if !s.Remove("World") || len(s.large) != 0 {
+			t.Fatalf("Remove() of large region failed")
+		}
+	})
+	t.Run("remove", func(t *testing.T) {
+		if !s.Remove("high") || s.Remove("high") {
+			t.Fatalf("Remove() = false or removed twice")
+		}
+		if at := s.At(cube.Pos{5, 5, 5}); len(at) != 1 || at[0] != low {
+			t.Fatalf("At() after Remove() = %v, want only low", at)
+		}
+		if len(s.cells) == 0 {
+			t.Fatalf("Remove() cleared cells of other regions")
+		}
+	})
+}
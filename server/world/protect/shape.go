@@ -0,0 +1,93 @@
+package protect
+
+import (
+	"github.com/df-mc/dragonfly/server/block/cube"
+)
+
+// Shape is the shape of the area covered by a Region.
+type Shape interface {
+	// Contains checks if the block at the position passed is within the Shape.
+	Contains(pos cube.Pos) bool
+	// Bounds returns the lowest and highest corners of the smallest cuboid that holds the entire Shape. Both
+	// corners are within the cuboid.
+	Bounds() (lo, hi cube.Pos)
+}
+
+// Cuboid is a Shape covering all blocks between two corners.
+type Cuboid struct {
+	// Min and Max are the lowest and highest corners of the Cuboid. Both corners are within the Cuboid.
+	Min, Max cube.Pos
+}
+
+// NewCuboid returns a Cuboid with the two corners passed. The corners may be passed in any order.
+func NewCuboid(a, b cube.Pos) Cuboid {
+	return Cuboid{Min: cube.Min(a, b), Max: cube.Max(a, b)}
+}
+
+// Contains ...
+func (c Cuboid) Contains(pos cube.Pos) bool {
+	return pos[0] >= c.Min[0] && pos[0] <= c.Max[0] &&
+		pos[1] >= c.Min[1] && pos[1] <= c.Max[1] &&
+		pos[2] >= c.Min[2] && pos[2] <= c.Max[2]
+}
+
+// Bounds ...
+func (c Cuboid) Bounds() (lo, hi cube.Pos) {
+	return c.Min, c.Max
+}
+
+// Polygon is a Shape formed by extruding a polygon on the horizontal plane between two Y values.
+type Polygon struct {
+	// Points are the X and Z coordinates of the blocks at the corners of the polygon, in order. The edges of the
+	// polygon run between consecutive points and between the last and first point. Blocks on the edges are within
+	// the Polygon.
+	Points [][2]int
+	// MinY and MaxY are the lowest and highest Y values of blocks within the Polygon.
+	MinY, MaxY int
+}
+
+// Contains ...
+func (p Polygon) Contains(pos cube.Pos) bool {
+	if pos[1] < p.MinY || pos[1] > p.MaxY || len(p.Points) == 0 {
+		return false
+	}
+	x, z := pos[0], pos[2]
+	inside := false
+	for i, j := 0, len(p.Points)-1; i < len(p.Points); j, i = i, i+1 {
+		a, b := p.Points[i], p.Points[j]
+		if onSegment(a, b, x, z) {
+			return true
+		}
+		// Cast a ray from the position in the positive X direction and count the number of edges crossed.
+		if (a[1] > z) != (b[1] > z) {
+			// The X coordinate of the intersection is a[0] + (z-a[1])*(b[0]-a[0])/(b[1]-a[1]). It is compared with x
+			// without dividing, taking the sign of the denominator into account.
+			num, den := (z-a[1])*(b[0]-a[0]), b[1]-a[1]
+			if ((x-a[0])*den < num) == (den > 0) {
+				inside = !inside
+			}
+		}
+	}
+	return inside
+}
+
+// Bounds ...
+func (p Polygon) Bounds() (lo, hi cube.Pos) {
+	if len(p.Points) == 0 {
+		return cube.Pos{0, p.MinY, 0}, cube.Pos{0, p.MaxY, 0}
+	}
+	lo, hi = cube.Pos{p.Points[0][0], p.MinY, p.Points[0][1]}, cube.Pos{p.Points[0][0], p.MaxY, p.Points[0][1]}
+	for _, point := range p.Points[1:] {
+		lo[0], lo[2] = min(lo[0], point[0]), min(lo[2], point[1])
+		hi[0], hi[2] = max(hi[0], point[0]), max(hi[2], point[1])
+	}
+	return lo, hi
+}
+
+// onSegment checks if the point at x, z is on the line segment between a and b.
+func onSegment(a, b [2]int, x, z int) bool {
+	if (b[0]-a[0])*(z-a[1]) != (b[1]-a[1])*(x-a[0]) {
+		return false
+	}
+	return x >= min(a[0], b[0]) && x <= max(a[0], b[0]) && z >= min(a[1], b[1]) && z <= max(a[1], b[1])
+}
@@ -0,0 +1,68 @@
+package protect
+
+import (
+	"testing"
+
+	"github.com/df-mc/dragonfly/server/block/cube"
+)
+
+func TestPolygonContains(t *testing.T) {
+	// An L-shaped polygon, of which the square between (5, 5) and (10, 10) is
+	// cut out.
+	l := Polygon{Points: [][2]int{{0, 0}, {10, 0}, {10, 5}, {5, 5}, {5, 10}, {0, 10}}, MinY: 0, MaxY: 20}
+	reversed := Polygon{MinY: l.MinY, MaxY: l.MaxY}
+	for i := len(l.Points) - 1; i >= 0; i-- {
+		reversed.Points = append(reversed.Points, l.Points[i])
+	}
+
+	for _, tt := range []struct {
+		name string
+		pos  cube.Pos
+		want bool
+	}{
+		{"inside", cube.Pos{2, 5, 2}, true},
+		{"inside arm", cube.Pos{8, 5, 2}, true},
+		{"inside other arm", cube.Pos{2, 5, 8}, true},
+		{"cut out", cube.Pos{7, 5, 7}, false},
+		{"outside", cube.Pos{11, 5, 0}, false},
+		{"corner", cube.Pos{0, 5, 0}, true},
+		{"inner corner", cube.Pos{5, 5, 5}, true},
+		{"edge", cube.Pos{10, 5, 3}, true},
+		{"diagonal of cut out", cube.Pos{6, 5, 6}, false},
+		{"ray through vertex inside", cube.Pos{3, 5, 5}, true},
+		{"ray through vertices outside", cube.Pos{-2, 5, 5}, false},
+		{"ray along edge outside", cube.Pos{-2, 5, 0}, false},
+		{"below", cube.Pos{2, -1, 2}, false},
+		{"above", cube.Pos{2, 21, 2}, false},
+		{"top", cube.Pos{2, 20, 2}, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := l.Contains(tt.pos); got != tt.want {
+				t.Fatalf("Contains(%v) = %v, want %v", tt.pos, got, tt.want)
+			}
+			if got := reversed.Contains(tt.pos); got != tt.want {
+				t.Fatalf("Contains(%v) with points in reverse order = %v, want %v", tt.pos, got, tt.want)
+			}
+		})
+	}
+
+	if (Polygon{MaxY: 10}).Contains(cube.Pos{}) {
+		t.Fatalf("Contains() of polygon without points = true, want false")
+	}
+	if lo, hi := l.Bounds(); lo != (cube.Pos{0, 0, 0}) || hi != (cube.Pos{10, 20, 10}) {
+		t.Fatalf("Bounds() = %v, %v, want (0, 0, 0), (10, 20, 10)", lo, hi)
+	}
+}
+
+func TestCuboidContains(t *testing.T) {
+	c := NewCuboid(cube.Pos{5, 10, 5}, cube.Pos{0, 0, 0})
+	if c.Min != (cube.Pos{0, 0, 0}) || c.Max != (cube.Pos{5, 10, 5}) {
+		t.Fatalf("NewCuboid() = %v, want corners sorted", c)
+	}
+	if !c.Contains(cube.Pos{5, 10, 5}) || !c.Contains(cube.Pos{0, 0, 0}) {
+		t.Fatalf("Contains() of corner = false, want true")
+	}
+	if c.Contains(cube.Pos{6, 5, 5}) || c.Contains(cube.Pos{0, -1, 0}) {
+		t.Fatalf("Contains() of position outside = true, want false")
+	}
+}
@@ -0,0 +1,369 @@
+package world
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// recordingVersion is the version of the format of recordings written by a
+// Recorder.
+const recordingVersion = 1
+
+// Op is a serialisable transaction that may be recorded by a Recorder and
+// executed again when replaying a recording. Op implementations must be
+// registered using gob.Register so that they can be encoded and decoded.
+type Op interface {
+	// Run runs the Op in the transaction passed.
+	Run(tx *Tx)
+}
+
+// RecordKind is the kind of RecordEntry in a recording.
+type RecordKind uint8
+
+const (
+	// RecordTick is recorded for every tick of the World.
+	RecordTick RecordKind = iota
+	// RecordOp is recorded for every Op run using World.DoOp.
+	RecordOp
+	// RecordTransaction is recorded for every other transaction submitted to
+	// the World, such as using World.Do or Call, that did not record player
+	// input. Because these transactions are functions, they cannot be
+	// replayed. Only the location of the code that submitted the transaction
+	// is recorded.
+	RecordTransaction
+	// RecordInput is recorded for every input of a player, such as a packet
+	// received from the client, recorded using Tx.RecordInput.
+	RecordInput
+)
+
+// RecordEntry is a single entry in a recording of a World.
+type RecordEntry struct {
+	// Kind is the kind of entry.
+	Kind RecordKind
+	// Tick is the current tick of the World when the entry was recorded.
+	Tick int64
+	// Op is the Op that was run for RecordOp entries.
+	Op Op
+	// Caller is the location of the code that submitted the transaction for
+	// RecordTransaction entries.
+	Caller string
+	// Player and Input are the UUID of the player and the data of its input
+	// for RecordInput entries.
+	Player uuid.UUID
+	Input  []byte
+}
+
+// recordHeader is the first value encoded in a recording.
+type recordHeader struct {
+	Version int
+	// Seeded is true if the World used a rand.PCG created from Seed as its
+	// RandSource.
+	Seeded bool
+	Seed   [2]uint64
+}
+
+// Recorder records everything that happens to a World so that it can be
+// replayed using a Replay: The seed of its random number generator, every tick,
+// every transaction submitted to it and every player input. A Recorder is set
+// to a World using Config.Recorder and must not be shared between Worlds.
+//
+// Recording starts when the World is created, so the data of the World's
+// Provider at that moment serves as the snapshot that the recording must be
+// replayed against.
+//
+// Only changes made through Ops, submitted using World.DoOp, and through
+// player inputs recorded using Tx.RecordInput are reproduced by a replay.
+// Transactions submitted using World.Do, World.Exec, Call and similar
+// functions are closures that cannot be serialised: Only their caller is
+// recorded, and a Replay fails when it reaches one of them. A transaction that
+// records player input, such as one handling packets of a player, is replayed
+// through that input instead and does not make a Replay fail. Transactions
+// that call Tx.SkipRecording, such as those run by sessions to send chunks and
+// by the server to add and remove players, are not recorded at all: A Replay
+// does not add players to the World, so the input function passed to
+// Replay.Run is responsible for applying player input. Changes made by the
+// World on its own are reproduced as long as they do not depend on wall-clock
+// time, such as through World.DoAfter.
+type Recorder struct {
+	mu      sync.Mutex
+	enc     *gob.Encoder
+	started bool
+	err     error
+}
+
+// NewRecorder returns a Recorder that writes its recording to w.
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{enc: gob.NewEncoder(w)}
+}
+
+// Err returns the first error that occurred while writing the recording. No
+// more entries are written after an error occurs.
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// start writes the header of the recording and starts recording entries.
+func (r *Recorder) start(h recordHeader) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.started {
+		r.err = errors.New("record: recorder used by more than one world")
+		return
+	}
+	r.started = true
+	if err := r.enc.Encode(h); err != nil {
+		r.err = fmt.Errorf("record: encode header: %w", err)
+	}
+}
+
+// record writes an entry to the recording.
+func (r *Recorder) record(e RecordEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.started || r.err != nil {
+		return
+	}
+	if err := r.enc.Encode(e); err != nil {
+		r.err = fmt.Errorf("record: encode entry: %w", err)
+	}
+}
+
+// DoOp schedules the Op passed to run on the World, like Do. If the World has
+// a Recorder, the Op is recorded so that it is run again when the recording
+// is replayed.
+func (w *World) DoOp(op Op) *Task {
+	return w.scheduleTask(newTask(), func(tx *Tx) error {
+		op.Run(tx)
+		return nil
+	}, op)
+}
+
+// RecordInput records the input of the player with the UUID passed if the
+// World has a Recorder. It is called by sessions for every packet received
+// from a client and is passed to the input function of Replay.Run when the
+// recording is replayed.
+func (tx *Tx) RecordInput(id uuid.UUID, data []byte) {
+	w := tx.World()
+	if w.conf.Recorder == nil {
+		return
+	}
+	// The transaction is replayed through the input recorded, so it does not
+	// need to be recorded itself.
+	w.unreplayed = nil
+	w.conf.Recorder.record(RecordEntry{Kind: RecordInput, Tick: w.currentTick(), Player: id, Input: data})
+}
+
+// SkipRecording marks the transaction as one that does not need to be
+// replayed if the World has a Recorder, so that it does not make a Replay
+// fail. It is called by transactions that sessions and the server run on
+// behalf of players, such as to send chunks or to add a player that joined.
+// Changes made by such a transaction are not reproduced by a Replay.
+func (tx *Tx) SkipRecording() {
+	if w := tx.World(); w.conf.Recorder != nil {
+		w.unreplayed = nil
+	}
+}
+
+// Recording checks if the World of the transaction has a Recorder.
+func (tx *Tx) Recording() bool {
+	return tx.World().conf.Recorder != nil
+}
+
+// recordTick records a tick of the World if it has a Recorder.
+func (w *World) recordTick(tick int64) {
+	if w.conf.Recorder != nil {
+		w.conf.Recorder.record(RecordEntry{Kind: RecordTick, Tick: tick})
+	}
+}
+
+// beginRecord records an external transaction that is about to run on the
+// World if it has a Recorder. An Op is recorded immediately. Any other
+// transaction is only recorded by endRecord if no player input was recorded
+// while it ran: Transactions that handle player input are replayed through
+// that input. The transaction that was running before is returned, which
+// must be passed to endRecord.
+func (w *World) beginRecord(rec *transactionRecord) (prev *transactionRecord) {
+	if w.conf.Recorder == nil {
+		return nil
+	}
+	prev = w.unreplayed
+	if rec.op != nil {
+		w.conf.Recorder.record(RecordEntry{Kind: RecordOp, Tick: w.currentTick(), Op: rec.op})
+		w.unreplayed = nil
+		return prev
+	}
+	w.unreplayed = rec
+	return prev
+}
+
+// endRecord records the transaction started using beginRecord if it could not
+// be replayed, using the location of its caller, and restores the transaction
+// running before it.
+func (w *World) endRecord(prev *transactionRecord) {
+	if w.conf.Recorder == nil {
+		return
+	}
+	if rec := w.unreplayed; rec != nil {
+		w.conf.Recorder.record(RecordEntry{Kind: RecordTransaction, Tick: w.currentTick(), Caller: rec.caller})
+	}
+	w.unreplayed = prev
+}
+
+// currentTick returns the current tick of the World.
+func (w *World) currentTick() int64 {
+	w.set.Lock()
+	defer w.set.Unlock()
+	return w.set.CurrentTick
+}
+
+// worldPackage is the import path of the world package, used to find the
+// caller of a transaction outside the package.
+var worldPackage = func() string {
+	pc, _, _, _ := runtime.Caller(0)
+	name := runtime.FuncForPC(pc).Name()
+	slash := strings.LastIndex(name, "/")
+	return name[:slash+strings.Index(name[slash:], ".")+1]
+}()
+
+// externalCaller returns the location of the first caller outside the world
+// package.
+func externalCaller() string {
+	pcs := make([]uintptr, 16)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		f, more := frames.Next()
+		if !strings.HasPrefix(f.Function, worldPackage) {
+			return fmt.Sprintf("%v (%v:%v)", f.Function, f.File, f.Line)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
+
+// Replay replays a recording written by a Recorder against a World. The World
+// must be created from the same snapshot as the recorded World, with its
+// RandSource set to Replay.RandSource and Config.Synchronous set to true.
+type Replay struct {
+	dec    *gob.Decoder
+	header recordHeader
+}
+
+// NewReplay reads the header of the recording from r and returns a Replay
+// that reads the rest of the recording from r.
+func NewReplay(r io.Reader) (*Replay, error) {
+	dec := gob.NewDecoder(r)
+	var h recordHeader
+	if err := dec.Decode(&h); err != nil {
+		return nil, fmt.Errorf("replay: decode header: %w", err)
+	}
+	if h.Version != recordingVersion {
+		return nil, fmt.Errorf("replay: unsupported recording version %v", h.Version)
+	}
+	return &Replay{dec: dec, header: h}, nil
+}
+
+// RandSource returns a rand.Source that produces the same random numbers as
+// the RandSource of the recorded World. False is returned if the recorded
+// World had a RandSource set in its Config, in which case its random numbers
+// cannot be reproduced.
+func (r *Replay) RandSource() (rand.Source, bool) {
+	return rand.NewPCG(r.header.Seed[0], r.header.Seed[1]), r.header.Seeded
+}
+
+// ReplayStats holds statistics of a replayed recording.
+type ReplayStats struct {
+	// Ticks, Ops and Inputs are the number of ticks, Ops and player inputs
+	// replayed.
+	Ticks, Ops, Inputs int
+}
+
+// SkippedTransactionError is returned by Replay.Step and Replay.Run when the
+// recording holds a transaction that is not an Op. Such a transaction cannot
+// be replayed, so the state of the World no longer matches that of the
+// recorded World after it.
+type SkippedTransactionError struct {
+	// Tick is the tick at which the transaction was recorded.
+	Tick int64
+	// Caller is the location of the code that submitted the transaction.
+	Caller string
+}
+
+// Error ...
+func (e *SkippedTransactionError) Error() string {
+	return fmt.Sprintf("replay: transaction at tick %v submitted by %v is not an op and cannot be replayed", e.Tick, e.Caller)
+}
+
+// Step replays the next entry of the recording against the World passed and
+// returns it. Player inputs are passed to input, which may be nil to ignore
+// them. io.EOF is returned once the end of the recording is reached. A
+// *SkippedTransactionError is returned for a transaction that is not an Op:
+// Step may be called again to continue replaying after it, although the
+// replay is then no longer guaranteed to match the recording.
+func (r *Replay) Step(w *World, input func(tx *Tx, id uuid.UUID, data []byte) error) (RecordEntry, error) {
+	if !w.conf.Synchronous {
+		return RecordEntry{}, errors.New("replay: world must be synchronous")
+	}
+	var e RecordEntry
+	if err := r.dec.Decode(&e); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return e, io.EOF
+		}
+		return e, fmt.Errorf("replay: decode entry: %w", err)
+	}
+	var err error
+	switch e.Kind {
+	case RecordTick:
+		w.AdvanceTick()
+	case RecordOp:
+		if e.Op == nil {
+			return e, errors.New("replay: op entry without op")
+		}
+		w.Do(e.Op.Run)
+	case RecordInput:
+		if input != nil {
+			w.Do(func(tx *Tx) {
+				err = input(tx, e.Player, e.Input)
+			})
+		}
+	case RecordTransaction:
+		return e, &SkippedTransactionError{Tick: e.Tick, Caller: e.Caller}
+	}
+	if err != nil {
+		return e, fmt.Errorf("replay: input of %v at tick %v: %w", e.Player, e.Tick, err)
+	}
+	return e, nil
+}
+
+// Run replays the rest of the recording against the World passed, as if
+// calling Step until the end of the recording is reached. Run stops at the
+// first error returned by Step, including a *SkippedTransactionError.
+func (r *Replay) Run(w *World, input func(tx *Tx, id uuid.UUID, data []byte) error) (ReplayStats, error) {
+	var stats ReplayStats
+	for {
+		e, err := r.Step(w, input)
+		if errors.Is(err, io.EOF) {
+			return stats, nil
+		} else if err != nil {
+			return stats, err
+		}
+		switch e.Kind {
+		case RecordTick:
+			stats.Ticks++
+		case RecordOp:
+			stats.Ops++
+		case RecordInput:
+			stats.Inputs++
+		}
+	}
+}
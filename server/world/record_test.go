@@ -0,0 +1,100 @@
+package world
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestReplay(t *testing.T) {
+	record := func(f func(w *World)) *Replay {
+		buf := bytes.NewBuffer(nil)
+		rec := NewRecorder(buf)
+		w := Config{Synchronous: true, Recorder: rec}.New()
+		f(w)
+		_ = w.Close()
+		if err := rec.Err(); err != nil {
+			t.Fatalf("Recorder.Err() = %v, want nil", err)
+		}
+		r, err := NewReplay(buf)
+		if err != nil {
+			t.Fatalf("NewReplay() = %v, want nil", err)
+		}
+		return r
+	}
+
+	t.Run("ops and inputs", func(t *testing.T) {
+		id := uuid.New()
+		r := record(func(w *World) {
+			w.DoOp(testOp{Tick: 5})
+			w.AdvanceTick()
+			// The transaction records input, so it is replayed through it.
+			w.Do(func(tx *Tx) { tx.RecordInput(id, []byte{1}) })
+		})
+		w := Config{Synchronous: true}.New()
+		defer w.Close()
+
+		var inputs int
+		stats, err := r.Run(w, func(tx *Tx, player uuid.UUID, data []byte) error {
+			if player != id || !bytes.Equal(data, []byte{1}) {
+				t.Fatalf("input = %v, %v, want %v, [1]", player, data, id)
+			}
+			inputs++
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Run() = %v, want nil", err)
+		}
+		if stats.Ops != 1 || stats.Ticks != 1 || stats.Inputs != 1 || inputs != 1 {
+			t.Fatalf("Run() stats = %+v, want 1 op, 1 tick and 1 input", stats)
+		}
+		if tm := w.Time(); tm != 6 {
+			t.Fatalf("Time() after replay = %v, want 6", tm)
+		}
+	})
+	t.Run("skipped transaction", func(t *testing.T) {
+		r := record(func(w *World) {
+			w.DoOp(testOp{Tick: 5})
+			w.Do(func(tx *Tx) {})
+			w.DoOp(testOp{Tick: 6})
+		})
+		w := Config{Synchronous: true}.New()
+		defer w.Close()
+
+		stats, err := r.Run(w, nil)
+		var skipped *SkippedTransactionError
+		if !errors.As(err, &skipped) {
+			t.Fatalf("Run() = %v, want *SkippedTransactionError", err)
+		}
+		if stats.Ops != 1 || skipped.Caller == "" {
+			t.Fatalf("Run() stopped after %v ops at caller %q, want 1 op and a caller", stats.Ops, skipped.Caller)
+		}
+		if _, err := r.Step(w, nil); err != nil {
+			t.Fatalf("Step() after skipped transaction = %v, want nil", err)
+		}
+	})
+	t.Run("skip recording", func(t *testing.T) {
+		r := record(func(w *World) {
+			w.Do(func(tx *Tx) { tx.SkipRecording() })
+			w.DoOp(testOp{Tick: 5})
+		})
+		w := Config{Synchronous: true}.New()
+		defer w.Close()
+
+		if stats, err := r.Run(w, nil); err != nil || stats.Ops != 1 {
+			t.Fatalf("Run() = %+v, %v, want 1 op and nil", stats, err)
+		}
+	})
+}
+
+// testOp is an Op that sets the time of the World.
+type testOp struct{ Tick int64 }
+
+func (op testOp) Run(tx *Tx) { tx.World().SetTime(int(op.Tick)) }
+
+func init() {
+	gob.Register(testOp{})
+}
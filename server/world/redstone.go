@@ -276,6 +276,8 @@ func (e *redstoneEngine) tick(tx *Tx, tick int64) {
 		return
 	}
 	e.currentTick = tick
+	p := tx.World().profiler.Load()
+	start := p.now()
 	dirty := maps.Clone(e.dirty)
 	clear(e.dirty)
 
@@ -291,10 +293,15 @@ func (e *redstoneEngine) tick(tx *Tx, tick int64) {
 	}()
 
 	powers := e.graphPower(tx, graph)
+	// Compiling and evaluating the graph covers all dirty networks at once, so
+	// only the updates of individual nodes are attributed to chunks.
+	p.add(ProfileRedstone, start)
 	for i, node := range graph.nodes {
 		d := redstoneDirtyContext(dirty, node.pos)
 		if node.sink {
+			start := p.now()
 			e.update(tx, node.pos, d, powers[i])
+			p.addChunk(ProfileRedstone, chunkPosFromBlockPos(node.pos), start)
 		}
 	}
 	for _, node := range graph.nodes {
@@ -303,7 +310,9 @@ func (e *redstoneEngine) tick(tx *Tx, tick int64) {
 		}
 		d := redstoneDirtyContext(dirty, node.pos)
 		if node.source {
+			start := p.now()
 			e.updateSource(tx, node.pos, d)
+			p.addChunk(ProfileRedstone, chunkPosFromBlockPos(node.pos), start)
 		}
 	}
 }
@@ -0,0 +1,383 @@
+package world
+
+import (
+	"math"
+	"slices"
+
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/world/chunk"
+)
+
+// Mask selects the blocks affected by a region operation such as Tx.Fill. It is passed the block state at a
+// position and returns true if the block at that position should be changed. Block entity data is not included in
+// the block passed. A nil Mask selects all blocks.
+type Mask func(b Block) bool
+
+// MatchBlocks returns a Mask that selects blocks with the same state as any of the blocks passed.
+func MatchBlocks(blocks ...Block) Mask {
+	type state struct{ base, hash uint64 }
+	states := make(map[state]struct{}, len(blocks))
+	for _, b := range blocks {
+		base, hash := b.Hash()
+		states[state{base, hash}] = struct{}{}
+	}
+	return func(b Block) bool {
+		base, hash := b.Hash()
+		_, ok := states[state{base, hash}]
+		return ok
+	}
+}
+
+// Edit is a change made to a region of a World by one of the region operations of a Tx, such as Tx.Fill. An Edit
+// holds the previous state of the region, so that it may be undone using Revert.
+type Edit struct {
+	w    *World
+	pos  cube.Pos
+	prev *Clipboard
+}
+
+// Revert restores the region changed by the Edit to the state it had before the Edit. The state that the region had
+// before calling Revert is kept, so that calling Revert a second time redoes the Edit. Revert returns false if the
+// Edit was not made in the World of the Tx passed.
+func (e *Edit) Revert(tx *Tx) bool {
+	if e == nil || tx.World() != e.w {
+		return false
+	}
+	size := e.prev.Dimensions()
+	hi := e.pos.Add(cube.Pos{size[0] - 1, size[1] - 1, size[2] - 1})
+	cur := tx.copyRegion(e.pos, hi, e.pos)
+	tx.pasteRegion(e.pos, e.prev, false)
+	e.prev = cur
+	return true
+}
+
+// Volume returns the number of blocks in the region changed by the Edit.
+func (e *Edit) Volume() int {
+	size := e.prev.Dimensions()
+	return size[0] * size[1] * size[2]
+}
+
+// History keeps track of the Edits made by a player or other source, so that they may be undone and redone in
+// order. A History is not safe for concurrent use.
+type History struct {
+	limit      int
+	undo, redo []*Edit
+}
+
+// NewHistory returns a History that holds up to limit Edits. If limit is 0 or less, the History is unlimited.
+func NewHistory(limit int) *History {
+	return &History{limit: limit}
+}
+
+// Add adds an Edit to the History. Any Edits undone before are discarded, so that they can no longer be redone.
+// If the History is full, the oldest Edit is discarded.
+func (h *History) Add(e *Edit) {
+	if e == nil {
+		return
+	}
+	h.redo = h.redo[:0]
+	h.undo = append(h.undo, e)
+	if h.limit > 0 && len(h.undo) > h.limit {
+		h.undo = slices.Delete(h.undo, 0, len(h.undo)-h.limit)
+	}
+}
+
+// Undo undoes the latest Edit in the History that has not yet been undone. False is returned if there was no Edit to
+// undo or if the Edit was made in a different World than that of the Tx.
+func (h *History) Undo(tx *Tx) bool {
+	if len(h.undo) == 0 {
+		return false
+	}
+	e := h.undo[len(h.undo)-1]
+	if !e.Revert(tx) {
+		return false
+	}
+	h.undo, h.redo = h.undo[:len(h.undo)-1], append(h.redo, e)
+	return true
+}
+
+// Redo redoes the latest Edit in the History that was undone. False is returned if there was no Edit to redo or if
+// the Edit was made in a different World than that of the Tx.
+func (h *History) Redo(tx *Tx) bool {
+	if len(h.redo) == 0 {
+		return false
+	}
+	e := h.redo[len(h.redo)-1]
+	if !e.Revert(tx) {
+		return false
+	}
+	h.redo, h.undo = h.redo[:len(h.redo)-1], append(h.undo, e)
+	return true
+}
+
+// NextUndo returns the Edit that the next call to Undo would undo, or nil if there is no Edit to undo.
+func (h *History) NextUndo() *Edit {
+	if len(h.undo) == 0 {
+		return nil
+	}
+	return h.undo[len(h.undo)-1]
+}
+
+// NextRedo returns the Edit that the next call to Redo would redo, or nil if there is no Edit to redo.
+func (h *History) NextRedo() *Edit {
+	if len(h.redo) == 0 {
+		return nil
+	}
+	return h.redo[len(h.redo)-1]
+}
+
+// regionChange is a change to a single position in a region, returned by the function passed to Tx.editRegion.
+type regionChange struct {
+	// rid and liquid are the runtime IDs of the block and liquid to set.
+	rid, liquid uint32
+	// nbt is the block set if rid is the runtime ID of a block with block entity data. If nil, the block with the
+	// runtime ID is used.
+	nbt Block
+}
+
+// regionBounds returns the lowest and highest positions of the blocks that the cube.BBox passed intersects with,
+// limited to the height range of the World. False is returned if the box holds no blocks within that range.
+func (tx *Tx) regionBounds(box cube.BBox) (lo, hi cube.Pos, ok bool) {
+	boxMin, boxMax, r := box.Min(), box.Max(), tx.Range()
+	lo = cube.Pos{int(math.Floor(boxMin[0])), max(int(math.Floor(boxMin[1])), r[0]), int(math.Floor(boxMin[2]))}
+	hi = cube.Pos{int(math.Ceil(boxMax[0])) - 1, min(int(math.Ceil(boxMax[1]))-1, r[1]), int(math.Ceil(boxMax[2])) - 1}
+	return lo, hi, lo[0] <= hi[0] && lo[1] <= hi[1] && lo[2] <= hi[2]
+}
+
+// fill sets all blocks within the cube.BBox passed that are selected by the Mask and for which within returns true
+// to the Block passed, returning an Edit that may be used to undo the change.
+func (tx *Tx) fill(box cube.BBox, b Block, m Mask, within func(pos, lo, hi cube.Pos) bool) *Edit {
+	lo, hi, ok := tx.regionBounds(box)
+	if !ok {
+		return nil
+	}
+	w := tx.World()
+	edit := &Edit{w: w, pos: lo, prev: tx.copyRegion(lo, hi, lo)}
+
+	rid, air := w.conf.Blocks.BlockRuntimeID(b), w.conf.Blocks.AirRuntimeID()
+	change := regionChange{rid: rid, liquid: air}
+	masked := make(map[uint32]bool)
+	tx.editRegion(lo, hi, func(pos cube.Pos, current uint32) (regionChange, bool) {
+		if within != nil && !within(pos, lo, hi) {
+			return change, false
+		}
+		if m != nil {
+			selected, ok := masked[current]
+			if !ok {
+				selected = m(w.conf.Blocks.BlockByRuntimeIDOrAir(current))
+				masked[current] = selected
+			}
+			if !selected {
+				return change, false
+			}
+		}
+		if w.conf.Blocks.NBTBlock(rid) {
+			change.nbt = cloneBlockEntity(b)
+		}
+		return change, true
+	})
+	return edit
+}
+
+// walls reports if a position is on one of the vertical sides of the region between lo and hi.
+func walls(pos, lo, hi cube.Pos) bool {
+	return pos[0] == lo[0] || pos[0] == hi[0] || pos[2] == lo[2] || pos[2] == hi[2]
+}
+
+// outline reports if a position is on any of the sides of the region between lo and hi.
+func outline(pos, lo, hi cube.Pos) bool {
+	return walls(pos, lo, hi) || pos[1] == lo[1] || pos[1] == hi[1]
+}
+
+// copyRegion copies the blocks between lo and hi, inclusive, into a new Clipboard. The position origin is used as
+// the origin of the Clipboard.
+func (tx *Tx) copyRegion(lo, hi, origin cube.Pos) *Clipboard {
+	w := tx.World()
+	c := newClipboard(w.conf.Blocks, [3]int{hi[0] - lo[0] + 1, hi[1] - lo[1] + 1, hi[2] - lo[2] + 1}, origin.Sub(lo))
+	palette := make(map[uint32]uint32)
+	paletteIndex := func(rid uint32) uint32 {
+		i, ok := palette[rid]
+		if !ok {
+			i = uint32(len(c.palette))
+			palette[rid] = i
+			c.palette = append(c.palette, w.conf.Blocks.BlockByRuntimeIDOrAir(rid))
+		}
+		return i
+	}
+	// Make sure air is always present in the palette, so that positions outside the height range of the World
+	// are air.
+	paletteIndex(w.conf.Blocks.AirRuntimeID())
+
+	r := tx.Range()
+	for chunkX := lo[0] >> 4; chunkX <= hi[0]>>4; chunkX++ {
+		for chunkZ := lo[2] >> 4; chunkZ <= hi[2]>>4; chunkZ++ {
+			col := tx.chunk(ChunkPos{int32(chunkX), int32(chunkZ)})
+			for x := max(lo[0], chunkX<<4); x <= min(hi[0], chunkX<<4+15); x++ {
+				for z := max(lo[2], chunkZ<<4); z <= min(hi[2], chunkZ<<4+15); z++ {
+					for y := max(lo[1], r[0]); y <= min(hi[1], r[1]); y++ {
+						lx, ly, lz := uint8(x), int16(y), uint8(z)
+						i := c.index(x-lo[0], y-lo[1], z-lo[2])
+						rid := col.Block(lx, ly, lz, 0)
+						c.blocks[i], c.liquids[i] = paletteIndex(rid), paletteIndex(col.Block(lx, ly, lz, 1))
+						if be, ok := col.BlockEntities[cube.Pos{x, y, z}]; ok && w.conf.Blocks.NBTBlock(rid) {
+							c.entities[i] = cloneBlockEntity(be)
+						}
+					}
+				}
+			}
+		}
+	}
+	return c
+}
+
+// pasteRegion places the Clipboard passed so that its origin is at pos. If ignoreAir is true, positions with air in
+// the Clipboard are left unchanged.
+func (tx *Tx) pasteRegion(pos cube.Pos, c *Clipboard, ignoreAir bool) {
+	w := tx.World()
+	lo, size := pos.Sub(c.origin), c.Dimensions()
+	hi := lo.Add(cube.Pos{size[0] - 1, size[1] - 1, size[2] - 1})
+
+	rids := make([]uint32, len(c.palette))
+	for i, b := range c.palette {
+		if c.reg == w.conf.Blocks {
+			rids[i] = w.conf.Blocks.BlockRuntimeID(b)
+			continue
+		}
+		// The Clipboard was copied from a World with a different block registry, so the block might not exist
+		// in this one.
+		rids[i] = w.conf.Blocks.AirRuntimeID()
+		if nb, ok := w.conf.Blocks.BlockByName(b.EncodeBlock()); ok {
+			rids[i] = w.conf.Blocks.BlockRuntimeID(nb)
+		}
+	}
+	air := w.conf.Blocks.AirRuntimeID()
+	tx.editRegion(cube.Pos{lo[0], max(lo[1], tx.Range()[0]), lo[2]}, cube.Pos{hi[0], min(hi[1], tx.Range()[1]), hi[2]}, func(p cube.Pos, _ uint32) (regionChange, bool) {
+		i := c.index(p[0]-lo[0], p[1]-lo[1], p[2]-lo[2])
+		change := regionChange{rid: rids[c.blocks[i]], liquid: rids[c.liquids[i]]}
+		if ignoreAir && change.rid == air && change.liquid == air {
+			return change, false
+		}
+		if be, ok := c.entities[i]; ok {
+			change.nbt = cloneBlockEntity(be)
+		}
+		return change, true
+	})
+}
+
+// paste pastes the Clipboard passed so that its origin is at pos and returns an Edit that may be used to undo it.
+func (tx *Tx) paste(pos cube.Pos, c *Clipboard, ignoreAir bool) *Edit {
+	lo, size := pos.Sub(c.origin), c.Dimensions()
+	hi := lo.Add(cube.Pos{size[0] - 1, size[1] - 1, size[2] - 1})
+	lo[1], hi[1] = max(lo[1], tx.Range()[0]), min(hi[1], tx.Range()[1])
+	if lo[1] > hi[1] {
+		return nil
+	}
+	edit := &Edit{w: tx.World(), pos: lo, prev: tx.copyRegion(lo, hi, lo)}
+	tx.pasteRegion(pos, c, ignoreAir)
+	return edit
+}
+
+// stack repeats the blocks within the cube.BBox passed n times in the direction of the cube.Face passed, directly
+// adjacent to each other, and returns an Edit that may be used to undo it.
+func (tx *Tx) stack(box cube.BBox, f cube.Face, n int) *Edit {
+	lo, hi, ok := tx.regionBounds(box)
+	if !ok || n <= 0 {
+		return nil
+	}
+	c := tx.copyRegion(lo, hi, lo)
+	size := c.Dimensions()
+	offset := cube.Pos{}.Side(f)
+	offset = cube.Pos{offset[0] * size[0], offset[1] * size[1], offset[2] * size[2]}
+
+	last := cube.Pos{offset[0] * n, offset[1] * n, offset[2] * n}
+	editLo, editHi := cube.Min(lo.Add(offset), lo.Add(last)), cube.Max(hi.Add(offset), hi.Add(last))
+	editLo[1], editHi[1] = max(editLo[1], tx.Range()[0]), min(editHi[1], tx.Range()[1])
+	if editLo[1] > editHi[1] {
+		return nil
+	}
+	edit := &Edit{w: tx.World(), pos: editLo, prev: tx.copyRegion(editLo, editHi, editLo)}
+	for i := 1; i <= n; i++ {
+		tx.pasteRegion(lo.Add(cube.Pos{offset[0] * i, offset[1] * i, offset[2] * i}), c, false)
+	}
+	return edit
+}
+
+// editRegion changes the blocks between lo and hi, inclusive, to those returned by f, working on the paletted
+// storage of every chunk in the region directly. f is called for every position in the region along with the
+// runtime ID of the block currently there, and returns the change to make and whether the position should be
+// changed at all. No block updates are performed. Instead, the light of the changed chunks is recalculated, after
+// which every changed chunk is sent to its viewers again once.
+func (tx *Tx) editRegion(lo, hi cube.Pos, f func(pos cube.Pos, current uint32) (regionChange, bool)) {
+	w := tx.World()
+	changed := make(map[ChunkPos]*Column)
+	for chunkX := lo[0] >> 4; chunkX <= hi[0]>>4; chunkX++ {
+		for chunkZ := lo[2] >> 4; chunkZ <= hi[2]>>4; chunkZ++ {
+			chunkPos := ChunkPos{int32(chunkX), int32(chunkZ)}
+			c := tx.chunk(chunkPos)
+
+			var modified bool
+			for x := max(lo[0], chunkX<<4); x <= min(hi[0], chunkX<<4+15); x++ {
+				for z := max(lo[2], chunkZ<<4); z <= min(hi[2], chunkZ<<4+15); z++ {
+					for y := lo[1]; y <= hi[1]; y++ {
+						pos, lx, ly, lz := cube.Pos{x, y, z}, uint8(x), int16(y), uint8(z)
+						change, ok := f(pos, c.Block(lx, ly, lz, 0))
+						if !ok {
+							continue
+						}
+						c.SetBlock(lx, ly, lz, 0, change.rid)
+						c.SetBlock(lx, ly, lz, 1, change.liquid)
+						if w.conf.Blocks.NBTBlock(change.rid) {
+							if change.nbt == nil {
+								change.nbt = w.conf.Blocks.BlockByRuntimeIDOrAir(change.rid)
+							}
+							c.BlockEntities[pos] = change.nbt
+						} else {
+							delete(c.BlockEntities, pos)
+						}
+						w.redstone.forget(pos)
+						modified = true
+					}
+				}
+			}
+			if modified {
+				c.modified = true
+				changed[chunkPos] = c
+			}
+		}
+	}
+	w.relight(changed)
+	for pos, c := range changed {
+		for _, viewer := range c.viewers {
+			viewer.ViewChunk(pos, w.Dimension(), c.BlockEntities, c.Chunk)
+		}
+	}
+}
+
+// relight recalculates the light of the chunks passed. Because light from the chunks passed may have spread into
+// neighbouring chunks, the light of those neighbours is recalculated as well.
+func (w *World) relight(changed map[ChunkPos]*Column) {
+	neighbours := func(positions map[ChunkPos]struct{}) map[ChunkPos]struct{} {
+		m := make(map[ChunkPos]struct{}, len(positions)*9)
+		for pos := range positions {
+			for x := int32(-1); x <= 1; x++ {
+				for z := int32(-1); z <= 1; z++ {
+					if n := (ChunkPos{pos[0] + x, pos[1] + z}); w.chunks[n] != nil {
+						m[n] = struct{}{}
+					}
+				}
+			}
+		}
+		return m
+	}
+	positions := make(map[ChunkPos]struct{}, len(changed))
+	for pos := range changed {
+		positions[pos] = struct{}{}
+	}
+	fill := neighbours(positions)
+	for pos := range fill {
+		chunk.LightArea([]*chunk.Chunk{w.chunks[pos].Chunk}, int(pos[0]), int(pos[1])).Fill()
+	}
+	for pos := range neighbours(fill) {
+		w.spreadLight(pos)
+	}
+}
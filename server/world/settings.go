@@ -13,6 +13,12 @@ import (
 type Settings struct {
 	sync.Mutex
 	ref atomic.Int32
+	// rulesVersion is incremented every time a GameRule is changed through a World, so that Worlds sharing the
+	// Settings know to refresh their copy of the GameRules.
+	rulesVersion atomic.Uint32
+	// bordersVersion is incremented every time a Border is changed through a World, so that Worlds know to refresh
+	// their copy of their Border.
+	bordersVersion atomic.Uint32
 
 	// Name is the display name of the World.
 	Name string
@@ -45,6 +51,16 @@ type Settings struct {
 	// TickRange is the radius in chunks around a Viewer that has its blocks and entities ticked when the world is
 	// ticked. If set to 0, blocks and entities will never be ticked.
 	TickRange int32
+	// GameRules holds the values of GameRules changed in the World, indexed by their name. GameRules not present
+	// have their default value. GameRule.Value should be used to read the values held and GameRule.Set to change
+	// them, as Worlds do not notice changes made to GameRules directly.
+	GameRules map[string]any
+	// Borders holds the Border of every World using the Settings, indexed by the ID of the Dimension of the World as
+	// returned by DimensionID, so that Worlds of different dimensions sharing the Settings each have their own
+	// Border. Players and entities are unable to move beyond an enabled Border. Worlds without a Border in Borders
+	// have a disabled Border. World.SetBorder should be used to change a Border, as Worlds do not notice changes
+	// made to Borders directly.
+	Borders map[int]Border
 }
 
 // defaultSettings returns the default Settings for a new World.
@@ -276,7 +276,7 @@ func (w *World) Do(f func(tx *Tx)) *Task {
 	return w.scheduleTask(newTask(), func(tx *Tx) error {
 		f(tx)
 		return nil
-	})
+	}, nil)
 }
 
 // DoAfter schedules f to run on the world owner after delay. Cancelling the
@@ -288,7 +288,7 @@ func (w *World) DoAfter(delay time.Duration, f func(tx *Tx)) *Task {
 		return nil
 	}
 	if delay <= 0 {
-		return w.scheduleTask(t, run)
+		return w.scheduleTask(t, run, nil)
 	}
 	if w == nil || w.queue == nil || w.closed.Load() {
 		t.failIfPending(ErrWorldClosed)
@@ -299,7 +299,7 @@ func (w *World) DoAfter(delay time.Duration, f func(tx *Tx)) *Task {
 		defer timer.Stop()
 		select {
 		case <-timer.C:
-			w.scheduleTask(t, run)
+			w.scheduleTask(t, run, nil)
 		case <-t.Done():
 		case <-w.closeStarted:
 			t.failIfPending(ErrWorldClosed)
@@ -330,7 +330,7 @@ func Call[T any](ctx context.Context, w *World, f func(tx *Tx) (T, error)) (T, e
 		var err error
 		result, err = f(tx)
 		return err
-	})
+	}, nil)
 	return awaitTask(ctx, task, &result)
 }
 
@@ -343,7 +343,7 @@ func CallEntity[T any](ctx context.Context, h *EntityHandle, f func(tx *Tx, e En
 
 // scheduleTask enqueues a scheduledTransaction on the world's owner queue,
 // handing a full queue off to a helper goroutine rather than blocking.
-func (w *World) scheduleTask(task *Task, f func(tx *Tx) error) *Task {
+func (w *World) scheduleTask(task *Task, f func(tx *Tx) error, op Op) *Task {
 	if task == nil {
 		task = newTask()
 	}
@@ -355,6 +355,12 @@ func (w *World) scheduleTask(task *Task, f func(tx *Tx) error) *Task {
 		return task
 	}
 	st := scheduledTransaction{task: task, f: f}
+	if w.conf.Recorder != nil {
+		st.rec = &transactionRecord{op: op}
+		if op == nil {
+			st.rec.caller = externalCaller()
+		}
+	}
 	w.scheduleMu.Lock()
 	if w.closed.Load() {
 		w.scheduleMu.Unlock()
@@ -404,6 +410,16 @@ func (w *World) queueScheduled(st scheduledTransaction) {
 type scheduledTransaction struct {
 	task *Task
 	f    func(tx *Tx) error
+	// rec is non-nil if the transaction was submitted externally to a World
+	// with a Recorder and must be recorded when it runs.
+	rec *transactionRecord
+}
+
+// transactionRecord holds the Op or caller of a scheduledTransaction to be
+// recorded by a Recorder.
+type transactionRecord struct {
+	op     Op
+	caller string
 }
 
 // Run executes the scheduled callback on the world goroutine.
@@ -411,9 +427,16 @@ func (st scheduledTransaction) Run(w *World) {
 	if !st.task.begin() {
 		return
 	}
+	var prev *transactionRecord
+	if st.rec != nil {
+		prev = w.beginRecord(st.rec)
+	}
 	tx := newTx(w)
 	err := executeWithRecovery(w, func() error { return st.f(tx) })
 	tx.close()
 	tx.runDeferred()
+	if st.rec != nil {
+		w.endRecord(prev)
+	}
 	st.task.finish(err)
 }
@@ -68,6 +68,7 @@ func (t ticker) tick(tx *Tx) {
 			w.advanceWeather()
 		}
 	}
+	w.tickBorder()
 
 	rain, thunder, tick, tim, cycle := w.set.Raining, w.set.Thundering && w.set.Raining, w.set.CurrentTick, int(w.set.Time), w.set.TimeCycle
 
@@ -78,12 +79,21 @@ func (t ticker) tick(tx *Tx) {
 	}
 
 	w.set.Unlock()
+	w.recordTick(tick)
+
+	p, start := w.profiler.Load(), time.Now()
+	defer func() {
+		d := time.Since(start)
+		p.tick(d)
+		w.conf.Observer.ObserveTick(w, d)
+	}()
 
 	if tryAdvanceDay {
 		t.tryAdvanceDay(tx, cycle)
 	}
 
 	if tick%20 == 0 {
+		broadcastStart := p.now()
 		for _, viewer := range viewers {
 			if w.Dimension().TimeCycle() && cycle {
 				viewer.ViewTime(tim)
@@ -92,6 +102,7 @@ func (t ticker) tick(tx *Tx) {
 				viewer.ViewWeather(rain, thunder)
 			}
 		}
+		p.add(ProfileBroadcast, broadcastStart)
 	}
 	if thunder {
 		w.tickLightning(tx)
@@ -106,12 +117,14 @@ func (t ticker) tick(tx *Tx) {
 
 // performNeighbourUpdates performs all block updates that came as a result of a neighbouring block being changed.
 func (t ticker) performNeighbourUpdates(tx *Tx) {
+	p := tx.World().profiler.Load()
 	updates := slices.Clone(tx.World().neighbourUpdates)
 	clear(tx.World().neighbourUpdates)
 	tx.World().neighbourUpdates = tx.World().neighbourUpdates[:0]
 
 	for _, update := range updates {
 		pos, changedNeighbour := update.pos, update.neighbour
+		start := p.now()
 		if ticker, ok := tx.Block(pos).(NeighbourUpdateTicker); ok {
 			ticker.NeighbourUpdateTick(pos, changedNeighbour, tx)
 		}
@@ -120,12 +133,14 @@ func (t ticker) performNeighbourUpdates(tx *Tx) {
 				ticker.NeighbourUpdateTick(pos, changedNeighbour, tx)
 			}
 		}
+		p.addChunk(ProfileNeighbourUpdates, chunkPosFromBlockPos(pos), start)
 	}
 }
 
 // tickBlocksRandomly executes random block ticks in loaded chunks within range of loaders.
 func (t ticker) tickBlocksRandomly(tx *Tx, loaders []*Loader, tick int64) {
 	var (
+		p             = tx.World().profiler.Load()
 		r             = int32(tx.World().tickRange())
 		g             randUint4
 		blockEntities []cube.Pos
@@ -183,14 +198,18 @@ func (t ticker) tickBlocksRandomly(tx *Tx, loaders []*Loader, tick int64) {
 	}
 
 	for _, pos := range randomBlocks {
+		start := p.now()
 		if rb, ok := tx.Block(pos).(RandomTicker); ok {
 			rb.RandomTick(pos, tx, tx.World().r)
 		}
+		p.addChunk(ProfileRandomTicks, chunkPosFromBlockPos(pos), start)
 	}
 	for _, pos := range blockEntities {
+		start := p.now()
 		if tb, ok := tx.Block(pos).(TickerBlock); ok {
 			tb.Tick(tick, pos, tx)
 		}
+		p.addChunk(ProfileBlockEntities, chunkPosFromBlockPos(pos), start)
 	}
 }
 
@@ -210,6 +229,7 @@ func (t ticker) anyWithinDistance(pos ChunkPos, loaded []ChunkPos, r int32) bool
 // tickEntities ticks all entities in the world, making sure they are still located in the correct chunks and
 // updating where necessary.
 func (t ticker) tickEntities(tx *Tx, tick int64) {
+	p, a := tx.World().profiler.Load(), newActivation(tx, tick)
 	for handle, lastPos := range tx.World().entities {
 		e := handle.mustEntity(tx)
 		chunkPos := chunkPosFromVec3(handle.data.Pos)
@@ -220,6 +240,7 @@ func (t ticker) tickEntities(tx *Tx, tick int64) {
 		}
 
 		if lastPos != chunkPos {
+			start := p.now()
 			// The entity was stored using an outdated chunk position. We update it and make sure it is ready
 			// for loaders to view it.
 			tx.World().entities[handle] = chunkPos
@@ -249,11 +270,21 @@ func (t ticker) tickEntities(tx *Tx, tick int64) {
 					showEntity(e, viewer)
 				}
 			}
+			p.addChunk(ProfileBroadcast, chunkPos, start)
 		}
 
 		if tx.World().conf.Synchronous || len(c.viewers) > 0 {
 			if te, ok := e.(TickerEntity); ok {
+				skipped, ok := a.shouldTick(tx.World(), handle, handle.data.Pos, tick)
+				if !ok {
+					continue
+				}
+				start := p.now()
+				if c, ok := te.(CatchUpTicker); ok && skipped > 0 {
+					c.CatchUp(tx, skipped)
+				}
 				te.Tick(tx, tick)
+				p.addEntity(handle.t, chunkPos, start)
 			}
 		}
 	}
@@ -310,10 +341,12 @@ func (queue *scheduledTickQueue) tick(tx *Tx, tick int64) {
 	queue.currentTick = tick
 
 	w := tx.World()
+	p := w.profiler.Load()
 	for _, t := range queue.ticks {
 		if t.t > tick {
 			continue
 		}
+		start := p.now()
 		b := tx.Block(t.pos)
 		if ticker, ok := b.(ScheduledTicker); ok && w.conf.Blocks.BlockHash(b) == t.bhash {
 			ticker.ScheduledTick(t.pos, tx, w.r)
@@ -322,6 +355,7 @@ func (queue *scheduledTickQueue) tick(tx *Tx, tick int64) {
 				ticker.ScheduledTick(t.pos, tx, w.r)
 			}
 		}
+		p.addChunk(ProfileScheduledUpdates, chunkPosFromBlockPos(t.pos), start)
 	}
 
 	// Clear scheduled ticks that were processed from the queue.
@@ -138,6 +138,56 @@ func (tx *Tx) BuildStructure(pos cube.Pos, s Structure) {
 	tx.buildStructure(pos, s)
 }
 
+// Fill sets all blocks within the cube.BBox passed to the Block passed. If a
+// Mask is passed, only the blocks it selects are changed, so that Fill may also
+// be used to replace specific blocks. A block is within the box if the box
+// intersects with it, so cube.Box(0, 0, 0, 1, 1, 1) holds a single block.
+// Like BuildStructure, Fill works on entire chunks at once and does not
+// perform block updates. The Edit returned may be used to undo the change. It
+// is nil if the box holds no blocks.
+func (tx *Tx) Fill(box cube.BBox, b Block, m Mask) *Edit {
+	return tx.fill(box, b, m, nil)
+}
+
+// Walls sets the blocks on the four vertical sides of the cube.BBox passed to
+// the Block passed. The Edit returned may be used to undo the change.
+func (tx *Tx) Walls(box cube.BBox, b Block) *Edit {
+	return tx.fill(box, b, nil, walls)
+}
+
+// Outline sets the blocks on all six sides of the cube.BBox passed to the
+// Block passed, leaving the inside unchanged. The Edit returned may be used to
+// undo the change.
+func (tx *Tx) Outline(box cube.BBox, b Block) *Edit {
+	return tx.fill(box, b, nil, outline)
+}
+
+// Copy copies the blocks within the cube.BBox passed into a Clipboard, which
+// may be pasted using Paste. The position origin is the origin of the
+// Clipboard: When pasted, the origin is placed at the position passed to
+// Paste. Copy returns nil if the box holds no blocks.
+func (tx *Tx) Copy(box cube.BBox, origin cube.Pos) *Clipboard {
+	lo, hi, ok := tx.regionBounds(box)
+	if !ok {
+		return nil
+	}
+	return tx.copyRegion(lo, hi, origin)
+}
+
+// Paste places the blocks of the Clipboard passed so that its origin is at
+// pos. If ignoreAir is true, positions with air in the Clipboard are left
+// unchanged. The Edit returned may be used to undo the change.
+func (tx *Tx) Paste(pos cube.Pos, c *Clipboard, ignoreAir bool) *Edit {
+	return tx.paste(pos, c, ignoreAir)
+}
+
+// Stack repeats the blocks within the cube.BBox passed n times in the
+// direction of the cube.Face passed, with every copy directly adjacent to the
+// previous one. The Edit returned may be used to undo the change.
+func (tx *Tx) Stack(box cube.BBox, f cube.Face, n int) *Edit {
+	return tx.stack(box, f, n)
+}
+
 // ScheduleBlockUpdate schedules a block update at the position passed for the
 // block type passed after a specific delay. If the block at that position does
 // not handle block updates, nothing will happen.
@@ -148,6 +198,14 @@ func (tx *Tx) ScheduleBlockUpdate(pos cube.Pos, b Block, delay time.Duration) {
 	tx.World().scheduleBlockUpdate(pos, b, delay)
 }
 
+// RandomSpawn returns a random position within GameRuleSpawnRadius blocks of
+// the spawn of the World, on top of the highest obstructing block at that
+// position. It is used for players that have no spawn position of their own.
+// The spawn of the World is returned if no such block exists.
+func (tx *Tx) RandomSpawn() cube.Pos {
+	return tx.randomSpawn()
+}
+
 // HighestLightBlocker gets the Y value of the highest fully light blocking
 // block at the x and z values passed in the World.
 func (tx *Tx) HighestLightBlocker(x, z int) int {
@@ -291,6 +349,11 @@ func (tx *Tx) Players() iter.Seq[Entity] {
 	return tx.World().allPlayers(tx)
 }
 
+// LoadedChunks returns the number of chunks currently loaded in the World.
+func (tx *Tx) LoadedChunks() int {
+	return len(tx.World().chunks)
+}
+
 // Viewers returns all viewers viewing the position passed.
 func (tx *Tx) Viewers(pos mgl64.Vec3) []Viewer {
 	return tx.World().viewersOf(pos)
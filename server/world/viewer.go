@@ -45,6 +45,9 @@ type Viewer interface {
 	ViewTime(t int)
 	// ViewTimeCycle controls the automatic time-of-day cycle (day and night) in the world for this viewer.
 	ViewTimeCycle(doDayLightCycle bool)
+	// ViewGameRules views the values of the GameRules passed, indexed by their name. It is called when the viewer
+	// is added to a World and when a GameRule known by the client is changed.
+	ViewGameRules(rules map[string]any)
 	// ViewEntityItems views the items currently held by an Entity that is able to equip items.
 	ViewEntityItems(e Entity)
 	// ViewEntityArmour views the items currently equipped as armour by the Entity.
@@ -97,6 +100,7 @@ func (NopViewer) ViewEntityTeleport(Entity, mgl64.Vec3)
 func (NopViewer) ViewChunk(ChunkPos, Dimension, map[cube.Pos]Block, *chunk.Chunk)            {}
 func (NopViewer) ViewTime(int)                                                               {}
 func (NopViewer) ViewTimeCycle(bool)                                                         {}
+func (NopViewer) ViewGameRules(map[string]any)                                               {}
 func (NopViewer) ViewEntityItems(Entity)                                                     {}
 func (NopViewer) ViewEntityArmour(Entity)                                                    {}
 func (NopViewer) ViewEntityAction(Entity, EntityAction)                                      {}
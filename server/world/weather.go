@@ -186,6 +186,7 @@ func (w weather) enableWeatherCycle(v bool) {
 	w.w.set.Lock()
 	defer w.w.set.Unlock()
 	w.w.set.WeatherCycle = v
+	w.w.set.rulesVersion.Add(1)
 }
 
 // tickLightning iterates over all loaded chunks in the World, striking
@@ -53,6 +53,23 @@ type World struct {
 	set     *Settings
 	handler atomic.Pointer[Handler]
 
+	// unreplayed is the external transaction currently running that must be
+	// recorded by the Recorder of the World, if any, because it cannot be
+	// replayed. It is only accessed in transactions.
+	unreplayed *transactionRecord
+
+	// rules holds a copy of the values of all GameRules in set, indexed by
+	// their name. It is only accessed in transactions and is refreshed when
+	// rulesVersion no longer matches the version in set.
+	rules        map[string]any
+	rulesVersion uint32
+
+	// border holds a copy of the Border of the World in set. It is only
+	// accessed in transactions and is refreshed when borderVersion no longer
+	// matches the version in set.
+	border        Border
+	borderVersion uint32
+
 	weather
 
 	// closeStarted closes as soon as World.Close begins, before the close
@@ -71,6 +88,9 @@ type World struct {
 	// that the Entity was in. These are tracked so that a call to RemoveEntity
 	// can find the correct Entity.
 	entities map[*EntityHandle]ChunkPos
+	// inactive holds the tick at which entities outside their ActivationRange
+	// were last ticked.
+	inactive map[*EntityHandle]int64
 
 	r *rand.Rand
 
@@ -84,6 +104,10 @@ type World struct {
 
 	viewerMu sync.Mutex
 	viewers  map[*Loader]Viewer
+
+	// profiler is non-nil while the ticks of the World are being profiled
+	// after a call to StartProfiling.
+	profiler atomic.Pointer[profiler]
 }
 
 // transaction is a type that may be added to the transaction queue of a World.
@@ -795,6 +819,7 @@ func (w *World) enableTimeCycle(v bool) {
 	w.set.Lock()
 	defer w.set.Unlock()
 	w.set.TimeCycle = v
+	w.set.rulesVersion.Add(1)
 	viewers, _ := w.allViewers()
 	for _, viewer := range viewers {
 		viewer.ViewTimeCycle(v)
@@ -883,6 +908,7 @@ func (w *World) removeEntity(e Entity, tx *Tx) *EntityHandle {
 		v.HideEntity(e)
 	}
 	delete(w.entities, handle)
+	delete(w.inactive, handle)
 	handle.unsetAndLockWorld()
 	return handle
 }
@@ -972,6 +998,25 @@ func (w *World) Spawn() cube.Pos {
 	return w.set.Spawn
 }
 
+// randomSpawn returns a random position within GameRuleSpawnRadius blocks of
+// the spawn of the World, on top of the highest obstructing block at that
+// position, or the spawn of the World itself if no such block exists.
+func (tx *Tx) randomSpawn() cube.Pos {
+	w := tx.World()
+	spawn := w.Spawn()
+	radius := GameRuleSpawnRadius.Value(tx)
+	if radius <= 0 || w.Dimension() != Overworld {
+		return spawn
+	}
+	x := spawn.X() + w.r.IntN(radius*2+1) - radius
+	z := spawn.Z() + w.r.IntN(radius*2+1) - radius
+	y := tx.highestObstructingBlock(x, z)
+	if y == tx.Range()[0] {
+		return spawn
+	}
+	return cube.Pos{x, y + 1, z}
+}
+
 // SetSpawn sets the spawn of the world to a different position. The player
 // will be spawned in the centre of this position when newly joining.
 func (w *World) SetSpawn(pos cube.Pos) {
@@ -1195,9 +1240,12 @@ func (w *World) save(f func(*Tx, ChunkPos, *Column)) execFunc {
 func (w *World) saveChunk(_ *Tx, pos ChunkPos, c *Column) {
 	if !w.conf.ReadOnly && c.modified {
 		c.Compact()
+		start := time.Now()
 		if err := w.conf.Provider.StoreColumn(pos, w.conf.Dim, w.columnTo(c, pos)); err != nil {
 			w.conf.Log.Error("save chunk: "+err.Error(), "X", pos[0], "Z", pos[1])
+			return
 		}
+		w.conf.Observer.ObserveChunkSave(w, time.Since(start))
 	}
 }
 
@@ -1288,6 +1336,7 @@ func (w *World) addWorldViewer(l *Loader) {
 
 	l.viewer.ViewTime(w.Time())
 	l.viewer.ViewTimeCycle(w.TimeCycle())
+	l.viewer.ViewGameRules(w.clientGameRules())
 	w.set.Lock()
 	raining, thundering := w.set.Raining, w.set.Raining && w.set.Thundering
 	w.set.Unlock()
@@ -1378,6 +1427,7 @@ func (tx *Tx) chunk(pos ChunkPos) *Column {
 // loadChunk loads a chunk from the provider, or generates a chunk if one
 // doesn't currently exist, and calculates the light within it.
 func (w *World) loadChunk(pos ChunkPos) (*chunk.Column, error) {
+	start, generated := time.Now(), false
 	column, err := w.conf.Provider.LoadColumn(pos, w.conf.Dim)
 	if err != nil {
 		if !errors.Is(err, leveldb.ErrNotFound) {
@@ -1385,9 +1435,10 @@ func (w *World) loadChunk(pos ChunkPos) (*chunk.Column, error) {
 		}
 		ch := chunk.New(w.conf.Blocks, w.Range())
 		w.conf.Generator.GenerateChunk(pos, ch)
-		column = &chunk.Column{Chunk: ch}
+		column, generated = &chunk.Column{Chunk: ch}, true
 	}
 	chunk.LightArea([]*chunk.Chunk{column.Chunk}, int(pos[0]), int(pos[1])).Fill()
+	w.conf.Observer.ObserveChunkLoad(w, time.Since(start), generated)
 	return column, nil
 }
 
@@ -0,0 +1,580 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/df-mc/dragonfly/server/world"
+	"github.com/df-mc/dragonfly/server/world/mcdb"
+)
+
+// worldUnloadInterval is the interval at which worlds added with
+// WorldConfig.UnloadWhenEmpty are checked for players and unloaded if empty.
+const worldUnloadInterval = time.Minute
+
+// WorldConfig holds the configuration of a world added to a Server at runtime
+// using Server.AddWorld. If Config.WorldsFile is set, the WorldConfigs of all
+// worlds added are stored in it, so that the worlds are added again when the
+// Server is restarted.
+type WorldConfig struct {
+	// Name is the name of the world, which must be unique. Names are not
+	// case-sensitive. The names 'overworld', 'nether' and 'end' are reserved
+	// for the default worlds of the Server.
+	Name string
+	// Dimension is the world.Dimension of the world. If nil, the world is an
+	// world.Overworld.
+	Dimension world.Dimension
+	// Folder is the folder that the data of the world is stored in. If empty,
+	// the data of the world is not stored.
+	Folder string
+	// Generator is the name of the world.Generator used to generate new areas
+	// of the world. Generator is passed to Config.WorldLoader. The default
+	// WorldLoader supports 'flat' and 'void' and uses Config.Generator if
+	// Generator is empty.
+	Generator string
+	// ReadOnly specifies if the world should be read only. If true, no data
+	// is written to the Folder.
+	ReadOnly bool
+	// Nether and End are the names of the worlds that nether and end portals
+	// in the world lead to. Portals in the world do not function if empty.
+	// Custom pairs of worlds may be linked by setting Nether on an overworld
+	// and setting its name as Nether on the nether world.
+	Nether, End string
+	// UnloadWhenEmpty specifies if the world should be closed when no players
+	// are in it. The world is loaded again when it is requested using
+	// Server.WorldByName, or in the background when an entity enters a portal
+	// leading to it, after which the portal leads to the world.
+	UnloadWhenEmpty bool
+}
+
+// worldEntry is the stored representation of a WorldConfig in
+// Config.WorldsFile.
+type worldEntry struct {
+	Name            string `json:"name"`
+	Dimension       int    `json:"dimension"`
+	Folder          string `json:"folder,omitempty"`
+	Generator       string `json:"generator,omitempty"`
+	ReadOnly        bool   `json:"read_only,omitempty"`
+	Nether          string `json:"nether,omitempty"`
+	End             string `json:"end,omitempty"`
+	UnloadWhenEmpty bool   `json:"unload_when_empty,omitempty"`
+}
+
+// managedWorld is a world added using Server.AddWorld. w is nil if the world
+// is currently not loaded.
+type managedWorld struct {
+	conf WorldConfig
+	w    *world.World
+	// pending is non-nil while the world is being loaded, unloaded or removed
+	// without holding Server.wmu. It is closed when done, after which w may
+	// be used again.
+	pending chan struct{}
+}
+
+// AddWorld adds a new world to the Server using the WorldConfig passed and
+// loads it. An error is returned if a world with the same name already
+// exists or if the world could not be loaded.
+func (srv *Server) AddWorld(conf WorldConfig) (*world.World, error) {
+	if conf.Dimension == nil {
+		conf.Dimension = world.Overworld
+	}
+	if _, ok := world.DimensionID(conf.Dimension); !ok {
+		return nil, fmt.Errorf("add world %v: unregistered dimension %v", conf.Name, conf.Dimension)
+	}
+	key := strings.ToLower(conf.Name)
+
+	if _, ok := srv.defaultWorld(key); ok || key == "" {
+		return nil, fmt.Errorf("add world %v: name is reserved", conf.Name)
+	}
+	srv.wmu.Lock()
+	if _, ok := srv.worlds[key]; ok {
+		srv.wmu.Unlock()
+		return nil, fmt.Errorf("add world %v: world already exists", conf.Name)
+	}
+	// The world is added as pending, so that the name is reserved while the
+	// world is loaded without holding srv.wmu.
+	m := &managedWorld{conf: conf, pending: make(chan struct{})}
+	srv.worlds[key] = m
+	srv.wmu.Unlock()
+
+	w, err := srv.loadWorld(m.conf)
+
+	srv.wmu.Lock()
+	defer srv.wmu.Unlock()
+	defer m.done()
+	if err != nil {
+		delete(srv.worlds, key)
+		return nil, fmt.Errorf("add world %v: %w", conf.Name, err)
+	}
+	m.w = w
+	srv.storeWorlds()
+	return w, nil
+}
+
+// RemoveWorld closes the world with the name passed and removes it from the
+// Server, so that it is no longer added when the Server is restarted. The
+// data of the world is not deleted. An error is returned if no world with the
+// name was added using AddWorld or if players are still in the world.
+func (srv *Server) RemoveWorld(name string) error {
+	key := strings.ToLower(name)
+
+	m, ok := srv.acquireWorld(key)
+	if !ok {
+		srv.wmu.Unlock()
+		return fmt.Errorf("remove world %v: world not found", name)
+	}
+	w := m.w
+	m.pending = make(chan struct{})
+	srv.wmu.Unlock()
+
+	// The players in the world are counted without holding srv.wmu, as the
+	// world may be waiting for it to find the destination of a portal. As the
+	// world is pending, no new players can be sent to it using WorldByName.
+	if w != nil {
+		if n := worldPlayerCount(w); n > 0 {
+			srv.wmu.Lock()
+			m.done()
+			srv.wmu.Unlock()
+			return fmt.Errorf("remove world %v: %v players still in world", name, n)
+		}
+	}
+
+	srv.wmu.Lock()
+	m.w = nil
+	delete(srv.worlds, key)
+	srv.storeWorlds()
+	m.done()
+	srv.wmu.Unlock()
+
+	if w != nil {
+		if err := w.Close(); err != nil {
+			return fmt.Errorf("remove world %v: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// WorldByName returns the world with the name passed. Besides the worlds
+// added using AddWorld, the default worlds may be found using the names
+// 'overworld', 'nether' and 'end'. If the world was unloaded because it was
+// empty, it is loaded again. If the world is being unloaded, WorldByName
+// waits until it is closed and loads it again. False is returned if no world
+// with the name exists or if it could not be loaded.
+func (srv *Server) WorldByName(name string) (*world.World, bool) {
+	key := strings.ToLower(name)
+	if w, ok := srv.defaultWorld(key); ok {
+		return w, true
+	}
+
+	m, ok := srv.acquireWorld(key)
+	if !ok {
+		srv.wmu.Unlock()
+		return nil, false
+	}
+	if w := m.w; w != nil {
+		srv.wmu.Unlock()
+		return w, true
+	}
+	// The world is loaded without holding srv.wmu, so that loading it does
+	// not block access to other worlds.
+	m.pending = make(chan struct{})
+	srv.wmu.Unlock()
+	return srv.reloadWorld(m)
+}
+
+// portalDestination returns the world with the name passed as destination of
+// a portal. Unlike WorldByName, portalDestination never waits for a world to
+// be loaded, as it is called during a transaction of the world that the
+// portal is in. If the world is not loaded, it is loaded in the background
+// and nil is returned, so that the portal leads to the world once it is
+// loaded.
+func (srv *Server) portalDestination(name string) *world.World {
+	key := strings.ToLower(name)
+	if w, ok := srv.defaultWorld(key); ok {
+		return w
+	}
+	srv.wmu.Lock()
+	defer srv.wmu.Unlock()
+	m, ok := srv.worlds[key]
+	if !ok || m.pending != nil {
+		return nil
+	}
+	if m.w == nil {
+		m.pending = make(chan struct{})
+		go srv.reloadWorld(m)
+	}
+	return m.w
+}
+
+// reloadWorld loads the world of a managedWorld that was unloaded because it
+// was empty. The managedWorld must have been marked as pending by the caller
+// and is marked as no longer pending once the world is loaded. If the Server
+// started closing in the meantime, the world is closed again right away.
+func (srv *Server) reloadWorld(m *managedWorld) (*world.World, bool) {
+	w, err := srv.loadWorld(m.conf)
+	if err != nil {
+		srv.conf.Log.Error("Load world: "+err.Error(), "name", m.conf.Name)
+	} else {
+		select {
+		case <-srv.closing:
+			// The Server started closing while the world was loaded, after
+			// closeWorlds might already have run.
+			if err := w.Close(); err != nil {
+				srv.conf.Log.Error("Close world: "+err.Error(), "name", m.conf.Name)
+			}
+			w = nil
+		default:
+		}
+	}
+	srv.wmu.Lock()
+	defer srv.wmu.Unlock()
+	m.w = w
+	m.done()
+	return w, w != nil
+}
+
+// acquireWorld returns the managedWorld with the key passed, waiting until it
+// is no longer pending. srv.wmu is held when acquireWorld returns, also if no
+// world with the key was found.
+func (srv *Server) acquireWorld(key string) (*managedWorld, bool) {
+	srv.wmu.Lock()
+	for {
+		m, ok := srv.worlds[key]
+		if !ok || m.pending == nil {
+			return m, ok
+		}
+		pending := m.pending
+		srv.wmu.Unlock()
+		<-pending
+		srv.wmu.Lock()
+	}
+}
+
+// done marks the managedWorld as no longer pending. Server.wmu must be held
+// when calling done.
+func (m *managedWorld) done() {
+	close(m.pending)
+	m.pending = nil
+}
+
+// Worlds returns the names of all worlds of the Server, including worlds that
+// are currently unloaded. The names of the default worlds come first.
+func (srv *Server) Worlds() []string {
+	srv.wmu.Lock()
+	names := make([]string, 0, len(srv.worlds))
+	for _, m := range srv.worlds {
+		names = append(names, m.conf.Name)
+	}
+	srv.wmu.Unlock()
+
+	slices.Sort(names)
+	return append([]string{"overworld", "nether", "end"}, names...)
+}
+
+// LoadedWorlds returns an iterator that yields the names and worlds of all
+// worlds of the Server that are currently loaded, starting with the default
+// worlds. Unlike WorldByName, LoadedWorlds does not load worlds that were
+// unloaded because they were empty.
+func (srv *Server) LoadedWorlds() iter.Seq2[string, *world.World] {
+	srv.wmu.Lock()
+	names := make([]string, 0, len(srv.worlds))
+	worlds := make(map[string]*world.World, len(srv.worlds))
+	for _, m := range srv.worlds {
+		if m.w != nil && m.pending == nil {
+			names = append(names, m.conf.Name)
+			worlds[m.conf.Name] = m.w
+		}
+	}
+	srv.wmu.Unlock()
+	slices.Sort(names)
+
+	return func(yield func(string, *world.World) bool) {
+		for _, name := range []string{"overworld", "nether", "end"} {
+			w, _ := srv.defaultWorld(name)
+			if !yield(name, w) {
+				return
+			}
+		}
+		for _, name := range names {
+			if !yield(name, worlds[name]) {
+				return
+			}
+		}
+	}
+}
+
+// defaultWorld returns one of the default worlds of the Server by its name.
+func (srv *Server) defaultWorld(key string) (*world.World, bool) {
+	switch key {
+	case "overworld":
+		return srv.world, true
+	case "nether":
+		return srv.nether, true
+	case "end":
+		return srv.end, true
+	}
+	return nil, false
+}
+
+// loadWorld opens the world with the WorldConfig passed using
+// Config.WorldLoader. srv.wmu should not be held when calling loadWorld, as
+// opening the world may take a while.
+func (srv *Server) loadWorld(wc WorldConfig) (*world.World, error) {
+	prov, gen, err := srv.conf.WorldLoader(wc)
+	if err != nil {
+		return nil, err
+	}
+	logger := srv.conf.Log.With("world", wc.Name)
+	nether, end := wc.Nether, wc.End
+
+	conf := world.Config{
+		Log:                 logger,
+		Dim:                 wc.Dimension,
+		Provider:            prov,
+		Generator:           gen,
+		RandomTickSpeed:     srv.conf.RandomTickSpeed,
+		ReadOnly:            wc.ReadOnly,
+		SaveInterval:        srv.conf.SaveInterval,
+		ChunkUnloadInterval: srv.conf.ChunkUnloadInterval,
+		ChunkLoadWorkers:    srv.conf.ChunkLoadWorkers,
+		Entities:            srv.conf.Entities,
+		Blocks:              srv.conf.Blocks,
+		Observer:            srv.conf.WorldObserver,
+		ActivationRanges:    srv.conf.ActivationRanges,
+		PortalDestination: func(dim world.Dimension) *world.World {
+			var name string
+			switch dim {
+			case world.Nether:
+				name = nether
+			case world.End:
+				name = end
+			}
+			if name == "" {
+				return nil
+			}
+			return srv.portalDestination(name)
+		},
+	}
+	w := conf.New()
+	logger.Info("Opened world.", "name", w.Name(), "dimension", strings.ToLower(fmt.Sprint(wc.Dimension)))
+	return w, nil
+}
+
+// loadWorlds adds all worlds stored in Config.WorldsFile. Worlds with
+// WorldConfig.UnloadWhenEmpty set are not loaded until requested.
+func (srv *Server) loadWorlds() {
+	if srv.conf.WorldsFile == "" {
+		return
+	}
+	data, err := os.ReadFile(srv.conf.WorldsFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	} else if err != nil {
+		srv.conf.Log.Error("Read worlds file: " + err.Error())
+		return
+	}
+	var entries []worldEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		srv.conf.Log.Error("Decode worlds file: " + err.Error())
+		return
+	}
+
+	srv.wmu.Lock()
+	defer srv.wmu.Unlock()
+	for _, e := range entries {
+		dim, ok := world.DimensionByID(e.Dimension)
+		if !ok {
+			srv.conf.Log.Error("Load world: unknown dimension", "name", e.Name, "dimension", e.Dimension)
+			continue
+		}
+		m := &managedWorld{conf: WorldConfig{
+			Name:            e.Name,
+			Dimension:       dim,
+			Folder:          e.Folder,
+			Generator:       e.Generator,
+			ReadOnly:        e.ReadOnly,
+			Nether:          e.Nether,
+			End:             e.End,
+			UnloadWhenEmpty: e.UnloadWhenEmpty,
+		}}
+		if !m.conf.UnloadWhenEmpty {
+			w, err := srv.loadWorld(m.conf)
+			if err != nil {
+				srv.conf.Log.Error("Load world: "+err.Error(), "name", e.Name)
+				continue
+			}
+			m.w = w
+		}
+		srv.worlds[strings.ToLower(e.Name)] = m
+	}
+}
+
+// storeWorlds writes the WorldConfigs of all worlds added to the Server to
+// Config.WorldsFile. srv.wmu must be held when calling storeWorlds.
+func (srv *Server) storeWorlds() {
+	if srv.conf.WorldsFile == "" {
+		return
+	}
+	entries := make([]worldEntry, 0, len(srv.worlds))
+	for _, m := range srv.worlds {
+		id, _ := world.DimensionID(m.conf.Dimension)
+		entries = append(entries, worldEntry{
+			Name:            m.conf.Name,
+			Dimension:       id,
+			Folder:          m.conf.Folder,
+			Generator:       m.conf.Generator,
+			ReadOnly:        m.conf.ReadOnly,
+			Nether:          m.conf.Nether,
+			End:             m.conf.End,
+			UnloadWhenEmpty: m.conf.UnloadWhenEmpty,
+		})
+	}
+	slices.SortFunc(entries, func(a, b worldEntry) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		srv.conf.Log.Error("Encode worlds file: " + err.Error())
+		return
+	}
+	if dir := filepath.Dir(srv.conf.WorldsFile); dir != "." {
+		_ = os.MkdirAll(dir, 0777)
+	}
+	if err := os.WriteFile(srv.conf.WorldsFile, data, 0644); err != nil {
+		srv.conf.Log.Error("Write worlds file: " + err.Error())
+	}
+}
+
+// unloadEmptyWorlds periodically closes worlds that have
+// WorldConfig.UnloadWhenEmpty set and have no players in them, until the
+// Server is closed.
+func (srv *Server) unloadEmptyWorlds() {
+	t := time.NewTicker(worldUnloadInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-srv.closing:
+			return
+		case <-t.C:
+		}
+		srv.wmu.Lock()
+		candidates := make(map[*managedWorld]*world.World)
+		for _, m := range srv.worlds {
+			if m.w != nil && m.conf.UnloadWhenEmpty {
+				candidates[m] = m.w
+			}
+		}
+		srv.wmu.Unlock()
+
+		for m, w := range candidates {
+			srv.unloadWorld(m, w)
+		}
+	}
+}
+
+// unloadWorld closes the world w of the managedWorld passed if no players are
+// in it. While the players are counted and the world is closed, the world is
+// pending, so that WorldByName waits for it to be closed instead of returning
+// it, and no new players can be sent to it.
+func (srv *Server) unloadWorld(m *managedWorld, w *world.World) {
+	srv.wmu.Lock()
+	if m.w != w || m.pending != nil {
+		// The world was removed, reloaded or is being removed in the
+		// meantime.
+		srv.wmu.Unlock()
+		return
+	}
+	m.pending = make(chan struct{})
+	srv.wmu.Unlock()
+
+	if worldPlayerCount(w) > 0 {
+		srv.wmu.Lock()
+		m.done()
+		srv.wmu.Unlock()
+		return
+	}
+	srv.wmu.Lock()
+	m.w = nil
+	srv.wmu.Unlock()
+
+	if err := w.Close(); err != nil {
+		srv.conf.Log.Error("Unload world: "+err.Error(), "name", m.conf.Name)
+	}
+	srv.wmu.Lock()
+	m.done()
+	srv.wmu.Unlock()
+	srv.conf.Log.Debug("Unloaded empty world.", "name", m.conf.Name)
+}
+
+// closeWorlds closes all loaded worlds added using AddWorld. Worlds that are
+// pending are closed once they are no longer pending.
+func (srv *Server) closeWorlds() {
+	srv.wmu.Lock()
+	keys := slices.Collect(maps.Keys(srv.worlds))
+	srv.wmu.Unlock()
+
+	var worlds []*world.World
+	for _, key := range keys {
+		if m, ok := srv.acquireWorld(key); ok && m.w != nil {
+			worlds = append(worlds, m.w)
+			m.w = nil
+		}
+		srv.wmu.Unlock()
+	}
+
+	for _, w := range worlds {
+		if err := w.Close(); err != nil {
+			srv.conf.Log.Error("Close world: "+err.Error(), "name", w.Name())
+		}
+	}
+}
+
+// worldPlayerCount returns the number of players in the world.World passed.
+func worldPlayerCount(w *world.World) int {
+	n, _ := world.Call(context.Background(), w, func(tx *world.Tx) (int, error) {
+		tx.SkipRecording()
+		n := 0
+		for range tx.Players() {
+			n++
+		}
+		return n, nil
+	})
+	return n
+}
+
+// defaultWorldLoader returns the default Config.WorldLoader. It stores world
+// data in WorldConfig.Folder using the LevelDB provider and supports the
+// 'flat' and 'void' generators. If WorldConfig.Generator is empty, the
+// Generator of the Config passed is used.
+func defaultWorldLoader(conf Config) func(wc WorldConfig) (world.Provider, world.Generator, error) {
+	return func(wc WorldConfig) (world.Provider, world.Generator, error) {
+		var gen world.Generator
+		switch wc.Generator {
+		case "":
+			gen = conf.Generator(wc.Dimension)
+		case "flat":
+			gen = loadGenerator(wc.Dimension)
+		case "void":
+			gen = world.NopGenerator{}
+		default:
+			return nil, nil, fmt.Errorf("unknown generator %v", wc.Generator)
+		}
+		if wc.Folder == "" {
+			return world.NopProvider{}, gen, nil
+		}
+		prov, err := mcdb.Config{Log: conf.Log}.Open(wc.Folder)
+		if err != nil {
+			return nil, nil, fmt.Errorf("open provider: %w", err)
+		}
+		return prov, gen, nil
+	}
+}
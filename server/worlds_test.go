@@ -0,0 +1,169 @@
+package server
+
+import (
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/df-mc/dragonfly/server/world"
+)
+
+// newWorldsTestServer returns a running Server without listeners that is
+// closed when the test ends.
+func newWorldsTestServer(t *testing.T, conf Config) *Server {
+	conf.Log = slog.New(slog.DiscardHandler)
+	conf.DisableResourceBuilding = true
+	srv := conf.New()
+	srv.Listen()
+	t.Cleanup(func() { _ = srv.Close() })
+	return srv
+}
+
+func TestServerAddWorld(t *testing.T) {
+	srv := newWorldsTestServer(t, Config{})
+
+	w, err := srv.AddWorld(WorldConfig{Name: "Lobby", Generator: "void"})
+	if err != nil {
+		t.Fatalf("AddWorld() = %v, want nil", err)
+	}
+	if found, ok := srv.WorldByName("lobby"); !ok || found != w {
+		t.Fatalf("WorldByName(lobby) = %v, %v, want %v, true", found, ok, w)
+	}
+	if w.Dimension() != world.Overworld {
+		t.Fatalf("Dimension() = %v, want %v", w.Dimension(), world.Overworld)
+	}
+	if names, want := srv.Worlds(), []string{"overworld", "nether", "end", "Lobby"}; !slices.Equal(names, want) {
+		t.Fatalf("Worlds() = %v, want %v", names, want)
+	}
+
+	for _, conf := range []WorldConfig{{Name: "LOBBY"}, {Name: "nether"}, {Name: ""}, {Name: "unknown", Generator: "unknown"}} {
+		if _, err := srv.AddWorld(conf); err == nil {
+			t.Fatalf("AddWorld(%v) = nil, want error", conf.Name)
+		}
+	}
+	// A world that failed to load does not reserve its name.
+	if _, ok := srv.WorldByName("unknown"); ok {
+		t.Fatalf("WorldByName(unknown) = true, want false")
+	}
+}
+
+func TestServerRemoveWorld(t *testing.T) {
+	srv := newWorldsTestServer(t, Config{})
+
+	if _, err := srv.AddWorld(WorldConfig{Name: "arena", Generator: "void"}); err != nil {
+		t.Fatalf("AddWorld() = %v, want nil", err)
+	}
+	if err := srv.RemoveWorld("Arena"); err != nil {
+		t.Fatalf("RemoveWorld() = %v, want nil", err)
+	}
+	if _, ok := srv.WorldByName("arena"); ok {
+		t.Fatalf("WorldByName() of removed world = true, want false")
+	}
+	if err := srv.RemoveWorld("arena"); err == nil {
+		t.Fatalf("RemoveWorld() of removed world = nil, want error")
+	}
+	if err := srv.RemoveWorld("overworld"); err == nil {
+		t.Fatalf("RemoveWorld(overworld) = nil, want error")
+	}
+	// The name may be used again once the world was removed.
+	if _, err := srv.AddWorld(WorldConfig{Name: "arena", Generator: "void"}); err != nil {
+		t.Fatalf("AddWorld() after RemoveWorld() = %v, want nil", err)
+	}
+}
+
+func TestServerUnloadWorld(t *testing.T) {
+	srv := newWorldsTestServer(t, Config{})
+
+	w, err := srv.AddWorld(WorldConfig{Name: "event", Generator: "void", UnloadWhenEmpty: true})
+	if err != nil {
+		t.Fatalf("AddWorld() = %v, want nil", err)
+	}
+	unload := func() {
+		srv.wmu.Lock()
+		m := srv.worlds["event"]
+		srv.wmu.Unlock()
+		srv.unloadWorld(m, m.w)
+	}
+	loaded := func() bool {
+		for name := range srv.LoadedWorlds() {
+			if name == "event" {
+				return true
+			}
+		}
+		return false
+	}
+
+	t.Run("reload", func(t *testing.T) {
+		unload()
+		if loaded() {
+			t.Fatalf("LoadedWorlds() includes unloaded world")
+		}
+		if !slices.Contains(srv.Worlds(), "event") {
+			t.Fatalf("Worlds() does not include unloaded world")
+		}
+		reloaded, ok := srv.WorldByName("event")
+		if !ok {
+			t.Fatalf("WorldByName() of unloaded world = false, want true")
+		}
+		if reloaded == w {
+			t.Fatalf("WorldByName() of unloaded world returned closed world")
+		}
+		if !loaded() {
+			t.Fatalf("LoadedWorlds() does not include reloaded world")
+		}
+	})
+	t.Run("portal", func(t *testing.T) {
+		unload()
+		// The destination of a portal is loaded in the background instead of
+		// during the transaction of the world that the portal is in.
+		if dest := srv.portalDestination("event"); dest != nil {
+			t.Fatalf("portalDestination() of unloaded world = %v, want nil", dest)
+		}
+		deadline := time.Now().Add(time.Second * 10)
+		for srv.portalDestination("event") == nil {
+			if time.Now().After(deadline) {
+				t.Fatalf("portalDestination() did not load world")
+			}
+			time.Sleep(time.Millisecond * 10)
+		}
+		if dest, _ := srv.WorldByName("event"); srv.portalDestination("event") != dest {
+			t.Fatalf("portalDestination() differs from WorldByName()")
+		}
+		if srv.portalDestination("nether") != srv.nether {
+			t.Fatalf("portalDestination(nether) is not the default nether")
+		}
+		if srv.portalDestination("unknown") != nil {
+			t.Fatalf("portalDestination() of unknown world is not nil")
+		}
+	})
+}
+
+func TestServerWorldsFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "worlds.json")
+	srv := newWorldsTestServer(t, Config{WorldsFile: file})
+
+	for _, conf := range []WorldConfig{
+		{Name: "hub", Generator: "void", Nether: "hub_nether"},
+		{Name: "hub_nether", Dimension: world.Nether, Generator: "void", Nether: "hub", UnloadWhenEmpty: true},
+	} {
+		if _, err := srv.AddWorld(conf); err != nil {
+			t.Fatalf("AddWorld(%v) = %v, want nil", conf.Name, err)
+		}
+	}
+
+	other := newWorldsTestServer(t, Config{WorldsFile: file})
+	if names, want := other.Worlds(), []string{"overworld", "nether", "end", "hub", "hub_nether"}; !slices.Equal(names, want) {
+		t.Fatalf("Worlds() = %v, want %v", names, want)
+	}
+	other.wmu.Lock()
+	hub, nether := other.worlds["hub"], other.worlds["hub_nether"]
+	other.wmu.Unlock()
+	if hub.w == nil || nether.w != nil {
+		t.Fatalf("worlds loaded = %v, %v, want true, false", hub.w != nil, nether.w != nil)
+	}
+	if nether.conf.Dimension != world.Nether || nether.conf.Nether != "hub" {
+		t.Fatalf("stored WorldConfig = %+v, want nether linked to hub", nether.conf)
+	}
+}